@@ -42,7 +42,9 @@ import (
 	"github.com/spf13/viper"
 	"k8s.io/client-go/kubernetes"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 )
 
 const (
@@ -61,8 +63,17 @@ const (
 	OperatorNamespaceFlag   = "operator-namespace"
 	WebhookSecretFlag       = "webhook-secret"
 	WebhookPodsLabelFlag    = "webhook-pods-label"
+	WebhookPortFlag         = "webhook-port"
+	WebhookCertDirFlag      = "webhook-cert-dir"
+
+	EnableConversionWebhookFlag = "enable-conversion-webhook"
+
+	DefaultWebhookPort    = 9876
+	DefaultWebhookCertDir = "/tmp/cert"
 
 	DebugHTTPServerListenAddressFlag = "debug-http-listen"
+
+	conversionWebhookPath = "/convert"
 )
 
 var (
@@ -143,6 +154,21 @@ func init() {
 		"",
 		"k8s secret mounted into /tmp/cert to be used for webhook certificates",
 	)
+	Cmd.Flags().Int(
+		WebhookPortFlag,
+		DefaultWebhookPort,
+		"Port the webhook server listens on, used by the conversion webhook",
+	)
+	Cmd.Flags().String(
+		WebhookCertDirFlag,
+		DefaultWebhookCertDir,
+		"Directory containing the tls.crt and tls.key files used by the webhook server",
+	)
+	Cmd.Flags().Bool(
+		EnableConversionWebhookFlag,
+		false,
+		"Enables the CRD conversion webhook server. Requires a webhook certificate in the webhook cert dir and the CA bundle set in the CRDs conversion configuration",
+	)
 	Cmd.Flags().String(
 		DebugHTTPServerListenAddressFlag,
 		"localhost:6060",
@@ -225,6 +251,7 @@ func execute() {
 		log.Info("Exposing Prometheus metrics on /metrics", "port", metricsPort)
 	}
 	opts.MetricsBindAddress = fmt.Sprintf(":%d", metricsPort) // 0 to disable
+	opts.Port = viper.GetInt(WebhookPortFlag)
 
 	mgr, err := ctrl.NewManager(cfg, opts)
 	if err != nil {
@@ -260,6 +287,7 @@ func execute() {
 	params := operator.Parameters{
 		Dialer:            dialer,
 		OperatorNamespace: operatorNamespace,
+		ManagedNamespace:  viper.GetString(NamespaceFlagName),
 		OperatorInfo:      operatorInfo,
 		CACertRotation: certificates.RotationParams{
 			Validity:     caCertValidity,
@@ -312,6 +340,10 @@ func execute() {
 		}
 	}
 
+	if operator.HasRole(operator.WebhookServer, roles) && viper.GetBool(EnableConversionWebhookFlag) {
+		setupConversionWebhook(mgr)
+	}
+
 	// TODO (sabo): re-enable when webhooks are usable
 	// log.Info("Setting up webhooks")
 	// if err := webhook.AddToManager(mgr, roles, newWebhookParameters); err != nil {
@@ -329,6 +361,16 @@ func execute() {
 	}
 }
 
+// setupConversionWebhook registers the webhook converting resources between the v1alpha1 and v1beta1 versions.
+// The CRDs must be configured with a webhook conversion strategy pointing to the /convert path for it to be used.
+// The webhook server does not start without a certificate, which is why it is only set up when explicitly enabled.
+func setupConversionWebhook(mgr manager.Manager) {
+	log.Info("Setting up conversion webhook", "port", viper.GetInt(WebhookPortFlag))
+	server := mgr.GetWebhookServer()
+	server.CertDir = viper.GetString(WebhookCertDirFlag)
+	server.Register(conversionWebhookPath, &conversion.Webhook{})
+}
+
 // TODO (sabo): re-enable when webhooks are usable
 // func newWebhookParameters() (*webhook.Parameters, error) {
 // 	autoInstall := viper.GetBool(AutoInstallWebhooksFlag)
@@ -0,0 +1,100 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/apm/v1beta1"
+	commonv1alpha1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1alpha1"
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// betaFieldsAnnotation stores the v1beta1 fields that have no v1alpha1 equivalent.
+const betaFieldsAnnotation = "apm.k8s.elastic.co/v1beta1-fields"
+
+type betaFields struct {
+	AuthMode               commonv1beta1.AuthMode               `json:"authMode,omitempty"`
+	APIKeyExpiration       *metav1.Duration                     `json:"apiKeyExpiration,omitempty"`
+	CredentialRotation     commonv1beta1.CredentialRotationSpec `json:"credentialRotation,omitempty"`
+	LastCredentialRotation *metav1.Time                         `json:"lastCredentialRotation,omitempty"`
+	Conditions             commonv1beta1.Conditions             `json:"conditions,omitempty"`
+}
+
+var _ conversion.Convertible = &ApmServer{}
+
+// ConvertTo converts this ApmServer to the hub version.
+func (as *ApmServer) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.ApmServer)
+	dst.ObjectMeta = *as.ObjectMeta.DeepCopy()
+	dst.Spec = v1beta1.ApmServerSpec{
+		Version:          as.Spec.Version,
+		Image:            as.Spec.Image,
+		Count:            as.Spec.NodeCount,
+		Config:           commonv1alpha1.ConvertConfigToV1beta1(as.Spec.Config),
+		HTTP:             commonv1alpha1.ConvertHTTPConfigToV1beta1(as.Spec.HTTP),
+		ElasticsearchRef: commonv1alpha1.ConvertObjectSelectorToV1beta1(as.Spec.ElasticsearchRef),
+		PodTemplate:      *as.Spec.PodTemplate.DeepCopy(),
+		SecureSettings:   commonv1alpha1.ConvertSecretSourcesToV1beta1(as.Spec.SecureSettings),
+	}
+	dst.Status = v1beta1.ApmServerStatus{
+		ReconcilerStatus:      commonv1alpha1.ConvertReconcilerStatusToV1beta1(as.Status.ReconcilerStatus),
+		Health:                v1beta1.ApmServerHealth(as.Status.Health),
+		ExternalService:       as.Status.ExternalService,
+		SecretTokenSecretName: as.Status.SecretTokenSecretName,
+		Association:           commonv1beta1.AssociationStatus(as.Status.Association),
+	}
+
+	var beta betaFields
+	restored, err := commonv1alpha1.RestoreConversionData(&dst.ObjectMeta, betaFieldsAnnotation, &beta)
+	if err != nil {
+		return err
+	}
+	if restored {
+		dst.Spec.AuthMode = beta.AuthMode
+		dst.Spec.APIKeyExpiration = beta.APIKeyExpiration
+		dst.Spec.CredentialRotation = beta.CredentialRotation
+		dst.Status.LastCredentialRotation = beta.LastCredentialRotation
+		dst.Status.Conditions = beta.Conditions
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version to this ApmServer.
+func (as *ApmServer) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.ApmServer)
+	as.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	as.Spec = ApmServerSpec{
+		Version:          src.Spec.Version,
+		Image:            src.Spec.Image,
+		NodeCount:        src.Spec.Count,
+		Config:           commonv1alpha1.ConvertConfigFromV1beta1(src.Spec.Config),
+		HTTP:             commonv1alpha1.ConvertHTTPConfigFromV1beta1(src.Spec.HTTP),
+		ElasticsearchRef: commonv1alpha1.ConvertObjectSelectorFromV1beta1(src.Spec.ElasticsearchRef),
+		PodTemplate:      *src.Spec.PodTemplate.DeepCopy(),
+		SecureSettings:   commonv1alpha1.ConvertSecretSourcesFromV1beta1(src.Spec.SecureSettings),
+	}
+	as.Status = ApmServerStatus{
+		ReconcilerStatus:      commonv1alpha1.ConvertReconcilerStatusFromV1beta1(src.Status.ReconcilerStatus),
+		Health:                ApmServerHealth(src.Status.Health),
+		ExternalService:       src.Status.ExternalService,
+		SecretTokenSecretName: src.Status.SecretTokenSecretName,
+		Association:           commonv1alpha1.AssociationStatus(src.Status.Association),
+	}
+
+	beta := betaFields{
+		AuthMode:               src.Spec.AuthMode,
+		APIKeyExpiration:       src.Spec.APIKeyExpiration,
+		CredentialRotation:     src.Spec.CredentialRotation,
+		LastCredentialRotation: src.Status.LastCredentialRotation,
+		Conditions:             src.Status.Conditions,
+	}
+	if !reflect.DeepEqual(beta, betaFields{}) {
+		return commonv1alpha1.SaveConversionData(&as.ObjectMeta, betaFieldsAnnotation, beta)
+	}
+	return nil
+}
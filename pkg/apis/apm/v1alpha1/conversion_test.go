@@ -0,0 +1,65 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/apm/v1beta1"
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApmServer_ConvertFrom_ConvertTo(t *testing.T) {
+	lastRotation := metav1.NewTime(time.Unix(1570000000, 0))
+	beta := v1beta1.ApmServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "apm", Annotations: map[string]string{"a": "b"}},
+		Spec: v1beta1.ApmServerSpec{
+			Version:          "7.6.0",
+			Count:            2,
+			ElasticsearchRef: commonv1beta1.ObjectSelector{Name: "es"},
+			AuthMode:         commonv1beta1.APIKeyAuthMode,
+			APIKeyExpiration: &metav1.Duration{Duration: 24 * time.Hour},
+			CredentialRotation: commonv1beta1.CredentialRotationSpec{
+				Interval: &metav1.Duration{Duration: 720 * time.Hour},
+			},
+		},
+		Status: v1beta1.ApmServerStatus{
+			ReconcilerStatus: commonv1beta1.ReconcilerStatus{
+				AvailableNodes: 2,
+				Conditions: commonv1beta1.Conditions{{
+					Type:               commonv1beta1.ReadyCondition,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: lastRotation,
+				}},
+			},
+			Health:                 v1beta1.ApmServerGreen,
+			LastCredentialRotation: &lastRotation,
+		},
+	}
+
+	var alpha ApmServer
+	require.NoError(t, alpha.ConvertFrom(beta.DeepCopy()))
+	require.Equal(t, int32(2), alpha.Spec.NodeCount)
+	require.Equal(t, "es", alpha.Spec.ElasticsearchRef.Name)
+	require.Contains(t, alpha.Annotations, betaFieldsAnnotation)
+
+	var roundTripped v1beta1.ApmServer
+	require.NoError(t, alpha.ConvertTo(&roundTripped))
+	require.Equal(t, beta, roundTripped)
+}
+
+func TestApmServer_ConvertFrom_NoBetaFields(t *testing.T) {
+	beta := v1beta1.ApmServer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "apm"},
+		Spec:       v1beta1.ApmServerSpec{Version: "7.6.0", Count: 1},
+	}
+	var alpha ApmServer
+	require.NoError(t, alpha.ConvertFrom(beta.DeepCopy()))
+	require.NotContains(t, alpha.Annotations, betaFieldsAnnotation)
+}
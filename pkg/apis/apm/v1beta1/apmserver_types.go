@@ -33,6 +33,21 @@ type ApmServerSpec struct {
 	// If the namespace is not specified, the current resource namespace will be used.
 	ElasticsearchRef commonv1beta1.ObjectSelector `json:"elasticsearchRef,omitempty"`
 
+	// AuthMode is the way the APM Server authenticates to the Elasticsearch cluster referenced by ElasticsearchRef:
+	// with a file realm user (user), or with an API key created through the Elasticsearch API (apiKey).
+	// The API key requires APM Server 7.6 or above, and Elasticsearch 6.7 or above with TLS enabled on its HTTP
+	// layer. Defaults to user.
+	// +kubebuilder:validation:Enum=user;apiKey
+	AuthMode commonv1beta1.AuthMode `json:"authMode,omitempty"`
+
+	// APIKeyExpiration is the lifetime of the API key used in the apiKey auth mode. The key is replaced by a new
+	// one before it expires. Defaults to 168h.
+	APIKeyExpiration *metav1.Duration `json:"apiKeyExpiration,omitempty"`
+
+	// CredentialRotation rotates the credentials used to connect to the Elasticsearch cluster referenced by
+	// ElasticsearchRef on a schedule.
+	CredentialRotation commonv1beta1.CredentialRotationSpec `json:"credentialRotation,omitempty"`
+
 	// PodTemplate can be used to propagate configuration to APM Server pods.
 	// This allows specifying custom annotations, labels, environment variables,
 	// affinity, resources, etc. for the pods created from this spec.
@@ -69,6 +84,8 @@ type ApmServerStatus struct {
 	SecretTokenSecretName string `json:"secretTokenSecret,omitempty"`
 	// Association is the status of any auto-linking to Elasticsearch clusters.
 	Association commonv1beta1.AssociationStatus `json:"associationStatus,omitempty"`
+	// LastCredentialRotation is the time the credentials used to connect to Elasticsearch were last issued.
+	LastCredentialRotation *metav1.Time `json:"lastCredentialRotation,omitempty"`
 }
 
 // IsDegraded returns true if the current status is worse than the previous.
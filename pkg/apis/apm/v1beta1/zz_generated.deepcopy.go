@@ -10,6 +10,7 @@ package v1beta1
 
 import (
 	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -19,7 +20,7 @@ func (in *ApmServer) DeepCopyInto(out *ApmServer) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	if in.assocConf != nil {
 		in, out := &in.assocConf, &out.assocConf
 		*out = new(commonv1beta1.AssociationConf)
@@ -86,6 +87,12 @@ func (in *ApmServerSpec) DeepCopyInto(out *ApmServerSpec) {
 	}
 	in.HTTP.DeepCopyInto(&out.HTTP)
 	out.ElasticsearchRef = in.ElasticsearchRef
+	if in.APIKeyExpiration != nil {
+		in, out := &in.APIKeyExpiration, &out.APIKeyExpiration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	in.CredentialRotation.DeepCopyInto(&out.CredentialRotation)
 	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
 	if in.SecureSettings != nil {
 		in, out := &in.SecureSettings, &out.SecureSettings
@@ -109,7 +116,11 @@ func (in *ApmServerSpec) DeepCopy() *ApmServerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApmServerStatus) DeepCopyInto(out *ApmServerStatus) {
 	*out = *in
-	out.ReconcilerStatus = in.ReconcilerStatus
+	in.ReconcilerStatus.DeepCopyInto(&out.ReconcilerStatus)
+	if in.LastCredentialRotation != nil {
+		in, out := &in.LastCredentialRotation, &out.LastCredentialRotation
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApmServerStatus.
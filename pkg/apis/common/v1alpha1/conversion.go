@@ -0,0 +1,165 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The functions in this file convert the common types from and to their v1beta1 equivalent, which is the hub version
+// all the other versions are converted to and from.
+
+func ConvertObjectSelectorToV1beta1(in ObjectSelector) commonv1beta1.ObjectSelector {
+	return commonv1beta1.ObjectSelector(in)
+}
+
+func ConvertObjectSelectorFromV1beta1(in commonv1beta1.ObjectSelector) ObjectSelector {
+	return ObjectSelector(in)
+}
+
+// ConvertReconcilerStatusToV1beta1 converts the given status, which has no conditions. The conversion of each resource
+// restores the v1beta1 conditions from its conversion annotation.
+func ConvertReconcilerStatusToV1beta1(in ReconcilerStatus) commonv1beta1.ReconcilerStatus {
+	return commonv1beta1.ReconcilerStatus{AvailableNodes: in.AvailableNodes}
+}
+
+// ConvertReconcilerStatusFromV1beta1 converts the given status but its conditions, which the conversion of each resource
+// saves in its conversion annotation with SaveConversionData.
+func ConvertReconcilerStatusFromV1beta1(in commonv1beta1.ReconcilerStatus) ReconcilerStatus {
+	return ReconcilerStatus{AvailableNodes: in.AvailableNodes}
+}
+
+func ConvertConfigToV1beta1(in *Config) *commonv1beta1.Config {
+	if in == nil {
+		return nil
+	}
+	out := commonv1beta1.Config(*in.DeepCopy())
+	return &out
+}
+
+func ConvertConfigFromV1beta1(in *commonv1beta1.Config) *Config {
+	if in == nil {
+		return nil
+	}
+	out := Config(*in.DeepCopy())
+	return &out
+}
+
+func ConvertHTTPConfigToV1beta1(in HTTPConfig) commonv1beta1.HTTPConfig {
+	out := commonv1beta1.HTTPConfig{
+		Service: commonv1beta1.ServiceTemplate(*in.Service.DeepCopy()),
+		TLS: commonv1beta1.TLSOptions{
+			Certificate: commonv1beta1.SecretRef(in.TLS.Certificate),
+		},
+	}
+	if in.TLS.SelfSignedCertificate != nil {
+		out.TLS.SelfSignedCertificate = &commonv1beta1.SelfSignedCertificate{
+			Disabled: in.TLS.SelfSignedCertificate.Disabled,
+		}
+		for _, san := range in.TLS.SelfSignedCertificate.SubjectAlternativeNames {
+			out.TLS.SelfSignedCertificate.SubjectAlternativeNames = append(
+				out.TLS.SelfSignedCertificate.SubjectAlternativeNames, commonv1beta1.SubjectAlternativeName(san),
+			)
+		}
+	}
+	return out
+}
+
+func ConvertHTTPConfigFromV1beta1(in commonv1beta1.HTTPConfig) HTTPConfig {
+	out := HTTPConfig{
+		Service: ServiceTemplate(*in.Service.DeepCopy()),
+		TLS: TLSOptions{
+			Certificate: SecretRef(in.TLS.Certificate),
+		},
+	}
+	if in.TLS.SelfSignedCertificate != nil {
+		out.TLS.SelfSignedCertificate = &SelfSignedCertificate{
+			Disabled: in.TLS.SelfSignedCertificate.Disabled,
+		}
+		for _, san := range in.TLS.SelfSignedCertificate.SubjectAlternativeNames {
+			out.TLS.SelfSignedCertificate.SubjectAlternativeNames = append(
+				out.TLS.SelfSignedCertificate.SubjectAlternativeNames, SubjectAlternativeName(san),
+			)
+		}
+	}
+	return out
+}
+
+func ConvertSecretSourcesToV1beta1(in []SecretSource) []commonv1beta1.SecretSource {
+	if in == nil {
+		return nil
+	}
+	out := make([]commonv1beta1.SecretSource, 0, len(in))
+	for _, s := range in {
+		converted := commonv1beta1.SecretSource{SecretName: s.SecretName}
+		for _, e := range s.Entries {
+			converted.Entries = append(converted.Entries, commonv1beta1.KeyToPath(e))
+		}
+		out = append(out, converted)
+	}
+	return out
+}
+
+func ConvertSecretSourcesFromV1beta1(in []commonv1beta1.SecretSource) []SecretSource {
+	if in == nil {
+		return nil
+	}
+	out := make([]SecretSource, 0, len(in))
+	for _, s := range in {
+		converted := SecretSource{SecretName: s.SecretName}
+		for _, e := range s.Entries {
+			converted.Entries = append(converted.Entries, KeyToPath(e))
+		}
+		out = append(out, converted)
+	}
+	return out
+}
+
+func ConvertPodDisruptionBudgetToV1beta1(in *PodDisruptionBudgetTemplate) *commonv1beta1.PodDisruptionBudgetTemplate {
+	if in == nil {
+		return nil
+	}
+	out := commonv1beta1.PodDisruptionBudgetTemplate(*in.DeepCopy())
+	return &out
+}
+
+func ConvertPodDisruptionBudgetFromV1beta1(in *commonv1beta1.PodDisruptionBudgetTemplate) *PodDisruptionBudgetTemplate {
+	if in == nil {
+		return nil
+	}
+	out := PodDisruptionBudgetTemplate(*in.DeepCopy())
+	return &out
+}
+
+// SaveConversionData stores the given fields, which cannot be represented in the target version of a conversion,
+// in an annotation of the converted object so they are not lost on the way back.
+func SaveConversionData(meta *metav1.ObjectMeta, annotation string, fields interface{}) error {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[annotation] = string(data)
+	return nil
+}
+
+// RestoreConversionData restores the fields previously stored by SaveConversionData, and removes the annotation.
+// It returns false if there is nothing to restore.
+func RestoreConversionData(meta *metav1.ObjectMeta, annotation string, fields interface{}) (bool, error) {
+	data, exists := meta.Annotations[annotation]
+	if !exists {
+		return false, nil
+	}
+	delete(meta.Annotations, annotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	return true, json.Unmarshal([]byte(data), fields)
+}
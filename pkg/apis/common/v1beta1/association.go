@@ -5,6 +5,8 @@
 package v1beta1
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -39,13 +41,52 @@ type Associator interface {
 	SetAssociationConf(*AssociationConf)
 }
 
+// AuthMode is the way an associated object authenticates to Elasticsearch.
+type AuthMode string
+
+const (
+	// UserAuthMode authenticates with a file realm user created by the operator. This is the default.
+	UserAuthMode AuthMode = "user"
+	// APIKeyAuthMode authenticates with an API key created by the operator through the Elasticsearch API.
+	APIKeyAuthMode AuthMode = "apiKey"
+)
+
+// DefaultAPIKeyExpiration is the default lifetime of the API keys created for associated objects.
+var DefaultAPIKeyExpiration = 7 * 24 * time.Hour
+
+// APIKeyExpirationOrDefault returns the given API key lifetime, or the default one if not set.
+func APIKeyExpirationOrDefault(expiration *metav1.Duration) time.Duration {
+	if expiration == nil || expiration.Duration <= 0 {
+		return DefaultAPIKeyExpiration
+	}
+	return expiration.Duration
+}
+
+// CredentialRotationSpec is the rotation policy of the credentials an associated resource uses to connect to
+// Elasticsearch.
+type CredentialRotationSpec struct {
+	// Interval between two rotations of the credentials, for example 720h. The previous credentials stay valid
+	// until all the pods restarted with the new ones. Rotation is disabled if not set.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// GetInterval returns the rotation interval, zero if rotation is disabled.
+func (s CredentialRotationSpec) GetInterval() time.Duration {
+	if s.Interval == nil || s.Interval.Duration < 0 {
+		return 0
+	}
+	return s.Interval.Duration
+}
+
 // AssociationConf holds the association configuration of an Elasticsearch cluster.
 type AssociationConf struct {
 	AuthSecretName string `json:"authSecretName"`
 	AuthSecretKey  string `json:"authSecretKey"`
-	CACertProvided bool   `json:"caCertProvided"`
-	CASecretName   string `json:"caSecretName"`
-	URL            string `json:"url"`
+	// AuthMode is the way the credentials stored in the auth secret are used. Defaults to a user and password.
+	AuthMode       AuthMode `json:"authMode,omitempty"`
+	CACertProvided bool     `json:"caCertProvided"`
+	CASecretName   string   `json:"caSecretName"`
+	URL            string   `json:"url"`
 }
 
 // IsConfigured returns true if all the fields are set.
@@ -77,6 +118,14 @@ func (ac *AssociationConf) URLIsConfigured() bool {
 	return ac.URL != ""
 }
 
+// IsAPIKey returns true if the auth secret holds an API key, as `id:api_key`, rather than the password of a user.
+func (ac *AssociationConf) IsAPIKey() bool {
+	if ac == nil {
+		return false
+	}
+	return ac.AuthMode == APIKeyAuthMode
+}
+
 func (ac *AssociationConf) GetAuthSecretName() string {
 	if ac == nil {
 		return ""
@@ -17,6 +17,14 @@ import (
 // ReconcilerStatus represents status information about desired/available nodes.
 type ReconcilerStatus struct {
 	AvailableNodes int `json:"availableNodes,omitempty"`
+	// Conditions report the state of the resource: Ready, Reconciled, ConfigValid, Progressing, and AssociationReady
+	// for resources referencing an Elasticsearch cluster.
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
+	// RestartTrigger is the value of the `common.k8s.elastic.co/restart-trigger` annotation for which the rolling
+	// restart of all pods completed.
+	// +optional
+	RestartTrigger string `json:"restartTrigger,omitempty"`
 }
 
 // SecretRef reference a secret by name.
@@ -0,0 +1,108 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionType is the type of a status condition.
+type ConditionType string
+
+const (
+	// ReadyCondition is true when the resource is available and serving at the desired spec.
+	ReadyCondition ConditionType = "Ready"
+	// ReconciledCondition is true when the last reconciliation of the resource completed without error.
+	ReconciledCondition ConditionType = "Reconciled"
+	// AssociationReadyCondition is true when the associations of the resource with Elasticsearch are established.
+	// It is only reported for resources referencing an Elasticsearch cluster.
+	AssociationReadyCondition ConditionType = "AssociationReady"
+	// ConfigValidCondition is true when the spec of the resource passed validation.
+	ConfigValidCondition ConditionType = "ConfigValid"
+	// ProgressingCondition is true while changes to the spec of the resource are being rolled out.
+	ProgressingCondition ConditionType = "Progressing"
+)
+
+// Condition describes an aspect of the state of a resource at a point in time.
+type Condition struct {
+	// Type of the condition.
+	Type ConditionType `json:"type"`
+	// Status of the condition, one of True, False or Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// ObservedGeneration is the generation of the resource the condition was computed from.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastTransitionTime is the last time the condition changed from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a CamelCase reason for the last transition of the condition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable message with details about the last transition of the condition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// Conditions is a list of status conditions, with at most one condition per type.
+type Conditions []Condition
+
+// Get returns the condition of the given type, or nil if there is none.
+func (c Conditions) Get(t ConditionType) *Condition {
+	for i := range c {
+		if c[i].Type == t {
+			return &c[i]
+		}
+	}
+	return nil
+}
+
+// IsTrue returns true if the condition of the given type exists and is true.
+func (c Conditions) IsTrue(t ConditionType) bool {
+	condition := c.Get(t)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// Set returns the conditions with the given one added, or replacing the existing one of the same type.
+// The last transition time of an existing condition is kept if its status does not change, and set to now otherwise
+// unless the given condition specifies one.
+func (c Conditions) Set(condition Condition) Conditions {
+	if existing := c.Get(condition.Type); existing != nil && existing.Status == condition.Status {
+		condition.LastTransitionTime = existing.LastTransitionTime
+	}
+	if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = metav1.Now()
+	}
+	result := make(Conditions, 0, len(c)+1)
+	replaced := false
+	for _, existing := range c {
+		if existing.Type == condition.Type {
+			existing = condition
+			replaced = true
+		}
+		result = append(result, existing)
+	}
+	if !replaced {
+		result = append(result, condition)
+	}
+	return result
+}
+
+// Remove returns the conditions without the one of the given type.
+func (c Conditions) Remove(t ConditionType) Conditions {
+	if c.Get(t) == nil {
+		return c
+	}
+	result := make(Conditions, 0, len(c)-1)
+	for _, existing := range c {
+		if existing.Type != t {
+			result = append(result, existing)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
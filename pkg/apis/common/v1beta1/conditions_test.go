@@ -0,0 +1,53 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConditions_Set(t *testing.T) {
+	past := metav1.NewTime(time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC))
+	existing := Conditions{
+		{Type: ReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: past, Reason: "Available"},
+		{Type: ReconciledCondition, Status: corev1.ConditionTrue, LastTransitionTime: past},
+	}
+
+	// same status: the transition time is kept, other fields are updated
+	updated := existing.Set(Condition{Type: ReadyCondition, Status: corev1.ConditionTrue, ObservedGeneration: 2, Reason: "Other"})
+	require.Len(t, updated, 2)
+	require.Equal(t, past, updated.Get(ReadyCondition).LastTransitionTime)
+	require.Equal(t, int64(2), updated.Get(ReadyCondition).ObservedGeneration)
+	require.Equal(t, "Other", updated.Get(ReadyCondition).Reason)
+	// the original conditions are not modified
+	require.Equal(t, "Available", existing.Get(ReadyCondition).Reason)
+
+	// status change: the transition time is updated
+	updated = existing.Set(Condition{Type: ReadyCondition, Status: corev1.ConditionFalse})
+	require.True(t, updated.Get(ReadyCondition).LastTransitionTime.After(past.Time))
+	require.False(t, updated.IsTrue(ReadyCondition))
+	require.True(t, updated.IsTrue(ReconciledCondition))
+
+	// new condition: appended
+	updated = existing.Set(Condition{Type: ProgressingCondition, Status: corev1.ConditionTrue})
+	require.Len(t, updated, 3)
+	require.True(t, updated.IsTrue(ProgressingCondition))
+	require.False(t, updated.Get(ProgressingCondition).LastTransitionTime.IsZero())
+}
+
+func TestConditions_Remove(t *testing.T) {
+	c := Conditions{{Type: ReadyCondition}, {Type: AssociationReadyCondition}}
+	require.Equal(t, c, c.Remove(ProgressingCondition))
+	c = c.Remove(AssociationReadyCondition)
+	require.Equal(t, Conditions{{Type: ReadyCondition}}, c)
+	require.Nil(t, c.Remove(ReadyCondition))
+	require.Nil(t, c.Get(AssociationReadyCondition))
+	require.False(t, c.IsTrue(AssociationReadyCondition))
+}
@@ -0,0 +1,164 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	commonv1alpha1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1alpha1"
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// betaFieldsAnnotation stores the v1beta1 fields that cannot be represented exactly in v1alpha1.
+const betaFieldsAnnotation = "elasticsearch.k8s.elastic.co/v1beta1-fields"
+
+type betaFields struct {
+	// ChangeBudget is the v1beta1 change budget, whose nil values have no v1alpha1 equivalent.
+	ChangeBudget v1beta1.ChangeBudget `json:"changeBudget,omitempty"`
+	// Snapshots has no v1alpha1 equivalent.
+	Snapshots *v1beta1.SnapshotsSpec `json:"snapshots,omitempty"`
+	// IndexManagement has no v1alpha1 equivalent.
+	IndexManagement *v1beta1.IndexManagementSpec `json:"indexManagement,omitempty"`
+	// RemoteClusters has no v1alpha1 equivalent.
+	RemoteClusters []v1beta1.RemoteCluster `json:"remoteClusters,omitempty"`
+	// TopologyAwareness has no v1alpha1 equivalent.
+	TopologyAwareness *v1beta1.TopologyAwareness `json:"topologyAwareness,omitempty"`
+	// Conditions has no v1alpha1 equivalent.
+	Conditions commonv1beta1.Conditions `json:"conditions,omitempty"`
+}
+
+var _ conversion.Convertible = &Elasticsearch{}
+
+// ConvertTo converts this Elasticsearch to the hub version.
+// The v1alpha1 nodes are converted to v1beta1 nodeSets. Status fields that do not exist anymore in v1beta1 are dropped.
+func (e *Elasticsearch) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.Elasticsearch)
+	dst.ObjectMeta = *e.ObjectMeta.DeepCopy()
+	dst.Spec = v1beta1.ElasticsearchSpec{
+		Version: e.Spec.Version,
+		Image:   e.Spec.Image,
+		HTTP:    commonv1alpha1.ConvertHTTPConfigToV1beta1(e.Spec.HTTP),
+		UpdateStrategy: v1beta1.UpdateStrategy{
+			ChangeBudget: changeBudgetToV1beta1(e.Spec.UpdateStrategy.ChangeBudget),
+		},
+		PodDisruptionBudget: commonv1alpha1.ConvertPodDisruptionBudgetToV1beta1(e.Spec.PodDisruptionBudget),
+		SecureSettings:      commonv1alpha1.ConvertSecretSourcesToV1beta1(e.Spec.SecureSettings),
+	}
+	for _, n := range e.Spec.Nodes {
+		dst.Spec.NodeSets = append(dst.Spec.NodeSets, v1beta1.NodeSet{
+			Name:                 n.Name,
+			Config:               commonv1alpha1.ConvertConfigToV1beta1(n.Config),
+			Count:                n.NodeCount,
+			PodTemplate:          *n.PodTemplate.DeepCopy(),
+			VolumeClaimTemplates: copyClaims(n.VolumeClaimTemplates),
+		})
+	}
+	dst.Status = v1beta1.ElasticsearchStatus{
+		ReconcilerStatus: commonv1alpha1.ConvertReconcilerStatusToV1beta1(e.Status.ReconcilerStatus),
+		Health:           v1beta1.ElasticsearchHealth(e.Status.Health),
+		Phase:            v1beta1.ElasticsearchOrchestrationPhase(e.Status.Phase),
+	}
+
+	var beta betaFields
+	restored, err := commonv1alpha1.RestoreConversionData(&dst.ObjectMeta, betaFieldsAnnotation, &beta)
+	if err != nil {
+		return err
+	}
+	// only restore the exact v1beta1 change budget if it was not modified through v1alpha1 in the meantime
+	if restored && reflect.DeepEqual(changeBudgetFromV1beta1(beta.ChangeBudget), e.Spec.UpdateStrategy.ChangeBudget) {
+		dst.Spec.UpdateStrategy.ChangeBudget = beta.ChangeBudget
+	}
+	dst.Spec.Snapshots = beta.Snapshots
+	dst.Spec.IndexManagement = beta.IndexManagement
+	dst.Spec.RemoteClusters = beta.RemoteClusters
+	dst.Spec.TopologyAwareness = beta.TopologyAwareness
+	dst.Status.Conditions = beta.Conditions
+	return nil
+}
+
+// ConvertFrom converts the hub version to this Elasticsearch.
+func (e *Elasticsearch) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.Elasticsearch)
+	e.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	e.Spec = ElasticsearchSpec{
+		Version: src.Spec.Version,
+		Image:   src.Spec.Image,
+		HTTP:    commonv1alpha1.ConvertHTTPConfigFromV1beta1(src.Spec.HTTP),
+		UpdateStrategy: UpdateStrategy{
+			ChangeBudget: changeBudgetFromV1beta1(src.Spec.UpdateStrategy.ChangeBudget),
+		},
+		PodDisruptionBudget: commonv1alpha1.ConvertPodDisruptionBudgetFromV1beta1(src.Spec.PodDisruptionBudget),
+		SecureSettings:      commonv1alpha1.ConvertSecretSourcesFromV1beta1(src.Spec.SecureSettings),
+	}
+	for _, n := range src.Spec.NodeSets {
+		e.Spec.Nodes = append(e.Spec.Nodes, NodeSpec{
+			Name:                 n.Name,
+			Config:               commonv1alpha1.ConvertConfigFromV1beta1(n.Config),
+			NodeCount:            n.Count,
+			PodTemplate:          *n.PodTemplate.DeepCopy(),
+			VolumeClaimTemplates: copyClaims(n.VolumeClaimTemplates),
+		})
+	}
+	e.Status = ElasticsearchStatus{
+		ReconcilerStatus: commonv1alpha1.ConvertReconcilerStatusFromV1beta1(src.Status.ReconcilerStatus),
+		Health:           ElasticsearchHealth(src.Status.Health),
+		Phase:            ElasticsearchOrchestrationPhase(src.Status.Phase),
+	}
+
+	if src.Spec.UpdateStrategy.ChangeBudget != (v1beta1.ChangeBudget{}) || src.Spec.Snapshots != nil ||
+		src.Spec.IndexManagement != nil || len(src.Spec.RemoteClusters) > 0 || src.Spec.TopologyAwareness != nil ||
+		len(src.Status.Conditions) > 0 {
+		return commonv1alpha1.SaveConversionData(&e.ObjectMeta, betaFieldsAnnotation, betaFields{
+			ChangeBudget:      src.Spec.UpdateStrategy.ChangeBudget,
+			Snapshots:         src.Spec.Snapshots,
+			IndexManagement:   src.Spec.IndexManagement,
+			RemoteClusters:    src.Spec.RemoteClusters,
+			TopologyAwareness: src.Spec.TopologyAwareness,
+			Conditions:        src.Status.Conditions,
+		})
+	}
+	return nil
+}
+
+func changeBudgetToV1beta1(in *ChangeBudget) v1beta1.ChangeBudget {
+	if in == nil {
+		return v1beta1.ChangeBudget{}
+	}
+	maxSurge, maxUnavailable := int32(in.MaxSurge), int32(in.MaxUnavailable)
+	return v1beta1.ChangeBudget{
+		MaxSurge:       &maxSurge,
+		MaxUnavailable: &maxUnavailable,
+	}
+}
+
+// changeBudgetFromV1beta1 converts a v1beta1 change budget to v1alpha1. Unset values, which mean the v1beta1 default,
+// are converted to zero.
+func changeBudgetFromV1beta1(in v1beta1.ChangeBudget) *ChangeBudget {
+	if in == (v1beta1.ChangeBudget{}) {
+		return nil
+	}
+	out := ChangeBudget{}
+	if in.MaxSurge != nil {
+		out.MaxSurge = int(*in.MaxSurge)
+	}
+	if in.MaxUnavailable != nil {
+		out.MaxUnavailable = int(*in.MaxUnavailable)
+	}
+	return &out
+}
+
+func copyClaims(in []corev1.PersistentVolumeClaim) []corev1.PersistentVolumeClaim {
+	if in == nil {
+		return nil
+	}
+	out := make([]corev1.PersistentVolumeClaim, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestElasticsearch_ConvertFrom_ConvertTo(t *testing.T) {
+	tests := []struct {
+		name         string
+		changeBudget v1beta1.ChangeBudget
+		snapshots    *v1beta1.SnapshotsSpec
+		indices      *v1beta1.IndexManagementSpec
+		remotes      []v1beta1.RemoteCluster
+		topology     *v1beta1.TopologyAwareness
+		conditions   commonv1beta1.Conditions
+		modify       func(es *Elasticsearch)
+		want         v1beta1.ChangeBudget
+	}{
+		{
+			name: "no change budget",
+		},
+		{
+			name:         "partial change budget is restored",
+			changeBudget: v1beta1.ChangeBudget{MaxSurge: int32Ptr(2)},
+			want:         v1beta1.ChangeBudget{MaxSurge: int32Ptr(2)},
+		},
+		{
+			name:         "change budget modified in v1alpha1",
+			changeBudget: v1beta1.ChangeBudget{MaxSurge: int32Ptr(2)},
+			modify: func(es *Elasticsearch) {
+				es.Spec.UpdateStrategy.ChangeBudget.MaxUnavailable = 1
+			},
+			want: v1beta1.ChangeBudget{MaxSurge: int32Ptr(2), MaxUnavailable: int32Ptr(1)},
+		},
+		{
+			name: "snapshots are restored",
+			snapshots: &v1beta1.SnapshotsSpec{
+				Repository: v1beta1.SnapshotRepository{Type: "fs"},
+				Policy:     &v1beta1.SnapshotPolicy{Schedule: "0 30 1 * * ?"},
+			},
+		},
+		{
+			name: "index management is restored",
+			indices: &v1beta1.IndexManagementSpec{
+				IngestPipelines: []v1beta1.IndexManagementObject{{Name: "pipeline"}},
+			},
+		},
+		{
+			name: "remote clusters are restored",
+			remotes: []v1beta1.RemoteCluster{
+				{Name: "eu", ElasticsearchRef: commonv1beta1.ObjectSelector{Name: "es-eu", Namespace: "eu"}},
+			},
+		},
+		{
+			name:     "topology awareness is restored",
+			topology: &v1beta1.TopologyAwareness{TopologyKey: "rack"},
+		},
+		{
+			name: "conditions are restored",
+			conditions: commonv1beta1.Conditions{{
+				Type:               commonv1beta1.ReadyCondition,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(time.Unix(1570000000, 0)),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			beta := v1beta1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+				Spec: v1beta1.ElasticsearchSpec{
+					Version:           "7.4.0",
+					NodeSets:          []v1beta1.NodeSet{{Name: "default", Count: 3}},
+					UpdateStrategy:    v1beta1.UpdateStrategy{ChangeBudget: tt.changeBudget},
+					Snapshots:         tt.snapshots,
+					IndexManagement:   tt.indices,
+					RemoteClusters:    tt.remotes,
+					TopologyAwareness: tt.topology,
+				},
+				Status: v1beta1.ElasticsearchStatus{
+					ReconcilerStatus: commonv1beta1.ReconcilerStatus{Conditions: tt.conditions},
+					Health:           v1beta1.ElasticsearchGreenHealth,
+				},
+			}
+
+			var alpha Elasticsearch
+			require.NoError(t, alpha.ConvertFrom(beta.DeepCopy()))
+			require.Equal(t, []NodeSpec{{Name: "default", NodeCount: 3}}, alpha.Spec.Nodes)
+			if tt.modify != nil {
+				tt.modify(&alpha)
+			}
+
+			var roundTripped v1beta1.Elasticsearch
+			require.NoError(t, alpha.ConvertTo(&roundTripped))
+			expected := beta.DeepCopy()
+			expected.Spec.UpdateStrategy.ChangeBudget = tt.want
+			require.Equal(t, *expected, roundTripped)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1beta1
+
+// Hub marks v1beta1 as the version all the other Elasticsearch versions are converted to and from.
+func (*Elasticsearch) Hub() {}
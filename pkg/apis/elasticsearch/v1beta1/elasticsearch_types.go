@@ -45,6 +45,27 @@ type ElasticsearchSpec struct {
 	// entries and the `path` field to change the target path of a secret entry key.
 	// The secret must exist in the same namespace as the Elasticsearch resource.
 	SecureSettings []commonv1beta1.SecretSource `json:"secureSettings,omitempty"`
+
+	// Snapshots registers a snapshot repository and optionally schedules snapshots to it with a snapshot lifecycle
+	// management policy.
+	// +kubebuilder:validation:Optional
+	Snapshots *SnapshotsSpec `json:"snapshots,omitempty"`
+
+	// IndexManagement declares index lifecycle management policies, index templates and ingest pipelines, created and
+	// kept up to date in Elasticsearch.
+	// +kubebuilder:validation:Optional
+	IndexManagement *IndexManagementSpec `json:"indexManagement,omitempty"`
+
+	// RemoteClusters declares other Elasticsearch clusters managed by the operator as remote clusters of this one.
+	// The transport certificate authorities are trusted both ways, and the remote clusters are configured through
+	// the `cluster.remote.*` settings.
+	// +kubebuilder:validation:Optional
+	RemoteClusters []RemoteCluster `json:"remoteClusters,omitempty"`
+
+	// TopologyAwareness exposes the zone of the Kubernetes node of each Elasticsearch pod as the `zone` node attribute,
+	// and enables shard allocation awareness on it.
+	// +kubebuilder:validation:Optional
+	TopologyAwareness *TopologyAwareness `json:"topologyAwareness,omitempty"`
 }
 
 // NodeCount returns the total number of nodes of the Elasticsearch cluster
@@ -197,6 +218,12 @@ type ElasticsearchStatus struct {
 	commonv1beta1.ReconcilerStatus `json:",inline"`
 	Health                         ElasticsearchHealth             `json:"health,omitempty"`
 	Phase                          ElasticsearchOrchestrationPhase `json:"phase,omitempty"`
+	// Snapshots is the status of the snapshot lifecycle management policy, if any.
+	Snapshots *SnapshotsStatus `json:"snapshots,omitempty"`
+	// IndexManagement is the status of the objects declared in the index management specification.
+	IndexManagement []IndexManagementObjectStatus `json:"indexManagement,omitempty"`
+	// RemoteClusters is the connection status of the remote clusters declared in the specification.
+	RemoteClusters []RemoteClusterStatus `json:"remoteClusters,omitempty"`
 }
 
 type ZenDiscoveryStatus struct {
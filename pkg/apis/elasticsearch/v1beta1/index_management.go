@@ -0,0 +1,73 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1beta1
+
+import (
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IndexManagementKind is the kind of an Elasticsearch object managed through the index management specification.
+type IndexManagementKind string
+
+const (
+	// ILMPolicyKind is an index lifecycle management policy.
+	ILMPolicyKind IndexManagementKind = "ILMPolicy"
+	// ComponentTemplateKind is a component template, a building block of index templates.
+	ComponentTemplateKind IndexManagementKind = "ComponentTemplate"
+	// IndexTemplateKind is a composable index template.
+	IndexTemplateKind IndexManagementKind = "IndexTemplate"
+	// IngestPipelineKind is an ingest pipeline.
+	IngestPipelineKind IndexManagementKind = "IngestPipeline"
+)
+
+// IndexManagementSpec declares index lifecycle management policies, index templates and ingest pipelines to create
+// in Elasticsearch. Objects removed from the specification are deleted from Elasticsearch.
+type IndexManagementSpec struct {
+	// ILMPolicies are index lifecycle management policies. The body is the body of the create policy API request,
+	// holding the policy.
+	ILMPolicies []IndexManagementObject `json:"ilmPolicies,omitempty"`
+
+	// ComponentTemplates are component templates, which index templates are composed of. The body is the body of the
+	// put component template API request. Requires Elasticsearch 7.8.0 or above.
+	ComponentTemplates []IndexManagementObject `json:"componentTemplates,omitempty"`
+
+	// IndexTemplates are composable index templates. The body is the body of the put index template API request.
+	// Requires Elasticsearch 7.8.0 or above.
+	IndexTemplates []IndexManagementObject `json:"indexTemplates,omitempty"`
+
+	// IngestPipelines are ingest pipelines. The body is the body of the put pipeline API request.
+	IngestPipelines []IndexManagementObject `json:"ingestPipelines,omitempty"`
+}
+
+// IndexManagementObject is an Elasticsearch object declared in the index management specification.
+type IndexManagementObject struct {
+	// Name of the object in Elasticsearch.
+	Name string `json:"name"`
+
+	// Body of the Elasticsearch API request creating the object.
+	Body *commonv1beta1.Config `json:"body"`
+}
+
+// IndexManagementObjectStatus is the observed state of an Elasticsearch object declared in the index management
+// specification.
+type IndexManagementObjectStatus struct {
+	Kind IndexManagementKind `json:"kind"`
+	Name string              `json:"name"`
+
+	// Ready is true if the object in Elasticsearch matches its specification.
+	Ready bool `json:"ready"`
+
+	// Message explains why the object is not ready.
+	Message string `json:"message,omitempty"`
+
+	// LastUpdateTime is the last time the object was created or updated in Elasticsearch, to apply a change of its
+	// specification or to revert a change made outside of the operator.
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// AppliedBodyHash is a hash of the body last applied to Elasticsearch. The object is updated whenever its body
+	// changes, so that the fields removed from the body are removed from the object.
+	AppliedBodyHash string `json:"appliedBodyHash,omitempty"`
+}
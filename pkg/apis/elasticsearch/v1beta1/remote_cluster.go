@@ -0,0 +1,42 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1beta1
+
+import (
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RemoteCluster declares another Elasticsearch cluster managed by the operator as a remote cluster, to be used for
+// cross-cluster search and cross-cluster replication.
+type RemoteCluster struct {
+	// Name is the alias of the remote cluster, used to reference it in requests, such as `name:index` in searches.
+	Name string `json:"name"`
+
+	// ElasticsearchRef references the remote Elasticsearch resource. The namespace defaults to the namespace of this
+	// Elasticsearch resource.
+	ElasticsearchRef commonv1beta1.ObjectSelector `json:"elasticsearchRef"`
+}
+
+// NamespacedName returns the namespaced name of the remote Elasticsearch resource, relative to the given namespace.
+func (r RemoteCluster) NamespacedName(namespace string) types.NamespacedName {
+	nsn := r.ElasticsearchRef.NamespacedName()
+	if nsn.Namespace == "" {
+		nsn.Namespace = namespace
+	}
+	return nsn
+}
+
+// RemoteClusterStatus is the connection status of a remote cluster, as reported by Elasticsearch.
+type RemoteClusterStatus struct {
+	// Name is the alias of the remote cluster.
+	Name string `json:"name"`
+	// Connected is true if at least one node of the remote cluster is connected.
+	Connected bool `json:"connected"`
+	// NumNodesConnected is the number of remote nodes connected.
+	NumNodesConnected int `json:"numNodesConnected,omitempty"`
+	// Message explains why the remote cluster is not configured, if any.
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,94 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1beta1
+
+import (
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultSnapshotRepositoryName is the name of the snapshot repository if none is specified.
+const DefaultSnapshotRepositoryName = "default"
+
+// SnapshotsSpec defines the snapshot repository of an Elasticsearch cluster and the policy taking snapshots to it.
+type SnapshotsSpec struct {
+	// Repository is the snapshot repository registered in Elasticsearch.
+	Repository SnapshotRepository `json:"repository"`
+
+	// Policy schedules snapshots to the repository. No snapshot is taken automatically if not set.
+	// +kubebuilder:validation:Optional
+	Policy *SnapshotPolicy `json:"policy,omitempty"`
+}
+
+// SnapshotRepository defines an Elasticsearch snapshot repository.
+type SnapshotRepository struct {
+	// Name of the repository. Defaults to `default`.
+	Name string `json:"name,omitempty"`
+
+	// Type of the repository, for example `fs`, `s3`, `gcs` or `azure`. Repository types other than `fs` and `url`
+	// require the matching plugin to be installed.
+	Type string `json:"type"`
+
+	// Settings of the repository, for example the bucket or the location. Credentials must not be set here, but
+	// provided as secure settings of the Elasticsearch keystore through `spec.secureSettings`.
+	Settings *commonv1beta1.Config `json:"settings,omitempty"`
+}
+
+// RepositoryName returns the name of the repository, or the default one if not set.
+func (r SnapshotRepository) RepositoryName() string {
+	if r.Name == "" {
+		return DefaultSnapshotRepositoryName
+	}
+	return r.Name
+}
+
+// SnapshotPolicy defines the snapshot lifecycle management policy taking snapshots to the repository.
+type SnapshotPolicy struct {
+	// Schedule of the snapshots, as an Elasticsearch cron expression, for example `0 30 1 * * ?`.
+	Schedule string `json:"schedule"`
+
+	// SnapshotName is the name of the snapshots, supporting date math. Defaults to `<{name}-snap-{now/d}>` with the
+	// name of the Elasticsearch resource.
+	SnapshotName string `json:"snapshotName,omitempty"`
+
+	// Indices included in the snapshots. Defaults to all the indices.
+	Indices []string `json:"indices,omitempty"`
+
+	// Retention of the snapshots taken by the policy. Snapshots are kept forever if not set.
+	// +kubebuilder:validation:Optional
+	Retention *SnapshotRetention `json:"retention,omitempty"`
+}
+
+// SnapshotRetention defines how long the snapshots taken by a policy are kept.
+type SnapshotRetention struct {
+	// ExpireAfter is the time after which snapshots are deleted, for example `30d`.
+	ExpireAfter string `json:"expireAfter,omitempty"`
+
+	// MinCount is the minimum number of snapshots kept, even if they expired.
+	MinCount *int32 `json:"minCount,omitempty"`
+
+	// MaxCount is the maximum number of snapshots kept, even if they did not expire.
+	MaxCount *int32 `json:"maxCount,omitempty"`
+}
+
+// SnapshotsStatus is the observed state of the snapshot repository and lifecycle management policy.
+type SnapshotsStatus struct {
+	// Repository is the name of the snapshot repository registered by the operator, unregistered once removed from
+	// the specification or renamed.
+	Repository string `json:"repository,omitempty"`
+
+	// Policy is the name of the snapshot lifecycle management policy created by the operator, deleted once removed
+	// from the specification.
+	Policy string `json:"policy,omitempty"`
+
+	// LastSuccessfulSnapshot is the name of the last snapshot taken successfully by the policy.
+	LastSuccessfulSnapshot string `json:"lastSuccessfulSnapshot,omitempty"`
+
+	// LastSuccessTime is the time the last successful snapshot was taken.
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+
+	// LastFailure is the reason of the last failed snapshot, if it failed after the last successful one.
+	LastFailure string `json:"lastFailure,omitempty"`
+}
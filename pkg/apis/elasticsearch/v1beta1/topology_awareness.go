@@ -0,0 +1,30 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1beta1
+
+const (
+	// TopologyZoneLabel is the well-known label holding the zone of a Kubernetes node.
+	TopologyZoneLabel = "topology.kubernetes.io/zone"
+	// FailureDomainZoneLabel is the deprecated label holding the zone of a Kubernetes node, set by older clusters.
+	FailureDomainZoneLabel = "failure-domain.beta.kubernetes.io/zone"
+)
+
+// TopologyAwareness makes Elasticsearch aware of the zone of the Kubernetes nodes its pods are scheduled on.
+// The zone is exposed as the `zone` node attribute and used for shard allocation awareness, so that copies of the same
+// shard are spread across zones.
+type TopologyAwareness struct {
+	// TopologyKey is the label of the Kubernetes nodes holding their zone. Defaults to `topology.kubernetes.io/zone`,
+	// falling back to `failure-domain.beta.kubernetes.io/zone`.
+	// +kubebuilder:validation:Optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+}
+
+// TopologyKeys returns the labels of the Kubernetes node to read the zone from, by order of preference.
+func (t TopologyAwareness) TopologyKeys() []string {
+	if t.TopologyKey != "" {
+		return []string{t.TopologyKey}
+	}
+	return []string{TopologyZoneLabel, FailureDomainZoneLabel}
+}
@@ -0,0 +1,90 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	commonv1alpha1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1alpha1"
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/kibana/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// betaFieldsAnnotation stores the v1beta1 fields that have no v1alpha1 equivalent.
+const betaFieldsAnnotation = "kibana.k8s.elastic.co/v1beta1-fields"
+
+type betaFields struct {
+	CredentialRotation     commonv1beta1.CredentialRotationSpec `json:"credentialRotation,omitempty"`
+	LastCredentialRotation *metav1.Time                         `json:"lastCredentialRotation,omitempty"`
+	Conditions             commonv1beta1.Conditions             `json:"conditions,omitempty"`
+}
+
+var _ conversion.Convertible = &Kibana{}
+
+// ConvertTo converts this Kibana to the hub version.
+func (k *Kibana) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.Kibana)
+	dst.ObjectMeta = *k.ObjectMeta.DeepCopy()
+	dst.Spec = v1beta1.KibanaSpec{
+		Version:          k.Spec.Version,
+		Image:            k.Spec.Image,
+		Count:            k.Spec.NodeCount,
+		ElasticsearchRef: commonv1alpha1.ConvertObjectSelectorToV1beta1(k.Spec.ElasticsearchRef),
+		Config:           commonv1alpha1.ConvertConfigToV1beta1(k.Spec.Config),
+		HTTP:             commonv1alpha1.ConvertHTTPConfigToV1beta1(k.Spec.HTTP),
+		PodTemplate:      *k.Spec.PodTemplate.DeepCopy(),
+		SecureSettings:   commonv1alpha1.ConvertSecretSourcesToV1beta1(k.Spec.SecureSettings),
+	}
+	dst.Status = v1beta1.KibanaStatus{
+		ReconcilerStatus:  commonv1alpha1.ConvertReconcilerStatusToV1beta1(k.Status.ReconcilerStatus),
+		Health:            v1beta1.KibanaHealth(k.Status.Health),
+		AssociationStatus: commonv1beta1.AssociationStatus(k.Status.AssociationStatus),
+	}
+
+	var beta betaFields
+	restored, err := commonv1alpha1.RestoreConversionData(&dst.ObjectMeta, betaFieldsAnnotation, &beta)
+	if err != nil {
+		return err
+	}
+	if restored {
+		dst.Spec.CredentialRotation = beta.CredentialRotation
+		dst.Status.LastCredentialRotation = beta.LastCredentialRotation
+		dst.Status.Conditions = beta.Conditions
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version to this Kibana.
+func (k *Kibana) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.Kibana)
+	k.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	k.Spec = KibanaSpec{
+		Version:          src.Spec.Version,
+		Image:            src.Spec.Image,
+		NodeCount:        src.Spec.Count,
+		ElasticsearchRef: commonv1alpha1.ConvertObjectSelectorFromV1beta1(src.Spec.ElasticsearchRef),
+		Config:           commonv1alpha1.ConvertConfigFromV1beta1(src.Spec.Config),
+		HTTP:             commonv1alpha1.ConvertHTTPConfigFromV1beta1(src.Spec.HTTP),
+		PodTemplate:      *src.Spec.PodTemplate.DeepCopy(),
+		SecureSettings:   commonv1alpha1.ConvertSecretSourcesFromV1beta1(src.Spec.SecureSettings),
+	}
+	k.Status = KibanaStatus{
+		ReconcilerStatus:  commonv1alpha1.ConvertReconcilerStatusFromV1beta1(src.Status.ReconcilerStatus),
+		Health:            KibanaHealth(src.Status.Health),
+		AssociationStatus: commonv1alpha1.AssociationStatus(src.Status.AssociationStatus),
+	}
+
+	beta := betaFields{
+		CredentialRotation:     src.Spec.CredentialRotation,
+		LastCredentialRotation: src.Status.LastCredentialRotation,
+		Conditions:             src.Status.Conditions,
+	}
+	if !reflect.DeepEqual(beta, betaFields{}) {
+		return commonv1alpha1.SaveConversionData(&k.ObjectMeta, betaFieldsAnnotation, beta)
+	}
+	return nil
+}
@@ -28,6 +28,10 @@ type KibanaSpec struct {
 	// If the namespace is not specified, the current resource namespace will be used.
 	ElasticsearchRef commonv1beta1.ObjectSelector `json:"elasticsearchRef,omitempty"`
 
+	// CredentialRotation rotates the credentials used to connect to the Elasticsearch cluster referenced by
+	// ElasticsearchRef on a schedule.
+	CredentialRotation commonv1beta1.CredentialRotationSpec `json:"credentialRotation,omitempty"`
+
 	// Config represents Kibana configuration.
 	Config *commonv1beta1.Config `json:"config,omitempty"`
 
@@ -65,6 +69,8 @@ type KibanaStatus struct {
 	commonv1beta1.ReconcilerStatus `json:",inline"`
 	Health                         KibanaHealth                    `json:"health,omitempty"`
 	AssociationStatus              commonv1beta1.AssociationStatus `json:"associationStatus,omitempty"`
+	// LastCredentialRotation is the time the credentials used to connect to Elasticsearch were last issued.
+	LastCredentialRotation *metav1.Time `json:"lastCredentialRotation,omitempty"`
 }
 
 // IsDegraded returns true if the current status is worse than the previous.
@@ -19,7 +19,7 @@ func (in *Kibana) DeepCopyInto(out *Kibana) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	if in.assocConf != nil {
 		in, out := &in.assocConf, &out.assocConf
 		*out = new(commonv1beta1.AssociationConf)
@@ -81,6 +81,7 @@ func (in *KibanaList) DeepCopyObject() runtime.Object {
 func (in *KibanaSpec) DeepCopyInto(out *KibanaSpec) {
 	*out = *in
 	out.ElasticsearchRef = in.ElasticsearchRef
+	in.CredentialRotation.DeepCopyInto(&out.CredentialRotation)
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = (*in).DeepCopy()
@@ -109,7 +110,11 @@ func (in *KibanaSpec) DeepCopy() *KibanaSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KibanaStatus) DeepCopyInto(out *KibanaStatus) {
 	*out = *in
-	out.ReconcilerStatus = in.ReconcilerStatus
+	in.ReconcilerStatus.DeepCopyInto(&out.ReconcilerStatus)
+	if in.LastCredentialRotation != nil {
+		in, out := &in.LastCredentialRotation, &out.LastCredentialRotation
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaStatus.
@@ -0,0 +1,172 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	commonv1alpha1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1alpha1"
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+const (
+	// alphaFieldsAnnotation stores the v1alpha1 fields that have no v1beta1 equivalent.
+	alphaFieldsAnnotation = "logstash.k8s.elastic.co/v1alpha1-fields"
+	// betaFieldsAnnotation stores the v1beta1 fields that have no v1alpha1 equivalent.
+	betaFieldsAnnotation = "logstash.k8s.elastic.co/v1beta1-fields"
+)
+
+type alphaFields struct {
+	Config *commonv1alpha1.Config `json:"config,omitempty"`
+}
+
+type betaFields struct {
+	OutputConf                  string                               `json:"outputConf,omitempty"`
+	InputConf                   string                               `json:"inputConf,omitempty"`
+	PipelineSelector            *metav1.LabelSelector                `json:"pipelineSelector,omitempty"`
+	Topology                    []v1beta1.TopologyPipeline           `json:"topology,omitempty"`
+	UpdateStrategy              v1beta1.UpdateStrategy               `json:"updateStrategy,omitempty"`
+	DrainTimeout                *metav1.Duration                     `json:"drainTimeout,omitempty"`
+	Monitoring                  v1beta1.MonitoringSpec               `json:"monitoring,omitempty"`
+	DeadLetterQueue             v1beta1.DeadLetterQueueSpec          `json:"deadLetterQueue,omitempty"`
+	IndexBootstrap              v1beta1.IndexBootstrapSpec           `json:"indexBootstrap,omitempty"`
+	JVMOptions                  []string                             `json:"jvmOptions,omitempty"`
+	Logging                     v1beta1.LoggingSpec                  `json:"logging,omitempty"`
+	Services                    []v1beta1.InputService               `json:"services,omitempty"`
+	CentralManagement           v1beta1.CentralManagementSpec        `json:"centralManagement,omitempty"`
+	AuthMode                    commonv1beta1.AuthMode               `json:"authMode,omitempty"`
+	APIKeyExpiration            *metav1.Duration                     `json:"apiKeyExpiration,omitempty"`
+	CredentialRotation          commonv1beta1.CredentialRotationSpec `json:"credentialRotation,omitempty"`
+	Canary                      *v1beta1.CanaryStatus                `json:"canary,omitempty"`
+	MonitoringAssociationStatus commonv1beta1.AssociationStatus      `json:"monitoringAssociationStatus,omitempty"`
+	DeadLetterQueueBytes        int64                                `json:"deadLetterQueueBytes,omitempty"`
+	IndexBootstrapStatus        *v1beta1.IndexBootstrapStatus        `json:"indexBootstrapStatus,omitempty"`
+	ServicesStatus              []v1beta1.InputServiceStatus         `json:"servicesStatus,omitempty"`
+	CentralManagementStatus     *v1beta1.CentralManagementStatus     `json:"centralManagementStatus,omitempty"`
+	LastCredentialRotation      *metav1.Time                         `json:"lastCredentialRotation,omitempty"`
+	Conditions                  commonv1beta1.Conditions             `json:"conditions,omitempty"`
+}
+
+var _ conversion.Convertible = &Logstash{}
+
+// ConvertTo converts this Logstash to the hub version.
+func (l *Logstash) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.Logstash)
+	dst.ObjectMeta = *l.ObjectMeta.DeepCopy()
+	dst.Spec = v1beta1.LogstashSpec{
+		Version:          l.Spec.Version,
+		Image:            l.Spec.Image,
+		Count:            l.Spec.NodeCount,
+		ElasticsearchRef: commonv1alpha1.ConvertObjectSelectorToV1beta1(l.Spec.ElasticsearchRef),
+		HTTP:             commonv1alpha1.ConvertHTTPConfigToV1beta1(l.Spec.HTTP),
+		PodTemplate:      *l.Spec.PodTemplate.DeepCopy(),
+		SecureSettings:   commonv1alpha1.ConvertSecretSourcesToV1beta1(l.Spec.SecureSettings),
+	}
+	dst.Status = v1beta1.LogstashStatus{
+		ReconcilerStatus:  commonv1alpha1.ConvertReconcilerStatusToV1beta1(l.Status.ReconcilerStatus),
+		Health:            v1beta1.LogstashHealth(l.Status.Health),
+		AssociationStatus: commonv1beta1.AssociationStatus(l.Status.AssociationStatus),
+	}
+
+	var beta betaFields
+	restored, err := commonv1alpha1.RestoreConversionData(&dst.ObjectMeta, betaFieldsAnnotation, &beta)
+	if err != nil {
+		return err
+	}
+	if restored {
+		dst.Spec.OutputConf = beta.OutputConf
+		dst.Spec.InputConf = beta.InputConf
+		dst.Spec.PipelineSelector = beta.PipelineSelector
+		dst.Spec.Topology = beta.Topology
+		dst.Spec.UpdateStrategy = beta.UpdateStrategy
+		dst.Spec.DrainTimeout = beta.DrainTimeout
+		dst.Spec.Monitoring = beta.Monitoring
+		dst.Spec.DeadLetterQueue = beta.DeadLetterQueue
+		dst.Spec.IndexBootstrap = beta.IndexBootstrap
+		dst.Spec.JVMOptions = beta.JVMOptions
+		dst.Spec.Logging = beta.Logging
+		dst.Spec.Services = beta.Services
+		dst.Spec.CentralManagement = beta.CentralManagement
+		dst.Spec.AuthMode = beta.AuthMode
+		dst.Spec.APIKeyExpiration = beta.APIKeyExpiration
+		dst.Spec.CredentialRotation = beta.CredentialRotation
+		dst.Status.Canary = beta.Canary
+		dst.Status.MonitoringAssociationStatus = beta.MonitoringAssociationStatus
+		dst.Status.DeadLetterQueueBytes = beta.DeadLetterQueueBytes
+		dst.Status.IndexBootstrap = beta.IndexBootstrapStatus
+		dst.Status.Services = beta.ServicesStatus
+		dst.Status.CentralManagement = beta.CentralManagementStatus
+		dst.Status.LastCredentialRotation = beta.LastCredentialRotation
+		dst.Status.Conditions = beta.Conditions
+	}
+
+	if l.Spec.Config != nil {
+		return commonv1alpha1.SaveConversionData(&dst.ObjectMeta, alphaFieldsAnnotation, alphaFields{Config: l.Spec.Config})
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version to this Logstash.
+func (l *Logstash) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.Logstash)
+	l.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	l.Spec = LogstashSpec{
+		Version:          src.Spec.Version,
+		Image:            src.Spec.Image,
+		NodeCount:        src.Spec.Count,
+		ElasticsearchRef: commonv1alpha1.ConvertObjectSelectorFromV1beta1(src.Spec.ElasticsearchRef),
+		HTTP:             commonv1alpha1.ConvertHTTPConfigFromV1beta1(src.Spec.HTTP),
+		PodTemplate:      *src.Spec.PodTemplate.DeepCopy(),
+		SecureSettings:   commonv1alpha1.ConvertSecretSourcesFromV1beta1(src.Spec.SecureSettings),
+	}
+	l.Status = LogstashStatus{
+		ReconcilerStatus:  commonv1alpha1.ConvertReconcilerStatusFromV1beta1(src.Status.ReconcilerStatus),
+		Health:            LogstashHealth(src.Status.Health),
+		AssociationStatus: commonv1alpha1.AssociationStatus(src.Status.AssociationStatus),
+	}
+
+	var alpha alphaFields
+	restored, err := commonv1alpha1.RestoreConversionData(&l.ObjectMeta, alphaFieldsAnnotation, &alpha)
+	if err != nil {
+		return err
+	}
+	if restored {
+		l.Spec.Config = alpha.Config
+	}
+
+	beta := betaFields{
+		OutputConf:                  src.Spec.OutputConf,
+		InputConf:                   src.Spec.InputConf,
+		PipelineSelector:            src.Spec.PipelineSelector,
+		Topology:                    src.Spec.Topology,
+		UpdateStrategy:              src.Spec.UpdateStrategy,
+		DrainTimeout:                src.Spec.DrainTimeout,
+		Monitoring:                  src.Spec.Monitoring,
+		DeadLetterQueue:             src.Spec.DeadLetterQueue,
+		IndexBootstrap:              src.Spec.IndexBootstrap,
+		JVMOptions:                  src.Spec.JVMOptions,
+		Logging:                     src.Spec.Logging,
+		Services:                    src.Spec.Services,
+		CentralManagement:           src.Spec.CentralManagement,
+		AuthMode:                    src.Spec.AuthMode,
+		APIKeyExpiration:            src.Spec.APIKeyExpiration,
+		CredentialRotation:          src.Spec.CredentialRotation,
+		Canary:                      src.Status.Canary,
+		MonitoringAssociationStatus: src.Status.MonitoringAssociationStatus,
+		DeadLetterQueueBytes:        src.Status.DeadLetterQueueBytes,
+		IndexBootstrapStatus:        src.Status.IndexBootstrap,
+		ServicesStatus:              src.Status.Services,
+		CentralManagementStatus:     src.Status.CentralManagement,
+		LastCredentialRotation:      src.Status.LastCredentialRotation,
+		Conditions:                  src.Status.Conditions,
+	}
+	if !reflect.DeepEqual(beta, betaFields{}) {
+		return commonv1alpha1.SaveConversionData(&l.ObjectMeta, betaFieldsAnnotation, beta)
+	}
+	return nil
+}
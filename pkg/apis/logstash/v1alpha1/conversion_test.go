@@ -0,0 +1,73 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	commonv1alpha1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1alpha1"
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLogstash_ConvertFrom_ConvertTo(t *testing.T) {
+	maxFilterFailures := int64(10)
+	beta := v1beta1.Logstash{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls", Annotations: map[string]string{"a": "b"}},
+		Spec: v1beta1.LogstashSpec{
+			Version:          "7.4.0",
+			Count:            3,
+			ElasticsearchRef: commonv1beta1.ObjectSelector{Name: "es"},
+			InputConf:        "input { beats {} }",
+			OutputConf:       "output { stdout {} }",
+			UpdateStrategy: v1beta1.UpdateStrategy{
+				Type:   v1beta1.CanaryStrategyType,
+				Canary: &v1beta1.CanaryStrategy{MaxFilterFailures: &maxFilterFailures},
+			},
+			DrainTimeout: &metav1.Duration{Duration: time.Minute},
+			Monitoring: v1beta1.MonitoringSpec{
+				ElasticsearchRef: commonv1beta1.ObjectSelector{Name: "monitoring"},
+				Mode:             v1beta1.MetricbeatMonitoringMode,
+			},
+		},
+		Status: v1beta1.LogstashStatus{
+			Health: v1beta1.LogstashGreen,
+			Canary: &v1beta1.CanaryStatus{Phase: v1beta1.CanaryProgressing},
+		},
+	}
+
+	var alpha Logstash
+	require.NoError(t, alpha.ConvertFrom(beta.DeepCopy()))
+	require.Equal(t, int32(3), alpha.Spec.NodeCount)
+	require.Equal(t, "es", alpha.Spec.ElasticsearchRef.Name)
+	require.Contains(t, alpha.Annotations, betaFieldsAnnotation)
+
+	var roundTripped v1beta1.Logstash
+	require.NoError(t, alpha.ConvertTo(&roundTripped))
+	require.Equal(t, beta, roundTripped)
+}
+
+func TestLogstash_ConvertTo_ConvertFrom(t *testing.T) {
+	alpha := Logstash{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"},
+		Spec: LogstashSpec{
+			Version:   "7.4.0",
+			NodeCount: 1,
+			Config:    &commonv1alpha1.Config{Data: map[string]interface{}{"pipeline.workers": float64(2)}},
+		},
+	}
+
+	var beta v1beta1.Logstash
+	require.NoError(t, alpha.ConvertTo(&beta))
+	require.Equal(t, int32(1), beta.Spec.Count)
+	require.Contains(t, beta.Annotations, alphaFieldsAnnotation)
+
+	var roundTripped Logstash
+	require.NoError(t, roundTripped.ConvertFrom(&beta))
+	require.Equal(t, alpha, roundTripped)
+}
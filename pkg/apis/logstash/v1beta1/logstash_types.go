@@ -5,6 +5,9 @@
 package v1beta1
 
 import (
+	"fmt"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -28,12 +31,36 @@ type LogstashSpec struct {
 	// If the namespace is not specified, the current resource namespace will be used.
 	ElasticsearchRef commonv1beta1.ObjectSelector `json:"elasticsearchRef,omitempty"`
 
+	// AuthMode is the way Logstash authenticates to the Elasticsearch cluster referenced by ElasticsearchRef:
+	// with a file realm user (user), or with an API key created through the Elasticsearch API (apiKey).
+	// The API key can only write to the indices of the default output, of the index bootstrap and of the dead letter
+	// queue reprocessing. It requires Logstash 7.6 or above, and Elasticsearch 6.7 or above with TLS enabled on its
+	// HTTP layer. Defaults to user.
+	// +kubebuilder:validation:Enum=user;apiKey
+	AuthMode commonv1beta1.AuthMode `json:"authMode,omitempty"`
+
+	// APIKeyExpiration is the lifetime of the API key used in the apiKey auth mode. The key is replaced by a new
+	// one before it expires. Defaults to 168h.
+	APIKeyExpiration *metav1.Duration `json:"apiKeyExpiration,omitempty"`
+
+	// CredentialRotation rotates the credentials used to connect to the Elasticsearch cluster referenced by
+	// ElasticsearchRef on a schedule.
+	CredentialRotation commonv1beta1.CredentialRotationSpec `json:"credentialRotation,omitempty"`
+
 	// OutputConf represents Logstash configuration for outputs.
 	OutputConf string `json:"outputConf,omitempty"`
 
 	// InputConf represents Logstash configuration for inputs.
 	InputConf string `json:"inputConf,omitempty"`
 
+	// PipelineSelector selects the LogstashPipeline resources, in the same namespace, run by the Logstash nodes
+	// in addition to the main pipeline defined by InputConf and OutputConf.
+	PipelineSelector *metav1.LabelSelector `json:"pipelineSelector,omitempty"`
+
+	// Topology declares pipelines connected to each other through pipeline-to-pipeline communication, run in
+	// addition to the main pipeline. The pipeline inputs and outputs connecting them are generated.
+	Topology []TopologyPipeline `json:"topology,omitempty"`
+
 	// HTTP contains settings for HTTP.
 	HTTP commonv1beta1.HTTPConfig `json:"http,omitempty"`
 
@@ -51,6 +78,366 @@ type LogstashSpec struct {
 	// entries and the `path` field to change the target path of a secret entry key.
 	// The secret must exist in the same namespace as the Logstash resource.
 	SecureSettings []commonv1beta1.SecretSource `json:"secureSettings,omitempty"`
+
+	// UpdateStrategy specifies how pipeline changes are rolled out to the Logstash nodes.
+	UpdateStrategy UpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// DrainTimeout is the maximum duration a terminating Logstash node waits for its pipeline queues to be
+	// drained before being stopped. The pod termination grace period is derived from it. Defaults to 2m.
+	DrainTimeout *metav1.Duration `json:"drainTimeout,omitempty"`
+
+	// Monitoring configures the shipping of Logstash monitoring data to an Elasticsearch cluster, to be
+	// visualized in Kibana Stack Monitoring.
+	Monitoring MonitoringSpec `json:"monitoring,omitempty"`
+
+	// DeadLetterQueue configures the dead letter queue of the pipelines, where the events the elasticsearch outputs
+	// fail to index are written.
+	DeadLetterQueue DeadLetterQueueSpec `json:"deadLetterQueue,omitempty"`
+
+	// IndexBootstrap configures the index template and lifecycle policy installed in the associated Elasticsearch
+	// cluster for the events written by the default output.
+	IndexBootstrap IndexBootstrapSpec `json:"indexBootstrap,omitempty"`
+
+	// JVMOptions are additional options of the Logstash JVM, for example garbage collection flags. The heap size is
+	// derived from the memory request of the Logstash container.
+	JVMOptions []string `json:"jvmOptions,omitempty"`
+
+	// Logging configures the logs of Logstash.
+	Logging LoggingSpec `json:"logging,omitempty"`
+
+	// Services are additional services exposing input ports of the Logstash container, each with its own template,
+	// for example an internal load balancer for a syslog input.
+	Services []InputService `json:"services,omitempty"`
+
+	// CentralManagement makes Logstash load its pipelines from the associated Elasticsearch cluster, where they can
+	// be edited in Kibana, instead of the pipelines defined in this resource.
+	CentralManagement CentralManagementSpec `json:"centralManagement,omitempty"`
+}
+
+// CentralManagementSpec configures the central management of the Logstash pipelines through Elasticsearch.
+type CentralManagementSpec struct {
+	// Enabled makes Logstash load its pipelines from the associated Elasticsearch cluster, with a dedicated user.
+	// It requires a gold, platinum or trial license in the Elasticsearch cluster.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// PipelineIDs are the ids of the centrally managed pipelines run by Logstash. Wildcards are supported.
+	// Defaults to the ids of the pipelines defined in this resource.
+	PipelineIDs []string `json:"pipelineIds,omitempty"`
+
+	// PollInterval is how often Logstash checks for changes in the centrally managed pipelines. Defaults to 5s.
+	// +kubebuilder:validation:Pattern=^[0-9]+(ms|s|m|h)$
+	PollInterval string `json:"pollInterval,omitempty"`
+
+	// SeedPipelines stores the pipelines defined in this resource in Elasticsearch, if they do not exist yet.
+	// Existing pipelines are never overwritten, so that the changes made in Kibana are preserved.
+	SeedPipelines bool `json:"seedPipelines,omitempty"`
+}
+
+// InputService is a service exposing input ports of the Logstash container.
+type InputService struct {
+	// Name of the service, appended to the name of the Logstash resource to name the Kubernetes Service.
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	// +kubebuilder:validation:MaxLength=20
+	Name string `json:"name"`
+
+	// Ports are the names of the exposed ports of the Logstash container, either the default beats port or ports
+	// declared in the pod template.
+	// +kubebuilder:validation:MinItems=1
+	Ports []string `json:"ports"`
+
+	// Service is a template for the Kubernetes Service.
+	Service commonv1beta1.ServiceTemplate `json:"service,omitempty"`
+}
+
+// LoggingSpec configures the logs of Logstash.
+type LoggingSpec struct {
+	// Level is the level of the Logstash logs. Defaults to info.
+	// +kubebuilder:validation:Enum=fatal;error;warn;info;debug;trace
+	Level string `json:"level,omitempty"`
+
+	// Format of the Logstash logs, plain or json. Defaults to plain.
+	// +kubebuilder:validation:Enum=plain;json
+	Format string `json:"format,omitempty"`
+
+	// Loggers overrides the level of the given loggers, for example {"logstash.outputs.elasticsearch": "debug"}.
+	Loggers map[string]string `json:"loggers,omitempty"`
+}
+
+// IndexBootstrapSpec configures the index template and lifecycle policy of the events written by the default output.
+type IndexBootstrapSpec struct {
+	// Enabled installs the index template and the ILM policy in the associated Elasticsearch cluster before Logstash
+	// is started, and configures the default output to write to a rollover alias or data stream managed by them.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Name of the index template, of the ILM policy and of the rollover alias. Defaults to logstash.
+	// +kubebuilder:validation:Pattern=^[-_a-z0-9]+$
+	Name string `json:"name,omitempty"`
+
+	// Template holds the settings and mappings of the index template.
+	Template *commonv1beta1.Config `json:"template,omitempty"`
+
+	// ILMPolicy holds the phases of the ILM policy. Defaults to a rollover after 30 days or 50gb.
+	ILMPolicy *commonv1beta1.Config `json:"ilmPolicy,omitempty"`
+
+	// DataStream makes the default output write to a data stream instead of a rollover alias, if the associated
+	// Elasticsearch cluster runs version 7.9 or above and Logstash runs version 7.13 or above.
+	DataStream *DataStreamSpec `json:"dataStream,omitempty"`
+}
+
+// DefaultIndexBootstrapName is the default name of the index template, ILM policy and rollover alias.
+const DefaultIndexBootstrapName = "logstash"
+
+// GetName returns the name of the index template, ILM policy and rollover alias, or its default value.
+func (i IndexBootstrapSpec) GetName() string {
+	if i.Name == "" {
+		return DefaultIndexBootstrapName
+	}
+	return i.Name
+}
+
+// DataStreamSpec identifies the data stream written by the default output, named <type>-<dataset>-<namespace>.
+type DataStreamSpec struct {
+	// Type of the data stream. Defaults to logs.
+	// +kubebuilder:validation:Enum=logs;metrics;synthetics
+	Type string `json:"type,omitempty"`
+
+	// Dataset of the data stream. Defaults to generic.
+	Dataset string `json:"dataset,omitempty"`
+
+	// Namespace of the data stream. Defaults to default.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// WithDefaults returns a copy of the data stream with the unset fields set to their default value.
+func (d DataStreamSpec) WithDefaults() DataStreamSpec {
+	if d.Type == "" {
+		d.Type = "logs"
+	}
+	if d.Dataset == "" {
+		d.Dataset = "generic"
+	}
+	if d.Namespace == "" {
+		d.Namespace = "default"
+	}
+	return d
+}
+
+// Name returns the name of the data stream.
+func (d DataStreamSpec) Name() string {
+	d = d.WithDefaults()
+	return fmt.Sprintf("%s-%s-%s", d.Type, d.Dataset, d.Namespace)
+}
+
+// DeadLetterQueueSpec configures the dead letter queue of the pipelines.
+type DeadLetterQueueSpec struct {
+	// Enabled enables the dead letter queue of the pipelines.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// MaxBytes is the maximum size of the dead letter queue of each pipeline, for example 1gb. Defaults to 1024mb.
+	// +kubebuilder:validation:Pattern=^[0-9]+(b|kb|mb|gb|tb|pb)$
+	MaxBytes string `json:"maxBytes,omitempty"`
+
+	// VolumeClaimSpec is the specification of a persistent volume claim storing the dead letter queues, which are
+	// otherwise lost when a Logstash pod is deleted. The claim is shared by the Logstash nodes, each writing to its
+	// own directory, which is taken over by the node replacing it: it must support the ReadWriteMany access mode if
+	// there is more than one node.
+	// The claim is deleted along with the Logstash resource.
+	VolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"volumeClaimSpec,omitempty"`
+
+	// Reprocessing configures a pipeline reading the dead letter queues and indexing the failed events in the
+	// associated Elasticsearch cluster.
+	Reprocessing *DeadLetterQueueReprocessing `json:"reprocessing,omitempty"`
+}
+
+// DeadLetterQueueReprocessing configures the pipeline reprocessing the dead letter queues.
+type DeadLetterQueueReprocessing struct {
+	// Index is the Elasticsearch index the failed events are written to. Defaults to logstash-dlq-%{+YYYY.MM.dd}.
+	Index string `json:"index,omitempty"`
+}
+
+// DefaultDeadLetterQueueIndex is the default index the failed events are written to by the reprocessing pipeline.
+const DefaultDeadLetterQueueIndex = "logstash-dlq-%{+YYYY.MM.dd}"
+
+// ReprocessingEnabled returns true if the dead letter queues are read by a reprocessing pipeline.
+func (d DeadLetterQueueSpec) ReprocessingEnabled() bool {
+	return d.Enabled && d.Reprocessing != nil
+}
+
+// GetIndex returns the index the failed events are written to, or its default value.
+func (r DeadLetterQueueReprocessing) GetIndex() string {
+	if r.Index == "" {
+		return DefaultDeadLetterQueueIndex
+	}
+	return r.Index
+}
+
+// TopologyPipeline is a pipeline of a topology, sending its events to other pipelines of the topology.
+// Several edges from a pipeline fork its events, conditional edges distribute them, and several edges to a pipeline
+// collect events from different pipelines.
+type TopologyPipeline struct {
+	// Name of the pipeline, used as its pipeline id and as its address for the other pipelines.
+	// +kubebuilder:validation:Pattern=^[-._a-zA-Z0-9]+$
+	Name string `json:"name"`
+
+	// Config is the configuration of the pipeline, in the Logstash configuration language. The pipeline input
+	// receiving events from the other pipelines and the pipeline outputs sending events to them are generated, only
+	// the other plugins must be specified.
+	Config string `json:"config,omitempty"`
+
+	// SendTo lists the pipelines receiving the events of this pipeline.
+	SendTo []PipelineEdge `json:"sendTo,omitempty"`
+
+	// Workers is the number of workers executing the filter and output stages of the pipeline.
+	// Defaults to the number of CPU cores of the Logstash node.
+	// +kubebuilder:validation:Minimum=1
+	Workers *int32 `json:"workers,omitempty"`
+
+	// Queue configures the queue buffering the events of the pipeline.
+	Queue PipelineQueueSpec `json:"queue,omitempty"`
+}
+
+// PipelineEdge sends the events of a pipeline to another pipeline.
+type PipelineEdge struct {
+	// Pipeline is the name of the pipeline receiving the events.
+	Pipeline string `json:"pipeline"`
+
+	// If is a Logstash conditional expression restricting the events sent, for example [type] == "apache".
+	// All events are sent if empty.
+	If string `json:"if,omitempty"`
+}
+
+// MonitoringMode is the way Logstash monitoring data is collected.
+type MonitoringMode string
+
+const (
+	// InternalMonitoringMode lets Logstash ship its own monitoring data, through the legacy xpack.monitoring settings.
+	InternalMonitoringMode MonitoringMode = "Internal"
+	// MetricbeatMonitoringMode collects the monitoring data from the Logstash monitoring API with a Metricbeat sidecar.
+	MetricbeatMonitoringMode MonitoringMode = "Metricbeat"
+)
+
+// MonitoringSpec configures the shipping of Logstash monitoring data.
+type MonitoringSpec struct {
+	// ElasticsearchRef references the Elasticsearch cluster the monitoring data is shipped to.
+	// If the namespace is not specified, the current resource namespace will be used.
+	ElasticsearchRef commonv1beta1.ObjectSelector `json:"elasticsearchRef,omitempty"`
+
+	// Mode is the way monitoring data is collected. Defaults to Internal.
+	// +kubebuilder:validation:Enum=Internal;Metricbeat
+	Mode MonitoringMode `json:"mode,omitempty"`
+}
+
+// IsEnabled returns true if a monitoring Elasticsearch cluster is referenced.
+func (m MonitoringSpec) IsEnabled() bool {
+	return m.ElasticsearchRef.IsDefined()
+}
+
+// GetMode returns the monitoring mode, or its default value.
+func (m MonitoringSpec) GetMode() MonitoringMode {
+	if m.Mode == "" {
+		return InternalMonitoringMode
+	}
+	return m.Mode
+}
+
+// DefaultDrainTimeout is the default maximum duration a terminating Logstash node waits for its queues to be drained.
+const DefaultDrainTimeout = 2 * time.Minute
+
+// GetDrainTimeout returns the drain timeout of the Logstash nodes, or its default value.
+func (ls LogstashSpec) GetDrainTimeout() time.Duration {
+	if ls.DrainTimeout == nil {
+		return DefaultDrainTimeout
+	}
+	return ls.DrainTimeout.Duration
+}
+
+// UpdateStrategyType is the type of strategy used to roll out pipeline changes.
+type UpdateStrategyType string
+
+const (
+	// RollingUpdateStrategyType applies pipeline changes to all Logstash nodes at once, relying on automatic
+	// pipeline reloading.
+	RollingUpdateStrategyType UpdateStrategyType = "RollingUpdate"
+	// CanaryStrategyType applies pipeline changes to a single canary node first, and promotes or rolls them back
+	// depending on the canary node statistics.
+	CanaryStrategyType UpdateStrategyType = "Canary"
+
+	// DefaultCanaryAnalysisWindow is the default duration during which the canary node is observed.
+	DefaultCanaryAnalysisWindow = 5 * time.Minute
+	// DefaultCanaryMaxFilterFailures is the default number of filter failures tolerated on the canary node.
+	DefaultCanaryMaxFilterFailures int64 = 10
+	// DefaultCanaryMaxDeadLetterQueueBytes is the default number of bytes written to the dead letter queue tolerated
+	// on the canary node.
+	DefaultCanaryMaxDeadLetterQueueBytes int64 = 1024 * 1024
+	// DefaultCanaryMaxOutputErrors is the default number of output errors tolerated on the canary node.
+	DefaultCanaryMaxOutputErrors int64 = 10
+)
+
+// UpdateStrategy specifies how pipeline changes are rolled out.
+type UpdateStrategy struct {
+	// Type of the update strategy. Defaults to RollingUpdate.
+	// +kubebuilder:validation:Enum=RollingUpdate;Canary
+	Type UpdateStrategyType `json:"type,omitempty"`
+
+	// Canary configures the canary update strategy. Only used when Type is Canary.
+	Canary *CanaryStrategy `json:"canary,omitempty"`
+}
+
+// IsCanary returns true if pipeline changes should be rolled out through a canary node.
+func (s UpdateStrategy) IsCanary() bool {
+	return s.Type == CanaryStrategyType
+}
+
+// CanaryStrategy defines how long the canary node is observed and which statistics trigger an automatic rollback.
+type CanaryStrategy struct {
+	// AnalysisWindow is the duration during which the canary node must run the new pipeline without exceeding
+	// the failure thresholds before it is promoted to all nodes. Defaults to 5m.
+	AnalysisWindow *metav1.Duration `json:"analysisWindow,omitempty"`
+
+	// MaxFilterFailures is the number of filter failures (for example grok parse failures) tolerated on the canary node.
+	// Defaults to 10. Set to 0 to roll back on the first failure.
+	MaxFilterFailures *int64 `json:"maxFilterFailures,omitempty"`
+
+	// MaxDeadLetterQueueBytes is the number of bytes written to the dead letter queue tolerated on the canary node.
+	// Defaults to 1048576 (1MiB). Set to 0 to roll back on the first event written to the dead letter queue.
+	MaxDeadLetterQueueBytes *int64 `json:"maxDeadLetterQueueBytes,omitempty"`
+
+	// MaxOutputErrors is the number of output errors tolerated on the canary node. Defaults to 10. Set to 0 to roll
+	// back on the first error.
+	MaxOutputErrors *int64 `json:"maxOutputErrors,omitempty"`
+}
+
+// GetMaxFilterFailures returns the number of filter failures tolerated on the canary node, or its default value.
+func (c *CanaryStrategy) GetMaxFilterFailures() int64 {
+	if c == nil || c.MaxFilterFailures == nil {
+		return DefaultCanaryMaxFilterFailures
+	}
+	return *c.MaxFilterFailures
+}
+
+// GetMaxDeadLetterQueueBytes returns the number of bytes written to the dead letter queue tolerated on the canary
+// node, or its default value.
+func (c *CanaryStrategy) GetMaxDeadLetterQueueBytes() int64 {
+	if c == nil || c.MaxDeadLetterQueueBytes == nil {
+		return DefaultCanaryMaxDeadLetterQueueBytes
+	}
+	return *c.MaxDeadLetterQueueBytes
+}
+
+// GetMaxOutputErrors returns the number of output errors tolerated on the canary node, or its default value.
+func (c *CanaryStrategy) GetMaxOutputErrors() int64 {
+	if c == nil || c.MaxOutputErrors == nil {
+		return DefaultCanaryMaxOutputErrors
+	}
+	return *c.MaxOutputErrors
+}
+
+// GetAnalysisWindow returns the analysis window of the canary strategy, or its default value.
+func (c *CanaryStrategy) GetAnalysisWindow() time.Duration {
+	if c == nil || c.AnalysisWindow == nil {
+		return DefaultCanaryAnalysisWindow
+	}
+	return c.AnalysisWindow.Duration
 }
 
 // LogstashHealth expresses the status of the Logstash instances.
@@ -68,6 +455,107 @@ type LogstashStatus struct {
 	commonv1beta1.ReconcilerStatus `json:",inline"`
 	Health                         LogstashHealth                  `json:"health,omitempty"`
 	AssociationStatus              commonv1beta1.AssociationStatus `json:"associationStatus,omitempty"`
+	// Canary describes the last pipeline change rolled out through a canary node.
+	Canary *CanaryStatus `json:"canary,omitempty"`
+	// MonitoringAssociationStatus is the status of the association with the monitoring Elasticsearch cluster.
+	MonitoringAssociationStatus commonv1beta1.AssociationStatus `json:"monitoringAssociationStatus,omitempty"`
+	// DeadLetterQueueBytes is the total size of the dead letter queues of the Logstash nodes, if enabled.
+	DeadLetterQueueBytes int64 `json:"deadLetterQueueBytes,omitempty"`
+	// IndexBootstrap describes the installation of the index template and ILM policy, if enabled.
+	IndexBootstrap *IndexBootstrapStatus `json:"indexBootstrap,omitempty"`
+	// Services lists the external addresses of the input services.
+	Services []InputServiceStatus `json:"services,omitempty"`
+	// CentralManagement describes the central management of the pipelines, if enabled.
+	CentralManagement *CentralManagementStatus `json:"centralManagement,omitempty"`
+	// LastCredentialRotation is the time the credentials used to connect to Elasticsearch were last issued.
+	LastCredentialRotation *metav1.Time `json:"lastCredentialRotation,omitempty"`
+}
+
+// CentralManagementPhase is the phase of the central management of the pipelines.
+type CentralManagementPhase string
+
+const (
+	// CentralManagementReady means the license allows central management and the pipelines are seeded, if requested.
+	CentralManagementReady CentralManagementPhase = "Ready"
+	// CentralManagementUnlicensed means the license of the Elasticsearch cluster does not include central management.
+	CentralManagementUnlicensed CentralManagementPhase = "Unlicensed"
+	// CentralManagementFailed means the license could not be checked or the pipelines could not be seeded.
+	CentralManagementFailed CentralManagementPhase = "Failed"
+)
+
+// CentralManagementStatus describes the central management of the pipelines.
+type CentralManagementStatus struct {
+	// Phase of the central management.
+	Phase CentralManagementPhase `json:"phase,omitempty"`
+	// Active is true if Logstash loads its pipelines from Elasticsearch, as of the last successful license check.
+	// Logstash runs the pipelines defined in the resource otherwise.
+	Active bool `json:"active,omitempty"`
+	// SeededPipelines are the ids of the pipelines stored in Elasticsearch by the operator.
+	SeededPipelines []string `json:"seededPipelines,omitempty"`
+	// Message is a human readable explanation of the current phase.
+	Message string `json:"message,omitempty"`
+}
+
+// InputServiceStatus describes the external addresses of an input service.
+type InputServiceStatus struct {
+	// Name of the input service.
+	Name string `json:"name"`
+	// ExternalAddresses are the addresses of the load balancer and the external IPs of the service.
+	ExternalAddresses []string `json:"externalAddresses,omitempty"`
+}
+
+// IndexBootstrapPhase is the phase of the installation of the index template and ILM policy.
+type IndexBootstrapPhase string
+
+const (
+	// IndexBootstrapReady means the index template and ILM policy are installed.
+	IndexBootstrapReady IndexBootstrapPhase = "Ready"
+	// IndexBootstrapFailed means the index template or ILM policy could not be installed.
+	IndexBootstrapFailed IndexBootstrapPhase = "Failed"
+)
+
+// IndexBootstrapMode describes where the default output writes its events.
+type IndexBootstrapMode string
+
+const (
+	// IndexBootstrapRolloverAlias means the default output writes to a rollover alias managed by ILM.
+	IndexBootstrapRolloverAlias IndexBootstrapMode = "RolloverAlias"
+	// IndexBootstrapDataStream means the default output writes to a data stream.
+	IndexBootstrapDataStream IndexBootstrapMode = "DataStream"
+)
+
+// IndexBootstrapStatus describes the installation of the index template and ILM policy.
+type IndexBootstrapStatus struct {
+	// Phase of the installation.
+	Phase IndexBootstrapPhase `json:"phase,omitempty"`
+	// Mode is where the default output writes its events, as of the last successful installation.
+	Mode IndexBootstrapMode `json:"mode,omitempty"`
+	// Message is a human readable explanation of the current phase.
+	Message string `json:"message,omitempty"`
+}
+
+// CanaryPhase is the phase of a canary rollout.
+type CanaryPhase string
+
+const (
+	// CanaryProgressing means the canary node is running the new pipeline and being observed.
+	CanaryProgressing CanaryPhase = "Progressing"
+	// CanaryPromoted means the new pipeline was applied to all nodes.
+	CanaryPromoted CanaryPhase = "Promoted"
+	// CanaryRolledBack means the new pipeline was discarded because the canary node exceeded a failure threshold.
+	CanaryRolledBack CanaryPhase = "RolledBack"
+)
+
+// CanaryStatus describes the progress of a canary rollout.
+type CanaryStatus struct {
+	// Phase of the canary rollout.
+	Phase CanaryPhase `json:"phase,omitempty"`
+	// ConfigChecksum identifies the pipeline configuration under test.
+	ConfigChecksum string `json:"configChecksum,omitempty"`
+	// StartTime is the time at which the canary node was created.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Message is a human readable explanation of the current phase.
+	Message string `json:"message,omitempty"`
 }
 
 // IsDegraded returns true if the current status is worse than the previous.
@@ -96,6 +584,16 @@ func (l *Logstash) SetAssociationConf(assocConf *commonv1beta1.AssociationConf)
 	l.assocConf = assocConf
 }
 
+// MonitoringAssociationConf returns the configuration of the association with the monitoring Elasticsearch cluster.
+func (l *Logstash) MonitoringAssociationConf() *commonv1beta1.AssociationConf {
+	return l.monitoringAssocConf
+}
+
+// SetMonitoringAssociationConf sets the configuration of the association with the monitoring Elasticsearch cluster.
+func (l *Logstash) SetMonitoringAssociationConf(assocConf *commonv1beta1.AssociationConf) {
+	l.monitoringAssocConf = assocConf
+}
+
 // +kubebuilder:object:root=true
 
 // Logstash is the Schema for the logstashs API
@@ -110,9 +608,10 @@ type Logstash struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec      LogstashSpec                   `json:"spec,omitempty"`
-	Status    LogstashStatus                 `json:"status,omitempty"`
-	assocConf *commonv1beta1.AssociationConf `json:"-"` //nolint:govet
+	Spec                LogstashSpec                   `json:"spec,omitempty"`
+	Status              LogstashStatus                 `json:"status,omitempty"`
+	assocConf           *commonv1beta1.AssociationConf `json:"-"` //nolint:govet
+	monitoringAssocConf *commonv1beta1.AssociationConf `json:"-"` //nolint:govet
 }
 
 // +kubebuilder:object:root=true
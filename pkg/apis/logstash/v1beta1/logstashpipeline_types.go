@@ -0,0 +1,127 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LogstashPipelineSpec defines the desired state of LogstashPipeline
+type LogstashPipelineSpec struct {
+	// PipelineID is the id of the pipeline in the Logstash nodes. Defaults to the name of the resource.
+	// The id "main" is reserved for the pipeline defined in the Logstash resource.
+	// +kubebuilder:validation:Pattern=^[-._a-zA-Z0-9]+$
+	PipelineID string `json:"pipelineID,omitempty"`
+
+	// Config is the pipeline configuration, in the Logstash configuration language.
+	Config string `json:"config"`
+
+	// Workers is the number of workers executing the filter and output stages of the pipeline.
+	// Defaults to the number of CPU cores of the Logstash node.
+	// +kubebuilder:validation:Minimum=1
+	Workers *int32 `json:"workers,omitempty"`
+
+	// BatchSize is the maximum number of events an individual worker collects before executing the filters
+	// and outputs. Defaults to 125.
+	// +kubebuilder:validation:Minimum=1
+	BatchSize *int32 `json:"batchSize,omitempty"`
+
+	// BatchDelay is the time in milliseconds to wait for each event before dispatching an undersized batch
+	// to the workers. Defaults to 50.
+	// +kubebuilder:validation:Minimum=0
+	BatchDelay *int32 `json:"batchDelay,omitempty"`
+
+	// Queue configures the queue buffering the events of the pipeline.
+	Queue PipelineQueueSpec `json:"queue,omitempty"`
+}
+
+// PipelineQueueType is the type of the queue of a pipeline.
+type PipelineQueueType string
+
+const (
+	// MemoryQueueType buffers the events in memory.
+	MemoryQueueType PipelineQueueType = "memory"
+	// PersistedQueueType buffers the events on disk.
+	PersistedQueueType PipelineQueueType = "persisted"
+)
+
+// PipelineQueueSpec configures the queue of a pipeline. Unset values default to the queue settings of the
+// Logstash nodes.
+type PipelineQueueSpec struct {
+	// Type of the queue.
+	// +kubebuilder:validation:Enum=memory;persisted
+	Type PipelineQueueType `json:"type,omitempty"`
+
+	// MaxBytes is the total capacity of a persisted queue, for example 1gb.
+	// +kubebuilder:validation:Pattern=^[0-9]+(b|kb|mb|gb|tb|pb)$
+	MaxBytes string `json:"maxBytes,omitempty"`
+
+	// CheckpointWrites is the maximum number of events written to a persisted queue before forcing a checkpoint.
+	// +kubebuilder:validation:Minimum=0
+	CheckpointWrites *int32 `json:"checkpointWrites,omitempty"`
+}
+
+// PipelineAdmissionPhase expresses whether a pipeline is run by a Logstash resource.
+type PipelineAdmissionPhase string
+
+const (
+	// PipelineAdmitted means the pipeline is run by the Logstash nodes.
+	PipelineAdmitted PipelineAdmissionPhase = "Admitted"
+	// PipelineRejected means the pipeline is selected but cannot be run by the Logstash nodes.
+	PipelineRejected PipelineAdmissionPhase = "Rejected"
+)
+
+// PipelineAdmission is the admission status of a pipeline in a Logstash resource selecting it.
+type PipelineAdmission struct {
+	// Logstash is the name of the Logstash resource selecting the pipeline.
+	Logstash string `json:"logstash"`
+	// Phase of the admission.
+	Phase PipelineAdmissionPhase `json:"phase"`
+	// Message is a human readable explanation of the rejection of the pipeline.
+	Message string `json:"message,omitempty"`
+}
+
+// LogstashPipelineStatus defines the observed state of LogstashPipeline
+type LogstashPipelineStatus struct {
+	// Admissions are the admission statuses of the pipeline in the Logstash resources selecting it.
+	Admissions []PipelineAdmission `json:"admissions,omitempty"`
+}
+
+// GetPipelineID returns the id of the pipeline in the Logstash nodes.
+func (p LogstashPipeline) GetPipelineID() string {
+	if p.Spec.PipelineID == "" {
+		return p.Name
+	}
+	return p.Spec.PipelineID
+}
+
+// +kubebuilder:object:root=true
+
+// LogstashPipeline is the Schema for the logstashpipelines API
+// +kubebuilder:resource:categories=elastic,shortName=lsp
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="workers",type="integer",JSONPath=".spec.workers",description="Pipeline workers"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:storageversion
+type LogstashPipeline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LogstashPipelineSpec   `json:"spec,omitempty"`
+	Status LogstashPipelineStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LogstashPipelineList contains a list of LogstashPipeline
+type LogstashPipelineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LogstashPipeline `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LogstashPipeline{}, &LogstashPipelineList{})
+}
@@ -10,21 +10,283 @@ package v1beta1
 
 import (
 	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStatus.
+func (in *CanaryStatus) DeepCopy() *CanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStrategy) DeepCopyInto(out *CanaryStrategy) {
+	*out = *in
+	if in.AnalysisWindow != nil {
+		in, out := &in.AnalysisWindow, &out.AnalysisWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxFilterFailures != nil {
+		in, out := &in.MaxFilterFailures, &out.MaxFilterFailures
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxDeadLetterQueueBytes != nil {
+		in, out := &in.MaxDeadLetterQueueBytes, &out.MaxDeadLetterQueueBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxOutputErrors != nil {
+		in, out := &in.MaxOutputErrors, &out.MaxOutputErrors
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStrategy.
+func (in *CanaryStrategy) DeepCopy() *CanaryStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CentralManagementSpec) DeepCopyInto(out *CentralManagementSpec) {
+	*out = *in
+	if in.PipelineIDs != nil {
+		in, out := &in.PipelineIDs, &out.PipelineIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CentralManagementSpec.
+func (in *CentralManagementSpec) DeepCopy() *CentralManagementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CentralManagementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CentralManagementStatus) DeepCopyInto(out *CentralManagementStatus) {
+	*out = *in
+	if in.SeededPipelines != nil {
+		in, out := &in.SeededPipelines, &out.SeededPipelines
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CentralManagementStatus.
+func (in *CentralManagementStatus) DeepCopy() *CentralManagementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CentralManagementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataStreamSpec) DeepCopyInto(out *DataStreamSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataStreamSpec.
+func (in *DataStreamSpec) DeepCopy() *DataStreamSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataStreamSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeadLetterQueueReprocessing) DeepCopyInto(out *DeadLetterQueueReprocessing) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeadLetterQueueReprocessing.
+func (in *DeadLetterQueueReprocessing) DeepCopy() *DeadLetterQueueReprocessing {
+	if in == nil {
+		return nil
+	}
+	out := new(DeadLetterQueueReprocessing)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeadLetterQueueSpec) DeepCopyInto(out *DeadLetterQueueSpec) {
+	*out = *in
+	if in.VolumeClaimSpec != nil {
+		in, out := &in.VolumeClaimSpec, &out.VolumeClaimSpec
+		*out = new(corev1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reprocessing != nil {
+		in, out := &in.Reprocessing, &out.Reprocessing
+		*out = new(DeadLetterQueueReprocessing)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeadLetterQueueSpec.
+func (in *DeadLetterQueueSpec) DeepCopy() *DeadLetterQueueSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeadLetterQueueSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexBootstrapSpec) DeepCopyInto(out *IndexBootstrapSpec) {
+	*out = *in
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = (*in).DeepCopy()
+	}
+	if in.ILMPolicy != nil {
+		in, out := &in.ILMPolicy, &out.ILMPolicy
+		*out = (*in).DeepCopy()
+	}
+	if in.DataStream != nil {
+		in, out := &in.DataStream, &out.DataStream
+		*out = new(DataStreamSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexBootstrapSpec.
+func (in *IndexBootstrapSpec) DeepCopy() *IndexBootstrapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexBootstrapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexBootstrapStatus) DeepCopyInto(out *IndexBootstrapStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexBootstrapStatus.
+func (in *IndexBootstrapStatus) DeepCopy() *IndexBootstrapStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexBootstrapStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InputService) DeepCopyInto(out *InputService) {
+	*out = *in
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Service.DeepCopyInto(&out.Service)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InputService.
+func (in *InputService) DeepCopy() *InputService {
+	if in == nil {
+		return nil
+	}
+	out := new(InputService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InputServiceStatus) DeepCopyInto(out *InputServiceStatus) {
+	*out = *in
+	if in.ExternalAddresses != nil {
+		in, out := &in.ExternalAddresses, &out.ExternalAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InputServiceStatus.
+func (in *InputServiceStatus) DeepCopy() *InputServiceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InputServiceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingSpec) DeepCopyInto(out *LoggingSpec) {
+	*out = *in
+	if in.Loggers != nil {
+		in, out := &in.Loggers, &out.Loggers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingSpec.
+func (in *LoggingSpec) DeepCopy() *LoggingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Logstash) DeepCopyInto(out *Logstash) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	if in.assocConf != nil {
 		in, out := &in.assocConf, &out.assocConf
 		*out = new(commonv1beta1.AssociationConf)
 		**out = **in
 	}
+	if in.monitoringAssocConf != nil {
+		in, out := &in.monitoringAssocConf, &out.monitoringAssocConf
+		*out = new(commonv1beta1.AssociationConf)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Logstash.
@@ -77,10 +339,138 @@ func (in *LogstashList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogstashPipeline) DeepCopyInto(out *LogstashPipeline) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogstashPipeline.
+func (in *LogstashPipeline) DeepCopy() *LogstashPipeline {
+	if in == nil {
+		return nil
+	}
+	out := new(LogstashPipeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LogstashPipeline) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogstashPipelineList) DeepCopyInto(out *LogstashPipelineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LogstashPipeline, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogstashPipelineList.
+func (in *LogstashPipelineList) DeepCopy() *LogstashPipelineList {
+	if in == nil {
+		return nil
+	}
+	out := new(LogstashPipelineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LogstashPipelineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogstashPipelineSpec) DeepCopyInto(out *LogstashPipelineSpec) {
+	*out = *in
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BatchSize != nil {
+		in, out := &in.BatchSize, &out.BatchSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BatchDelay != nil {
+		in, out := &in.BatchDelay, &out.BatchDelay
+		*out = new(int32)
+		**out = **in
+	}
+	in.Queue.DeepCopyInto(&out.Queue)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogstashPipelineSpec.
+func (in *LogstashPipelineSpec) DeepCopy() *LogstashPipelineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogstashPipelineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogstashPipelineStatus) DeepCopyInto(out *LogstashPipelineStatus) {
+	*out = *in
+	if in.Admissions != nil {
+		in, out := &in.Admissions, &out.Admissions
+		*out = make([]PipelineAdmission, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogstashPipelineStatus.
+func (in *LogstashPipelineStatus) DeepCopy() *LogstashPipelineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LogstashPipelineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogstashSpec) DeepCopyInto(out *LogstashSpec) {
 	*out = *in
 	out.ElasticsearchRef = in.ElasticsearchRef
+	if in.APIKeyExpiration != nil {
+		in, out := &in.APIKeyExpiration, &out.APIKeyExpiration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	in.CredentialRotation.DeepCopyInto(&out.CredentialRotation)
+	if in.PipelineSelector != nil {
+		in, out := &in.PipelineSelector, &out.PipelineSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Topology != nil {
+		in, out := &in.Topology, &out.Topology
+		*out = make([]TopologyPipeline, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.HTTP.DeepCopyInto(&out.HTTP)
 	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
 	if in.SecureSettings != nil {
@@ -90,6 +480,29 @@ func (in *LogstashSpec) DeepCopyInto(out *LogstashSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.UpdateStrategy.DeepCopyInto(&out.UpdateStrategy)
+	if in.DrainTimeout != nil {
+		in, out := &in.DrainTimeout, &out.DrainTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	out.Monitoring = in.Monitoring
+	in.DeadLetterQueue.DeepCopyInto(&out.DeadLetterQueue)
+	in.IndexBootstrap.DeepCopyInto(&out.IndexBootstrap)
+	if in.JVMOptions != nil {
+		in, out := &in.JVMOptions, &out.JVMOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Logging.DeepCopyInto(&out.Logging)
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]InputService, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.CentralManagement.DeepCopyInto(&out.CentralManagement)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogstashSpec.
@@ -105,7 +518,33 @@ func (in *LogstashSpec) DeepCopy() *LogstashSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogstashStatus) DeepCopyInto(out *LogstashStatus) {
 	*out = *in
-	out.ReconcilerStatus = in.ReconcilerStatus
+	in.ReconcilerStatus.DeepCopyInto(&out.ReconcilerStatus)
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IndexBootstrap != nil {
+		in, out := &in.IndexBootstrap, &out.IndexBootstrap
+		*out = new(IndexBootstrapStatus)
+		**out = **in
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]InputServiceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CentralManagement != nil {
+		in, out := &in.CentralManagement, &out.CentralManagement
+		*out = new(CentralManagementStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastCredentialRotation != nil {
+		in, out := &in.LastCredentialRotation, &out.LastCredentialRotation
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogstashStatus.
@@ -117,3 +556,115 @@ func (in *LogstashStatus) DeepCopy() *LogstashStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	out.ElasticsearchRef = in.ElasticsearchRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineAdmission) DeepCopyInto(out *PipelineAdmission) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineAdmission.
+func (in *PipelineAdmission) DeepCopy() *PipelineAdmission {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineAdmission)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineEdge) DeepCopyInto(out *PipelineEdge) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineEdge.
+func (in *PipelineEdge) DeepCopy() *PipelineEdge {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineEdge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineQueueSpec) DeepCopyInto(out *PipelineQueueSpec) {
+	*out = *in
+	if in.CheckpointWrites != nil {
+		in, out := &in.CheckpointWrites, &out.CheckpointWrites
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineQueueSpec.
+func (in *PipelineQueueSpec) DeepCopy() *PipelineQueueSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineQueueSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyPipeline) DeepCopyInto(out *TopologyPipeline) {
+	*out = *in
+	if in.SendTo != nil {
+		in, out := &in.SendTo, &out.SendTo
+		*out = make([]PipelineEdge, len(*in))
+		copy(*out, *in)
+	}
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = new(int32)
+		**out = **in
+	}
+	in.Queue.DeepCopyInto(&out.Queue)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyPipeline.
+func (in *TopologyPipeline) DeepCopy() *TopologyPipeline {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyPipeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateStrategy) DeepCopyInto(out *UpdateStrategy) {
+	*out = *in
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateStrategy.
+func (in *UpdateStrategy) DeepCopy() *UpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
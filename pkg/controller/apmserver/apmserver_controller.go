@@ -21,6 +21,7 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates/http"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/conditions"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/deployment"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/driver"
@@ -225,12 +226,14 @@ func (r *ReconcileApmServer) doReconcile(request reconcile.Request, as *apmv1bet
 	state := NewState(request, as)
 	svc, err := common.ReconcileService(r.Client, r.scheme, NewService(*as), as)
 	if err != nil {
+		r.reportReconciliationError(state, err)
 		return reconcile.Result{}, err
 	}
 	results := apmcerts.Reconcile(r, as, []corev1.Service{*svc}, r.CACertRotation)
 	if results.HasError() {
 		res, err := results.Aggregate()
 		k8s.EmitErrorEvent(r.recorder, err, as, events.EventReconciliationError, "Certificate reconciliation error: %v", err)
+		r.reportReconciliationError(state, err)
 		return res, err
 	}
 
@@ -241,14 +244,25 @@ func (r *ReconcileApmServer) doReconcile(request reconcile.Request, as *apmv1bet
 			return reconcile.Result{Requeue: true}, nil
 		}
 		k8s.EmitErrorEvent(r.recorder, err, as, events.EventReconciliationError, "Deployment reconciliation error: %v", err)
+		r.reportReconciliationError(state, err)
 		return state.Result, err
 	}
 
 	state.UpdateApmServerExternalService(*svc)
+	state.SetConditions(conditions.ConfigValid(as.Generation, nil), conditions.Reconciled(as.Generation, nil))
 
 	return r.updateStatus(state)
 }
 
+// reportReconciliationError records the given reconciliation error in the conditions of the ApmServer status.
+// Failing to update the status is only logged, since the reconciliation is retried anyway.
+func (r *ReconcileApmServer) reportReconciliationError(state State, err error) {
+	state.SetConditions(conditions.Reconciled(state.ApmServer.Generation, err))
+	if _, err := r.updateStatus(state); err != nil {
+		log.Error(err, "Failed to update status", "namespace", state.ApmServer.Namespace, "as_name", state.ApmServer.Name)
+	}
+}
+
 func (r *ReconcileApmServer) reconcileApmServerSecret(as *apmv1beta1.ApmServer) (*corev1.Secret, error) {
 	expectedApmServerSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
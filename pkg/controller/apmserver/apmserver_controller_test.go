@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	apmv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/apm/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/apmserver/labels"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates/http"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
@@ -179,6 +180,7 @@ func expectedDeploymentParams() testParams {
 						},
 						Resources: DefaultResources,
 					}},
+					Affinity:                     defaults.SpreadAffinity(labels.NewLabels("test-apm-server")),
 					AutomountServiceAccountToken: &false,
 				},
 			},
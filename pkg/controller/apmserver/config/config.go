@@ -53,9 +53,13 @@ func NewConfigFromSpec(c k8s.Client, as *v1beta1.ApmServer) (*settings.Canonical
 		}
 
 		tmpOutputCfg := map[string]interface{}{
-			"output.elasticsearch.hosts":    []string{as.AssociationConf().GetURL()},
-			"output.elasticsearch.username": username,
-			"output.elasticsearch.password": password,
+			"output.elasticsearch.hosts": []string{as.AssociationConf().GetURL()},
+		}
+		if as.AssociationConf().IsAPIKey() {
+			tmpOutputCfg["output.elasticsearch.api_key"] = password
+		} else {
+			tmpOutputCfg["output.elasticsearch.username"] = username
+			tmpOutputCfg["output.elasticsearch.password"] = password
 		}
 		if as.AssociationConf().GetCACertProvided() {
 			tmpOutputCfg["output.elasticsearch.ssl.certificate_authorities"] = []string{filepath.Join(CertificatesDir, certificates.CAFileName)}
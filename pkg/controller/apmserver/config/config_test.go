@@ -68,6 +68,21 @@ func TestNewConfigFromSpec(t *testing.T) {
 				"output.elasticsearch.ssl.certificate_authorities": []string{"config/elasticsearch-certs/ca.crt"},
 			},
 		},
+		{
+			name: "with API key",
+			assocConf: &commonv1beta1.AssociationConf{
+				AuthSecretName: "test-es-elastic-user",
+				AuthSecretKey:  "elastic",
+				AuthMode:       commonv1beta1.APIKeyAuthMode,
+				CASecretName:   "test-es-http-ca-public",
+				CACertProvided: false,
+				URL:            "https://test-es-http.default.svc:9200",
+			},
+			wantConf: map[string]interface{}{
+				"output.elasticsearch.hosts":   []string{"https://test-es-http.default.svc:9200"},
+				"output.elasticsearch.api_key": "password",
+			},
+		},
 		{
 			name: "missing auth secret",
 			assocConf: &commonv1beta1.AssociationConf{
@@ -10,6 +10,7 @@ import (
 
 	"github.com/cloudptio/logstash-operator/pkg/apis/apm/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/controller/apmserver/config"
+	"github.com/cloudptio/logstash-operator/pkg/controller/apmserver/labels"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/keystore"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/volume"
@@ -110,6 +111,7 @@ func newPodSpec(as *v1beta1.ApmServer, p PodSpecParams) corev1.PodTemplateSpec {
 	builder := defaults.NewPodTemplateBuilder(
 		p.PodTemplate, v1beta1.APMServerContainerName).
 		WithResources(DefaultResources).
+		WithAffinity(defaults.SpreadAffinity(labels.NewLabels(as.Name))).
 		WithDockerImage(p.CustomImageName, imageWithVersion(defaultImageRepositoryAndName, p.Version)).
 		WithReadinessProbe(readinessProbe(as.Spec.HTTP.TLS.Enabled())).
 		WithPorts(ports).
@@ -12,6 +12,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/cloudptio/logstash-operator/pkg/apis/apm/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/apmserver/labels"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/volume"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/settings"
 )
@@ -60,6 +62,7 @@ func TestNewPodSpec(t *testing.T) {
 					Volumes: []corev1.Volume{
 						configSecretVol.Volume(), configVolume.Volume(),
 					},
+					Affinity:                     defaults.SpreadAffinity(labels.NewLabels("fake-apm")),
 					AutomountServiceAccountToken: &varFalse,
 					Containers: []corev1.Container{
 						{
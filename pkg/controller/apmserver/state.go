@@ -6,6 +6,9 @@ package apmserver
 
 import (
 	"github.com/cloudptio/logstash-operator/pkg/apis/apm/v1beta1"
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/conditions"
+	commondeployment "github.com/cloudptio/logstash-operator/pkg/controller/common/deployment"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -37,6 +40,17 @@ func (s State) UpdateApmServerState(deployment v1.Deployment, apmServerSecret co
 			s.ApmServer.Status.Health = v1beta1.ApmServerGreen
 		}
 	}
+	s.SetConditions(conditions.FromDeployment(s.ApmServer.Generation, deployment)...)
+	if trigger, completed := commondeployment.CompletedRestartTrigger(deployment); completed {
+		s.ApmServer.Status.RestartTrigger = trigger
+	}
+}
+
+// SetConditions sets the given conditions in the ApmServer status.
+func (s State) SetConditions(c ...commonv1beta1.Condition) {
+	for _, condition := range c {
+		s.ApmServer.Status.Conditions = s.ApmServer.Status.Conditions.Set(condition)
+	}
 }
 
 // UpdateApmServerExternalService updates the ApmServer ExternalService status.
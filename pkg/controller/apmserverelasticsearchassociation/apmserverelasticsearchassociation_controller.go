@@ -16,6 +16,7 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates/http"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/conditions"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/finalizer"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/operator"
@@ -145,6 +146,7 @@ func (r *ReconcileApmServerElasticsearchAssociation) Reconcile(request reconcile
 		&apmServer,
 		watchFinalizer(apmName, r.watches),
 		user.UserFinalizer(r.Client, apmServer.Kind, NewUserLabelSelector(apmName)),
+		association.APIKeyFinalizer(r.Client, r.Dialer, &apmServer, apmServer.Kind, apmAPIKeySuffix),
 	)
 	if err != nil {
 		// failed to prepare finalizer or run finalizer: retry
@@ -164,21 +166,28 @@ func (r *ReconcileApmServerElasticsearchAssociation) Reconcile(request reconcile
 		return reconcile.Result{}, err
 	}
 
-	newStatus, err := r.reconcileInternal(&apmServer)
+	newStatus, credentials, err := r.reconcileInternal(&apmServer)
 	oldStatus := apmServer.Status.Association
-	if !reflect.DeepEqual(oldStatus, newStatus) {
+	lastRotation := credentials.LastRotation(apmServer.Status.LastCredentialRotation)
+	newConditions := conditions.SetAssociationReady(apmServer.Status.Conditions, apmServer.Generation,
+		map[string]commonv1beta1.AssociationStatus{"Elasticsearch": newStatus})
+	if !reflect.DeepEqual(oldStatus, newStatus) || lastRotation != apmServer.Status.LastCredentialRotation ||
+		!reflect.DeepEqual(apmServer.Status.Conditions, newConditions) {
 		apmServer.Status.Association = newStatus
+		apmServer.Status.LastCredentialRotation = lastRotation
+		apmServer.Status.Conditions = newConditions
 		if err := r.Status().Update(&apmServer); err != nil {
 			return defaultRequeue, err
 		}
-		r.recorder.AnnotatedEventf(&apmServer,
-			annotation.ForAssociationStatusChange(oldStatus, newStatus),
-			corev1.EventTypeNormal,
-			events.EventAssociationStatusChange,
-			"Association status changed from [%s] to [%s]", oldStatus, newStatus)
-
+		if oldStatus != newStatus {
+			r.recorder.AnnotatedEventf(&apmServer,
+				annotation.ForAssociationStatusChange(oldStatus, newStatus),
+				corev1.EventTypeNormal,
+				events.EventAssociationStatusChange,
+				"Association status changed from [%s] to [%s]", oldStatus, newStatus)
+		}
 	}
-	return resultFromStatus(newStatus), err
+	return association.WithCredentialsRequeue(resultFromStatus(newStatus), credentials.Requeue), err
 }
 
 func elasticsearchWatchName(assocKey types.NamespacedName) string {
@@ -222,11 +231,13 @@ func (r *ReconcileApmServerElasticsearchAssociation) isCompatible(apmServer *apm
 	return compat, err
 }
 
-func (r *ReconcileApmServerElasticsearchAssociation) reconcileInternal(apmServer *apmtype.ApmServer) (commonv1beta1.AssociationStatus, error) {
+// reconcileInternal reconciles the association of the given APM Server with Elasticsearch. It returns the status of
+// the association, and the reconciled credentials of the APM Server if any.
+func (r *ReconcileApmServerElasticsearchAssociation) reconcileInternal(apmServer *apmtype.ApmServer) (commonv1beta1.AssociationStatus, association.Credentials, error) {
 	// no auto-association nothing to do
 	elasticsearchRef := apmServer.Spec.ElasticsearchRef
 	if !elasticsearchRef.IsDefined() {
-		return commonv1beta1.AssociationUnknown, nil
+		return commonv1beta1.AssociationUnknown, association.Credentials{}, nil
 	}
 	if elasticsearchRef.Namespace == "" {
 		// no namespace provided: default to the APM server namespace
@@ -242,7 +253,7 @@ func (r *ReconcileApmServerElasticsearchAssociation) reconcileInternal(apmServer
 		Watcher: assocKey,
 	})
 	if err != nil {
-		return commonv1beta1.AssociationFailed, err
+		return commonv1beta1.AssociationFailed, association.Credentials{}, err
 	}
 
 	var es estype.Elasticsearch
@@ -254,39 +265,40 @@ func (r *ReconcileApmServerElasticsearchAssociation) reconcileInternal(apmServer
 			// ES is not found, remove any existing backend configuration and retry in a bit.
 			if err := association.RemoveAssociationConf(r.Client, apmServer); err != nil && !errors.IsConflict(err) {
 				log.Error(err, "Failed to remove Elasticsearch output from APMServer object", "namespace", apmServer.Namespace, "name", apmServer.Name)
-				return commonv1beta1.AssociationPending, err
+				return commonv1beta1.AssociationPending, association.Credentials{}, err
 			}
 
-			return commonv1beta1.AssociationPending, nil
+			return commonv1beta1.AssociationPending, association.Credentials{}, nil
 		}
-		return commonv1beta1.AssociationFailed, err
+		return commonv1beta1.AssociationFailed, association.Credentials{}, err
 	}
 
-	if err := association.ReconcileEsUser(
-		r.Client,
-		r.scheme,
-		apmServer,
-		map[string]string{
-			AssociationLabelName:      apmServer.Name,
-			AssociationLabelNamespace: apmServer.Namespace,
-		},
-		"superuser",
-		apmUserSuffix,
-		es,
-	); err != nil { // TODO distinguish conflicts and non-recoverable errors here
-		return commonv1beta1.AssociationPending, err
+	if !association.IsReferenceAllowed(es, apmServer, apmServer.Kind) {
+		return commonv1beta1.AssociationFailed, association.Credentials{}, r.denyReference(apmServer, es)
+	}
+
+	if apmServer.Spec.AuthMode == commonv1beta1.APIKeyAuthMode {
+		if err := association.CheckAPIKeySupport(es, apmServer.Spec.Version, apmAPIKeyMinVersion); err != nil {
+			k8s.EmitErrorEvent(r.recorder, err, apmServer, events.EventAssociationError, "Unsupported apiKey auth mode: %v", err)
+			return commonv1beta1.AssociationFailed, association.Credentials{}, nil
+		}
+	}
+
+	credentials, err := r.reconcileAuth(apmServer, es)
+	if err != nil {
+		return commonv1beta1.AssociationPending, association.Credentials{}, err
 	}
 
 	caSecret, err := r.reconcileElasticsearchCA(apmServer, elasticsearchRef.NamespacedName())
 	if err != nil {
-		return commonv1beta1.AssociationPending, err // maybe not created yet
+		return commonv1beta1.AssociationPending, association.Credentials{}, err // maybe not created yet
 	}
 
 	// construct the expected ES output configuration
-	authSecretRef := association.ClearTextSecretKeySelector(apmServer, apmUserSuffix)
 	expectedAssocConf := &commonv1beta1.AssociationConf{
-		AuthSecretName: authSecretRef.Name,
-		AuthSecretKey:  authSecretRef.Key,
+		AuthSecretName: credentials.Selector.Name,
+		AuthSecretKey:  credentials.Selector.Key,
+		AuthMode:       apmServer.Spec.AuthMode,
 		CACertProvided: caSecret.CACertProvided,
 		CASecretName:   caSecret.Name,
 		URL:            services.ExternalServiceURL(es),
@@ -296,10 +308,10 @@ func (r *ReconcileApmServerElasticsearchAssociation) reconcileInternal(apmServer
 		log.Info("Updating APMServer spec with Elasticsearch association configuration", "namespace", apmServer.Namespace, "name", apmServer.Name)
 		if err := association.UpdateAssociationConf(r.Client, apmServer, expectedAssocConf); err != nil {
 			if errors.IsConflict(err) {
-				return commonv1beta1.AssociationPending, nil
+				return commonv1beta1.AssociationPending, association.Credentials{}, nil
 			}
 			log.Error(err, "Failed to update APMServer association configuration", "namespace", apmServer.Namespace, "name", apmServer.Name)
-			return commonv1beta1.AssociationPending, err
+			return commonv1beta1.AssociationPending, association.Credentials{}, err
 		}
 		apmServer.SetAssociationConf(expectedAssocConf)
 	}
@@ -308,7 +320,7 @@ func (r *ReconcileApmServerElasticsearchAssociation) reconcileInternal(apmServer
 		log.Error(err, "Error while trying to delete orphaned resources. Continuing.", "namespace", apmServer.Namespace, "as_name", apmServer.Name)
 	}
 
-	return commonv1beta1.AssociationEstablished, nil
+	return commonv1beta1.AssociationEstablished, credentials, nil
 }
 
 func (r *ReconcileApmServerElasticsearchAssociation) reconcileElasticsearchCA(apm *apmtype.ApmServer, es types.NamespacedName) (association.CASecret, error) {
@@ -0,0 +1,147 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package apmserverelasticsearchassociation
+
+import (
+	"context"
+	"time"
+
+	apmtype "github.com/cloudptio/logstash-operator/pkg/apis/apm/v1beta1"
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	estype "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	apmlabels "github.com/cloudptio/logstash-operator/pkg/controller/apmserver/labels"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// apmAPIKeySuffix is used to suffix the API key and associated secret resources.
+const apmAPIKeySuffix = "apm-api-key"
+
+// apmAPIKeyMinVersion is the first APM Server version supporting the `output.elasticsearch.api_key` setting.
+var apmAPIKeyMinVersion = version.MustParse("7.6.0")
+
+// apmAPIKeyRoleDescriptors limit the privileges of the APM Server API key to the setup and the writing of the APM
+// indices.
+var apmAPIKeyRoleDescriptors = map[string]esclient.RoleDescriptor{
+	"apm_writer": {
+		Cluster: []string{"monitor", "manage_index_templates", "manage_ilm", "manage_ingest_pipelines"},
+		Indices: []esclient.IndicesPrivileges{{
+			Names:      []string{"apm-*"},
+			Privileges: []string{"write", "create_index", "manage", "manage_ilm"},
+		}},
+	},
+}
+
+// reconcileAuth reconciles the credentials the APM Server uses to authenticate to the given Elasticsearch cluster,
+// a user or an API key depending on the auth mode, and deletes the credentials of the other mode.
+// The credentials are rotated with the rotation policy of the APM Server.
+func (r *ReconcileApmServerElasticsearchAssociation) reconcileAuth(
+	apmServer *apmtype.ApmServer,
+	es estype.Elasticsearch,
+) (association.Credentials, error) {
+	labels := map[string]string{
+		AssociationLabelName:      apmServer.Name,
+		AssociationLabelNamespace: apmServer.Namespace,
+	}
+	newESClient := func() (esclient.Client, error) {
+		return association.NewElasticsearchClient(r.Client, r.Dialer, es)
+	}
+	rotation := association.CredentialRotation{
+		Interval:  apmServer.Spec.CredentialRotation.GetInterval(),
+		PodLabels: map[string]string{apmlabels.ApmServerNameLabelName: apmServer.Name},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+
+	if apmServer.Spec.AuthMode != commonv1beta1.APIKeyAuthMode {
+		if err := association.DeleteAPIKey(ctx, r.Client, newESClient, apmServer, apmAPIKeySuffix); err != nil {
+			return association.Credentials{}, err
+		}
+		// TODO distinguish conflicts and non-recoverable errors here
+		return association.ReconcileRotatedEsUser(
+			r.Client,
+			r.scheme,
+			apmServer,
+			labels,
+			"superuser",
+			apmUserSuffix,
+			es,
+			rotation,
+			time.Now(),
+		)
+	}
+
+	credentials, err := association.ReconcileAPIKey(ctx, r.Client, r.scheme, newESClient, association.APIKeyParams{
+		Associated:      apmServer,
+		Elasticsearch:   k8s.ExtractNamespacedName(&es),
+		Labels:          labels,
+		Suffix:          apmAPIKeySuffix,
+		RoleDescriptors: apmAPIKeyRoleDescriptors,
+		Expiration:      commonv1beta1.APIKeyExpirationOrDefault(apmServer.Spec.APIKeyExpiration),
+		Rotation:        rotation,
+	}, time.Now())
+	if err != nil {
+		return association.Credentials{}, err
+	}
+	if err := deleteUser(r.Client, apmServer, es.Namespace); err != nil {
+		return association.Credentials{}, err
+	}
+	return credentials, nil
+}
+
+// denyReference reports that the given APM Server is not allowed to reference the given Elasticsearch cluster, and
+// revokes the connection details and the credentials it may have been granted before.
+func (r *ReconcileApmServerElasticsearchAssociation) denyReference(apmServer *apmtype.ApmServer, es estype.Elasticsearch) error {
+	r.recorder.Event(apmServer, corev1.EventTypeWarning, events.EventAssociationDenied,
+		association.ReferenceDeniedError(es, apmServer, apmServer.Kind).Error())
+	if err := association.RemoveAssociationConf(r.Client, apmServer); err != nil && !apierrors.IsConflict(err) {
+		return err
+	}
+	newESClient := func() (esclient.Client, error) {
+		return association.NewElasticsearchClient(r.Client, r.Dialer, es)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	if err := association.DeleteAPIKey(ctx, r.Client, newESClient, apmServer, apmAPIKeySuffix); err != nil {
+		return err
+	}
+	return deleteUser(r.Client, apmServer, es.Namespace)
+}
+
+// deleteUser deletes the users of the given APM Server in the given Elasticsearch namespace, and the secret holding
+// their passwords, if they exist.
+func deleteUser(c k8s.Client, apmServer *apmtype.ApmServer, esNamespace string) error {
+	keys := append(association.RotatedUserKeys(apmServer, esNamespace, apmUserSuffix), types.NamespacedName{
+		Namespace: apmServer.Namespace,
+		Name:      association.ClearTextSecretKeySelector(apmServer, apmUserSuffix).Name,
+	})
+	for _, key := range keys {
+		var secret corev1.Secret
+		err := c.Get(key, &secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		createdBy := secret.Labels[AssociationLabelName] == apmServer.Name &&
+			secret.Labels[AssociationLabelNamespace] == apmServer.Namespace
+		if !metav1.IsControlledBy(&secret, apmServer) && !createdBy {
+			continue
+		}
+		log.Info("Deleting secret", "namespace", secret.Namespace, "secret_name", secret.Name, "as_name", apmServer.Name)
+		if err := c.Delete(&secret); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
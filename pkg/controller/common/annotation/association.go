@@ -16,6 +16,9 @@ const (
 	PrevAssocStatusAnnotation = "association.k8s.elastic.co/previous-status"
 	// AssociationConfAnnotation is the annotation used to define the config for associated Elasticsearch cluster.
 	AssociationConfAnnotation = "association.k8s.elastic.co/es-conf"
+	// MonitoringAssociationConfAnnotation is the annotation used to define the config for the associated monitoring
+	// Elasticsearch cluster.
+	MonitoringAssociationConfAnnotation = "association.k8s.elastic.co/monitoring-es-conf"
 )
 
 // ForAssociationStatusChange constructs the annotation map for an association status change event.
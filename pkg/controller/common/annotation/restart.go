@@ -0,0 +1,29 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package annotation
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RestartTriggerAnnotation triggers a rolling restart of the pods of a resource whenever its value changes.
+	// The value is copied to the pod template, and reported in the status of the resource once all pods restarted.
+	RestartTriggerAnnotation = "common.k8s.elastic.co/restart-trigger"
+)
+
+// PropagateRestartTrigger copies the restart trigger annotation of the given resource, if any, to the given pod
+// template, so that a change of its value rolls out a new version of the pods.
+func PropagateRestartTrigger(resource metav1.Object, podTemplate *corev1.PodTemplateSpec) {
+	trigger, exists := resource.GetAnnotations()[RestartTriggerAnnotation]
+	if !exists {
+		return
+	}
+	if podTemplate.Annotations == nil {
+		podTemplate.Annotations = map[string]string{}
+	}
+	podTemplate.Annotations[RestartTriggerAnnotation] = trigger
+}
@@ -0,0 +1,46 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package annotation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPropagateRestartTrigger(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		podTemplate corev1.PodTemplateSpec
+		want        map[string]string
+	}{
+		{
+			name: "no restart trigger",
+		},
+		{
+			name:        "restart trigger set on a template without annotations",
+			annotations: map[string]string{RestartTriggerAnnotation: "1"},
+			want:        map[string]string{RestartTriggerAnnotation: "1"},
+		},
+		{
+			name:        "restart trigger updated on a template with annotations",
+			annotations: map[string]string{RestartTriggerAnnotation: "2", "other": "a"},
+			podTemplate: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{RestartTriggerAnnotation: "1", "pod": "b"},
+			}},
+			want: map[string]string{RestartTriggerAnnotation: "2", "pod": "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := metav1.ObjectMeta{Annotations: tt.annotations}
+			PropagateRestartTrigger(&resource, &tt.podTemplate)
+			require.Equal(t, tt.want, tt.podTemplate.Annotations)
+		})
+	}
+}
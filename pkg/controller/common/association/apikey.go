@@ -0,0 +1,421 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates/http"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/finalizer"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/hash"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/reconciler"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	esname "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/services"
+	esuser "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/user"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/cloudptio/logstash-operator/pkg/utils/net"
+	pkgerrors "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// APIKeyIDAnnotation holds the id of the API key stored in an association secret.
+	APIKeyIDAnnotation = "association.k8s.elastic.co/api-key-id"
+	// APIKeyExpirationAnnotation holds the expiration time of the API key stored in an association secret,
+	// in RFC 3339 format.
+	APIKeyExpirationAnnotation = "association.k8s.elastic.co/api-key-expiration"
+	// APIKeyElasticsearchAnnotation holds the namespaced name of the Elasticsearch cluster the API key stored in an
+	// association secret was created in.
+	APIKeyElasticsearchAnnotation = "association.k8s.elastic.co/api-key-elasticsearch"
+	// APIKeyPreviousIDAnnotation holds the id of the API key replaced by the one stored in an association secret,
+	// until it is invalidated.
+	APIKeyPreviousIDAnnotation = "association.k8s.elastic.co/api-key-previous-id"
+	// APIKeyRoleDescriptorsHashAnnotation holds a hash of the role descriptors of the API key stored in an association
+	// secret, the key being replaced when they change.
+	APIKeyRoleDescriptorsHashAnnotation = "association.k8s.elastic.co/api-key-role-descriptors-hash"
+)
+
+var log = logf.Log.WithName("association")
+
+// APIKeyMinElasticsearchVersion is the first Elasticsearch version with the API key service.
+var APIKeyMinElasticsearchVersion = version.MustParse("6.7.0")
+
+// CheckAPIKeySupport returns an error if an associated object of the given version cannot authenticate to the given
+// Elasticsearch cluster with an API key. The API key service requires Elasticsearch 6.7 or above and is only enabled
+// with TLS on the HTTP layer, and the associated object must be of the given minimum version to use API keys.
+func CheckAPIKeySupport(es esv1beta1.Elasticsearch, associatedVersion string, associatedMinVersion version.Version) error {
+	esVersion, err := version.Parse(es.Spec.Version)
+	if err != nil {
+		return err
+	}
+	if !esVersion.IsSameOrAfter(APIKeyMinElasticsearchVersion) {
+		return fmt.Errorf("API keys require Elasticsearch %s or above, got %s", APIKeyMinElasticsearchVersion, esVersion)
+	}
+	if !es.Spec.HTTP.TLS.Enabled() {
+		return fmt.Errorf("API keys require TLS on the HTTP layer of Elasticsearch %s/%s", es.Namespace, es.Name)
+	}
+	v, err := version.Parse(associatedVersion)
+	if err != nil {
+		return err
+	}
+	if !v.IsSameOrAfter(associatedMinVersion) {
+		return fmt.Errorf("API key authentication requires version %s or above, got %s", associatedMinVersion, v)
+	}
+	return nil
+}
+
+// APIKeyParams describes the API key of an associated object.
+type APIKeyParams struct {
+	Associated commonv1beta1.Associated
+	// Elasticsearch is the cluster the API key is created in.
+	Elasticsearch types.NamespacedName
+	// Labels are applied to the secret holding the API key.
+	Labels map[string]string
+	// Suffix is used to suffix the secret holding the API key, and the name of the API key.
+	Suffix string
+	// RoleDescriptors limit the privileges of the API key.
+	RoleDescriptors map[string]esclient.RoleDescriptor
+	// Expiration is the lifetime of the API key.
+	Expiration time.Duration
+	// Rotation is the rotation policy of the API key.
+	Rotation CredentialRotation
+}
+
+// apiKeyRenewal returns the time at which the API key stored in the given secret must be replaced, leaving a quarter
+// of its lifetime to the associated pods to pick up the new one.
+// A key expiring later than the given lifetime allows, which was created with a longer lifetime, is replaced now, and
+// so is a key created with different role descriptors.
+// It returns false if the secret does not hold a key of the given Elasticsearch cluster.
+func apiKeyRenewal(secret corev1.Secret, params APIKeyParams, now time.Time) (time.Time, bool) {
+	dataKey := elasticsearchUserName(params.Associated, params.Suffix)
+	if len(secret.Data[dataKey]) == 0 || secret.Annotations[APIKeyIDAnnotation] == "" ||
+		secret.Annotations[APIKeyElasticsearchAnnotation] != params.Elasticsearch.String() {
+		return time.Time{}, false
+	}
+	lifetime := params.Expiration
+	expiration, err := time.Parse(time.RFC3339, secret.Annotations[APIKeyExpirationAnnotation])
+	if err != nil {
+		return time.Time{}, false
+	}
+	if expiration.Sub(now) > lifetime ||
+		secret.Annotations[APIKeyRoleDescriptorsHashAnnotation] != hash.HashObject(params.RoleDescriptors) {
+		return now, true
+	}
+	return expiration.Add(-lifetime / 4), true
+}
+
+// ReconcileAPIKey makes sure the associated object has a valid API key, stored as `id:api_key` in a secret in its
+// namespace, along with the id and expiration time of the key in annotations.
+// A new key is created if there is none yet, if the current one is close to its expiration, or if it is due for
+// rotation. The replaced key stays valid until all the pods of the associated object restarted with the new one, and
+// is invalidated then. The Elasticsearch client is only created if a key must be created or invalidated.
+func ReconcileAPIKey(
+	ctx context.Context,
+	c k8s.Client,
+	s *runtime.Scheme,
+	newESClient func() (esclient.Client, error),
+	params APIKeyParams,
+	now time.Time,
+) (Credentials, error) {
+	secKey := secretKey(params.Associated, params.Suffix)
+	dataKey := elasticsearchUserName(params.Associated, params.Suffix)
+
+	var current corev1.Secret
+	err := c.Get(secKey, &current)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return Credentials{}, err
+	}
+
+	var esClient esclient.Client
+	getESClient := func() (esclient.Client, error) {
+		if esClient != nil {
+			return esClient, nil
+		}
+		created, err := newESClient()
+		esClient = created
+		return created, err
+	}
+	defer func() {
+		if esClient != nil {
+			esClient.Close()
+		}
+	}()
+
+	annotations := make(map[string]string)
+	data := current.Data
+	var issued time.Time
+	renewal, valid := apiKeyRenewal(current, params, now)
+	if valid {
+		for _, k := range []string{
+			APIKeyIDAnnotation, APIKeyExpirationAnnotation, APIKeyElasticsearchAnnotation, APIKeyPreviousIDAnnotation,
+			APIKeyRoleDescriptorsHashAnnotation,
+		} {
+			if v, exists := current.Annotations[k]; exists {
+				annotations[k] = v
+			}
+		}
+		expiration, _ := time.Parse(time.RFC3339, current.Annotations[APIKeyExpirationAnnotation])
+		issued = issuedAt(current, expiration.Add(-params.Expiration))
+		renewal = rotationDue(renewal, issued, params.Rotation)
+	}
+
+	if !valid || !now.Before(renewal) {
+		client, err := getESClient()
+		if err != nil {
+			return Credentials{}, err
+		}
+		apiKey, err := client.CreateAPIKey(ctx, esclient.APIKeyRequest{
+			Name:            dataKey,
+			Expiration:      fmt.Sprintf("%ds", int64(params.Expiration/time.Second)),
+			RoleDescriptors: params.RoleDescriptors,
+		})
+		if err != nil {
+			return Credentials{}, pkgerrors.Wrap(err, "failed to create API key")
+		}
+		expiration := now.Add(params.Expiration)
+		if apiKey.Expiration > 0 {
+			expiration = time.Unix(0, apiKey.Expiration*int64(time.Millisecond))
+		}
+		log.Info("Created API key", "namespace", secKey.Namespace, "name", params.Associated.GetName(),
+			"api_key_id", apiKey.ID, "expiration", expiration)
+
+		if valid {
+			// an older key still waiting for the pods to restart is left to expire
+			annotations[APIKeyPreviousIDAnnotation] = current.Annotations[APIKeyIDAnnotation]
+		}
+		issued = now.UTC().Truncate(time.Second)
+		annotations[APIKeyIDAnnotation] = apiKey.ID
+		annotations[APIKeyExpirationAnnotation] = expiration.UTC().Format(time.RFC3339)
+		annotations[APIKeyElasticsearchAnnotation] = params.Elasticsearch.String()
+		annotations[APIKeyRoleDescriptorsHashAnnotation] = hash.HashObject(params.RoleDescriptors)
+		data = map[string][]byte{
+			dataKey: []byte(apiKey.Credentials()),
+		}
+		renewal = rotationDue(expiration.Add(-params.Expiration/4), issued, params.Rotation)
+	}
+	annotations[CredentialsIssuedAnnotation] = issued.Format(time.RFC3339)
+	requeue := renewal.Sub(now)
+
+	if previousID := annotations[APIKeyPreviousIDAnnotation]; previousID != "" {
+		restarted, err := podsRestartedSince(c, params.Associated.GetNamespace(), params.Rotation.PodLabels, issued)
+		if err != nil {
+			return Credentials{}, err
+		}
+		if restarted {
+			client, err := getESClient()
+			if err != nil {
+				return Credentials{}, err
+			}
+			if err := client.InvalidateAPIKey(ctx, previousID); err != nil && !esclient.IsNotFound(err) {
+				return Credentials{}, pkgerrors.Wrap(err, "failed to invalidate API key")
+			}
+			log.Info("Invalidated API key", "namespace", secKey.Namespace, "name", params.Associated.GetName(),
+				"api_key_id", previousID)
+			delete(annotations, APIKeyPreviousIDAnnotation)
+		} else if requeue > retirementCheckInterval {
+			requeue = retirementCheckInterval
+		}
+	}
+
+	expected := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secKey.Name,
+			Namespace:   secKey.Namespace,
+			Labels:      params.Labels,
+			Annotations: annotations,
+		},
+		Data: data,
+	}
+	reconciled := corev1.Secret{}
+	if err := reconciler.ReconcileResource(reconciler.Params{
+		Client:     c,
+		Scheme:     s,
+		Owner:      params.Associated,
+		Expected:   &expected,
+		Reconciled: &reconciled,
+		NeedsUpdate: func() bool {
+			_, hasPrevious := reconciled.Annotations[APIKeyPreviousIDAnnotation]
+			return !reflect.DeepEqual(expected.Data, reconciled.Data) ||
+				!hasExpectedLabels(&expected, &reconciled) ||
+				!hasExpectedAnnotations(&expected, &reconciled) ||
+				hasPrevious && annotations[APIKeyPreviousIDAnnotation] == ""
+		},
+		UpdateReconciled: func() {
+			setExpectedLabels(&expected, &reconciled)
+			setExpectedAnnotations(&expected, &reconciled)
+			if annotations[APIKeyPreviousIDAnnotation] == "" {
+				delete(reconciled.Annotations, APIKeyPreviousIDAnnotation)
+			}
+			reconciled.Data = expected.Data
+		},
+	}); err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{
+		Selector: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secKey.Name},
+			Key:                  dataKey,
+		},
+		IssuedAt: issued,
+		Requeue:  requeue,
+	}, nil
+}
+
+// rotationDue returns the earliest of the given renewal time and of the next rotation of credentials issued at the
+// given time.
+func rotationDue(renewal time.Time, issued time.Time, rotation CredentialRotation) time.Time {
+	if rotation.Interval > 0 && issued.Add(rotation.Interval).Before(renewal) {
+		return issued.Add(rotation.Interval)
+	}
+	return renewal
+}
+
+// WithCredentialsRequeue makes the given reconciliation result requeue no later than the given delay, if any, to
+// renew, rotate or retire credentials.
+func WithCredentialsRequeue(result reconcile.Result, requeue time.Duration) reconcile.Result {
+	if requeue <= 0 || (result.RequeueAfter > 0 && result.RequeueAfter < requeue) {
+		return result
+	}
+	return reconcile.Result{Requeue: true, RequeueAfter: requeue}
+}
+
+// DeleteAPIKey invalidates the API key of the associated object, if any, and deletes the secret holding it.
+// The Elasticsearch client is only created if there is a key to invalidate. A nil client skips the invalidation,
+// for example if the Elasticsearch cluster does not exist anymore.
+func DeleteAPIKey(
+	ctx context.Context,
+	c k8s.Client,
+	newESClient func() (esclient.Client, error),
+	associated commonv1beta1.Associated,
+	suffix string,
+) error {
+	var secret corev1.Secret
+	err := c.Get(secretKey(associated, suffix), &secret)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(&secret, associated) {
+		return nil
+	}
+
+	var ids []string
+	for _, annotation := range []string{APIKeyIDAnnotation, APIKeyPreviousIDAnnotation} {
+		if id := secret.Annotations[annotation]; id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		esClient, err := newESClient()
+		if err != nil {
+			return err
+		}
+		if esClient != nil {
+			defer esClient.Close()
+			for _, id := range ids {
+				if err := esClient.InvalidateAPIKey(ctx, id); err != nil && !esclient.IsNotFound(err) {
+					return pkgerrors.Wrap(err, "failed to invalidate API key")
+				}
+				log.Info("Invalidated API key", "namespace", secret.Namespace, "name", associated.GetName(), "api_key_id", id)
+			}
+		}
+	}
+
+	if err := c.Delete(&secret); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// APIKeyFinalizer invalidates the API key of the associated object when it is deleted.
+// Invalidation is best-effort: a key that cannot be invalidated, for example because Elasticsearch is unavailable,
+// is left to expire rather than blocking the deletion.
+func APIKeyFinalizer(c k8s.Client, dialer net.Dialer, associated commonv1beta1.Associated, kind string, suffix string) finalizer.Finalizer {
+	return finalizer.Finalizer{
+		Name: "finalizer.association." + strings.ToLower(kind) + ".k8s.elastic.co/api-key",
+		Execute: func() error {
+			newESClient := func() (esclient.Client, error) {
+				esRef := associated.ElasticsearchRef()
+				if !esRef.IsDefined() {
+					return nil, nil
+				}
+				if esRef.Namespace == "" {
+					esRef.Namespace = associated.GetNamespace()
+				}
+				var es esv1beta1.Elasticsearch
+				if err := c.Get(esRef.NamespacedName(), &es); err != nil {
+					if apierrors.IsNotFound(err) {
+						return nil, nil
+					}
+					return nil, err
+				}
+				return NewElasticsearchClient(c, dialer, es)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+			defer cancel()
+			if err := DeleteAPIKey(ctx, c, newESClient, associated, suffix); err != nil {
+				log.Error(err, "Failed to invalidate API key, leaving it to expire",
+					"namespace", associated.GetNamespace(), "name", associated.GetName())
+			}
+			return nil
+		},
+	}
+}
+
+// NewElasticsearchClient returns a client of the given Elasticsearch cluster authenticated as the internal user of
+// the operator, to manage the API keys of the associated objects.
+func NewElasticsearchClient(c k8s.Client, dialer net.Dialer, es esv1beta1.Elasticsearch) (esclient.Client, error) {
+	var usersSecret corev1.Secret
+	usersKey := types.NamespacedName{Namespace: es.Namespace, Name: esuser.ElasticInternalUsersSecretName(es.Name)}
+	if err := c.Get(usersKey, &usersSecret); err != nil {
+		return nil, err
+	}
+	password, exists := usersSecret.Data[esuser.InternalControllerUserName]
+	if !exists {
+		return nil, fmt.Errorf("no password for user %s in secret %s", esuser.InternalControllerUserName, usersKey)
+	}
+
+	var caSecret corev1.Secret
+	if err := c.Get(http.PublicCertsSecretRef(esname.ESNamer, k8s.ExtractNamespacedName(&es)), &caSecret); err != nil {
+		return nil, err
+	}
+	var caCerts []*x509.Certificate
+	if pem := caSecret.Data[certificates.CertFileName]; len(pem) > 0 {
+		var err error
+		if caCerts, err = certificates.ParsePEMCerts(pem); err != nil {
+			return nil, err
+		}
+	}
+
+	v, err := version.Parse(es.Spec.Version)
+	if err != nil {
+		return nil, err
+	}
+	return esclient.NewElasticsearchClient(
+		dialer,
+		services.ExternalServiceURL(es),
+		esclient.UserAuth{Name: esuser.InternalControllerUserName, Password: string(password)},
+		*v,
+		caCerts,
+	), nil
+}
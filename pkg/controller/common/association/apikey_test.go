@@ -0,0 +1,243 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const apiKeySuffix = "kibana-api-key"
+
+// apiKeyMock is an Elasticsearch API mock creating API keys with increasing ids, and recording the invalidated ones.
+type apiKeyMock struct {
+	created     int
+	invalidated []string
+}
+
+func (m *apiKeyMock) newClient(t *testing.T) func() (esclient.Client, error) {
+	return func() (esclient.Client, error) {
+		return m.client(t), nil
+	}
+}
+
+func (m *apiKeyMock) client(t *testing.T) esclient.Client {
+	return esclient.NewMockClient(version.MustParse("7.6.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_security/api_key", req.URL.Path)
+		switch req.Method {
+		case http.MethodPost:
+			m.created++
+			return esclient.NewMockResponse(200, req, fmt.Sprintf(`{"id":"id%d","api_key":"key%d"}`, m.created, m.created))
+		case http.MethodDelete:
+			var body struct {
+				ID string `json:"id"`
+			}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			m.invalidated = append(m.invalidated, body.ID)
+			return esclient.NewMockResponse(200, req, "{}")
+		}
+		return esclient.NewMockResponse(400, req, "{}")
+	})
+}
+
+func TestReconcileAPIKey(t *testing.T) {
+	sc := setupScheme(t)
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         "default",
+		Name:              "kibana-foo-pod",
+		Labels:            map[string]string{"name": kibanaFixture.Name},
+		CreationTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+	}}
+	c := k8s.WrapClient(fake.NewFakeClientWithScheme(sc, &pod))
+	mock := &apiKeyMock{}
+	params := APIKeyParams{
+		Associated:    &kibanaFixture,
+		Elasticsearch: types.NamespacedName{Namespace: "default", Name: "es-foo"},
+		Labels:        map[string]string{associationLabelName: kibanaFixture.Name},
+		Suffix:        apiKeySuffix,
+		Expiration:    4 * time.Hour,
+		Rotation:      CredentialRotation{PodLabels: pod.Labels},
+	}
+	secretKey := types.NamespacedName{Namespace: "default", Name: "kibana-foo-kibana-api-key"}
+
+	// a new key is created and stored in a secret
+	credentials, err := ReconcileAPIKey(context.Background(), c, sc, mock.newClient(t), params, now)
+	require.NoError(t, err)
+	require.Equal(t, 3*time.Hour, credentials.Requeue)
+	require.Equal(t, now, credentials.IssuedAt)
+	require.Equal(t, "default-kibana-foo-kibana-api-key", credentials.Selector.Key)
+	var secret corev1.Secret
+	require.NoError(t, c.Get(secretKey, &secret))
+	require.Equal(t, []byte("id1:key1"), secret.Data["default-kibana-foo-kibana-api-key"])
+	require.Equal(t, "id1", secret.Annotations[APIKeyIDAnnotation])
+	require.Equal(t, "2019-10-01T16:00:00Z", secret.Annotations[APIKeyExpirationAnnotation])
+	require.Equal(t, "2019-10-01T12:00:00Z", secret.Annotations[CredentialsIssuedAnnotation])
+	require.Equal(t, kibanaFixture.Name, secret.Labels[associationLabelName])
+
+	// the key is kept until it must be replaced
+	credentials, err = ReconcileAPIKey(context.Background(), c, sc, mock.newClient(t), params, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Hour, credentials.Requeue)
+	require.Equal(t, 1, mock.created)
+
+	// then replaced, keeping the previous one valid until the pods restart
+	credentials, err = ReconcileAPIKey(context.Background(), c, sc, mock.newClient(t), params, now.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, retirementCheckInterval, credentials.Requeue)
+	require.NoError(t, c.Get(secretKey, &secret))
+	require.Equal(t, []byte("id2:key2"), secret.Data["default-kibana-foo-kibana-api-key"])
+	require.Equal(t, "2019-10-01T19:00:00Z", secret.Annotations[APIKeyExpirationAnnotation])
+	require.Equal(t, "id1", secret.Annotations[APIKeyPreviousIDAnnotation])
+	require.Empty(t, mock.invalidated)
+
+	// the previous key is invalidated once the pods restarted
+	pod.CreationTimestamp = metav1.NewTime(now.Add(4 * time.Hour))
+	require.NoError(t, c.Update(&pod))
+	credentials, err = ReconcileAPIKey(context.Background(), c, sc, mock.newClient(t), params, now.Add(4*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Hour, credentials.Requeue)
+	require.Equal(t, []string{"id1"}, mock.invalidated)
+	secret = corev1.Secret{}
+	require.NoError(t, c.Get(secretKey, &secret))
+	require.NotContains(t, secret.Annotations, APIKeyPreviousIDAnnotation)
+
+	// a rotation interval shorter than the lifetime replaces the key earlier
+	params.Rotation.Interval = 90 * time.Minute
+	credentials, err = ReconcileAPIKey(context.Background(), c, sc, mock.newClient(t), params, now.Add(4*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Minute, credentials.Requeue)
+	credentials, err = ReconcileAPIKey(context.Background(), c, sc, mock.newClient(t), params, now.Add(270*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 3, mock.created)
+	require.Equal(t, now.Add(270*time.Minute), credentials.IssuedAt)
+
+	// a shorter lifetime replaces the key immediately
+	params.Rotation.Interval = 0
+	params.Expiration = time.Hour
+	_, err = ReconcileAPIKey(context.Background(), c, sc, mock.newClient(t), params, now.Add(270*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 4, mock.created)
+
+	// referencing another Elasticsearch cluster replaces the key immediately
+	params.Elasticsearch.Name = "es-bar"
+	_, err = ReconcileAPIKey(context.Background(), c, sc, mock.newClient(t), params, now.Add(270*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 5, mock.created)
+	secret = corev1.Secret{}
+	require.NoError(t, c.Get(secretKey, &secret))
+	require.Equal(t, "default/es-bar", secret.Annotations[APIKeyElasticsearchAnnotation])
+	require.NotContains(t, secret.Annotations, APIKeyPreviousIDAnnotation)
+
+	// changing the privileges of the key replaces it immediately
+	params.RoleDescriptors = map[string]esclient.RoleDescriptor{
+		"writer": {Indices: []esclient.IndicesPrivileges{{Names: []string{"logs-*"}, Privileges: []string{"write"}}}},
+	}
+	_, err = ReconcileAPIKey(context.Background(), c, sc, mock.newClient(t), params, now.Add(270*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 6, mock.created)
+	_, err = ReconcileAPIKey(context.Background(), c, sc, mock.newClient(t), params, now.Add(270*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 6, mock.created)
+}
+
+func TestDeleteAPIKey(t *testing.T) {
+	sc := setupScheme(t)
+	c := k8s.WrapClient(fake.NewFakeClientWithScheme(sc))
+	mock := &apiKeyMock{}
+	newESClient := mock.newClient(t)
+
+	// nothing to delete
+	require.NoError(t, DeleteAPIKey(context.Background(), c, newESClient, &kibanaFixture, apiKeySuffix))
+	require.Empty(t, mock.invalidated)
+
+	_, err := ReconcileAPIKey(context.Background(), c, sc, mock.newClient(t), APIKeyParams{
+		Associated: &kibanaFixture,
+		Suffix:     apiKeySuffix,
+		Expiration: time.Hour,
+	}, time.Now())
+	require.NoError(t, err)
+
+	require.NoError(t, DeleteAPIKey(context.Background(), c, newESClient, &kibanaFixture, apiKeySuffix))
+	require.Equal(t, []string{"id1"}, mock.invalidated)
+	var secret corev1.Secret
+	err = c.Get(types.NamespacedName{Namespace: "default", Name: "kibana-foo-kibana-api-key"}, &secret)
+	require.True(t, apierrors.IsNotFound(err))
+}
+
+func TestWithCredentialsRequeue(t *testing.T) {
+	retry := reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second}
+	require.Equal(t, reconcile.Result{}, WithCredentialsRequeue(reconcile.Result{}, 0))
+	require.Equal(t, reconcile.Result{Requeue: true, RequeueAfter: time.Hour}, WithCredentialsRequeue(reconcile.Result{}, time.Hour))
+	require.Equal(t, retry, WithCredentialsRequeue(retry, time.Hour))
+	require.Equal(t, reconcile.Result{Requeue: true, RequeueAfter: time.Second}, WithCredentialsRequeue(retry, time.Second))
+}
+
+func TestCheckAPIKeySupport(t *testing.T) {
+	newES := func(v string, tls bool) esv1beta1.Elasticsearch {
+		es := esv1beta1.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+			Spec:       esv1beta1.ElasticsearchSpec{Version: v},
+		}
+		if !tls {
+			es.Spec.HTTP.TLS.SelfSignedCertificate = &commonv1beta1.SelfSignedCertificate{Disabled: true}
+		}
+		return es
+	}
+	minVersion := version.MustParse("7.6.0")
+	tests := []struct {
+		name              string
+		es                esv1beta1.Elasticsearch
+		associatedVersion string
+		wantErr           bool
+	}{
+		{
+			name:              "supported",
+			es:                newES("7.6.0", true),
+			associatedVersion: "7.6.0",
+		},
+		{
+			name:              "Elasticsearch without API keys",
+			es:                newES("6.6.2", true),
+			associatedVersion: "7.6.0",
+			wantErr:           true,
+		},
+		{
+			name:              "Elasticsearch without HTTP TLS",
+			es:                newES("7.6.0", false),
+			associatedVersion: "7.6.0",
+			wantErr:           true,
+		},
+		{
+			name:              "associated version without API keys",
+			es:                newES("7.6.0", true),
+			associatedVersion: "7.5.2",
+			wantErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckAPIKeySupport(tt.es, tt.associatedVersion, minVersion)
+			require.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
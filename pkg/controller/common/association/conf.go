@@ -39,22 +39,28 @@ func FetchWithAssociation(client k8s.Client, request reconcile.Request, obj comm
 
 // GetAssociationConf extracts the association configuration from the given object by reading the annotations.
 func GetAssociationConf(obj runtime.Object) (*commonv1beta1.AssociationConf, error) {
+	return GetAssociationConfFromAnnotation(obj, annotation.AssociationConfAnnotation)
+}
+
+// GetAssociationConfFromAnnotation extracts the association configuration stored in the given annotation of the
+// given object.
+func GetAssociationConfFromAnnotation(obj runtime.Object, annotationName string) (*commonv1beta1.AssociationConf, error) {
 	accessor := meta.NewAccessor()
 	annotations, err := accessor.Annotations(obj)
 	if err != nil {
 		return nil, err
 	}
 
-	return extractAssociationConf(annotations)
+	return extractAssociationConf(annotations, annotationName)
 }
 
-func extractAssociationConf(annotations map[string]string) (*commonv1beta1.AssociationConf, error) {
+func extractAssociationConf(annotations map[string]string, annotationName string) (*commonv1beta1.AssociationConf, error) {
 	if len(annotations) == 0 {
 		return nil, nil
 	}
 
 	var assocConf commonv1beta1.AssociationConf
-	serializedConf, exists := annotations[annotation.AssociationConfAnnotation]
+	serializedConf, exists := annotations[annotationName]
 	if !exists || serializedConf == "" {
 		return nil, nil
 	}
@@ -68,6 +74,11 @@ func extractAssociationConf(annotations map[string]string) (*commonv1beta1.Assoc
 
 // RemoveAssociationConf removes the association configuration annotation.
 func RemoveAssociationConf(client k8s.Client, obj runtime.Object) error {
+	return RemoveAssociationConfAnnotation(client, obj, annotation.AssociationConfAnnotation)
+}
+
+// RemoveAssociationConfAnnotation removes the given association configuration annotation.
+func RemoveAssociationConfAnnotation(client k8s.Client, obj runtime.Object, annotationName string) error {
 	accessor := meta.NewAccessor()
 	annotations, err := accessor.Annotations(obj)
 	if err != nil {
@@ -78,11 +89,11 @@ func RemoveAssociationConf(client k8s.Client, obj runtime.Object) error {
 		return nil
 	}
 
-	if _, exists := annotations[annotation.AssociationConfAnnotation]; !exists {
+	if _, exists := annotations[annotationName]; !exists {
 		return nil
 	}
 
-	delete(annotations, annotation.AssociationConfAnnotation)
+	delete(annotations, annotationName)
 	if err := accessor.SetAnnotations(obj, annotations); err != nil {
 		return err
 	}
@@ -92,6 +103,16 @@ func RemoveAssociationConf(client k8s.Client, obj runtime.Object) error {
 
 // UpdateAssociationConf updates the association configuration annotation.
 func UpdateAssociationConf(client k8s.Client, obj runtime.Object, wantConf *commonv1beta1.AssociationConf) error {
+	return UpdateAssociationConfAnnotation(client, obj, annotation.AssociationConfAnnotation, wantConf)
+}
+
+// UpdateAssociationConfAnnotation updates the given association configuration annotation.
+func UpdateAssociationConfAnnotation(
+	client k8s.Client,
+	obj runtime.Object,
+	annotationName string,
+	wantConf *commonv1beta1.AssociationConf,
+) error {
 	accessor := meta.NewAccessor()
 	annotations, err := accessor.Annotations(obj)
 	if err != nil {
@@ -108,7 +129,7 @@ func UpdateAssociationConf(client k8s.Client, obj runtime.Object, wantConf *comm
 		annotations = make(map[string]string)
 	}
 
-	annotations[annotation.AssociationConfAnnotation] = unsafeBytesToString(serializedConf)
+	annotations[annotationName] = unsafeBytesToString(serializedConf)
 	if err := accessor.SetAnnotations(obj, annotations); err != nil {
 		return err
 	}
@@ -0,0 +1,55 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"fmt"
+	"strings"
+
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AllowedReferencesAnnotation lists, on an Elasticsearch resource, the associated objects of other namespaces
+// allowed to reference it. Entries are comma-separated, either a namespace allowing all the kinds of associated
+// objects, or a kind and a namespace separated by a slash, such as `Kibana/monitoring`. `*` matches any namespace or
+// kind. Associated objects in the namespace of the Elasticsearch resource are always allowed.
+const AllowedReferencesAnnotation = "association.k8s.elastic.co/allowed-references"
+
+// wildcard matches any namespace or kind in the allowed references.
+const wildcard = "*"
+
+// IsReferenceAllowed returns true if the given associated object, of the given kind, is allowed to reference the given
+// Elasticsearch cluster. The referencing object can also be another Elasticsearch cluster declaring a remote cluster.
+func IsReferenceAllowed(es esv1beta1.Elasticsearch, associated metav1.Object, kind string) bool {
+	if es.Namespace == associated.GetNamespace() {
+		return true
+	}
+	for _, entry := range strings.Split(es.Annotations[AllowedReferencesAnnotation], ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		allowedKind, allowedNamespace := wildcard, entry
+		if i := strings.Index(entry, "/"); i >= 0 {
+			allowedKind, allowedNamespace = entry[:i], entry[i+1:]
+		}
+		if (allowedKind == wildcard || allowedKind == kind) &&
+			(allowedNamespace == wildcard || allowedNamespace == associated.GetNamespace()) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReferenceDeniedError returns the error reported when the given associated object is not allowed to reference the
+// given Elasticsearch cluster.
+func ReferenceDeniedError(es esv1beta1.Elasticsearch, associated metav1.Object, kind string) error {
+	return fmt.Errorf(
+		"%s %s/%s is not allowed to reference Elasticsearch %s/%s: add %s/%s to the %s annotation of the Elasticsearch resource",
+		kind, associated.GetNamespace(), associated.GetName(), es.Namespace, es.Name,
+		kind, associated.GetNamespace(), AllowedReferencesAnnotation,
+	)
+}
@@ -0,0 +1,84 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+
+	estype "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	kbtype "github.com/cloudptio/logstash-operator/pkg/apis/kibana/v1beta1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsReferenceAllowed(t *testing.T) {
+	tests := []struct {
+		name              string
+		namespace         string
+		allowedReferences *string
+		want              bool
+	}{
+		{
+			name:      "same namespace without annotation",
+			namespace: "default",
+			want:      true,
+		},
+		{
+			name:      "other namespace without annotation",
+			namespace: "kibana",
+			want:      false,
+		},
+		{
+			name:              "other namespace allowed for all kinds",
+			namespace:         "kibana",
+			allowedReferences: stringPtr("logstash, kibana"),
+			want:              true,
+		},
+		{
+			name:              "other namespace allowed for the kind",
+			namespace:         "kibana",
+			allowedReferences: stringPtr("Kibana/kibana"),
+			want:              true,
+		},
+		{
+			name:              "other namespace allowed for another kind",
+			namespace:         "kibana",
+			allowedReferences: stringPtr("Logstash/kibana"),
+			want:              false,
+		},
+		{
+			name:              "all namespaces allowed for the kind",
+			namespace:         "kibana",
+			allowedReferences: stringPtr("Kibana/*"),
+			want:              true,
+		},
+		{
+			name:              "all namespaces allowed",
+			namespace:         "kibana",
+			allowedReferences: stringPtr("*"),
+			want:              true,
+		},
+		{
+			name:              "other namespaces allowed",
+			namespace:         "kibana",
+			allowedReferences: stringPtr("logstash,*/apm"),
+			want:              false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := estype.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "es"}}
+			if tt.allowedReferences != nil {
+				es.Annotations = map[string]string{AllowedReferencesAnnotation: *tt.allowedReferences}
+			}
+			kb := kbtype.Kibana{ObjectMeta: metav1.ObjectMeta{Namespace: tt.namespace, Name: "kb"}}
+			require.Equal(t, tt.want, IsReferenceAllowed(es, &kb, "Kibana"))
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
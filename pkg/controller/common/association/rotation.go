@@ -0,0 +1,269 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"reflect"
+	"time"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/reconciler"
+	commonuser "github.com/cloudptio/logstash-operator/pkg/controller/common/user"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ActiveCredentialsAnnotation holds the key of the active credentials in an association secret, which holds both
+	// the active and the previous credentials during a rotation.
+	ActiveCredentialsAnnotation = "association.k8s.elastic.co/active-credentials"
+	// CredentialsIssuedAnnotation holds the time the active credentials of an association secret were issued, in
+	// RFC 3339 format.
+	CredentialsIssuedAnnotation = "association.k8s.elastic.co/credentials-issued-at"
+
+	// alternateUserSuffix distinguishes the second user an associated object alternates with on rotation.
+	alternateUserSuffix = "-alt"
+)
+
+// retirementCheckInterval is the delay between two checks that the pods of an associated object restarted with the
+// rotated credentials, before retiring the previous ones.
+var retirementCheckInterval = 30 * time.Second
+
+// CredentialRotation is the rotation policy of the credentials of an associated object.
+type CredentialRotation struct {
+	// Interval between two rotations. Zero disables the rotation.
+	Interval time.Duration
+	// PodLabels select the pods of the associated object. The previous credentials are retired once all of them
+	// were created after the rotation.
+	PodLabels map[string]string
+}
+
+// Credentials are the reconciled credentials of an associated object.
+type Credentials struct {
+	// Selector selects the active credentials in the namespace of the associated object.
+	Selector *corev1.SecretKeySelector
+	// IssuedAt is the time the active credentials were issued.
+	IssuedAt time.Time
+	// Requeue is the delay before the credentials must be reconciled again, to rotate them or to retire the
+	// previous ones. Zero if not needed.
+	Requeue time.Duration
+}
+
+// LastRotation returns the issue time of the credentials to report in the status of the associated object, or the
+// given current one if the credentials were not reconciled.
+func (c Credentials) LastRotation(current *metav1.Time) *metav1.Time {
+	if c.IssuedAt.IsZero() || (current != nil && current.Time.Equal(c.IssuedAt)) {
+		return current
+	}
+	issuedAt := metav1.NewTime(c.IssuedAt)
+	return &issuedAt
+}
+
+// RotatedUserKeys returns the keys of the two users the associated object alternates between in the given
+// Elasticsearch namespace.
+func RotatedUserKeys(associated commonv1beta1.Associated, esNamespace string, userSuffix string) []types.NamespacedName {
+	key := UserKeyInNamespace(associated, esNamespace, userSuffix)
+	alternate := key
+	alternate.Name += alternateUserSuffix
+	return []types.NamespacedName{key, alternate}
+}
+
+// ActiveSecretKeySelector selects the active credentials in the given association secret of the associated object,
+// or the credentials of its single user if they are not rotated.
+func ActiveSecretKeySelector(associated commonv1beta1.Associated, userSuffix string, secret corev1.Secret) *corev1.SecretKeySelector {
+	selector := ClearTextSecretKeySelector(associated, userSuffix)
+	if active := secret.Annotations[ActiveCredentialsAnnotation]; active != "" {
+		selector.Key = active
+	}
+	return selector
+}
+
+// podsRestartedSince returns true if all the pods matching the given labels were created after the given time.
+func podsRestartedSince(c k8s.Client, namespace string, podLabels map[string]string, t time.Time) (bool, error) {
+	if len(podLabels) == 0 {
+		return true, nil
+	}
+	var pods corev1.PodList
+	if err := c.List(&pods, client.InNamespace(namespace), client.MatchingLabels(podLabels)); err != nil {
+		return false, err
+	}
+	for _, p := range pods.Items {
+		if p.CreationTimestamp.Time.Before(t) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// issuedAt returns the issue time recorded in the given secret, or the given fallback if there is none.
+func issuedAt(secret corev1.Secret, fallback time.Time) time.Time {
+	t, err := time.Parse(time.RFC3339, secret.Annotations[CredentialsIssuedAnnotation])
+	if err != nil {
+		return fallback.UTC().Truncate(time.Second)
+	}
+	return t
+}
+
+// ReconcileRotatedEsUser creates the file realm user of the associated object and the secret holding its password
+// like ReconcileEsUser, and rotates the password with the given policy.
+// The file realm holds a single password per user, so the associated object alternates between two users: on
+// rotation, the second user is created with a new password and becomes the active one. The previous user stays valid
+// until all the pods of the associated object restarted with the new credentials, and is deleted then.
+func ReconcileRotatedEsUser(
+	c k8s.Client,
+	s *runtime.Scheme,
+	associated commonv1beta1.Associated,
+	labels map[string]string,
+	userRoles string,
+	userObjectSuffix string,
+	es v1beta1.Elasticsearch,
+	rotation CredentialRotation,
+	now time.Time,
+) (Credentials, error) {
+	secKey := secretKey(associated, userObjectSuffix)
+	usrKeys := RotatedUserKeys(associated, es.Namespace, userObjectSuffix)
+
+	var current corev1.Secret
+	if err := c.Get(secKey, &current); err != nil && !apierrors.IsNotFound(err) {
+		return Credentials{}, err
+	}
+	active, previous := usrKeys[0], usrKeys[1]
+	if current.Annotations[ActiveCredentialsAnnotation] == usrKeys[1].Name {
+		active, previous = usrKeys[1], usrKeys[0]
+	}
+	issued := issuedAt(current, now)
+	passwords := map[string][]byte{active.Name: current.Data[active.Name]}
+	if len(passwords[active.Name]) == 0 {
+		passwords[active.Name] = commonuser.RandomPasswordBytes()
+	}
+
+	var requeue time.Duration
+	if previousPw, inRotation := current.Data[previous.Name]; inRotation {
+		restarted, err := podsRestartedSince(c, associated.GetNamespace(), rotation.PodLabels, issued)
+		if err != nil {
+			return Credentials{}, err
+		}
+		if !restarted {
+			// keep the previous credentials valid until all the pods use the new ones
+			passwords[previous.Name] = previousPw
+			requeue = retirementCheckInterval
+		}
+	} else if rotation.Interval > 0 && !now.Before(issued.Add(rotation.Interval)) {
+		active, previous = previous, active
+		passwords[active.Name] = commonuser.RandomPasswordBytes()
+		issued = now.UTC().Truncate(time.Second)
+		requeue = retirementCheckInterval
+		log.Info("Rotating credentials", "namespace", associated.GetNamespace(), "name", associated.GetName(),
+			"user", active.Name)
+	}
+	if requeue == 0 && rotation.Interval > 0 {
+		requeue = issued.Add(rotation.Interval).Sub(now)
+	}
+
+	expectedSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secKey.Name,
+			Namespace: secKey.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				ActiveCredentialsAnnotation: active.Name,
+				CredentialsIssuedAnnotation: issued.Format(time.RFC3339),
+			},
+		},
+		Data: passwords,
+	}
+	reconciledSecret := corev1.Secret{}
+	if err := reconciler.ReconcileResource(reconciler.Params{
+		Client:     c,
+		Scheme:     s,
+		Owner:      associated,
+		Expected:   &expectedSecret,
+		Reconciled: &reconciledSecret,
+		NeedsUpdate: func() bool {
+			return !reflect.DeepEqual(expectedSecret.Data, reconciledSecret.Data) ||
+				!hasExpectedLabels(&expectedSecret, &reconciledSecret) ||
+				!hasExpectedAnnotations(&expectedSecret, &reconciledSecret)
+		},
+		UpdateReconciled: func() {
+			setExpectedLabels(&expectedSecret, &reconciledSecret)
+			setExpectedAnnotations(&expectedSecret, &reconciledSecret)
+			reconciledSecret.Data = expectedSecret.Data
+		},
+	}); err != nil {
+		return Credentials{}, err
+	}
+
+	for _, key := range usrKeys {
+		password, exists := reconciledSecret.Data[key.Name]
+		if !exists {
+			if err := deleteEsUserSecret(c, key, labels); err != nil {
+				return Credentials{}, err
+			}
+			continue
+		}
+		if err := reconcileEsUserSecret(c, s, key, password, labels, userRoles, es); err != nil {
+			return Credentials{}, err
+		}
+	}
+
+	return Credentials{
+		Selector: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secKey.Name},
+			Key:                  active.Name,
+		},
+		IssuedAt: issued,
+		Requeue:  requeue,
+	}, nil
+}
+
+// deleteEsUserSecret deletes the secret of the file realm user with the given key, if it exists and has the given
+// association labels.
+func deleteEsUserSecret(c k8s.Client, key types.NamespacedName, labels map[string]string) error {
+	var secret corev1.Secret
+	err := c.Get(key, &secret)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !hasExpectedLabels(&metav1.ObjectMeta{Labels: labels}, &secret) {
+		return nil
+	}
+	log.Info("Deleting retired user", "namespace", key.Namespace, "user", key.Name)
+	if err := c.Delete(&secret); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// hasExpectedAnnotations does a left-biased comparison ensuring all key/value pairs in expected exist in actual.
+func hasExpectedAnnotations(expected, actual metav1.Object) bool {
+	actualAnnotations := actual.GetAnnotations()
+	for k, v := range expected.GetAnnotations() {
+		if actualAnnotations[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// setExpectedAnnotations set the annotations from expected into actual.
+func setExpectedAnnotations(expected, actual metav1.Object) {
+	actualAnnotations := actual.GetAnnotations()
+	if actualAnnotations == nil {
+		actualAnnotations = make(map[string]string)
+	}
+	for k, v := range expected.GetAnnotations() {
+		actualAnnotations[k] = v
+	}
+	actual.SetAnnotations(actualAnnotations)
+}
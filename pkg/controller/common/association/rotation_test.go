@@ -0,0 +1,117 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package association
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/user"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileRotatedEsUser(t *testing.T) {
+	sc := setupScheme(t)
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         "default",
+		Name:              "kibana-foo-pod",
+		Labels:            map[string]string{"name": kibanaFixture.Name},
+		CreationTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+	}}
+	c := k8s.WrapClient(fake.NewFakeClientWithScheme(sc, &pod))
+	labels := map[string]string{associationLabelName: kibanaFixture.Name}
+	rotation := CredentialRotation{Interval: 24 * time.Hour, PodLabels: pod.Labels}
+	secretKey := types.NamespacedName{Namespace: "default", Name: userSecretName}
+	altUserName := userName + alternateUserSuffix
+	reconcile := func(now time.Time) Credentials {
+		credentials, err := ReconcileRotatedEsUser(c, sc, &kibanaFixture, labels, "kibana_system", "kibana-user", esFixture, rotation, now)
+		require.NoError(t, err)
+		return credentials
+	}
+	getSecret := func(key types.NamespacedName) (corev1.Secret, error) {
+		var secret corev1.Secret
+		err := c.Get(key, &secret)
+		return secret, err
+	}
+
+	// the first user is created
+	credentials := reconcile(now)
+	require.Equal(t, userName, credentials.Selector.Key)
+	require.Equal(t, now, credentials.IssuedAt)
+	require.Equal(t, 24*time.Hour, credentials.Requeue)
+	secret, err := getSecret(secretKey)
+	require.NoError(t, err)
+	require.Len(t, secret.Data, 1)
+	require.Equal(t, userName, secret.Annotations[ActiveCredentialsAnnotation])
+	password := secret.Data[userName]
+	esUser, err := getSecret(types.NamespacedName{Namespace: "default", Name: userName})
+	require.NoError(t, err)
+	require.NoError(t, bcrypt.CompareHashAndPassword(esUser.Data[user.PasswordHash], password))
+
+	// the password is kept until the rotation is due
+	credentials = reconcile(now.Add(time.Hour))
+	require.Equal(t, userName, credentials.Selector.Key)
+	require.Equal(t, 23*time.Hour, credentials.Requeue)
+
+	// the alternate user is created and becomes the active one, both are valid until the pods restart
+	rotatedAt := now.Add(25 * time.Hour)
+	credentials = reconcile(rotatedAt)
+	require.Equal(t, altUserName, credentials.Selector.Key)
+	require.Equal(t, rotatedAt, credentials.IssuedAt)
+	require.Equal(t, retirementCheckInterval, credentials.Requeue)
+	secret, err = getSecret(secretKey)
+	require.NoError(t, err)
+	require.Len(t, secret.Data, 2)
+	require.Equal(t, password, secret.Data[userName])
+	require.NotEqual(t, password, secret.Data[altUserName])
+	for _, name := range []string{userName, altUserName} {
+		_, err := getSecret(types.NamespacedName{Namespace: "default", Name: name})
+		require.NoError(t, err)
+	}
+	credentials = reconcile(rotatedAt.Add(time.Minute))
+	require.Equal(t, retirementCheckInterval, credentials.Requeue)
+
+	// the previous user is deleted once the pods restarted
+	pod.CreationTimestamp = metav1.NewTime(rotatedAt.Add(time.Minute))
+	require.NoError(t, c.Update(&pod))
+	credentials = reconcile(rotatedAt.Add(2 * time.Minute))
+	require.Equal(t, altUserName, credentials.Selector.Key)
+	require.Equal(t, 24*time.Hour-2*time.Minute, credentials.Requeue)
+	secret, err = getSecret(secretKey)
+	require.NoError(t, err)
+	require.Equal(t, []string{altUserName}, keys(secret.Data))
+	_, err = getSecret(types.NamespacedName{Namespace: "default", Name: userName})
+	require.True(t, apierrors.IsNotFound(err))
+
+	// the next rotation switches back to the first user
+	credentials = reconcile(rotatedAt.Add(24 * time.Hour))
+	require.Equal(t, userName, credentials.Selector.Key)
+}
+
+func TestCredentials_LastRotation(t *testing.T) {
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	current := metav1.NewTime(now)
+	require.Nil(t, Credentials{}.LastRotation(nil))
+	require.Equal(t, &current, Credentials{}.LastRotation(&current))
+	require.Equal(t, &current, Credentials{IssuedAt: now}.LastRotation(&current))
+	later := Credentials{IssuedAt: now.Add(time.Hour)}.LastRotation(&current)
+	require.Equal(t, now.Add(time.Hour), later.Time)
+}
+
+func keys(data map[string][]byte) []string {
+	var keys []string
+	for k := range data {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -40,6 +40,12 @@ func UserKey(associated commonv1beta1.Associated, userSuffix string) types.Names
 		// no namespace given, default to the associated object's one
 		esNamespace = associated.GetNamespace()
 	}
+	return UserKeyInNamespace(associated, esNamespace, userSuffix)
+}
+
+// UserKeyInNamespace returns the key of the user of the associated object living in the given Elasticsearch
+// namespace, which is not necessarily the namespace of the Elasticsearch cluster referenced by the associated object.
+func UserKeyInNamespace(associated commonv1beta1.Associated, esNamespace string, userSuffix string) types.NamespacedName {
 	return types.NamespacedName{
 		// user lives in the ES namespace
 		Namespace: esNamespace,
@@ -79,7 +85,7 @@ func ReconcileEsUser(
 	pw := commonuser.RandomPasswordBytes()
 
 	secKey := secretKey(associated, userObjectSuffix)
-	usrKey := UserKey(associated, userObjectSuffix)
+	usrKey := UserKeyInNamespace(associated, es.Namespace, userObjectSuffix)
 	expectedSecret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      secKey.Name,
@@ -112,7 +118,21 @@ func ReconcileEsUser(
 	}
 
 	reconciledPw := reconciledSecret.Data[usrKey.Name] // make sure we don't constantly update the password
-	bcryptHash, err := bcrypt.GenerateFromPassword(reconciledPw, bcrypt.DefaultCost)
+	return reconcileEsUserSecret(c, s, usrKey, reconciledPw, labels, userRoles, es)
+}
+
+// reconcileEsUserSecret creates or updates the secret of the file realm user with the given key in the Elasticsearch
+// namespace, with the hash of the given password.
+func reconcileEsUserSecret(
+	c k8s.Client,
+	s *runtime.Scheme,
+	usrKey types.NamespacedName,
+	password []byte,
+	labels map[string]string,
+	userRoles string,
+	es v1beta1.Elasticsearch,
+) error {
+	bcryptHash, err := bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
@@ -151,7 +171,7 @@ func ReconcileEsUser(
 			return !hasExpectedLabels(expectedEsUser, &reconciledEsSecret) ||
 				!bytes.Equal(expectedEsUser.Data[commonuser.UserName], reconciledEsSecret.Data[commonuser.UserName]) ||
 				!bytes.Equal(expectedEsUser.Data[commonuser.UserRoles], reconciledEsSecret.Data[commonuser.UserRoles]) ||
-				bcrypt.CompareHashAndPassword(reconciledEsSecret.Data[commonuser.PasswordHash], reconciledPw) != nil
+				bcrypt.CompareHashAndPassword(reconciledEsSecret.Data[commonuser.PasswordHash], password) != nil
 		},
 		UpdateReconciled: func() {
 			setExpectedLabels(expectedEsUser, &reconciledEsSecret)
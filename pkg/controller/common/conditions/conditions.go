@@ -0,0 +1,146 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package conditions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	commondeployment "github.com/cloudptio/logstash-operator/pkg/controller/common/deployment"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/validation"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Condition reasons shared by the controllers.
+const (
+	ReconciledReason          = "Reconciled"
+	ReconciliationErrorReason = "ReconciliationError"
+	ValidReason               = "Valid"
+	ValidationFailedReason    = "ValidationFailed"
+	InvalidVersionReason      = "InvalidVersion"
+	AvailableReason           = "Available"
+	UnavailableReason         = "Unavailable"
+	RollingOutReason          = "RollingOut"
+	RolledOutReason           = "RolledOut"
+)
+
+// New returns a condition of the given type computed from the given generation of a resource.
+func New(t commonv1beta1.ConditionType, status bool, generation int64, reason, message string) commonv1beta1.Condition {
+	conditionStatus := corev1.ConditionFalse
+	if status {
+		conditionStatus = corev1.ConditionTrue
+	}
+	return commonv1beta1.Condition{
+		Type:               t,
+		Status:             conditionStatus,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// Reconciled returns the Reconciled condition from the error of a reconciliation, if any.
+func Reconciled(generation int64, err error) commonv1beta1.Condition {
+	if err != nil {
+		return New(commonv1beta1.ReconciledCondition, false, generation, ReconciliationErrorReason, err.Error())
+	}
+	return New(commonv1beta1.ReconciledCondition, true, generation, ReconciledReason, "")
+}
+
+// ConfigValid returns the ConfigValid condition from the given validation violations.
+func ConfigValid(generation int64, violations []validation.Result) commonv1beta1.Condition {
+	if len(violations) == 0 {
+		return New(commonv1beta1.ConfigValidCondition, true, generation, ValidReason, "")
+	}
+	reasons := make([]string, 0, len(violations))
+	for _, v := range violations {
+		reasons = append(reasons, v.Reason)
+	}
+	return New(commonv1beta1.ConfigValidCondition, false, generation, ValidationFailedReason, strings.Join(reasons, "; "))
+}
+
+// InvalidVersion returns the ConfigValid condition of a resource with an invalid version.
+func InvalidVersion(generation int64, err error) commonv1beta1.Condition {
+	return New(commonv1beta1.ConfigValidCondition, false, generation, InvalidVersionReason, err.Error())
+}
+
+// FromDeployment returns the Ready and Progressing conditions of a resource running as the given deployment.
+func FromDeployment(generation int64, deployment appsv1.Deployment) []commonv1beta1.Condition {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	nodes := fmt.Sprintf("%d/%d nodes available", status.AvailableReplicas, desired)
+
+	ready := New(commonv1beta1.ReadyCondition, false, generation, UnavailableReason, nodes)
+	for _, c := range status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionTrue {
+			ready = New(commonv1beta1.ReadyCondition, true, generation, AvailableReason, nodes)
+		}
+	}
+
+	progressing := New(commonv1beta1.ProgressingCondition, false, generation, RolledOutReason, "")
+	if !commondeployment.RolledOut(deployment) {
+		progressing = New(commonv1beta1.ProgressingCondition, true, generation, RollingOutReason,
+			fmt.Sprintf("%d/%d nodes updated", status.UpdatedReplicas, desired))
+	}
+	return []commonv1beta1.Condition{ready, progressing}
+}
+
+// AssociationReady returns the AssociationReady condition from the given association statuses, or false if no
+// association is configured.
+func AssociationReady(generation int64, statuses map[string]commonv1beta1.AssociationStatus) (commonv1beta1.Condition, bool) {
+	configured := false
+	var notReady []string
+	reason := string(commonv1beta1.AssociationEstablished)
+	for _, name := range sortedKeys(statuses) {
+		switch status := statuses[name]; status {
+		case commonv1beta1.AssociationUnknown:
+			continue
+		case commonv1beta1.AssociationEstablished:
+			configured = true
+		default:
+			configured = true
+			if reason == string(commonv1beta1.AssociationEstablished) || status == commonv1beta1.AssociationFailed {
+				reason = string(status)
+			}
+			notReady = append(notReady, fmt.Sprintf("%s association is %s", name, strings.ToLower(string(status))))
+		}
+	}
+	if !configured {
+		return commonv1beta1.Condition{}, false
+	}
+	if len(notReady) > 0 {
+		return New(commonv1beta1.AssociationReadyCondition, false, generation, reason, strings.Join(notReady, "; ")), true
+	}
+	return New(commonv1beta1.AssociationReadyCondition, true, generation, reason, ""), true
+}
+
+// SetAssociationReady sets or removes the AssociationReady condition in the given conditions, from the given
+// association statuses.
+func SetAssociationReady(
+	c commonv1beta1.Conditions,
+	generation int64,
+	statuses map[string]commonv1beta1.AssociationStatus,
+) commonv1beta1.Conditions {
+	condition, configured := AssociationReady(generation, statuses)
+	if !configured {
+		return c.Remove(commonv1beta1.AssociationReadyCondition)
+	}
+	return c.Set(condition)
+}
+
+func sortedKeys(statuses map[string]commonv1beta1.AssociationStatus) []string {
+	keys := make([]string, 0, len(statuses))
+	for k := range statuses {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,112 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package conditions
+
+import (
+	"errors"
+	"testing"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/validation"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestReconciled(t *testing.T) {
+	c := Reconciled(3, nil)
+	require.Equal(t, corev1.ConditionTrue, c.Status)
+	require.Equal(t, int64(3), c.ObservedGeneration)
+	c = Reconciled(3, errors.New("boom"))
+	require.Equal(t, corev1.ConditionFalse, c.Status)
+	require.Equal(t, ReconciliationErrorReason, c.Reason)
+	require.Equal(t, "boom", c.Message)
+}
+
+func TestConfigValid(t *testing.T) {
+	require.Equal(t, corev1.ConditionTrue, ConfigValid(1, nil).Status)
+	c := ConfigValid(1, []validation.Result{{Reason: "a"}, {Reason: "b"}})
+	require.Equal(t, corev1.ConditionFalse, c.Status)
+	require.Equal(t, ValidationFailedReason, c.Reason)
+	require.Equal(t, "a; b", c.Message)
+}
+
+func TestFromDeployment(t *testing.T) {
+	available := []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}
+	tests := []struct {
+		name            string
+		deployment      appsv1.Deployment
+		wantReady       bool
+		wantProgressing bool
+	}{
+		{
+			name: "rolled out and available",
+			deployment: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2, Conditions: available,
+				},
+			},
+			wantReady: true,
+		},
+		{
+			name: "rolling out and available",
+			deployment: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1, AvailableReplicas: 2, Conditions: available,
+				},
+			},
+			wantReady:       true,
+			wantProgressing: true,
+		},
+		{
+			name: "not observed yet",
+			deployment: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+			},
+			wantProgressing: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := commonv1beta1.Conditions(FromDeployment(5, tt.deployment))
+			require.Equal(t, tt.wantReady, c.IsTrue(commonv1beta1.ReadyCondition))
+			require.Equal(t, tt.wantProgressing, c.IsTrue(commonv1beta1.ProgressingCondition))
+			require.Equal(t, int64(5), c.Get(commonv1beta1.ReadyCondition).ObservedGeneration)
+		})
+	}
+}
+
+func TestSetAssociationReady(t *testing.T) {
+	c := SetAssociationReady(nil, 1, map[string]commonv1beta1.AssociationStatus{"Elasticsearch": ""})
+	require.Nil(t, c)
+
+	c = SetAssociationReady(c, 1, map[string]commonv1beta1.AssociationStatus{
+		"Elasticsearch": commonv1beta1.AssociationEstablished,
+		"Monitoring":    commonv1beta1.AssociationUnknown,
+	})
+	require.True(t, c.IsTrue(commonv1beta1.AssociationReadyCondition))
+
+	c = SetAssociationReady(c, 2, map[string]commonv1beta1.AssociationStatus{
+		"Elasticsearch": commonv1beta1.AssociationPending,
+		"Monitoring":    commonv1beta1.AssociationFailed,
+	})
+	condition := c.Get(commonv1beta1.AssociationReadyCondition)
+	require.Equal(t, corev1.ConditionFalse, condition.Status)
+	require.Equal(t, string(commonv1beta1.AssociationFailed), condition.Reason)
+	require.Equal(t, "Elasticsearch association is pending; Monitoring association is failed", condition.Message)
+
+	c = SetAssociationReady(c, 3, map[string]commonv1beta1.AssociationStatus{"Elasticsearch": ""})
+	require.Nil(t, c)
+}
@@ -0,0 +1,48 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package defaults
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// HostnameTopologyKey is the well-known node label holding the node hostname.
+	HostnameTopologyKey = "kubernetes.io/hostname"
+	// ZoneTopologyKey is the well-known node label holding the node availability zone.
+	ZoneTopologyKey = "failure-domain.beta.kubernetes.io/zone"
+)
+
+// SpreadAffinity returns an affinity that prefers to spread the pods matching the given labels
+// across nodes first, and across availability zones second.
+// Scheduling is never blocked: both terms are preferences. Topology spread constraints are not
+// available in the Kubernetes API version we support, hence the use of weighted anti-affinity.
+// Users can override it by specifying their own affinity in the pod template, or disable it
+// with an empty one (`affinity: {}`).
+func SpreadAffinity(matchLabels map[string]string) *corev1.Affinity {
+	return &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				spreadTerm(100, HostnameTopologyKey, matchLabels),
+				spreadTerm(50, ZoneTopologyKey, matchLabels),
+			},
+		},
+	}
+}
+
+func spreadTerm(weight int32, topologyKey string, matchLabels map[string]string) corev1.WeightedPodAffinityTerm {
+	selector := make(map[string]string, len(matchLabels))
+	for k, v := range matchLabels {
+		selector[k] = v
+	}
+	return corev1.WeightedPodAffinityTerm{
+		Weight: weight,
+		PodAffinityTerm: corev1.PodAffinityTerm{
+			TopologyKey:   topologyKey,
+			LabelSelector: &metav1.LabelSelector{MatchLabels: selector},
+		},
+	}
+}
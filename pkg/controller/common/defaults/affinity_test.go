@@ -0,0 +1,65 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package defaults
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSpreadAffinity(t *testing.T) {
+	labels := map[string]string{"app": "foo"}
+	affinity := SpreadAffinity(labels)
+
+	require.Nil(t, affinity.NodeAffinity)
+	require.Nil(t, affinity.PodAffinity)
+	terms := affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	require.Len(t, terms, 2)
+	require.Equal(t, int32(100), terms[0].Weight)
+	require.Equal(t, HostnameTopologyKey, terms[0].PodAffinityTerm.TopologyKey)
+	require.Equal(t, labels, terms[0].PodAffinityTerm.LabelSelector.MatchLabels)
+	require.Equal(t, int32(50), terms[1].Weight)
+	require.Equal(t, ZoneTopologyKey, terms[1].PodAffinityTerm.TopologyKey)
+	require.Equal(t, labels, terms[1].PodAffinityTerm.LabelSelector.MatchLabels)
+
+	// mutating the given labels must not affect the selectors
+	labels["app"] = "bar"
+	require.Equal(t, "foo", terms[0].PodAffinityTerm.LabelSelector.MatchLabels["app"])
+}
+
+func TestSpreadAffinity_Override(t *testing.T) {
+	labels := map[string]string{"app": "foo"}
+	userAffinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}
+
+	tests := []struct {
+		name     string
+		template corev1.PodTemplateSpec
+		want     *corev1.Affinity
+	}{
+		{
+			name:     "default spread affinity",
+			template: corev1.PodTemplateSpec{},
+			want:     SpreadAffinity(labels),
+		},
+		{
+			name:     "user-provided affinity",
+			template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Affinity: userAffinity}},
+			want:     userAffinity,
+		},
+		{
+			name:     "disabled with an empty affinity",
+			template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Affinity: &corev1.Affinity{}}},
+			want:     &corev1.Affinity{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewPodTemplateBuilder(tt.template, "c").WithAffinity(SpreadAffinity(labels)).PodTemplate.Spec.Affinity
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -219,6 +219,17 @@ func (b *PodTemplateBuilder) WithTerminationGracePeriod(period int64) *PodTempla
 	return b
 }
 
+// WithPreStopHook sets the given pre-stop hook on the Container if not already specified in the template.
+func (b *PodTemplateBuilder) WithPreStopHook(handler corev1.Handler) *PodTemplateBuilder {
+	if b.Container.Lifecycle == nil {
+		b.Container.Lifecycle = &corev1.Lifecycle{}
+	}
+	if b.Container.Lifecycle.PreStop == nil {
+		b.Container.Lifecycle.PreStop = &handler
+	}
+	return b
+}
+
 // findVolumeMountByNameOrMountPath attempts to find a volume mount with the given name or mount path in the mounts
 // Returns the index of the volume mount or -1 if no volume mount by that name was found.
 func (b *PodTemplateBuilder) findVolumeMountByNameOrMountPath(
@@ -699,6 +699,48 @@ func TestPodTemplateBuilder_WithTerminationGracePeriod(t *testing.T) {
 	}
 }
 
+func TestPodTemplateBuilder_WithPreStopHook(t *testing.T) {
+	containerName := "mycontainer"
+	hook := corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"default"}}}
+	userHook := corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"user"}}}
+	tests := []struct {
+		name        string
+		PodTemplate corev1.PodTemplateSpec
+		hook        corev1.Handler
+		want        *corev1.Handler
+	}{
+		{
+			name:        "set default",
+			PodTemplate: corev1.PodTemplateSpec{},
+			hook:        hook,
+			want:        &hook,
+		},
+		{
+			name: "don't override user-specified value",
+			PodTemplate: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:      containerName,
+							Lifecycle: &corev1.Lifecycle{PreStop: &userHook},
+						},
+					},
+				},
+			},
+			hook: hook,
+			want: &userHook,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewPodTemplateBuilder(tt.PodTemplate, containerName)
+			if got := b.WithPreStopHook(tt.hook).Container.Lifecycle.PreStop; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PodTemplateBuilder.WithPreStopHook() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestPodTemplateBuilder_WithInitContainerDefaults(t *testing.T) {
 	defaultVolumeMount := corev1.VolumeMount{
 		Name:      "default-volume-mount",
@@ -11,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/cloudptio/logstash-operator/pkg/controller/common"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/hash"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/reconciler"
 	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
@@ -22,12 +23,14 @@ var (
 
 // Params to specify a Deployment specification.
 type Params struct {
-	Name            string
-	Namespace       string
-	Selector        map[string]string
-	Labels          map[string]string
-	PodTemplateSpec corev1.PodTemplateSpec
-	Replicas        int32
+	Name      string
+	Namespace string
+	Selector  map[string]string
+	// SelectorExpressions complete the selector with set-based requirements.
+	SelectorExpressions []metav1.LabelSelectorRequirement
+	Labels              map[string]string
+	PodTemplateSpec     corev1.PodTemplateSpec
+	Replicas            int32
 }
 
 // New creates a Deployment from the given params.
@@ -41,7 +44,8 @@ func New(params Params) appsv1.Deployment {
 		Spec: appsv1.DeploymentSpec{
 			RevisionHistoryLimit: common.Int32(defaultRevisionHistoryLimit),
 			Selector: &metav1.LabelSelector{
-				MatchLabels: params.Selector,
+				MatchLabels:      params.Selector,
+				MatchExpressions: params.SelectorExpressions,
 			},
 			Template: params.PodTemplateSpec,
 			Replicas: &params.Replicas,
@@ -56,6 +60,10 @@ func Reconcile(
 	expected appsv1.Deployment,
 	owner metav1.Object,
 ) (appsv1.Deployment, error) {
+	// restart the pods whenever the restart trigger of the owner changes
+	expected = *expected.DeepCopy()
+	annotation.PropagateRestartTrigger(owner, &expected.Spec.Template)
+
 	// label the deployment with a hash of itself
 	expected = WithTemplateHash(expected)
 
@@ -83,3 +91,23 @@ func WithTemplateHash(d appsv1.Deployment) appsv1.Deployment {
 	dCopy.Labels = hash.SetTemplateHashLabel(dCopy.Labels, dCopy)
 	return dCopy
 }
+
+// RolledOut returns true if all the desired pods of the given deployment run its current template and are available.
+func RolledOut(d appsv1.Deployment) bool {
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	status := d.Status
+	return status.ObservedGeneration >= d.Generation && status.UpdatedReplicas >= desired &&
+		status.Replicas <= status.UpdatedReplicas && status.AvailableReplicas >= status.UpdatedReplicas
+}
+
+// CompletedRestartTrigger returns the restart trigger of the pod template of the given deployment, and whether all its
+// pods were restarted with it.
+func CompletedRestartTrigger(d appsv1.Deployment) (string, bool) {
+	if !RolledOut(d) {
+		return "", false
+	}
+	return d.Spec.Template.Annotations[annotation.RestartTriggerAnnotation], true
+}
@@ -10,11 +10,13 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/cloudptio/logstash-operator/pkg/controller/common"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/hash"
 	commonscheme "github.com/cloudptio/logstash-operator/pkg/controller/common/scheme"
 	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
@@ -94,3 +96,73 @@ func TestReconcile(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, reconciled, retrieved)
 }
+
+func TestReconcile_RestartTrigger(t *testing.T) {
+	require.NoError(t, commonscheme.SetupScheme())
+	k8sClient := k8s.WrapClient(fake.NewFakeClient())
+	expected := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "dep", Namespace: "ns"},
+		Spec:       appsv1.DeploymentSpec{Replicas: common.Int32(2)},
+	}
+	owner := v1beta1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Name: "es", Namespace: "ns"}}
+
+	initial, err := Reconcile(k8sClient, scheme.Scheme, expected, &owner)
+	require.NoError(t, err)
+	require.Empty(t, initial.Spec.Template.Annotations)
+
+	// setting the restart trigger updates the pod template
+	owner.Annotations = map[string]string{annotation.RestartTriggerAnnotation: "1"}
+	restarted, err := Reconcile(k8sClient, scheme.Scheme, expected, &owner)
+	require.NoError(t, err)
+	require.Equal(t, "1", restarted.Spec.Template.Annotations[annotation.RestartTriggerAnnotation])
+	require.NotEqual(t, initial.Labels[hash.TemplateHashLabelName], restarted.Labels[hash.TemplateHashLabelName])
+	// the expected deployment is not modified
+	require.Empty(t, expected.Spec.Template.Annotations)
+}
+
+func TestCompletedRestartTrigger(t *testing.T) {
+	dep := func(status appsv1.DeploymentStatus) appsv1.Deployment {
+		return appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: common.Int32(2),
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{annotation.RestartTriggerAnnotation: "1"},
+				}},
+			},
+			Status: status,
+		}
+	}
+	tests := []struct {
+		name          string
+		deployment    appsv1.Deployment
+		wantTrigger   string
+		wantCompleted bool
+	}{
+		{
+			name:       "new generation not observed yet",
+			deployment: dep(appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}),
+		},
+		{
+			name:       "pods being restarted",
+			deployment: dep(appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1, AvailableReplicas: 2}),
+		},
+		{
+			name:       "restarted pods not available yet",
+			deployment: dep(appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1}),
+		},
+		{
+			name:          "all pods restarted",
+			deployment:    dep(appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}),
+			wantTrigger:   "1",
+			wantCompleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger, completed := CompletedRestartTrigger(tt.deployment)
+			require.Equal(t, tt.wantTrigger, trigger)
+			require.Equal(t, tt.wantCompleted, completed)
+		})
+	}
+}
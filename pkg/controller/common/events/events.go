@@ -30,6 +30,8 @@ const (
 	EventAssociationError = "AssociationError"
 	// EventAssociationStatusChange describes association status change events.
 	EventAssociationStatusChange = "AssociationStatusChange"
+	// EventAssociationDenied describes an event fired when a cross-namespace reference is not allowed.
+	EventAssociationDenied = "AssociationDenied"
 )
 
 // Event reasons for common error conditions
@@ -14,6 +14,8 @@ import (
 type Parameters struct {
 	// OperatorNamespace is the control plane namespace of the operator.
 	OperatorNamespace string
+	// ManagedNamespace is the namespace the operator is restricted to, empty if it manages all namespaces.
+	ManagedNamespace string
 	// OperatorInfo is information about the operator
 	OperatorInfo about.OperatorInfo
 	// Dialer is used to create the Elasticsearch HTTP client.
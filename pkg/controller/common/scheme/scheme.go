@@ -5,11 +5,17 @@
 package scheme
 
 import (
+	apmv1alpha1 "github.com/cloudptio/logstash-operator/pkg/apis/apm/v1alpha1"
 	apmv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/apm/v1beta1"
+	commonv1alpha1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1alpha1"
 	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	esv1alpha1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1alpha1"
 	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	kbv1alpha1 "github.com/cloudptio/logstash-operator/pkg/apis/kibana/v1alpha1"
 	kbv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/kibana/v1beta1"
+	lsv1alpha1 "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1alpha1"
 	lsv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 )
 
@@ -37,5 +43,24 @@ func SetupScheme() error {
 		return err
 	}
 	err = lsv1beta1.AddToScheme(clientgoscheme.Scheme)
-	return err
+	if err != nil {
+		return err
+	}
+	return setupV1alpha1Scheme()
+}
+
+// setupV1alpha1Scheme registers the v1alpha1 types, which are only served through the conversion webhook.
+func setupV1alpha1Scheme() error {
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		apmv1alpha1.AddToScheme,
+		commonv1alpha1.AddToScheme,
+		esv1alpha1.AddToScheme,
+		kbv1alpha1.AddToScheme,
+		lsv1alpha1.AddToScheme,
+	} {
+		if err := addToScheme(clientgoscheme.Scheme); err != nil {
+			return err
+		}
+	}
+	return nil
 }
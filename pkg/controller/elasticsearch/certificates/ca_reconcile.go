@@ -33,10 +33,12 @@ type CertificateResources struct {
 }
 
 // reconcileGenericResources reconciles the expected generic resources of a cluster.
+// The given remote transport CAs are trusted in addition to the transport CA of the cluster.
 func Reconcile(
 	driver driver.Interface,
 	es v1beta1.Elasticsearch,
 	services []corev1.Service,
+	remoteCAs []byte,
 	caRotation certificates.RotationParams,
 	certRotation certificates.RotationParams,
 ) (*CertificateResources, *reconciler.Results) {
@@ -109,6 +111,7 @@ func Reconcile(
 		driver.K8sClient(),
 		driver.Scheme(),
 		transportCA,
+		remoteCAs,
 		es,
 		certRotation,
 	)
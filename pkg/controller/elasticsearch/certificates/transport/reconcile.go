@@ -27,11 +27,12 @@ import (
 var log = logf.Log.WithName("transport")
 
 // ReconcileTransportCertificatesSecrets reconciles the secret containing transport certificates for all nodes in the
-// cluster.
+// cluster. The trusted certificate authorities include the given remote certificate authorities, if any.
 func ReconcileTransportCertificatesSecrets(
 	c k8s.Client,
 	scheme *runtime.Scheme,
 	ca *certificates.CA,
+	remoteCAs []byte,
 	es v1beta1.Elasticsearch,
 	rotationParams certificates.RotationParams,
 ) (reconcile.Result, error) {
@@ -87,7 +88,7 @@ func ReconcileTransportCertificatesSecrets(
 		}
 	}
 
-	caBytes := certificates.EncodePEMCert(ca.Cert.Raw)
+	caBytes := append(certificates.EncodePEMCert(ca.Cert.Raw), remoteCAs...)
 
 	// compare with current trusted CA certs.
 	if !bytes.Equal(caBytes, secret.Data[certificates.CAFileName]) {
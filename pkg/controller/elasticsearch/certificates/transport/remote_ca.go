@@ -0,0 +1,32 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package transport
+
+import (
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RemoteCAs returns the concatenated transport certificate authorities of the given remote clusters, read from their
+// public transport certificates secret. Remote clusters whose secret does not exist yet are skipped: they are
+// trusted once their certificate authority is published.
+func RemoteCAs(c k8s.Client, remoteClusters []types.NamespacedName) ([]byte, error) {
+	var remoteCAs []byte
+	for _, remote := range remoteClusters {
+		var secret corev1.Secret
+		if err := c.Get(PublicCertsSecretRef(remote), &secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				log.Info("Remote cluster transport CA not published yet", "namespace", remote.Namespace, "es_name", remote.Name)
+				continue
+			}
+			return nil, err
+		}
+		remoteCAs = append(remoteCAs, secret.Data[certificates.CAFileName]...)
+	}
+	return remoteCAs, nil
+}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -59,7 +60,9 @@ func (c *baseClient) doRequest(context context.Context, request *http.Request) (
 	withContext := request.WithContext(context)
 	withContext.Header.Set("Content-Type", "application/json; charset=utf-8")
 
-	if c.User != (UserAuth{}) {
+	if c.User.APIKey != "" {
+		withContext.Header.Set("Authorization", "ApiKey "+base64.StdEncoding.EncodeToString([]byte(c.User.APIKey)))
+	} else if c.User != (UserAuth{}) {
 		withContext.SetBasicAuth(c.User.Name, c.User.Password)
 	}
 
@@ -134,9 +137,10 @@ func versioned(b *baseClient, v version.Version) Client {
 		baseClient: *b,
 	}
 	switch v.Major {
-	case 7:
+	case 7, 8:
 		return &clientV7{
 			clientV6: v6,
+			version:  v,
 		}
 	default:
 		return &v6
@@ -30,6 +30,8 @@ const (
 type UserAuth struct {
 	Name     string
 	Password string
+	// APIKey is an API key, as `id:api_key`, used instead of the user name and password if set.
+	APIKey string
 }
 
 // Role represents an Elasticsearch role.
@@ -97,6 +99,85 @@ type Client interface {
 	//
 	// Introduced in: Elasticsearch 7.0.0
 	DeleteVotingConfigExclusions(ctx context.Context, waitForRemoval bool) error
+	// PutILMPolicy creates or updates the index lifecycle management policy of the given name.
+	//
+	// Introduced in: Elasticsearch 6.6.0
+	PutILMPolicy(ctx context.Context, name string, policy ILMPolicy) error
+	// GetILMPolicy returns the index lifecycle management policy of the given name.
+	//
+	// Introduced in: Elasticsearch 6.6.0
+	GetILMPolicy(ctx context.Context, name string) (ILMPolicy, error)
+	// DeleteILMPolicy deletes the index lifecycle management policy of the given name.
+	//
+	// Introduced in: Elasticsearch 6.6.0
+	DeleteILMPolicy(ctx context.Context, name string) error
+	// PutIndexTemplate creates or updates the legacy index template of the given name.
+	PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error
+	// PutComposableIndexTemplate creates or updates the composable index template of the given name.
+	//
+	// Introduced in: Elasticsearch 7.8.0
+	PutComposableIndexTemplate(ctx context.Context, name string, template ComposableIndexTemplate) error
+	// GetComposableIndexTemplate returns the composable index template of the given name.
+	//
+	// Introduced in: Elasticsearch 7.8.0
+	GetComposableIndexTemplate(ctx context.Context, name string) (ComposableIndexTemplate, error)
+	// DeleteComposableIndexTemplate deletes the composable index template of the given name.
+	//
+	// Introduced in: Elasticsearch 7.8.0
+	DeleteComposableIndexTemplate(ctx context.Context, name string) error
+	// PutComponentTemplate creates or updates the component template of the given name.
+	//
+	// Introduced in: Elasticsearch 7.8.0
+	PutComponentTemplate(ctx context.Context, name string, template ComponentTemplate) error
+	// GetComponentTemplate returns the component template of the given name.
+	//
+	// Introduced in: Elasticsearch 7.8.0
+	GetComponentTemplate(ctx context.Context, name string) (ComponentTemplate, error)
+	// DeleteComponentTemplate deletes the component template of the given name.
+	//
+	// Introduced in: Elasticsearch 7.8.0
+	DeleteComponentTemplate(ctx context.Context, name string) error
+	// PutIngestPipeline creates or updates the ingest pipeline of the given name.
+	PutIngestPipeline(ctx context.Context, name string, pipeline IngestPipeline) error
+	// GetIngestPipeline returns the ingest pipeline of the given name.
+	GetIngestPipeline(ctx context.Context, name string) (IngestPipeline, error)
+	// DeleteIngestPipeline deletes the ingest pipeline of the given name.
+	DeleteIngestPipeline(ctx context.Context, name string) error
+	// CreateLogstashPipeline stores the given centrally managed Logstash pipeline, unless a pipeline with the same id
+	// already exists. It returns true if the pipeline was created.
+	CreateLogstashPipeline(ctx context.Context, id string, pipeline LogstashPipeline) (bool, error)
+	// CreateAPIKey creates an API key with the given name, lifetime and privileges.
+	//
+	// Introduced in: Elasticsearch 6.7.0
+	CreateAPIKey(ctx context.Context, request APIKeyRequest) (APIKey, error)
+	// InvalidateAPIKey invalidates the API key with the given id.
+	//
+	// Introduced in: Elasticsearch 6.7.0
+	InvalidateAPIKey(ctx context.Context, id string) error
+	// PutSnapshotRepository creates or updates the snapshot repository of the given name.
+	PutSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository) error
+	// GetSnapshotRepository returns the snapshot repository of the given name.
+	GetSnapshotRepository(ctx context.Context, name string) (SnapshotRepository, error)
+	// DeleteSnapshotRepository unregisters the snapshot repository of the given name, leaving its snapshots in place.
+	DeleteSnapshotRepository(ctx context.Context, name string) error
+	// PutSLMPolicy creates or updates the snapshot lifecycle management policy of the given name.
+	//
+	// Introduced in: Elasticsearch 7.4.0
+	PutSLMPolicy(ctx context.Context, name string, policy SLMPolicy) error
+	// DeleteSLMPolicy deletes the snapshot lifecycle management policy of the given name.
+	//
+	// Introduced in: Elasticsearch 7.4.0
+	DeleteSLMPolicy(ctx context.Context, name string) error
+	// GetSLMStatus returns the snapshot lifecycle management policy of the given name, along with its last successful
+	// and failed snapshots.
+	//
+	// Introduced in: Elasticsearch 7.4.0
+	GetSLMStatus(ctx context.Context, name string) (SLMPolicyStatus, error)
+	// UpdateRemoteClusterSettings updates the persistent settings of the given remote clusters. Remote clusters with nil
+	// seeds are removed.
+	UpdateRemoteClusterSettings(ctx context.Context, settings RemoteClustersSettings) error
+	// GetRemoteClusterInfo returns the configuration and connection status of the remote clusters, by alias.
+	GetRemoteClusterInfo(ctx context.Context) (map[string]RemoteClusterInfo, error)
 	// Request exposes a low level interface to the underlying HTTP client e.g. for testing purposes.
 	// The Elasticsearch endpoint will be added automatically to the request URL which should therefore just be the path
 	// with a leading /
@@ -188,3 +269,12 @@ func IsConflict(err error) bool {
 		return false
 	}
 }
+
+// createDocument returns true if the document was created, given the error of a create request, which is a conflict
+// if the document already exists.
+func createDocument(err error) (bool, error) {
+	if IsConflict(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
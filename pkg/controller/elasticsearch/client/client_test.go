@@ -667,3 +667,360 @@ func TestIsConflict(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_PutILMPolicy(t *testing.T) {
+	client := NewMockClient(version.MustParse("7.9.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodPut, req.Method)
+		require.Equal(t, "/_ilm/policy/logstash", req.URL.Path)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"policy":{"phases":{"hot":{"actions":{}}}}}`, string(body))
+		return NewMockResponse(200, req, "{}")
+	})
+	policy := ILMPolicy{Phases: map[string]interface{}{"hot": map[string]interface{}{"actions": map[string]interface{}{}}}}
+	require.NoError(t, client.PutILMPolicy(context.Background(), "logstash", policy))
+}
+
+func TestClient_PutComposableIndexTemplate(t *testing.T) {
+	template := ComposableIndexTemplate{
+		IndexPatterns: []string{"logs-generic-default"},
+		DataStream:    &DataStreamTemplate{},
+	}
+	tests := []struct {
+		name    string
+		version version.Version
+		wantErr bool
+	}{
+		{
+			name:    "not supported in v6",
+			version: version.MustParse("6.8.0"),
+			wantErr: true,
+		},
+		{
+			name:    "supported in v7",
+			version: version.MustParse("7.9.0"),
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		client := NewMockClient(tt.version, func(req *http.Request) *http.Response {
+			require.Equal(t, "/_index_template/logstash", req.URL.Path)
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"index_patterns":["logs-generic-default"],"data_stream":{},"template":{}}`, string(body))
+			return NewMockResponse(200, req, "{}")
+		})
+		err := client.PutComposableIndexTemplate(context.Background(), "logstash", template)
+		require.Equal(t, tt.wantErr, err != nil, tt.name)
+	}
+}
+
+func TestClient_CreateLogstashPipeline(t *testing.T) {
+	pipeline := LogstashPipeline{
+		Pipeline:         "input { stdin {} }",
+		LastModified:     "2019-10-01T00:00:00.000Z",
+		PipelineMetadata: LogstashPipelineMetadata{Type: "logstash_pipeline", Version: "1"},
+		Username:         "elastic",
+	}
+	tests := []struct {
+		name         string
+		version      version.Version
+		expectedPath string
+	}{
+		{
+			name:         "v6 document type",
+			version:      version.MustParse("6.8.0"),
+			expectedPath: "/.logstash/doc/main/_create",
+		},
+		{
+			name:         "v7 typeless",
+			version:      version.MustParse("7.9.0"),
+			expectedPath: "/.logstash/_create/main",
+		},
+		{
+			name:         "v7.12 pipeline API",
+			version:      version.MustParse("7.12.0"),
+			expectedPath: "/_logstash/pipeline/main",
+		},
+		{
+			name:         "v8 pipeline API",
+			version:      version.MustParse("8.0.0"),
+			expectedPath: "/_logstash/pipeline/main",
+		},
+	}
+
+	for _, tt := range tests {
+		client := NewMockClient(tt.version, func(req *http.Request) *http.Response {
+			if req.Method == http.MethodGet {
+				// the pipeline does not exist yet
+				require.Equal(t, tt.expectedPath, req.URL.Path, tt.name)
+				return NewMockResponse(404, req, "{}")
+			}
+			require.Equal(t, http.MethodPut, req.Method)
+			require.Equal(t, tt.expectedPath, req.URL.Path, tt.name)
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"pipeline":"input { stdin {} }","last_modified":"2019-10-01T00:00:00.000Z","pipeline_metadata":{"type":"logstash_pipeline","version":"1"},"username":"elastic"}`, string(body))
+			return NewMockResponse(201, req, "{}")
+		})
+		created, err := client.CreateLogstashPipeline(context.Background(), "main", pipeline)
+		require.NoError(t, err, tt.name)
+		require.True(t, created, tt.name)
+	}
+
+	// existing pipelines are not overwritten
+	conflicting := NewMockClient(version.MustParse("7.9.0"), func(req *http.Request) *http.Response {
+		return NewMockResponse(409, req, "{}")
+	})
+	created, err := conflicting.CreateLogstashPipeline(context.Background(), "main", pipeline)
+	require.NoError(t, err)
+	require.False(t, created)
+
+	existing := NewMockClient(version.MustParse("7.12.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodGet, req.Method)
+		return NewMockResponse(200, req, `{"main": {}}`)
+	})
+	created, err = existing.CreateLogstashPipeline(context.Background(), "main", pipeline)
+	require.NoError(t, err)
+	require.False(t, created)
+}
+
+func TestClient_CreateAPIKey(t *testing.T) {
+	client := NewMockClient(version.MustParse("7.6.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodPost, req.Method)
+		require.Equal(t, "/_security/api_key", req.URL.Path)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"name": "ns-ls-logstash-api-key",
+			"expiration": "3600s",
+			"role_descriptors": {"logstash_writer": {"cluster": ["monitor"], "indices": [{"names": ["*"], "privileges": ["write"]}]}}
+		}`, string(body))
+		return NewMockResponse(200, req, `{"id":"VuaCfGcBCdbkQm-e5aOx","name":"ns-ls-logstash-api-key","expiration":1544068612110,"api_key":"ui2lp2axTNmsyakw9tvNnw"}`)
+	})
+	key, err := client.CreateAPIKey(context.Background(), APIKeyRequest{
+		Name:       "ns-ls-logstash-api-key",
+		Expiration: "3600s",
+		RoleDescriptors: map[string]RoleDescriptor{
+			"logstash_writer": {
+				Cluster: []string{"monitor"},
+				Indices: []IndicesPrivileges{{Names: []string{"*"}, Privileges: []string{"write"}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, APIKey{
+		ID:         "VuaCfGcBCdbkQm-e5aOx",
+		Name:       "ns-ls-logstash-api-key",
+		APIKey:     "ui2lp2axTNmsyakw9tvNnw",
+		Expiration: 1544068612110,
+	}, key)
+	require.Equal(t, "VuaCfGcBCdbkQm-e5aOx:ui2lp2axTNmsyakw9tvNnw", key.Credentials())
+}
+
+func TestClient_InvalidateAPIKey(t *testing.T) {
+	client := NewMockClient(version.MustParse("7.6.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodDelete, req.Method)
+		require.Equal(t, "/_security/api_key", req.URL.Path)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id":"VuaCfGcBCdbkQm-e5aOx"}`, string(body))
+		return NewMockResponse(200, req, `{"invalidated_api_keys":["VuaCfGcBCdbkQm-e5aOx"]}`)
+	})
+	require.NoError(t, client.InvalidateAPIKey(context.Background(), "VuaCfGcBCdbkQm-e5aOx"))
+}
+
+func TestClient_APIKeyAuth(t *testing.T) {
+	client := NewMockClientWithUser(version.MustParse("7.6.0"), UserAuth{APIKey: "id:key"}, func(req *http.Request) *http.Response {
+		require.Equal(t, "ApiKey aWQ6a2V5", req.Header.Get("Authorization"))
+		return NewMockResponse(200, req, `{}`)
+	})
+	_, err := client.GetClusterHealth(context.Background())
+	require.NoError(t, err)
+}
+
+func TestClient_PutSnapshotRepository(t *testing.T) {
+	client := NewMockClient(version.MustParse("6.8.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodPut, req.Method)
+		require.Equal(t, "/_snapshot/default", req.URL.Path)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"type":"fs","settings":{"location":"/snapshots"}}`, string(body))
+		return NewMockResponse(200, req, `{"acknowledged":true}`)
+	})
+	repository := SnapshotRepository{Type: "fs", Settings: map[string]interface{}{"location": "/snapshots"}}
+	require.NoError(t, client.PutSnapshotRepository(context.Background(), "default", repository))
+}
+
+func TestClient_PutSLMPolicy(t *testing.T) {
+	maxCount := int32(10)
+	policy := SLMPolicy{
+		Schedule:   "0 30 1 * * ?",
+		Name:       "<es-snap-{now/d}>",
+		Repository: "default",
+		Retention:  &SLMRetention{ExpireAfter: "30d", MaxCount: &maxCount},
+	}
+	tests := []struct {
+		name    string
+		version version.Version
+		wantErr bool
+	}{
+		{
+			name:    "not supported in v6",
+			version: version.MustParse("6.8.0"),
+			wantErr: true,
+		},
+		{
+			name:    "supported in v7",
+			version: version.MustParse("7.4.0"),
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		client := NewMockClient(tt.version, func(req *http.Request) *http.Response {
+			require.Equal(t, http.MethodPut, req.Method)
+			require.Equal(t, "/_slm/policy/es", req.URL.Path)
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"schedule":"0 30 1 * * ?","name":"<es-snap-{now/d}>","repository":"default","config":{},"retention":{"expire_after":"30d","max_count":10}}`, string(body))
+			return NewMockResponse(200, req, `{"acknowledged":true}`)
+		})
+		err := client.PutSLMPolicy(context.Background(), "es", policy)
+		require.Equal(t, tt.wantErr, err != nil, tt.name)
+	}
+}
+
+func TestClient_GetSLMStatus(t *testing.T) {
+	client := NewMockClient(version.MustParse("7.4.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodGet, req.Method)
+		require.Equal(t, "/_slm/policy/es", req.URL.Path)
+		return NewMockResponse(200, req, `{"es":{"version":1,"modified_date_millis":1570000000000,
+			"policy":{"schedule":"0 30 1 * * ?","name":"<es-snap-{now/d}>","repository":"default"},
+			"last_success":{"snapshot_name":"es-snap-2019.10.02-abc","time":1570001000000},
+			"last_failure":{"snapshot_name":"es-snap-2019.10.01-def","time":1569990000000,"details":"boom"}}}`)
+	})
+	status, err := client.GetSLMStatus(context.Background(), "es")
+	require.NoError(t, err)
+	require.Equal(t, SLMPolicyStatus{
+		Policy:      SLMPolicy{Schedule: "0 30 1 * * ?", Name: "<es-snap-{now/d}>", Repository: "default"},
+		LastSuccess: &SLMInvocation{SnapshotName: "es-snap-2019.10.02-abc", Time: 1570001000000},
+		LastFailure: &SLMInvocation{SnapshotName: "es-snap-2019.10.01-def", Time: 1569990000000, Details: "boom"},
+	}, status)
+}
+
+func TestClient_GetILMPolicy(t *testing.T) {
+	client := NewMockClient(version.MustParse("7.9.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodGet, req.Method)
+		require.Equal(t, "/_ilm/policy/logs", req.URL.Path)
+		return NewMockResponse(200, req, `{"logs":{"version":2,"modified_date":"2020-08-18T12:00:00.000Z",
+			"policy":{"phases":{"hot":{"min_age":"0ms","actions":{}}}}}}`)
+	})
+	policy, err := client.GetILMPolicy(context.Background(), "logs")
+	require.NoError(t, err)
+	require.Equal(t, ILMPolicy{Phases: map[string]interface{}{
+		"hot": map[string]interface{}{"min_age": "0ms", "actions": map[string]interface{}{}},
+	}}, policy)
+}
+
+func TestClient_GetComponentTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		version version.Version
+		wantErr bool
+	}{
+		{
+			name:    "not supported in v6",
+			version: version.MustParse("6.8.0"),
+			wantErr: true,
+		},
+		{
+			name:    "supported in v7",
+			version: version.MustParse("7.9.0"),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		client := NewMockClient(tt.version, func(req *http.Request) *http.Response {
+			require.Equal(t, "/_component_template/logs-settings", req.URL.Path)
+			return NewMockResponse(200, req, `{"component_templates":[{"name":"logs-settings",
+				"component_template":{"template":{"settings":{"index":{"number_of_shards":"1"}}}}}]}`)
+		})
+		template, err := client.GetComponentTemplate(context.Background(), "logs-settings")
+		require.Equal(t, tt.wantErr, err != nil, tt.name)
+		if !tt.wantErr {
+			require.Equal(t, map[string]interface{}{"index": map[string]interface{}{"number_of_shards": "1"}}, template.Template.Settings)
+		}
+	}
+}
+
+func TestClient_GetComposableIndexTemplate(t *testing.T) {
+	client := NewMockClient(version.MustParse("7.9.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_index_template/logs", req.URL.Path)
+		return NewMockResponse(200, req, `{"index_templates":[{"name":"logs",
+			"index_template":{"index_patterns":["logs-*"],"composed_of":["logs-settings"],"priority":100}}]}`)
+	})
+	template, err := client.GetComposableIndexTemplate(context.Background(), "logs")
+	require.NoError(t, err)
+	require.Equal(t, ComposableIndexTemplate{
+		IndexPatterns: []string{"logs-*"},
+		ComposedOf:    []string{"logs-settings"},
+		Priority:      100,
+	}, template)
+}
+
+func TestClient_IngestPipeline(t *testing.T) {
+	client := NewMockClient(version.MustParse("6.8.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, "/_ingest/pipeline/geoip", req.URL.Path)
+		switch req.Method {
+		case http.MethodPut:
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"processors":[{"geoip":{"field":"ip"}}]}`, string(body))
+			return NewMockResponse(200, req, `{"acknowledged":true}`)
+		case http.MethodGet:
+			return NewMockResponse(200, req, `{"geoip":{"processors":[{"geoip":{"field":"ip"}}]}}`)
+		default:
+			return NewMockResponse(200, req, `{"acknowledged":true}`)
+		}
+	})
+	pipeline := IngestPipeline{Processors: []map[string]interface{}{{"geoip": map[string]interface{}{"field": "ip"}}}}
+	require.NoError(t, client.PutIngestPipeline(context.Background(), "geoip", pipeline))
+	actual, err := client.GetIngestPipeline(context.Background(), "geoip")
+	require.NoError(t, err)
+	require.Equal(t, pipeline, actual)
+	require.NoError(t, client.DeleteIngestPipeline(context.Background(), "geoip"))
+}
+
+func TestClient_UpdateRemoteClusterSettings(t *testing.T) {
+	client := NewMockClient(version.MustParse("7.4.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodPut, req.Method)
+		require.Equal(t, "/_cluster/settings", req.URL.Path)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"persistent":{"cluster":{"remote":{
+			"eu":{"seeds":["es-eu-es-transport.eu.svc:9300"]},
+			"us":{"seeds":null}}}}}`, string(body))
+		return NewMockResponse(200, req, `{"acknowledged":true}`)
+	})
+	settings := NewRemoteClustersSettings(map[string][]string{
+		"eu": {"es-eu-es-transport.eu.svc:9300"},
+		"us": nil,
+	})
+	require.NoError(t, client.UpdateRemoteClusterSettings(context.Background(), settings))
+}
+
+func TestClient_GetRemoteClusterInfo(t *testing.T) {
+	client := NewMockClient(version.MustParse("7.4.0"), func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodGet, req.Method)
+		require.Equal(t, "/_remote/info", req.URL.Path)
+		return NewMockResponse(200, req, `{"eu":{"seeds":["es-eu-es-transport.eu.svc:9300"],"connected":true,
+			"num_nodes_connected":3,"max_connections_per_cluster":3,"initial_connect_timeout":"30s","skip_unavailable":false}}`)
+	})
+	info, err := client.GetRemoteClusterInfo(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]RemoteClusterInfo{
+		"eu": {Seeds: []string{"es-eu-es-transport.eu.svc:9300"}, Connected: true, NumNodesConnected: 3},
+	}, info)
+}
@@ -328,3 +328,213 @@ type SearchResults struct {
 	Shards json.RawMessage            // model when needed
 	Aggs   map[string]json.RawMessage // model when needed
 }
+
+// ILMPolicy is an index lifecycle management policy.
+type ILMPolicy struct {
+	Phases map[string]interface{} `json:"phases"`
+	Meta   map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// ilmPolicyRequest is the body of a request creating or updating an ILM policy.
+type ilmPolicyRequest struct {
+	Policy ILMPolicy `json:"policy"`
+}
+
+// IndexTemplate is a legacy index template, applied to the indices created with a name matching its patterns.
+type IndexTemplate struct {
+	IndexPatterns []string               `json:"index_patterns"`
+	Order         int                    `json:"order,omitempty"`
+	Settings      map[string]interface{} `json:"settings,omitempty"`
+	Mappings      map[string]interface{} `json:"mappings,omitempty"`
+}
+
+// ComposableIndexTemplate is an index template applied to the indices and data streams created with a name matching
+// its patterns. The template with the highest priority applies.
+type ComposableIndexTemplate struct {
+	IndexPatterns []string                 `json:"index_patterns"`
+	Priority      int                      `json:"priority,omitempty"`
+	DataStream    *DataStreamTemplate      `json:"data_stream,omitempty"`
+	Template      ComposableTemplateConfig `json:"template"`
+	// ComposedOf are the names of the component templates merged into this template, in order.
+	ComposedOf []string               `json:"composed_of,omitempty"`
+	Version    *int64                 `json:"version,omitempty"`
+	Meta       map[string]interface{} `json:"_meta,omitempty"`
+}
+
+type composableIndexTemplatesResponse struct {
+	IndexTemplates []struct {
+		Name          string                  `json:"name"`
+		IndexTemplate ComposableIndexTemplate `json:"index_template"`
+	} `json:"index_templates"`
+}
+
+// ComponentTemplate is a building block of composable index templates.
+type ComponentTemplate struct {
+	Template ComposableTemplateConfig `json:"template"`
+	Version  *int64                   `json:"version,omitempty"`
+	Meta     map[string]interface{}   `json:"_meta,omitempty"`
+}
+
+type componentTemplatesResponse struct {
+	ComponentTemplates []struct {
+		Name              string            `json:"name"`
+		ComponentTemplate ComponentTemplate `json:"component_template"`
+	} `json:"component_templates"`
+}
+
+// DataStreamTemplate makes a composable index template create data streams.
+type DataStreamTemplate struct{}
+
+// ComposableTemplateConfig holds the settings and mappings of a composable index template.
+type ComposableTemplateConfig struct {
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	Mappings map[string]interface{} `json:"mappings,omitempty"`
+	Aliases  map[string]interface{} `json:"aliases,omitempty"`
+}
+
+// IngestPipeline is a pipeline of processors applied to documents before indexing.
+type IngestPipeline struct {
+	Description string                   `json:"description,omitempty"`
+	Processors  []map[string]interface{} `json:"processors"`
+	OnFailure   []map[string]interface{} `json:"on_failure,omitempty"`
+	Version     *int64                   `json:"version,omitempty"`
+}
+
+// LogstashPipeline is a centrally managed Logstash pipeline, as stored in the .logstash index.
+type LogstashPipeline struct {
+	Pipeline         string                   `json:"pipeline"`
+	Description      string                   `json:"description,omitempty"`
+	LastModified     string                   `json:"last_modified"`
+	PipelineMetadata LogstashPipelineMetadata `json:"pipeline_metadata"`
+	PipelineSettings map[string]interface{}   `json:"pipeline_settings,omitempty"`
+	Username         string                   `json:"username"`
+}
+
+// LogstashPipelineMetadata identifies the format of a centrally managed Logstash pipeline.
+type LogstashPipelineMetadata struct {
+	Type    string `json:"type"`
+	Version string `json:"version"`
+}
+
+// APIKeyRequest is a request to create an API key.
+type APIKeyRequest struct {
+	Name string `json:"name"`
+	// Expiration is the lifetime of the API key, in the Elasticsearch time units format, for example 168h.
+	Expiration string `json:"expiration,omitempty"`
+	// RoleDescriptors limit the privileges of the API key to a subset of the privileges of the authenticated user.
+	RoleDescriptors map[string]RoleDescriptor `json:"role_descriptors,omitempty"`
+}
+
+// RoleDescriptor describes the privileges of a role.
+type RoleDescriptor struct {
+	Cluster []string            `json:"cluster,omitempty"`
+	Indices []IndicesPrivileges `json:"indices,omitempty"`
+}
+
+// IndicesPrivileges are the privileges granted on the indices matching the given names.
+type IndicesPrivileges struct {
+	Names      []string `json:"names"`
+	Privileges []string `json:"privileges"`
+}
+
+// APIKey is an API key created by Elasticsearch.
+type APIKey struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	APIKey string `json:"api_key"`
+	// Expiration is the expiration time of the API key, in milliseconds since the epoch.
+	Expiration int64 `json:"expiration,omitempty"`
+}
+
+// Credentials returns the API key as `id:api_key`, as expected by the Elastic stack applications.
+func (k APIKey) Credentials() string {
+	return k.ID + ":" + k.APIKey
+}
+
+type invalidateAPIKeyRequest struct {
+	ID string `json:"id"`
+}
+
+// SnapshotRepository is an Elasticsearch snapshot repository.
+type SnapshotRepository struct {
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// SLMPolicy is a snapshot lifecycle management policy.
+type SLMPolicy struct {
+	// Schedule is the cron expression triggering the snapshots.
+	Schedule string `json:"schedule"`
+	// Name is the name of the snapshots, supporting date math.
+	Name       string        `json:"name"`
+	Repository string        `json:"repository"`
+	Config     SLMConfig     `json:"config,omitempty"`
+	Retention  *SLMRetention `json:"retention,omitempty"`
+}
+
+// SLMConfig configures the snapshots taken by a snapshot lifecycle management policy.
+type SLMConfig struct {
+	Indices []string `json:"indices,omitempty"`
+}
+
+// SLMRetention defines how long the snapshots taken by a snapshot lifecycle management policy are kept.
+type SLMRetention struct {
+	ExpireAfter string `json:"expire_after,omitempty"`
+	MinCount    *int32 `json:"min_count,omitempty"`
+	MaxCount    *int32 `json:"max_count,omitempty"`
+}
+
+// SLMPolicyStatus holds a snapshot lifecycle management policy and its last invocations.
+type SLMPolicyStatus struct {
+	Policy      SLMPolicy      `json:"policy"`
+	LastSuccess *SLMInvocation `json:"last_success,omitempty"`
+	LastFailure *SLMInvocation `json:"last_failure,omitempty"`
+}
+
+// SLMInvocation is an invocation of a snapshot lifecycle management policy.
+type SLMInvocation struct {
+	SnapshotName string `json:"snapshot_name"`
+	// Time of the invocation, in milliseconds since the epoch.
+	Time int64 `json:"time"`
+	// Details of the failure, if any.
+	Details string `json:"details,omitempty"`
+}
+
+// RemoteClustersSettings is the subset of the persistent cluster settings configuring remote clusters.
+type RemoteClustersSettings struct {
+	Persistent RemoteClustersSettingsGroup `json:"persistent"`
+}
+
+// RemoteClustersSettingsGroup holds the `cluster.remote` settings.
+type RemoteClustersSettingsGroup struct {
+	Cluster RemoteClustersSettingsCluster `json:"cluster"`
+}
+
+// RemoteClustersSettingsCluster holds the settings of the remote clusters, by alias.
+type RemoteClustersSettingsCluster struct {
+	Remote map[string]RemoteClusterSeeds `json:"remote"`
+}
+
+// RemoteClusterSeeds are the seed nodes of a remote cluster. Nil seeds are serialized as null, which removes the
+// remote cluster.
+type RemoteClusterSeeds struct {
+	Seeds []string `json:"seeds"`
+}
+
+// NewRemoteClustersSettings returns the settings configuring the given seeds, by remote cluster alias.
+func NewRemoteClustersSettings(seeds map[string][]string) RemoteClustersSettings {
+	remotes := make(map[string]RemoteClusterSeeds, len(seeds))
+	for alias, s := range seeds {
+		remotes[alias] = RemoteClusterSeeds{Seeds: s}
+	}
+	return RemoteClustersSettings{
+		Persistent: RemoteClustersSettingsGroup{Cluster: RemoteClustersSettingsCluster{Remote: remotes}},
+	}
+}
+
+// RemoteClusterInfo is the configuration and connection status of a remote cluster.
+type RemoteClusterInfo struct {
+	Seeds             []string `json:"seeds"`
+	Connected         bool     `json:"connected"`
+	NumNodesConnected int      `json:"num_nodes_connected"`
+}
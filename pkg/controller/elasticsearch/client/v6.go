@@ -102,6 +102,117 @@ func (c *clientV6) DeleteVotingConfigExclusions(ctx context.Context, waitForRemo
 	return errors.New("Not supported in Elasticsearch 6.x")
 }
 
+func (c *clientV6) PutILMPolicy(ctx context.Context, name string, policy ILMPolicy) error {
+	return c.put(ctx, "/_ilm/policy/"+name, ilmPolicyRequest{Policy: policy}, nil)
+}
+
+func (c *clientV6) GetILMPolicy(ctx context.Context, name string) (ILMPolicy, error) {
+	var policies map[string]ilmPolicyRequest
+	if err := c.get(ctx, "/_ilm/policy/"+name, &policies); err != nil {
+		return ILMPolicy{}, err
+	}
+	return policies[name].Policy, nil
+}
+
+func (c *clientV6) DeleteILMPolicy(ctx context.Context, name string) error {
+	return c.delete(ctx, "/_ilm/policy/"+name, nil, nil)
+}
+
+func (c *clientV6) PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error {
+	return c.put(ctx, "/_template/"+name, template, nil)
+}
+
+func (c *clientV6) PutComposableIndexTemplate(ctx context.Context, name string, template ComposableIndexTemplate) error {
+	return errors.New("Not supported in Elasticsearch 6.x")
+}
+
+func (c *clientV6) GetComposableIndexTemplate(ctx context.Context, name string) (ComposableIndexTemplate, error) {
+	return ComposableIndexTemplate{}, errors.New("Not supported in Elasticsearch 6.x")
+}
+
+func (c *clientV6) DeleteComposableIndexTemplate(ctx context.Context, name string) error {
+	return errors.New("Not supported in Elasticsearch 6.x")
+}
+
+func (c *clientV6) PutComponentTemplate(ctx context.Context, name string, template ComponentTemplate) error {
+	return errors.New("Not supported in Elasticsearch 6.x")
+}
+
+func (c *clientV6) GetComponentTemplate(ctx context.Context, name string) (ComponentTemplate, error) {
+	return ComponentTemplate{}, errors.New("Not supported in Elasticsearch 6.x")
+}
+
+func (c *clientV6) DeleteComponentTemplate(ctx context.Context, name string) error {
+	return errors.New("Not supported in Elasticsearch 6.x")
+}
+
+func (c *clientV6) PutIngestPipeline(ctx context.Context, name string, pipeline IngestPipeline) error {
+	return c.put(ctx, "/_ingest/pipeline/"+name, pipeline, nil)
+}
+
+func (c *clientV6) GetIngestPipeline(ctx context.Context, name string) (IngestPipeline, error) {
+	var pipelines map[string]IngestPipeline
+	if err := c.get(ctx, "/_ingest/pipeline/"+name, &pipelines); err != nil {
+		return IngestPipeline{}, err
+	}
+	return pipelines[name], nil
+}
+
+func (c *clientV6) DeleteIngestPipeline(ctx context.Context, name string) error {
+	return c.delete(ctx, "/_ingest/pipeline/"+name, nil, nil)
+}
+
+func (c *clientV6) CreateLogstashPipeline(ctx context.Context, id string, pipeline LogstashPipeline) (bool, error) {
+	return createDocument(c.put(ctx, "/.logstash/doc/"+id+"/_create", pipeline, nil))
+}
+
+func (c *clientV6) CreateAPIKey(ctx context.Context, request APIKeyRequest) (APIKey, error) {
+	var key APIKey
+	err := c.post(ctx, "/_security/api_key", request, &key)
+	return key, err
+}
+
+func (c *clientV6) InvalidateAPIKey(ctx context.Context, id string) error {
+	return c.delete(ctx, "/_security/api_key", invalidateAPIKeyRequest{ID: id}, nil)
+}
+
+func (c *clientV6) PutSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository) error {
+	return c.put(ctx, "/_snapshot/"+name, repository, nil)
+}
+
+func (c *clientV6) GetSnapshotRepository(ctx context.Context, name string) (SnapshotRepository, error) {
+	var repositories map[string]SnapshotRepository
+	if err := c.get(ctx, "/_snapshot/"+name, &repositories); err != nil {
+		return SnapshotRepository{}, err
+	}
+	return repositories[name], nil
+}
+
+func (c *clientV6) DeleteSnapshotRepository(ctx context.Context, name string) error {
+	return c.delete(ctx, "/_snapshot/"+name, nil, nil)
+}
+
+func (c *clientV6) PutSLMPolicy(ctx context.Context, name string, policy SLMPolicy) error {
+	return errors.New("Not supported in Elasticsearch 6.x")
+}
+
+func (c *clientV6) DeleteSLMPolicy(ctx context.Context, name string) error {
+	return errors.New("Not supported in Elasticsearch 6.x")
+}
+
+func (c *clientV6) GetSLMStatus(ctx context.Context, name string) (SLMPolicyStatus, error) {
+	return SLMPolicyStatus{}, errors.New("Not supported in Elasticsearch 6.x")
+}
+
+func (c *clientV6) UpdateRemoteClusterSettings(ctx context.Context, settings RemoteClustersSettings) error {
+	return c.put(ctx, "/_cluster/settings", settings, nil)
+}
+
+func (c *clientV6) GetRemoteClusterInfo(ctx context.Context) (map[string]RemoteClusterInfo, error) {
+	var info map[string]RemoteClusterInfo
+	return info, c.get(ctx, "/_remote/info", &info)
+}
+
 func (c *clientV6) Request(ctx context.Context, r *http.Request) (*http.Response, error) {
 	newURL, err := url.Parse(stringsutil.Concat(c.Endpoint, r.URL.String()))
 	if err != nil {
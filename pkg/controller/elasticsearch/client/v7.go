@@ -10,11 +10,14 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
 	"github.com/pkg/errors"
 )
 
 type clientV7 struct {
 	clientV6
+	// version of Elasticsearch, for the APIs introduced in minor versions
+	version version.Version
 }
 
 func (c *clientV7) GetLicense(ctx context.Context) (License, error) {
@@ -55,6 +58,67 @@ func (c *clientV7) DeleteVotingConfigExclusions(ctx context.Context, waitForRemo
 	return nil
 }
 
+func (c *clientV7) PutComposableIndexTemplate(ctx context.Context, name string, template ComposableIndexTemplate) error {
+	return c.put(ctx, "/_index_template/"+name, template, nil)
+}
+
+func (c *clientV7) GetComposableIndexTemplate(ctx context.Context, name string) (ComposableIndexTemplate, error) {
+	var response composableIndexTemplatesResponse
+	if err := c.get(ctx, "/_index_template/"+name, &response); err != nil {
+		return ComposableIndexTemplate{}, err
+	}
+	for _, t := range response.IndexTemplates {
+		if t.Name == name {
+			return t.IndexTemplate, nil
+		}
+	}
+	return ComposableIndexTemplate{}, fmt.Errorf("index template %s not found in response", name)
+}
+
+func (c *clientV7) DeleteComposableIndexTemplate(ctx context.Context, name string) error {
+	return c.delete(ctx, "/_index_template/"+name, nil, nil)
+}
+
+func (c *clientV7) PutComponentTemplate(ctx context.Context, name string, template ComponentTemplate) error {
+	return c.put(ctx, "/_component_template/"+name, template, nil)
+}
+
+func (c *clientV7) GetComponentTemplate(ctx context.Context, name string) (ComponentTemplate, error) {
+	var response componentTemplatesResponse
+	if err := c.get(ctx, "/_component_template/"+name, &response); err != nil {
+		return ComponentTemplate{}, err
+	}
+	for _, t := range response.ComponentTemplates {
+		if t.Name == name {
+			return t.ComponentTemplate, nil
+		}
+	}
+	return ComponentTemplate{}, fmt.Errorf("component template %s not found in response", name)
+}
+
+func (c *clientV7) DeleteComponentTemplate(ctx context.Context, name string) error {
+	return c.delete(ctx, "/_component_template/"+name, nil, nil)
+}
+
+// logstashPipelineAPIVersion is the first version with the Logstash pipeline API, direct writes to the .logstash system
+// index being rejected as of 8.0.
+var logstashPipelineAPIVersion = version.MustParse("7.12.0")
+
+func (c *clientV7) CreateLogstashPipeline(ctx context.Context, id string, pipeline LogstashPipeline) (bool, error) {
+	if !c.version.IsSameOrAfter(logstashPipelineAPIVersion) {
+		return createDocument(c.put(ctx, "/.logstash/_create/"+id, pipeline, nil))
+	}
+	// the pipeline API always overwrites the existing pipeline
+	err := c.get(ctx, "/_logstash/pipeline/"+id, nil)
+	if err == nil {
+		return false, nil
+	}
+	if !IsNotFound(err) {
+		return false, err
+	}
+	return true, c.put(ctx, "/_logstash/pipeline/"+id, pipeline, nil)
+}
+
 func (c *clientV7) Equal(c2 Client) bool {
 	other, ok := c2.(*clientV7)
 	if !ok {
@@ -64,3 +128,19 @@ func (c *clientV7) Equal(c2 Client) bool {
 }
 
 var _ Client = &clientV7{}
+
+func (c *clientV7) PutSLMPolicy(ctx context.Context, name string, policy SLMPolicy) error {
+	return c.put(ctx, "/_slm/policy/"+name, policy, nil)
+}
+
+func (c *clientV7) DeleteSLMPolicy(ctx context.Context, name string) error {
+	return c.delete(ctx, "/_slm/policy/"+name, nil, nil)
+}
+
+func (c *clientV7) GetSLMStatus(ctx context.Context, name string) (SLMPolicyStatus, error) {
+	var policies map[string]SLMPolicyStatus
+	if err := c.get(ctx, "/_slm/policy/"+name, &policies); err != nil {
+		return SLMPolicyStatus{}, err
+	}
+	return policies[name], nil
+}
@@ -42,6 +42,7 @@ func ReconcileScriptsConfigMap(c k8s.Client, scheme *runtime.Scheme, es v1beta1.
 		map[string]string{
 			nodespec.ReadinessProbeScriptConfigKey: nodespec.ReadinessProbeScript,
 			initcontainer.PrepareFsScriptConfigKey: fsScript,
+			initcontainer.TopologyScriptConfigKey:  initcontainer.TopologyScript,
 		},
 	)
 
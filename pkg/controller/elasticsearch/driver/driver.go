@@ -29,8 +29,10 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/name"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/observer"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/reconcile"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/remotecluster"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/services"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/settings"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/topology"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/user"
 	esversion "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/version"
 	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
@@ -120,15 +122,31 @@ func (d *defaultDriver) Reconcile() *reconciler.Results {
 		return results.WithError(err)
 	}
 
+	// allow the topology init container to read the Kubernetes nodes
+	if err := topology.Reconcile(d.Client, d.Scheme(), d.ES); err != nil {
+		return results.WithError(err)
+	}
+
 	externalService, err := common.ReconcileService(d.Client, d.Scheme(), services.NewExternalService(d.ES), &d.ES)
 	if err != nil {
 		return results.WithError(err)
 	}
 
+	if _, err := common.ReconcileService(d.Client, d.Scheme(), services.NewTransportService(d.ES), &d.ES); err != nil {
+		return results.WithError(err)
+	}
+
+	// trust the transport CA of the remote clusters, and of the clusters using this one as a remote cluster
+	remoteCAs, err := remotecluster.TrustedCAs(d.Client, d.DynamicWatches(), d.ES)
+	if err != nil {
+		return results.WithError(err)
+	}
+
 	certificateResources, res := certificates.Reconcile(
 		d,
 		d.ES,
 		[]corev1.Service{*externalService},
+		remoteCAs,
 		d.OperatorParameters.CACertRotation,
 		d.OperatorParameters.CertRotation,
 	)
@@ -208,6 +226,27 @@ func (d *defaultDriver) Reconcile() *reconciler.Results {
 		},
 	)
 
+	results.Apply(
+		"reconcile-snapshots",
+		func() (controller.Result, error) {
+			return d.reconcileSnapshots(esReachable, esClient, *min)
+		},
+	)
+
+	results.Apply(
+		"reconcile-index-management",
+		func() (controller.Result, error) {
+			return d.reconcileIndexManagement(esReachable, esClient, *min)
+		},
+	)
+
+	results.Apply(
+		"reconcile-remote-clusters",
+		func() (controller.Result, error) {
+			return d.reconcileRemoteClusters(esReachable, esClient)
+		},
+	)
+
 	// Compute seed hosts based on current masters with a podIP
 	if err := settings.UpdateSeedHostsConfigMap(d.Client, d.Scheme(), d.ES, resourcesState.AllPods); err != nil {
 		return results.WithError(err)
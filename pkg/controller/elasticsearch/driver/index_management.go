@@ -0,0 +1,54 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/indexmanagement"
+	corev1 "k8s.io/api/core/v1"
+	controller "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// indexManagementDriftCheckInterval is the delay between two checks that the objects of the index management
+// specification were not modified outside of the operator.
+var indexManagementDriftCheckInterval = 5 * time.Minute
+
+// reconcileIndexManagement creates, updates and deletes the objects of the index management specification of the
+// cluster, and reports their status.
+func (d *defaultDriver) reconcileIndexManagement(
+	esReachable bool,
+	esClient esclient.Client,
+	esVersion version.Version,
+) (controller.Result, error) {
+	if d.ES.Spec.IndexManagement == nil && len(d.ES.Status.IndexManagement) == 0 {
+		return controller.Result{}, nil
+	}
+	if !esReachable {
+		return defaultRequeue, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	statuses, err := indexmanagement.Reconcile(ctx, esClient, d.ES, esVersion, time.Now())
+	d.ReconcileState.UpdateIndexManagement(statuses)
+	if err != nil {
+		d.ReconcileState.AddEvent(
+			corev1.EventTypeWarning,
+			events.EventReasonUnexpected,
+			fmt.Sprintf("Could not reconcile index management objects: %s", err.Error()),
+		)
+		return defaultRequeue, err
+	}
+	if len(statuses) == 0 {
+		return controller.Result{}, nil
+	}
+	return controller.Result{RequeueAfter: indexManagementDriftCheckInterval}, nil
+}
@@ -6,7 +6,9 @@ package driver
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/keystore"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/reconciler"
@@ -34,6 +36,15 @@ func (d *defaultDriver) reconcileNodeSpecs(
 ) *reconciler.Results {
 	results := &reconciler.Results{}
 
+	// recreate StatefulSets deleted to update their volume claim templates before applying any other change
+	recreations, err := recreateStatefulSets(d.K8sClient(), &d.ES)
+	if err != nil {
+		return results.WithError(err)
+	}
+	if recreations > 0 {
+		return results.WithResult(defaultRequeue)
+	}
+
 	actualStatefulSets, err := sset.RetrieveActualStatefulSets(d.Client, k8s.ExtractNamespacedName(&d.ES))
 	if err != nil {
 		return results.WithError(err)
@@ -57,6 +68,39 @@ func (d *defaultDriver) reconcileNodeSpecs(
 		return results.WithError(err)
 	}
 
+	// expand the volumes whose claim templates request more storage, then recreate their StatefulSets
+	for i := range expectedResources {
+		expected := &expectedResources[i].StatefulSet
+		actual, exists := actualStatefulSets.GetByName(expected.Name)
+		if !exists {
+			continue
+		}
+		recreating, err := handleVolumeExpansion(d.K8sClient(), reconcileState, &d.ES, expected, actual)
+		if err != nil {
+			reconcileState.AddEvent(corev1.EventTypeWarning, events.EventReconciliationError, fmt.Sprintf("Failed to expand volumes: %v", err))
+			return results.WithError(err)
+		}
+		if recreating {
+			return results.WithResult(defaultRequeue)
+		}
+	}
+	resizing, fileSystemResizePending, err := pendingVolumeExpansions(d.K8sClient(), d.ES)
+	if err != nil {
+		return results.WithError(err)
+	}
+	if len(resizing) > 0 {
+		// volumes are being expanded, keep checking until they are done
+		log.Info("Waiting for volume expansion", "namespace", d.ES.Namespace, "es_name", d.ES.Name, "pvcs", resizing)
+		results.WithResult(defaultRequeue)
+	}
+	if len(fileSystemResizePending) > 0 {
+		// offline volume expansion: the file system is only resized when the pod is restarted, which is left to the user
+		reconcileState.AddEvent(corev1.EventTypeWarning, events.EventReasonDelayed, fmt.Sprintf(
+			"The file system of PVCs %s will be resized when their pods are restarted, for example with the %s annotation",
+			strings.Join(fileSystemResizePending, ", "), annotation.RestartTriggerAnnotation,
+		))
+	}
+
 	esState := NewMemoizingESState(esClient)
 
 	// Phase 1: apply expected StatefulSets resources and scale up.
@@ -131,6 +175,8 @@ func (d *defaultDriver) reconcileNodeSpecs(
 	// override another "not Ready" phase like MigratingData.
 	if Reconciled(expectedResources.StatefulSets(), actualStatefulSets, d.Client) {
 		reconcileState.UpdateElasticsearchReady(resourcesState, observedState)
+		// all nodes run the expected pod template, including the current restart trigger
+		reconcileState.UpdateRestartTrigger(d.ES.Annotations[annotation.RestartTriggerAnnotation])
 	} else if reconcileState.IsElasticsearchReady(observedState) {
 		reconcileState.UpdateElasticsearchApplyingChanges(resourcesState.CurrentPods)
 	}
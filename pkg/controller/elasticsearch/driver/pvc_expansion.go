@@ -0,0 +1,254 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/hash"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/label"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/reconcile"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/sset"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+)
+
+// RecreateStatefulSetAnnotationPrefix prefixes the annotations of the Elasticsearch resource storing the StatefulSets
+// deleted to update their volume claim templates, until they are recreated.
+const RecreateStatefulSetAnnotationPrefix = "elasticsearch.k8s.elastic.co/recreate-"
+
+// handleVolumeExpansion expands the PVCs of the given StatefulSet whose claim templates request more storage, if their
+// storage class allows volume expansion. Since volume claim templates cannot be updated in place, the StatefulSet is
+// then deleted without deleting its pods, to be recreated with the expected claim templates by recreateStatefulSets.
+// It returns true if the StatefulSet is being recreated.
+// If a volume cannot be expanded, a warning event is emitted and the expected StatefulSet keeps the actual claim
+// templates, so that the other changes can still be applied.
+func handleVolumeExpansion(
+	k8sClient k8s.Client,
+	reconcileState *reconcile.State,
+	es *v1beta1.Elasticsearch,
+	expected *appsv1.StatefulSet,
+	actual appsv1.StatefulSet,
+) (bool, error) {
+	resized := resizedClaims(expected.Spec.VolumeClaimTemplates, actual.Spec.VolumeClaimTemplates)
+	if len(resized) == 0 {
+		return false, nil
+	}
+
+	// check all the PVCs can be expanded before expanding any of them
+	var toExpand []corev1.PersistentVolumeClaim
+	for _, claim := range resized {
+		for _, podName := range sset.PodNames(actual) {
+			var pvc corev1.PersistentVolumeClaim
+			nsn := types.NamespacedName{Namespace: actual.Namespace, Name: fmt.Sprintf("%s-%s", claim.Name, podName)}
+			if err := k8sClient.Get(nsn, &pvc); err != nil {
+				if apierrors.IsNotFound(err) {
+					// the pod does not exist yet, its PVC will be created with the expected size
+					continue
+				}
+				return false, err
+			}
+			requested := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+			current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			if requested.Cmp(current) <= 0 {
+				continue
+			}
+			supported, err := volumeExpansionSupported(k8sClient, pvc)
+			if err != nil {
+				return false, err
+			}
+			if !supported {
+				msg := fmt.Sprintf(
+					"Cannot increase the storage request of volume claim template %s of StatefulSet %s: "+
+						"the storage class of PVC %s does not allow volume expansion",
+					claim.Name, actual.Name, pvc.Name,
+				)
+				log.Info(msg, "namespace", actual.Namespace, "es_name", es.Name)
+				reconcileState.AddEvent(corev1.EventTypeWarning, events.EventReasonUnexpected, msg)
+				keepActualClaims(expected, actual)
+				return false, nil
+			}
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = requested
+			toExpand = append(toExpand, pvc)
+		}
+	}
+	for i := range toExpand {
+		pvc := toExpand[i]
+		storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		log.Info("Expanding PVC", "namespace", pvc.Namespace, "pvc_name", pvc.Name, "storage", storage.String())
+		if err := k8sClient.Update(&pvc); err != nil {
+			return false, err
+		}
+	}
+
+	// recreate the StatefulSet with the expected claims, leaving other changes to the next reconciliations
+	toRecreate := actual.DeepCopy()
+	toRecreate.Spec.VolumeClaimTemplates = expected.Spec.VolumeClaimTemplates
+	if err := annotateForRecreation(k8sClient, es, *toRecreate); err != nil {
+		return false, err
+	}
+	if err := deleteOrphaningPods(k8sClient, actual); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// deleteOrphaningPods deletes the given StatefulSet, if it still has the same UID, but keeps its pods.
+func deleteOrphaningPods(k8sClient k8s.Client, sset appsv1.StatefulSet) error {
+	log.Info("Deleting StatefulSet to update its volume claim templates, pods are kept",
+		"namespace", sset.Namespace, "statefulset_name", sset.Name)
+	uid := sset.UID
+	err := k8sClient.Delete(
+		&sset,
+		client.PropagationPolicy(metav1.DeletePropagationOrphan),
+		client.Preconditions{UID: &uid},
+	)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// resizedClaims returns the expected claims requesting more storage than the actual claims of the same name.
+func resizedClaims(expected, actual []corev1.PersistentVolumeClaim) []corev1.PersistentVolumeClaim {
+	var resized []corev1.PersistentVolumeClaim
+	for _, expectedClaim := range expected {
+		for _, actualClaim := range actual {
+			if expectedClaim.Name != actualClaim.Name {
+				continue
+			}
+			expectedStorage := expectedClaim.Spec.Resources.Requests[corev1.ResourceStorage]
+			actualStorage := actualClaim.Spec.Resources.Requests[corev1.ResourceStorage]
+			if expectedStorage.Cmp(actualStorage) > 0 {
+				resized = append(resized, expectedClaim)
+			}
+		}
+	}
+	return resized
+}
+
+// volumeExpansionSupported returns true if the storage class of the given PVC allows volume expansion.
+func volumeExpansionSupported(k8sClient k8s.Client, pvc corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return false, nil
+	}
+	var storageClass storagev1.StorageClass
+	err := k8sClient.Get(types.NamespacedName{Name: *pvc.Spec.StorageClassName}, &storageClass)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion, nil
+}
+
+// keepActualClaims replaces the claim templates of the expected StatefulSet with the actual ones, which cannot be
+// updated in place, and updates its template hash accordingly.
+func keepActualClaims(expected *appsv1.StatefulSet, actual appsv1.StatefulSet) {
+	expected.Spec.VolumeClaimTemplates = actual.Spec.VolumeClaimTemplates
+	expected.Labels = hash.SetTemplateHashLabel(expected.Labels, expected.Spec)
+}
+
+// annotateForRecreation stores the StatefulSet to recreate in an annotation of the Elasticsearch resource, so it is
+// recreated even if the operator restarts right after deleting it.
+func annotateForRecreation(k8sClient k8s.Client, es *v1beta1.Elasticsearch, toRecreate appsv1.StatefulSet) error {
+	// the UID of the deleted StatefulSet is kept to know when it was recreated
+	toRecreate.ResourceVersion = ""
+	toRecreate.Status = appsv1.StatefulSetStatus{}
+	asJSON, err := json.Marshal(toRecreate)
+	if err != nil {
+		return err
+	}
+	if es.Annotations == nil {
+		es.Annotations = map[string]string{}
+	}
+	es.Annotations[RecreateStatefulSetAnnotationPrefix+toRecreate.Name] = string(asJSON)
+	return k8sClient.Update(es)
+}
+
+// recreateStatefulSets recreates the StatefulSets deleted to update their volume claim templates. It returns the
+// number of StatefulSets whose recreation is still in progress.
+func recreateStatefulSets(k8sClient k8s.Client, es *v1beta1.Elasticsearch) (int, error) {
+	recreations := 0
+	for key, value := range es.Annotations {
+		if !strings.HasPrefix(key, RecreateStatefulSetAnnotationPrefix) {
+			continue
+		}
+		var toRecreate appsv1.StatefulSet
+		if err := json.Unmarshal([]byte(value), &toRecreate); err != nil {
+			return recreations, err
+		}
+		var existing appsv1.StatefulSet
+		err := k8sClient.Get(k8s.ExtractNamespacedName(&toRecreate), &existing)
+		switch {
+		case apierrors.IsNotFound(err):
+			log.Info("Recreating StatefulSet", "namespace", toRecreate.Namespace, "statefulset_name", toRecreate.Name)
+			toCreate := toRecreate.DeepCopy()
+			toCreate.UID = ""
+			if err := k8sClient.Create(toCreate); err != nil && !apierrors.IsAlreadyExists(err) {
+				return recreations, err
+			}
+			recreations++
+		case err != nil:
+			return recreations, err
+		case existing.UID == toRecreate.UID:
+			if existing.DeletionTimestamp.IsZero() {
+				// the StatefulSet was annotated for recreation but its deletion failed or was interrupted
+				if err := deleteOrphaningPods(k8sClient, existing); err != nil {
+					return recreations, err
+				}
+			}
+			// the StatefulSet is still being deleted, while its pods are orphaned
+			recreations++
+		default:
+			// the StatefulSet was recreated
+			delete(es.Annotations, key)
+			if err := k8sClient.Update(es); err != nil {
+				return recreations, err
+			}
+		}
+	}
+	return recreations, nil
+}
+
+// pendingVolumeExpansions returns the names of the PVCs of the given cluster being expanded, and the names of the
+// PVCs whose file system is waiting for their pod to be restarted to be resized, as with offline volume expansion.
+func pendingVolumeExpansions(k8sClient k8s.Client, es v1beta1.Elasticsearch) ([]string, []string, error) {
+	var pvcs corev1.PersistentVolumeClaimList
+	ns := client.InNamespace(es.Namespace)
+	matchLabels := label.NewLabelSelectorForElasticsearch(es)
+	if err := k8sClient.List(&pvcs, ns, matchLabels); err != nil {
+		return nil, nil, err
+	}
+	var resizing, fileSystemResizePending []string
+	for _, pvc := range pvcs.Items {
+		for _, condition := range pvc.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				continue
+			}
+			if condition.Type == corev1.PersistentVolumeClaimResizing {
+				resizing = append(resizing, pvc.Name)
+				break
+			}
+			if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending {
+				fileSystemResizePending = append(fileSystemResizePending, pvc.Name)
+				break
+			}
+		}
+	}
+	return resizing, fileSystemResizePending, nil
+}
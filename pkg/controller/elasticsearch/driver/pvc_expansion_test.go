@@ -0,0 +1,212 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package driver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/hash"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/scheme"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/label"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/reconcile"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+)
+
+func withStorage(s appsv1.StatefulSet, storage string) appsv1.StatefulSet {
+	s = *s.DeepCopy()
+	for i := range s.Spec.VolumeClaimTemplates {
+		s.Spec.VolumeClaimTemplates[i].Spec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceStorage: resource.MustParse(storage),
+		}
+	}
+	return s
+}
+
+func pvcWithStorage(name string, storageClass string, storage string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(storage)},
+			},
+		},
+	}
+}
+
+func storageClass(name string, allowExpansion bool) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: name},
+		AllowVolumeExpansion: &allowExpansion,
+	}
+}
+
+func Test_handleVolumeExpansion(t *testing.T) {
+	require.NoError(t, scheme.SetupScheme())
+	es := v1beta1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	actual := withStorage(buildSsetWithClaims("sset", 2, "data"), "1Gi")
+	actual.UID = "sset-uid"
+	expected := withStorage(actual, "2Gi")
+
+	tests := []struct {
+		name          string
+		expected      appsv1.StatefulSet
+		storageClass  *storagev1.StorageClass
+		wantErr       bool
+		wantRecreated bool
+		wantStorage   string
+		wantEvent     bool
+	}{
+		{
+			name:         "no storage increase",
+			expected:     actual,
+			storageClass: storageClass("standard", true),
+			wantStorage:  "1Gi",
+		},
+		{
+			name:          "storage increase with a storage class allowing expansion",
+			expected:      expected,
+			storageClass:  storageClass("standard", true),
+			wantRecreated: true,
+			wantStorage:   "2Gi",
+		},
+		{
+			name:         "storage increase with a storage class not allowing expansion",
+			expected:     expected,
+			storageClass: storageClass("standard", false),
+			wantStorage:  "1Gi",
+			wantEvent:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := *es.DeepCopy()
+			existingSset := actual.DeepCopy()
+			c := k8s.WrapClient(fake.NewFakeClient(
+				&es,
+				existingSset,
+				tt.storageClass,
+				pvcWithStorage("data-sset-0", "standard", "1Gi"),
+				pvcWithStorage("data-sset-1", "standard", "1Gi"),
+			))
+
+			reconcileState := reconcile.NewState(es)
+			expected := tt.expected.DeepCopy()
+			recreated, err := handleVolumeExpansion(c, reconcileState, &es, expected, *existingSset)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.wantRecreated, recreated)
+			require.Equal(t, tt.wantEvent, len(reconcileState.Events()) > 0)
+			if tt.wantEvent {
+				// the other changes can still be applied with the actual claims
+				require.Equal(t, existingSset.Spec.VolumeClaimTemplates, expected.Spec.VolumeClaimTemplates)
+				require.Equal(t, hash.HashObject(expected.Spec), expected.Labels[hash.TemplateHashLabelName])
+			}
+
+			for _, name := range []string{"data-sset-0", "data-sset-1"} {
+				var pvc corev1.PersistentVolumeClaim
+				require.NoError(t, c.Get(types.NamespacedName{Namespace: "ns", Name: name}, &pvc))
+				storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				require.Equal(t, tt.wantStorage, storage.String())
+			}
+			var sset appsv1.StatefulSet
+			err = c.Get(k8s.ExtractNamespacedName(&actual), &sset)
+			require.Equal(t, tt.wantRecreated, apierrors.IsNotFound(err))
+			_, annotated := es.Annotations[RecreateStatefulSetAnnotationPrefix+"sset"]
+			require.Equal(t, tt.wantRecreated, annotated)
+		})
+	}
+}
+
+func Test_recreateStatefulSets(t *testing.T) {
+	require.NoError(t, scheme.SetupScheme())
+	toRecreate := withStorage(buildSsetWithClaims("sset", 2, "data"), "2Gi")
+	toRecreate.UID = "sset-uid"
+	es := &v1beta1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	c := k8s.WrapClient(fake.NewFakeClient(es))
+	require.NoError(t, annotateForRecreation(c, es, toRecreate))
+
+	// the StatefulSet is recreated
+	recreations, err := recreateStatefulSets(c, es)
+	require.NoError(t, err)
+	require.Equal(t, 1, recreations)
+	var recreated appsv1.StatefulSet
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(&toRecreate), &recreated))
+	require.Equal(t, toRecreate.Spec.VolumeClaimTemplates, recreated.Spec.VolumeClaimTemplates)
+
+	// the recreated StatefulSet has a new UID: the annotation is removed
+	recreated.UID = "new-uid"
+	require.NoError(t, c.Update(&recreated))
+	recreations, err = recreateStatefulSets(c, es)
+	require.NoError(t, err)
+	require.Equal(t, 0, recreations)
+	require.Empty(t, es.Annotations)
+
+	// nothing to recreate
+	recreations, err = recreateStatefulSets(c, es)
+	require.NoError(t, err)
+	require.Equal(t, 0, recreations)
+}
+
+func Test_recreateStatefulSets_deletionFailed(t *testing.T) {
+	require.NoError(t, scheme.SetupScheme())
+	toRecreate := withStorage(buildSsetWithClaims("sset", 2, "data"), "2Gi")
+	toRecreate.UID = "sset-uid"
+	// the StatefulSet was annotated for recreation, but its deletion failed
+	actual := withStorage(buildSsetWithClaims("sset", 2, "data"), "1Gi")
+	actual.UID = "sset-uid"
+	es := &v1beta1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	c := k8s.WrapClient(fake.NewFakeClient(es, &actual))
+	require.NoError(t, annotateForRecreation(c, es, toRecreate))
+
+	// the StatefulSet is deleted again
+	recreations, err := recreateStatefulSets(c, es)
+	require.NoError(t, err)
+	require.Equal(t, 1, recreations)
+	var deleted appsv1.StatefulSet
+	err = c.Get(k8s.ExtractNamespacedName(&actual), &deleted)
+	require.True(t, apierrors.IsNotFound(err))
+
+	// then recreated with the expected claims
+	recreations, err = recreateStatefulSets(c, es)
+	require.NoError(t, err)
+	require.Equal(t, 1, recreations)
+	var recreated appsv1.StatefulSet
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(&toRecreate), &recreated))
+	require.Equal(t, toRecreate.Spec.VolumeClaimTemplates, recreated.Spec.VolumeClaimTemplates)
+}
+
+func Test_pendingVolumeExpansions(t *testing.T) {
+	es := v1beta1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"}}
+	resizing := pvcWithStorage("data-sset-0", "standard", "2Gi")
+	resizing.Labels = map[string]string{label.ClusterNameLabelName: "es"}
+	resizing.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+		{Type: corev1.PersistentVolumeClaimResizing, Status: corev1.ConditionTrue},
+	}
+	fileSystemResizePending := pvcWithStorage("data-sset-1", "standard", "2Gi")
+	fileSystemResizePending.Labels = resizing.Labels
+	fileSystemResizePending.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+		{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue},
+	}
+	resized := pvcWithStorage("data-sset-2", "standard", "2Gi")
+	resized.Labels = resizing.Labels
+	c := k8s.WrapClient(fake.NewFakeClient([]runtime.Object{resizing, fileSystemResizePending, resized}...))
+
+	pendingResize, pendingFileSystemResize, err := pendingVolumeExpansions(c, es)
+	require.NoError(t, err)
+	require.Equal(t, []string{"data-sset-0"}, pendingResize)
+	require.Equal(t, []string{"data-sset-1"}, pendingFileSystemResize)
+}
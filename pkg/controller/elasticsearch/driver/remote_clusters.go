@@ -0,0 +1,50 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/remotecluster"
+	corev1 "k8s.io/api/core/v1"
+	controller "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// remoteClustersStatusInterval is the delay between two refreshes of the connection status of the remote clusters.
+var remoteClustersStatusInterval = 1 * time.Minute
+
+// reconcileRemoteClusters configures the remote clusters of the cluster, and reports their connection status.
+func (d *defaultDriver) reconcileRemoteClusters(
+	esReachable bool,
+	esClient esclient.Client,
+) (controller.Result, error) {
+	if len(d.ES.Spec.RemoteClusters) == 0 && len(d.ES.Status.RemoteClusters) == 0 {
+		return controller.Result{}, nil
+	}
+	if !esReachable {
+		return defaultRequeue, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	statuses, err := remotecluster.Reconcile(ctx, d.Client, esClient, d.ES)
+	d.ReconcileState.UpdateRemoteClusters(statuses)
+	if err != nil {
+		d.ReconcileState.AddEvent(
+			corev1.EventTypeWarning,
+			events.EventReasonUnexpected,
+			fmt.Sprintf("Could not reconcile remote clusters: %s", err.Error()),
+		)
+		return defaultRequeue, err
+	}
+	if len(statuses) == 0 {
+		return controller.Result{}, nil
+	}
+	return controller.Result{RequeueAfter: remoteClustersStatusInterval}, nil
+}
@@ -0,0 +1,53 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/snapshot"
+	corev1 "k8s.io/api/core/v1"
+	controller "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// snapshotStatusRefreshInterval is the delay between two refreshes of the last snapshots in the status.
+var snapshotStatusRefreshInterval = 5 * time.Minute
+
+// reconcileSnapshots registers the snapshot repository and the snapshot lifecycle management policy of the cluster,
+// or deletes the ones removed from the specification, and reports the last snapshots in the status.
+func (d *defaultDriver) reconcileSnapshots(
+	esReachable bool,
+	esClient esclient.Client,
+	esVersion version.Version,
+) (controller.Result, error) {
+	if d.ES.Spec.Snapshots == nil && d.ES.Status.Snapshots == nil {
+		return controller.Result{}, nil
+	}
+	if !esReachable {
+		return defaultRequeue, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	status, err := snapshot.Reconcile(ctx, esClient, d.ES, esVersion)
+	if err != nil {
+		d.ReconcileState.AddEvent(
+			corev1.EventTypeWarning,
+			events.EventReasonUnexpected,
+			fmt.Sprintf("Could not reconcile snapshots: %s", err.Error()),
+		)
+		return defaultRequeue, err
+	}
+	d.ReconcileState.UpdateSnapshots(status)
+	if status == nil || status.Policy == "" {
+		return controller.Result{}, nil
+	}
+	return controller.Result{RequeueAfter: snapshotStatusRefreshInterval}, nil
+}
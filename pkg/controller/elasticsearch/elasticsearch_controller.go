@@ -12,6 +12,7 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/controller/common"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates/http"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/conditions"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/expectations"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/finalizer"
@@ -25,6 +26,8 @@ import (
 	esname "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/name"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/observer"
 	esreconcile "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/reconcile"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/remotecluster"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/topology"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/validation"
 	esversion "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/version"
 	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
@@ -91,6 +94,26 @@ func addWatches(c controller.Controller, r *ReconcileElasticsearch) error {
 		return err
 	}
 
+	// Watch Elasticsearch clusters declaring remote clusters, for the remote clusters to trust their transport CA
+	if err := c.Watch(
+		&source.Kind{Type: &elasticsearchv1beta1.Elasticsearch{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(
+				func(object handler.MapObject) []reconcile.Request {
+					es, ok := object.Object.(*elasticsearchv1beta1.Elasticsearch)
+					if !ok {
+						return nil
+					}
+					requests := make([]reconcile.Request, 0, len(es.Spec.RemoteClusters))
+					for _, remote := range es.Spec.RemoteClusters {
+						requests = append(requests, reconcile.Request{NamespacedName: remote.NamespacedName(es.Namespace)})
+					}
+					return requests
+				}),
+		},
+	); err != nil {
+		return err
+	}
+
 	// Watch StatefulSets
 	if err := c.Watch(
 		&source.Kind{Type: &appsv1.StatefulSet{}}, &handler.EnqueueRequestForOwner{
@@ -217,6 +240,8 @@ func (r *ReconcileElasticsearch) Reconcile(request reconcile.Request) (reconcile
 
 	state := esreconcile.NewState(es)
 	results := r.internalReconcile(es, state)
+	_, reconcileErr := results.Aggregate()
+	state.UpdateReconciled(reconcileErr)
 	err = r.updateStatus(es, state)
 	if err != nil {
 		if apierrors.IsConflict(err) {
@@ -248,6 +273,7 @@ func (r *ReconcileElasticsearch) internalReconcile(
 	if err != nil {
 		return results.WithError(err)
 	}
+	violations = append(violations, validation.ValidateOperatorScope(es, r.ManagedNamespace)...)
 	if len(violations) > 0 {
 		log.Error(
 			fmt.Errorf("manifest validation failed"),
@@ -262,8 +288,10 @@ func (r *ReconcileElasticsearch) internalReconcile(
 
 	ver, err := commonversion.Parse(es.Spec.Version)
 	if err != nil {
+		reconcileState.SetConditions(conditions.InvalidVersion(es.Generation, err))
 		return results.WithError(err)
 	}
+	reconcileState.SetConditions(conditions.ConfigValid(es.Generation, nil))
 	supported := esversion.SupportedVersions(*ver)
 	if supported == nil {
 		return results.WithError(fmt.Errorf("unsupported version: %s", ver))
@@ -309,5 +337,7 @@ func (r *ReconcileElasticsearch) finalizersFor(
 		r.esObservers.Finalizer(clusterName),
 		keystore.Finalizer(k8s.ExtractNamespacedName(&es), r.dynamicWatches, es.Kind),
 		http.DynamicWatchesFinalizer(r.dynamicWatches, es.Kind, es.Name, esname.ESNamer),
+		remotecluster.Finalizer(k8s.ExtractNamespacedName(&es), r.dynamicWatches),
+		topology.Finalizer(r.Client, es, r.ManagedNamespace),
 	}
 }
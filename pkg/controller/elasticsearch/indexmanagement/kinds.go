@@ -0,0 +1,186 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package indexmanagement
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+)
+
+var (
+	// ILMMinVersion is the first Elasticsearch version supporting index lifecycle management.
+	ILMMinVersion = version.MustParse("6.6.0")
+	// ComposableTemplatesMinVersion is the first Elasticsearch version supporting component and composable index
+	// templates.
+	ComposableTemplatesMinVersion = version.MustParse("7.8.0")
+)
+
+// indexSettingsPrefix prefixes the index settings returned by Elasticsearch.
+const indexSettingsPrefix = "index."
+
+// kind manages the Elasticsearch objects of a kind of the index management specification.
+type kind struct {
+	kind       v1beta1.IndexManagementKind
+	minVersion version.Version
+	// objects returns the objects of this kind in the given specification.
+	objects func(spec v1beta1.IndexManagementSpec) []v1beta1.IndexManagementObject
+	// decode returns the expected Elasticsearch object from the body of its specification.
+	decode func(body []byte) (interface{}, error)
+	// normalize returns the given Elasticsearch object in the form used to detect drifts.
+	normalize func(object interface{}) interface{}
+	get       func(ctx context.Context, c esclient.Client, name string) (interface{}, error)
+	put       func(ctx context.Context, c esclient.Client, name string, object interface{}) error
+	delete    func(ctx context.Context, c esclient.Client, name string) error
+}
+
+// ilmPolicyBody is the body of a request creating an ILM policy.
+type ilmPolicyBody struct {
+	Policy esclient.ILMPolicy `json:"policy"`
+}
+
+// kinds are the kinds of the index management specification, in creation order: policies and component templates
+// must exist before the index templates referencing them. Objects are deleted in the reverse order.
+var kinds = []kind{
+	{
+		kind:       v1beta1.ILMPolicyKind,
+		minVersion: ILMMinVersion,
+		objects: func(spec v1beta1.IndexManagementSpec) []v1beta1.IndexManagementObject {
+			return spec.ILMPolicies
+		},
+		decode: func(body []byte) (interface{}, error) {
+			var policy ilmPolicyBody
+			err := decodeStrict(body, &policy)
+			return policy.Policy, err
+		},
+		normalize: identity,
+		get: func(ctx context.Context, c esclient.Client, name string) (interface{}, error) {
+			return c.GetILMPolicy(ctx, name)
+		},
+		put: func(ctx context.Context, c esclient.Client, name string, object interface{}) error {
+			return c.PutILMPolicy(ctx, name, object.(esclient.ILMPolicy))
+		},
+		delete: func(ctx context.Context, c esclient.Client, name string) error {
+			return c.DeleteILMPolicy(ctx, name)
+		},
+	},
+	{
+		kind:       v1beta1.ComponentTemplateKind,
+		minVersion: ComposableTemplatesMinVersion,
+		objects: func(spec v1beta1.IndexManagementSpec) []v1beta1.IndexManagementObject {
+			return spec.ComponentTemplates
+		},
+		decode: func(body []byte) (interface{}, error) {
+			var template esclient.ComponentTemplate
+			err := decodeStrict(body, &template)
+			return template, err
+		},
+		normalize: func(object interface{}) interface{} {
+			template := object.(esclient.ComponentTemplate)
+			template.Template.Settings = normalizeIndexSettings(template.Template.Settings)
+			return template
+		},
+		get: func(ctx context.Context, c esclient.Client, name string) (interface{}, error) {
+			return c.GetComponentTemplate(ctx, name)
+		},
+		put: func(ctx context.Context, c esclient.Client, name string, object interface{}) error {
+			return c.PutComponentTemplate(ctx, name, object.(esclient.ComponentTemplate))
+		},
+		delete: func(ctx context.Context, c esclient.Client, name string) error {
+			return c.DeleteComponentTemplate(ctx, name)
+		},
+	},
+	{
+		kind:       v1beta1.IndexTemplateKind,
+		minVersion: ComposableTemplatesMinVersion,
+		objects: func(spec v1beta1.IndexManagementSpec) []v1beta1.IndexManagementObject {
+			return spec.IndexTemplates
+		},
+		decode: func(body []byte) (interface{}, error) {
+			var template esclient.ComposableIndexTemplate
+			err := decodeStrict(body, &template)
+			return template, err
+		},
+		normalize: func(object interface{}) interface{} {
+			template := object.(esclient.ComposableIndexTemplate)
+			template.Template.Settings = normalizeIndexSettings(template.Template.Settings)
+			return template
+		},
+		get: func(ctx context.Context, c esclient.Client, name string) (interface{}, error) {
+			return c.GetComposableIndexTemplate(ctx, name)
+		},
+		put: func(ctx context.Context, c esclient.Client, name string, object interface{}) error {
+			return c.PutComposableIndexTemplate(ctx, name, object.(esclient.ComposableIndexTemplate))
+		},
+		delete: func(ctx context.Context, c esclient.Client, name string) error {
+			return c.DeleteComposableIndexTemplate(ctx, name)
+		},
+	},
+	{
+		kind: v1beta1.IngestPipelineKind,
+		objects: func(spec v1beta1.IndexManagementSpec) []v1beta1.IndexManagementObject {
+			return spec.IngestPipelines
+		},
+		decode: func(body []byte) (interface{}, error) {
+			var pipeline esclient.IngestPipeline
+			err := decodeStrict(body, &pipeline)
+			return pipeline, err
+		},
+		normalize: identity,
+		get: func(ctx context.Context, c esclient.Client, name string) (interface{}, error) {
+			return c.GetIngestPipeline(ctx, name)
+		},
+		put: func(ctx context.Context, c esclient.Client, name string, object interface{}) error {
+			return c.PutIngestPipeline(ctx, name, object.(esclient.IngestPipeline))
+		},
+		delete: func(ctx context.Context, c esclient.Client, name string) error {
+			return c.DeleteIngestPipeline(ctx, name)
+		},
+	},
+}
+
+func identity(object interface{}) interface{} {
+	return object
+}
+
+// decodeStrict decodes the given JSON into out, rejecting unknown fields.
+func decodeStrict(body []byte, out interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(out)
+}
+
+// normalizeIndexSettings returns the given index settings as a flat map of dotted keys prefixed with `index.`, the
+// form Elasticsearch settings are compared in whether they are specified nested or not.
+func normalizeIndexSettings(settings map[string]interface{}) map[string]interface{} {
+	if len(settings) == 0 {
+		return nil
+	}
+	flat := map[string]interface{}{}
+	flatten("", settings, flat)
+	normalized := make(map[string]interface{}, len(flat))
+	for k, v := range flat {
+		if !strings.HasPrefix(k, indexSettingsPrefix) {
+			k = indexSettingsPrefix + k
+		}
+		normalized[k] = v
+	}
+	return normalized
+}
+
+func flatten(prefix string, in map[string]interface{}, out map[string]interface{}) {
+	for k, v := range in {
+		if nested, ok := v.(map[string]interface{}); ok {
+			flatten(prefix+k+".", nested, out)
+			continue
+		}
+		out[prefix+k] = v
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package indexmanagement
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("index-management")
+
+// Reconcile creates or updates in Elasticsearch the objects declared in the index management specification of the
+// given cluster of the given version, and deletes the ones removed from the specification since the last
+// reconciliation, as recorded in the status. It returns the resulting status of the objects.
+// Objects are reconciled independently: a failure is reported in the status of the object, and the aggregated errors
+// are returned.
+func Reconcile(
+	ctx context.Context,
+	c esclient.Client,
+	es v1beta1.Elasticsearch,
+	esVersion version.Version,
+	now time.Time,
+) ([]v1beta1.IndexManagementObjectStatus, error) {
+	spec := v1beta1.IndexManagementSpec{}
+	if es.Spec.IndexManagement != nil {
+		spec = *es.Spec.IndexManagement
+	}
+	previous := make(map[statusKey]v1beta1.IndexManagementObjectStatus, len(es.Status.IndexManagement))
+	for _, s := range es.Status.IndexManagement {
+		previous[statusKey{kind: s.Kind, name: s.Name}] = s
+	}
+
+	var statuses []v1beta1.IndexManagementObjectStatus
+	var errs []error
+	declared := map[statusKey]bool{}
+	for _, k := range kinds {
+		for _, object := range k.objects(spec) {
+			key := statusKey{kind: k.kind, name: object.Name}
+			declared[key] = true
+			status, err := reconcileObject(ctx, c, k, object, esVersion, previous[key], now)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %s", k.kind, object.Name, err))
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	for i := len(kinds) - 1; i >= 0; i-- {
+		k := kinds[i]
+		for _, s := range es.Status.IndexManagement {
+			if s.Kind != k.kind || declared[statusKey{kind: s.Kind, name: s.Name}] {
+				continue
+			}
+			log.Info("Deleting object removed from the index management specification",
+				"namespace", es.Namespace, "es_name", es.Name, "kind", s.Kind, "name", s.Name)
+			if err := k.delete(ctx, c, s.Name); err != nil && !esclient.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("%s %s: %s", k.kind, s.Name, err))
+				// keep the object in the status to retry the deletion
+				s.Ready = false
+				s.Message = fmt.Sprintf("deletion failed: %s", err)
+				statuses = append(statuses, s)
+			}
+		}
+	}
+	return statuses, utilerrors.NewAggregate(errs)
+}
+
+type statusKey struct {
+	kind v1beta1.IndexManagementKind
+	name string
+}
+
+// reconcileObject creates or updates the given object in Elasticsearch if it does not match its specification, and
+// returns its status.
+func reconcileObject(
+	ctx context.Context,
+	c esclient.Client,
+	k kind,
+	object v1beta1.IndexManagementObject,
+	esVersion version.Version,
+	previous v1beta1.IndexManagementObjectStatus,
+	now time.Time,
+) (v1beta1.IndexManagementObjectStatus, error) {
+	status := v1beta1.IndexManagementObjectStatus{
+		Kind:            k.kind,
+		Name:            object.Name,
+		LastUpdateTime:  previous.LastUpdateTime,
+		AppliedBodyHash: previous.AppliedBodyHash,
+	}
+	fail := func(err error) (v1beta1.IndexManagementObjectStatus, error) {
+		status.Message = err.Error()
+		return status, err
+	}
+
+	if !esVersion.IsSameOrAfter(k.minVersion) {
+		return fail(fmt.Errorf("requires Elasticsearch %s or above, got %s", k.minVersion, esVersion))
+	}
+	var body []byte
+	if object.Body != nil {
+		var err error
+		if body, err = json.Marshal(object.Body.Data); err != nil {
+			return fail(err)
+		}
+	}
+	expected, err := k.decode(body)
+	if err != nil {
+		return fail(fmt.Errorf("invalid body: %s", err))
+	}
+
+	bodyHash := fmt.Sprintf("%x", sha256.Sum224(body))
+	upToDate := false
+	// the drift detection ignores the fields not set in the body: changes of the body are applied regardless
+	if bodyHash == previous.AppliedBodyHash {
+		actual, err := k.get(ctx, c, object.Name)
+		if err != nil && !esclient.IsNotFound(err) {
+			return fail(err)
+		}
+		if err == nil {
+			if upToDate, err = matches(k.normalize(actual), k.normalize(expected)); err != nil {
+				return fail(err)
+			}
+		}
+	}
+	if !upToDate {
+		if err := k.put(ctx, c, object.Name, expected); err != nil {
+			return fail(err)
+		}
+		lastUpdate := metav1.NewTime(now)
+		status.LastUpdateTime = &lastUpdate
+		status.AppliedBodyHash = bodyHash
+	}
+	status.Ready = true
+	return status, nil
+}
+
+// matches returns true if the given actual Elasticsearch object matches the expected one. Fields not set in the
+// expected object are ignored, since Elasticsearch may set defaults.
+func matches(actual, expected interface{}) (bool, error) {
+	actualValue, err := toUnstructured(actual)
+	if err != nil {
+		return false, err
+	}
+	expectedValue, err := toUnstructured(expected)
+	if err != nil {
+		return false, err
+	}
+	return contains(actualValue, expectedValue), nil
+}
+
+func toUnstructured(object interface{}) (interface{}, error) {
+	bytes, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	return value, json.Unmarshal(bytes, &value)
+}
+
+// contains returns true if all the values set in expected are set to the same values in actual.
+// Scalars are compared through their string representation, as Elasticsearch returns some numbers and booleans as
+// strings.
+func contains(actual, expected interface{}) bool {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range e {
+			if av, exists := a[k]; !exists || !contains(av, v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for i := range e {
+			if !contains(a[i], e[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return fmt.Sprint(actual) == fmt.Sprint(expected)
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package indexmanagement
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeES stores the objects of the index management APIs by path, and records the write requests.
+type fakeES struct {
+	objects map[string]string
+	writes  []string
+}
+
+func (f *fakeES) roundTrip(req *http.Request) *http.Response {
+	path := req.URL.Path
+	name := path[strings.LastIndex(path, "/")+1:]
+	switch req.Method {
+	case http.MethodPut:
+		body, _ := ioutil.ReadAll(req.Body)
+		f.objects[path] = string(body)
+		f.writes = append(f.writes, "PUT "+path)
+	case http.MethodDelete:
+		f.writes = append(f.writes, "DELETE "+path)
+		if _, exists := f.objects[path]; !exists {
+			return esclient.NewMockResponse(404, req, "{}")
+		}
+		delete(f.objects, path)
+	case http.MethodGet:
+		object, exists := f.objects[path]
+		if !exists {
+			return esclient.NewMockResponse(404, req, "{}")
+		}
+		switch {
+		case strings.HasPrefix(path, "/_ilm/"):
+			return esclient.NewMockResponse(200, req, fmt.Sprintf(`{%q:{"version":1,%s}`, name, object[1:]))
+		case strings.HasPrefix(path, "/_component_template/"):
+			return esclient.NewMockResponse(200, req,
+				fmt.Sprintf(`{"component_templates":[{"name":%q,"component_template":%s}]}`, name, object))
+		case strings.HasPrefix(path, "/_index_template/"):
+			return esclient.NewMockResponse(200, req,
+				fmt.Sprintf(`{"index_templates":[{"name":%q,"index_template":%s}]}`, name, object))
+		default:
+			return esclient.NewMockResponse(200, req, fmt.Sprintf(`{%q:%s}`, name, object))
+		}
+	}
+	return esclient.NewMockResponse(200, req, `{"acknowledged":true}`)
+}
+
+// bodyHash returns the hash of the body of the object of the given kind and name in the given specification.
+func bodyHash(t *testing.T, spec v1beta1.IndexManagementSpec, objectKind v1beta1.IndexManagementKind, name string) string {
+	for _, k := range kinds {
+		if k.kind != objectKind {
+			continue
+		}
+		for _, o := range k.objects(spec) {
+			if o.Name == name {
+				body, err := json.Marshal(o.Body.Data)
+				require.NoError(t, err)
+				return fmt.Sprintf("%x", sha256.Sum224(body))
+			}
+		}
+	}
+	return ""
+}
+
+func object(name string, body map[string]interface{}) v1beta1.IndexManagementObject {
+	config := commonv1beta1.NewConfig(body)
+	return v1beta1.IndexManagementObject{Name: name, Body: &config}
+}
+
+func TestReconcile(t *testing.T) {
+	now := time.Date(2020, 8, 18, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	fake := &fakeES{objects: map[string]string{}}
+	c := esclient.NewMockClient(version.MustParse("7.9.0"), fake.roundTrip)
+	es := v1beta1.Elasticsearch{
+		Spec: v1beta1.ElasticsearchSpec{
+			IndexManagement: &v1beta1.IndexManagementSpec{
+				ILMPolicies: []v1beta1.IndexManagementObject{
+					object("logs", map[string]interface{}{"policy": map[string]interface{}{
+						"phases": map[string]interface{}{"hot": map[string]interface{}{"actions": map[string]interface{}{}}},
+					}}),
+				},
+				ComponentTemplates: []v1beta1.IndexManagementObject{
+					object("logs-settings", map[string]interface{}{"template": map[string]interface{}{
+						"settings": map[string]interface{}{"number_of_shards": 1, "index.lifecycle.name": "logs"},
+					}}),
+				},
+				IndexTemplates: []v1beta1.IndexManagementObject{
+					object("logs", map[string]interface{}{
+						"index_patterns": []interface{}{"logs-*"},
+						"composed_of":    []interface{}{"logs-settings"},
+						"priority":       100,
+					}),
+				},
+				IngestPipelines: []v1beta1.IndexManagementObject{
+					object("geoip", map[string]interface{}{
+						"processors": []interface{}{map[string]interface{}{"geoip": map[string]interface{}{"field": "ip"}}},
+					}),
+				},
+			},
+		},
+	}
+	reconcile := func(now time.Time) []v1beta1.IndexManagementObjectStatus {
+		fake.writes = nil
+		statuses, err := Reconcile(context.Background(), c, es, version.MustParse("7.9.0"), now)
+		require.NoError(t, err)
+		es.Status.IndexManagement = statuses
+		return statuses
+	}
+	ready := func(kind v1beta1.IndexManagementKind, name string, updated time.Time) v1beta1.IndexManagementObjectStatus {
+		return v1beta1.IndexManagementObjectStatus{
+			Kind: kind, Name: name, Ready: true, LastUpdateTime: &metav1.Time{Time: updated},
+			AppliedBodyHash: bodyHash(t, *es.Spec.IndexManagement, kind, name),
+		}
+	}
+
+	// all the objects are created, in order
+	statuses := reconcile(now)
+	require.Equal(t, []string{
+		"PUT /_ilm/policy/logs",
+		"PUT /_component_template/logs-settings",
+		"PUT /_index_template/logs",
+		"PUT /_ingest/pipeline/geoip",
+	}, fake.writes)
+	require.Equal(t, []v1beta1.IndexManagementObjectStatus{
+		ready(v1beta1.ILMPolicyKind, "logs", now),
+		ready(v1beta1.ComponentTemplateKind, "logs-settings", now),
+		ready(v1beta1.IndexTemplateKind, "logs", now),
+		ready(v1beta1.IngestPipelineKind, "geoip", now),
+	}, statuses)
+
+	// nothing to do if the objects match, even with defaults and normalized settings
+	fake.objects["/_ilm/policy/logs"] = `{"policy":{"phases":{"hot":{"min_age":"0ms","actions":{}}}}}`
+	fake.objects["/_component_template/logs-settings"] =
+		`{"template":{"settings":{"index":{"number_of_shards":"1","lifecycle":{"name":"logs"}}}}}`
+	require.Equal(t, statuses, reconcile(later))
+	require.Empty(t, fake.writes)
+
+	// drifts are reverted
+	fake.objects["/_ingest/pipeline/geoip"] = `{"processors":[]}`
+	statuses = reconcile(later)
+	require.Equal(t, []string{"PUT /_ingest/pipeline/geoip"}, fake.writes)
+	require.Equal(t, ready(v1beta1.IngestPipelineKind, "geoip", later), statuses[3])
+	require.Equal(t, ready(v1beta1.ILMPolicyKind, "logs", now), statuses[0])
+
+	// fields removed from the body are removed from the object, although the object still contains the body
+	es.Spec.IndexManagement.ILMPolicies[0] = object("logs", map[string]interface{}{"policy": map[string]interface{}{
+		"phases": map[string]interface{}{},
+		"_meta":  map[string]interface{}{"managed_by": "eck"},
+	}})
+	statuses = reconcile(later)
+	require.Equal(t, []string{"PUT /_ilm/policy/logs"}, fake.writes)
+	require.JSONEq(t, `{"policy":{"phases":{},"_meta":{"managed_by":"eck"}}}`, fake.objects["/_ilm/policy/logs"])
+	require.Equal(t, ready(v1beta1.ILMPolicyKind, "logs", later), statuses[0])
+	require.Equal(t, statuses, reconcile(later))
+	require.Empty(t, fake.writes)
+
+	// removed objects are deleted, index templates before component templates
+	es.Spec.IndexManagement.ComponentTemplates = nil
+	es.Spec.IndexManagement.IndexTemplates = nil
+	statuses = reconcile(later)
+	require.Equal(t, []string{"DELETE /_index_template/logs", "DELETE /_component_template/logs-settings"}, fake.writes)
+	require.Len(t, statuses, 2)
+	require.NotContains(t, fake.objects, "/_index_template/logs")
+
+	// invalid bodies are reported in the status
+	es.Spec.IndexManagement.IngestPipelines[0] = object("geoip", map[string]interface{}{"unknown": true})
+	statuses, err := Reconcile(context.Background(), c, es, version.MustParse("7.9.0"), later)
+	require.Error(t, err)
+	require.False(t, statuses[1].Ready)
+	require.Contains(t, statuses[1].Message, "invalid body")
+	require.Equal(t, &metav1.Time{Time: later}, statuses[1].LastUpdateTime)
+}
+
+func TestReconcile_UnsupportedVersion(t *testing.T) {
+	fake := &fakeES{objects: map[string]string{}}
+	c := esclient.NewMockClient(version.MustParse("7.7.0"), fake.roundTrip)
+	es := v1beta1.Elasticsearch{Spec: v1beta1.ElasticsearchSpec{IndexManagement: &v1beta1.IndexManagementSpec{
+		IndexTemplates: []v1beta1.IndexManagementObject{object("logs", map[string]interface{}{})},
+	}}}
+	statuses, err := Reconcile(context.Background(), c, es, version.MustParse("7.7.0"), time.Now())
+	require.Error(t, err)
+	require.Equal(t, []v1beta1.IndexManagementObjectStatus{{
+		Kind:    v1beta1.IndexTemplateKind,
+		Name:    "logs",
+		Message: "requires Elasticsearch 7.8.0 or above, got 7.7.0",
+	}}, statuses)
+	require.Empty(t, fake.writes)
+}
+
+func TestNormalizeIndexSettings(t *testing.T) {
+	require.Nil(t, normalizeIndexSettings(nil))
+	require.Equal(t, map[string]interface{}{
+		"index.number_of_shards": 1,
+		"index.lifecycle.name":   "logs",
+		"index.refresh_interval": "5s",
+	}, normalizeIndexSettings(map[string]interface{}{
+		"number_of_shards":     1,
+		"index.lifecycle.name": "logs",
+		"index":                map[string]interface{}{"refresh_interval": "5s"},
+	}))
+}
@@ -5,6 +5,7 @@
 package initcontainer
 
 import (
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/keystore"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/volume"
 	corev1 "k8s.io/api/core/v1"
@@ -19,6 +20,7 @@ func NewInitContainers(
 	transportCertificatesVolume volume.SecretVolume,
 	clusterName string,
 	keystoreResources *keystore.Resources,
+	topologyAwareness *v1beta1.TopologyAwareness,
 ) ([]corev1.Container, error) {
 	var containers []corev1.Container
 	prepareFsContainer, err := NewPrepareFSInitContainer(elasticsearchImage, transportCertificatesVolume, clusterName)
@@ -31,5 +33,9 @@ func NewInitContainers(
 		containers = append(containers, keystoreResources.InitContainer)
 	}
 
+	if topologyAwareness != nil {
+		containers = append(containers, NewTopologyInitContainer(elasticsearchImage, clusterName, *topologyAwareness))
+	}
+
 	return containers, nil
 }
@@ -7,6 +7,7 @@ package initcontainer
 import (
 	"testing"
 
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/keystore"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/volume"
 	"github.com/stretchr/testify/assert"
@@ -17,6 +18,7 @@ func TestNewInitContainers(t *testing.T) {
 		elasticsearchImage string
 		operatorImage      string
 		keystoreResources  *keystore.Resources
+		topologyAwareness  *v1beta1.TopologyAwareness
 	}
 	tests := []struct {
 		name                       string
@@ -32,6 +34,16 @@ func TestNewInitContainers(t *testing.T) {
 			},
 			expectedNumberOfContainers: 2,
 		},
+		{
+			name: "with topology awareness",
+			args: args{
+				elasticsearchImage: "es-image",
+				operatorImage:      "op-image",
+				keystoreResources:  &keystore.Resources{},
+				topologyAwareness:  &v1beta1.TopologyAwareness{},
+			},
+			expectedNumberOfContainers: 3,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -40,6 +52,7 @@ func TestNewInitContainers(t *testing.T) {
 				volume.SecretVolume{},
 				"clustername",
 				tt.args.keystoreResources,
+				tt.args.topologyAwareness,
 			)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedNumberOfContainers, len(containers))
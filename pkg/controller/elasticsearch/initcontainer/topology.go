@@ -0,0 +1,122 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package initcontainer
+
+import (
+	"path"
+	"strings"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/volume"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/settings"
+	esvolume "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/volume"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// TopologyContainerName is the name of the container that sets the zone node attribute
+	TopologyContainerName = "elastic-internal-init-topology"
+	// TopologyScriptConfigKey is the key of the topology script in the scripts ConfigMap
+	TopologyScriptConfigKey = "topology.sh"
+
+	topologyTokenVolumeName      = "elastic-internal-topology-token"
+	topologyTokenVolumeMountPath = "/mnt/elastic-internal/topology-token"
+
+	envNodeName      = "NODE_NAME"
+	envTopologyKeys  = "TOPOLOGY_KEYS"
+	envTokenPath     = "TOKEN_PATH"
+	envConfigSource  = "CONFIG_SOURCE"
+	envConfigTarget  = "CONFIG_TARGET"
+	envZoneAttribute = "ZONE_ATTRIBUTE"
+)
+
+// TopologyScript reads the zone of the Kubernetes node the pod is scheduled on from its labels, through the Kubernetes
+// API, and appends it as a node attribute to a copy of the Elasticsearch configuration file.
+const TopologyScript = `#!/usr/bin/env bash
+
+	set -eu
+
+	# wait for the service account token to be populated by Kubernetes
+	echo "waiting for the service account token (${TOKEN_PATH})"
+	while [ ! -s ${TOKEN_PATH}/` + corev1.ServiceAccountTokenKey + ` ]
+	do
+	  sleep 0.2
+	done
+
+	node=$(curl --silent --show-error --fail \
+		--cacert ${TOKEN_PATH}/` + corev1.ServiceAccountRootCAKey + ` \
+		-H "Authorization: Bearer $(cat ${TOKEN_PATH}/` + corev1.ServiceAccountTokenKey + `)" \
+		"https://${KUBERNETES_SERVICE_HOST}:${KUBERNETES_SERVICE_PORT}/api/v1/nodes/${NODE_NAME}")
+
+	# label keys and values cannot contain double quotes, only dots need escaping
+	zone=""
+	for key in ${TOPOLOGY_KEYS}; do
+		zone=$(echo "${node}" | grep -o "\"${key//./\\.}\":[[:space:]]*\"[^\"]*\"" | head -n 1 | cut -d '"' -f 4)
+		if [[ -n "${zone}" ]]; then
+			break
+		fi
+	done
+	if [[ -z "${zone}" ]]; then
+		>&2 echo "none of the labels [${TOPOLOGY_KEYS}] is set on Kubernetes node ${NODE_NAME}"
+		exit 1
+	fi
+
+	# replace the link to the configuration file with a copy including the zone attribute
+	echo "Setting ${ZONE_ATTRIBUTE} to ${zone}"
+	rm -f ${CONFIG_TARGET}
+	cp ${CONFIG_SOURCE} ${CONFIG_TARGET}
+	printf '\n%s: "%s"\n' "${ZONE_ATTRIBUTE}" "${zone}" >> ${CONFIG_TARGET}
+`
+
+// TopologyTokenVolume returns the volume holding the token of the ServiceAccount allowed to read the Kubernetes nodes.
+// It is only mounted in the topology init container.
+func TopologyTokenVolume(esName string) volume.SecretVolume {
+	return volume.NewSecretVolumeWithMountPath(
+		name.TopologyTokenSecret(esName),
+		topologyTokenVolumeName,
+		topologyTokenVolumeMountPath,
+	)
+}
+
+// NewTopologyInitContainer creates an init container setting the zone of the Kubernetes node as a node attribute in the
+// Elasticsearch configuration. It must run after the prepare-fs init container, which links the configuration file.
+func NewTopologyInitContainer(
+	imageName string,
+	clusterName string,
+	topologyAwareness v1beta1.TopologyAwareness,
+) corev1.Container {
+	scriptsVolume := volume.NewConfigMapVolumeWithMode(
+		name.ScriptsConfigMap(clusterName),
+		esvolume.ScriptsVolumeName,
+		esvolume.ScriptsVolumeMountPath,
+		0755)
+
+	privileged := false
+	return corev1.Container{
+		Image:           imageName,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Name:            TopologyContainerName,
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: &privileged,
+		},
+		Env: []corev1.EnvVar{
+			{Name: envNodeName, ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "spec.nodeName"},
+			}},
+			{Name: envTopologyKeys, Value: strings.Join(topologyAwareness.TopologyKeys(), " ")},
+			{Name: envTokenPath, Value: topologyTokenVolumeMountPath},
+			{Name: envConfigSource, Value: path.Join(settings.ConfigVolumeMountPath, settings.ConfigFileName)},
+			{Name: envConfigTarget, Value: path.Join(EsConfigSharedVolume.InitContainerMountPath, settings.ConfigFileName)},
+			{Name: envZoneAttribute, Value: settings.NodeAttrZone},
+		},
+		Command: []string{"bash", "-c", path.Join(esvolume.ScriptsVolumeMountPath, TopologyScriptConfigKey)},
+		VolumeMounts: []corev1.VolumeMount{
+			EsConfigSharedVolume.InitContainerVolumeMount(),
+			TopologyTokenVolume(clusterName).VolumeMount(),
+			scriptsVolume.VolumeMount(),
+		},
+	}
+}
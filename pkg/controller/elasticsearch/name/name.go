@@ -12,8 +12,10 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
 	common_name "github.com/cloudptio/logstash-operator/pkg/controller/common/name"
 	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/cloudptio/logstash-operator/pkg/utils/stringsutil"
 	"github.com/pkg/errors"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
 )
 
@@ -21,6 +23,7 @@ const (
 	configSecretSuffix                = "config"
 	secureSettingsSecretSuffix        = "secure-settings"
 	httpServiceSuffix                 = "http"
+	transportServiceSuffix            = "transport"
 	elasticUserSecretSuffix           = "elastic-user"
 	xpackFileRealmSecretSuffix        = "xpack-file-realm"
 	internalUsersSecretSuffix         = "internal-users"
@@ -29,6 +32,8 @@ const (
 	defaultPodDisruptionBudget        = "default"
 	scriptsConfigMapSuffix            = "scripts"
 	transportCertificatesSecretSuffix = "transport-certificates"
+	topologySuffix                    = "topology"
+	topologyTokenSecretSuffix         = "topology-token"
 
 	controllerRevisionHashLen = 10
 )
@@ -41,6 +46,7 @@ var (
 		configSecretSuffix,
 		secureSettingsSecretSuffix,
 		httpServiceSuffix,
+		transportServiceSuffix,
 		elasticUserSecretSuffix,
 		xpackFileRealmSecretSuffix,
 		internalUsersSecretSuffix,
@@ -49,6 +55,8 @@ var (
 		defaultPodDisruptionBudget,
 		scriptsConfigMapSuffix,
 		transportCertificatesSecretSuffix,
+		topologySuffix,
+		topologyTokenSecretSuffix,
 	}
 )
 
@@ -111,6 +119,10 @@ func HTTPService(esName string) string {
 	return ESNamer.Suffix(esName, httpServiceSuffix)
 }
 
+func TransportService(esName string) string {
+	return ESNamer.Suffix(esName, transportServiceSuffix)
+}
+
 func ElasticUserSecret(esName string) string {
 	return ESNamer.Suffix(esName, elasticUserSecretSuffix)
 }
@@ -132,6 +144,24 @@ func ScriptsConfigMap(esName string) string {
 	return ESNamer.Suffix(esName, scriptsConfigMapSuffix)
 }
 
+// TopologyServiceAccount returns the name of the ServiceAccount allowed to read the Kubernetes nodes of the given
+// cluster's pods.
+func TopologyServiceAccount(esName string) string {
+	return ESNamer.Suffix(esName, topologySuffix)
+}
+
+// TopologyTokenSecret returns the name of the Secret holding the token of the topology ServiceAccount.
+func TopologyTokenSecret(esName string) string {
+	return ESNamer.Suffix(esName, topologyTokenSecretSuffix)
+}
+
+// TopologyClusterRoleBinding returns the name of the cluster-scoped ClusterRoleBinding granting the topology
+// ServiceAccount of the given cluster access to the Kubernetes nodes. It is prefixed with the namespace to be unique,
+// using a dot since namespaces cannot contain any.
+func TopologyClusterRoleBinding(es types.NamespacedName) string {
+	return stringsutil.Concat(es.Namespace, ".", TopologyServiceAccount(es.Name))
+}
+
 func LicenseSecretName(esName string) string {
 	return ESNamer.Suffix(esName, licenseSecretSuffix)
 }
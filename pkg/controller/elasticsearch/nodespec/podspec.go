@@ -11,6 +11,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/hash"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/keystore"
@@ -32,7 +33,7 @@ func BuildPodTemplateSpec(
 	cfg settings.CanonicalConfig,
 	keystoreResources *keystore.Resources,
 ) (corev1.PodTemplateSpec, error) {
-	volumes, volumeMounts := buildVolumes(es.Name, nodeSet, keystoreResources)
+	volumes, volumeMounts := buildVolumes(es.Name, nodeSet, keystoreResources, es.Spec.TopologyAwareness)
 	labels, err := buildLabels(es, cfg, nodeSet, keystoreResources)
 	if err != nil {
 		return corev1.PodTemplateSpec{}, err
@@ -46,6 +47,7 @@ func BuildPodTemplateSpec(
 		transportCertificatesVolume(es.Name),
 		es.Name,
 		keystoreResources,
+		es.Spec.TopologyAwareness,
 	)
 	if err != nil {
 		return corev1.PodTemplateSpec{}, err
@@ -64,6 +66,9 @@ func BuildPodTemplateSpec(
 		WithInitContainers(initContainers...).
 		WithInitContainerDefaults()
 
+	// restart the nodes through a rolling upgrade whenever the restart trigger changes
+	annotation.PropagateRestartTrigger(&es, &builder.PodTemplate)
+
 	return builder.PodTemplate, nil
 }
 
@@ -10,6 +10,7 @@ import (
 
 	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/certificates"
@@ -92,7 +93,7 @@ func TestBuildPodTemplateSpec(t *testing.T) {
 	nodeSet := sampleES.Spec.NodeSets[0]
 	ver, err := version.Parse(sampleES.Spec.Version)
 	require.NoError(t, err)
-	cfg, err := settings.NewMergedESConfig(sampleES.Name, *ver, sampleES.Spec.HTTP, *nodeSet.Config, &certResources)
+	cfg, err := settings.NewMergedESConfig(sampleES.Name, *ver, sampleES.Spec.HTTP, *nodeSet.Config, &certResources, nil)
 	require.NoError(t, err)
 
 	actual, err := BuildPodTemplateSpec(sampleES, sampleES.Spec.NodeSets[0], cfg, nil)
@@ -103,7 +104,7 @@ func TestBuildPodTemplateSpec(t *testing.T) {
 	terminationGracePeriodSeconds := DefaultTerminationGracePeriodSeconds
 	varFalse := false
 
-	volumes, volumeMounts := buildVolumes(sampleES.Name, nodeSet, nil)
+	volumes, volumeMounts := buildVolumes(sampleES.Name, nodeSet, nil, nil)
 	// should be sorted
 	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
 	sort.Slice(volumeMounts, func(i, j int) bool { return volumeMounts[i].Name < volumeMounts[j].Name })
@@ -113,6 +114,7 @@ func TestBuildPodTemplateSpec(t *testing.T) {
 		transportCertificatesVolume(sampleES.Name),
 		sampleES.Name,
 		nil,
+		nil,
 	)
 	require.NoError(t, err)
 	// should be patched with volume and env
@@ -194,3 +196,20 @@ func TestBuildPodTemplateSpec(t *testing.T) {
 	deep.MaxDepth = 25
 	require.Nil(t, deep.Equal(expected, actual))
 }
+
+func TestBuildPodTemplateSpec_RestartTrigger(t *testing.T) {
+	es := *sampleES.DeepCopy()
+	es.Annotations[annotation.RestartTriggerAnnotation] = "2019-10-01"
+	nodeSet := es.Spec.NodeSets[0]
+	ver, err := version.Parse(es.Spec.Version)
+	require.NoError(t, err)
+	cfg, err := settings.NewMergedESConfig(es.Name, *ver, es.Spec.HTTP, *nodeSet.Config, &certificates.CertificateResources{}, nil)
+	require.NoError(t, err)
+
+	actual, err := BuildPodTemplateSpec(es, nodeSet, cfg, nil)
+	require.NoError(t, err)
+	require.Equal(t, "2019-10-01", actual.Annotations[annotation.RestartTriggerAnnotation])
+	require.Equal(t, "pod-template-annotation-value", actual.Annotations["pod-template-annotation-name"])
+	// the node set pod template is left untouched
+	require.NotContains(t, nodeSet.PodTemplate.Annotations, annotation.RestartTriggerAnnotation)
+}
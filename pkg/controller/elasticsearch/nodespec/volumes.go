@@ -18,7 +18,12 @@ import (
 	esvolume "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/volume"
 )
 
-func buildVolumes(esName string, nodeSpec v1beta1.NodeSet, keystoreResources *keystore.Resources) ([]corev1.Volume, []corev1.VolumeMount) {
+func buildVolumes(
+	esName string,
+	nodeSpec v1beta1.NodeSet,
+	keystoreResources *keystore.Resources,
+	topologyAwareness *v1beta1.TopologyAwareness,
+) ([]corev1.Volume, []corev1.VolumeMount) {
 
 	configVolume := settings.ConfigSecretVolume(name.StatefulSet(esName, nodeSpec.Name))
 	probeSecret := volume.NewSelectiveSecretVolumeWithMountPath(
@@ -75,6 +80,10 @@ func buildVolumes(esName string, nodeSpec v1beta1.NodeSet, keystoreResources *ke
 	if keystoreResources != nil {
 		volumes = append(volumes, keystoreResources.Volume)
 	}
+	if topologyAwareness != nil {
+		// only mounted in the topology init container
+		volumes = append(volumes, initcontainer.TopologyTokenVolume(esName).Volume())
+	}
 
 	volumeMounts := append(
 		initcontainer.PluginVolumes.EsContainerVolumeMounts(),
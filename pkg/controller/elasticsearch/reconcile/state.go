@@ -5,9 +5,12 @@
 package reconcile
 
 import (
+	"fmt"
 	"reflect"
 
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/conditions"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/validation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/observer"
@@ -51,6 +54,36 @@ func (s *State) updateWithPhase(
 	if observedState.ClusterHealth != nil && observedState.ClusterHealth.Status != "" {
 		s.status.Health = v1beta1.ElasticsearchHealth(observedState.ClusterHealth.Status)
 	}
+	s.updatePhaseConditions()
+	return s
+}
+
+// updatePhaseConditions sets the Ready and Progressing conditions from the phase and health in the status.
+func (s *State) updatePhaseConditions() {
+	generation := s.cluster.Generation
+	health := fmt.Sprintf("health is %s", s.status.Health)
+	ready := conditions.New(commonv1beta1.ReadyCondition, false, generation, conditions.UnavailableReason, health)
+	if s.status.Phase == v1beta1.ElasticsearchReadyPhase && s.status.Health == v1beta1.ElasticsearchGreenHealth {
+		ready = conditions.New(commonv1beta1.ReadyCondition, true, generation, conditions.AvailableReason, health)
+	}
+	progressing := conditions.New(commonv1beta1.ProgressingCondition, false, generation, conditions.RolledOutReason, "")
+	switch s.status.Phase {
+	case v1beta1.ElasticsearchApplyingChangesPhase, v1beta1.ElasticsearchMigratingDataPhase:
+		progressing = conditions.New(commonv1beta1.ProgressingCondition, true, generation, string(s.status.Phase), "")
+	}
+	s.SetConditions(ready, progressing)
+}
+
+// SetConditions sets the given conditions in the resource status.
+func (s *State) SetConditions(c ...commonv1beta1.Condition) {
+	for _, condition := range c {
+		s.status.Conditions = s.status.Conditions.Set(condition)
+	}
+}
+
+// UpdateReconciled sets the Reconciled condition in the resource status from the error of the reconciliation, if any.
+func (s *State) UpdateReconciled(err error) *State {
+	s.SetConditions(conditions.Reconciled(s.cluster.Generation, err))
 	return s
 }
 
@@ -80,6 +113,7 @@ func (s *State) UpdateElasticsearchApplyingChanges(pods []corev1.Pod) *State {
 	s.status.AvailableNodes = len(AvailableElasticsearchNodes(pods))
 	s.status.Phase = v1beta1.ElasticsearchApplyingChangesPhase
 	s.status.Health = v1beta1.ElasticsearchRedHealth
+	s.updatePhaseConditions()
 	return s
 }
 
@@ -96,6 +130,30 @@ func (s *State) UpdateElasticsearchMigrating(
 	return s.updateWithPhase(v1beta1.ElasticsearchMigratingDataPhase, resourcesState, observedState)
 }
 
+// UpdateSnapshots sets the status of the snapshot lifecycle management policy in the resource status.
+func (s *State) UpdateSnapshots(status *v1beta1.SnapshotsStatus) *State {
+	s.status.Snapshots = status
+	return s
+}
+
+// UpdateIndexManagement sets the status of the objects of the index management specification in the resource status.
+func (s *State) UpdateIndexManagement(statuses []v1beta1.IndexManagementObjectStatus) *State {
+	s.status.IndexManagement = statuses
+	return s
+}
+
+// UpdateRemoteClusters sets the connection status of the remote clusters in the resource status.
+func (s *State) UpdateRemoteClusters(statuses []v1beta1.RemoteClusterStatus) *State {
+	s.status.RemoteClusters = statuses
+	return s
+}
+
+// UpdateRestartTrigger records the restart trigger for which all nodes were restarted in the resource status.
+func (s *State) UpdateRestartTrigger(trigger string) *State {
+	s.status.RestartTrigger = trigger
+	return s
+}
+
 // Apply takes the current Elasticsearch status, compares it to the previous status, and updates the status accordingly.
 // It returns the events to emit and an updated version of the Elasticsearch cluster resource with
 // the current status applied to its status sub-resource.
@@ -114,6 +172,7 @@ func (s *State) Apply() ([]events.Event, *v1beta1.Elasticsearch) {
 
 func (s *State) UpdateElasticsearchInvalid(results []validation.Result) {
 	s.status.Phase = v1beta1.ElasticsearchResourceInvalid
+	s.SetConditions(conditions.ConfigValid(s.cluster.Generation, results))
 	for _, r := range results {
 		s.AddEvent(corev1.EventTypeWarning, events.EventReasonValidation, r.Reason)
 	}
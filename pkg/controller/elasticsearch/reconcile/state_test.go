@@ -12,10 +12,13 @@ import (
 	v1beta12 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/validation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/observer"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -213,7 +216,9 @@ func TestState_Apply(t *testing.T) {
 			}
 			var actual *v1beta1.ElasticsearchStatus
 			if cluster != nil {
-				actual = &cluster.Status
+				actual = cluster.Status.DeepCopy()
+				// conditions are covered by TestState_Conditions
+				actual.Conditions = nil
 			}
 			if !reflect.DeepEqual(actual, tt.wantStatus) {
 				t.Errorf("State.Apply() cluster = %v, wantStatus %v", cluster.Status, tt.wantStatus)
@@ -310,3 +315,65 @@ func TestState_UpdateElasticsearchMigrating(t *testing.T) {
 		})
 	}
 }
+
+func TestState_Conditions(t *testing.T) {
+	greenHealth := observer.State{ClusterHealth: &client.Health{Status: string(v1beta1.ElasticsearchGreenHealth)}}
+	tests := []struct {
+		name            string
+		effects         func(s *State)
+		wantReady       bool
+		wantProgressing bool
+		wantConfigValid corev1.ConditionStatus
+	}{
+		{
+			name: "ready and green",
+			effects: func(s *State) {
+				s.UpdateElasticsearchReady(ResourcesState{}, greenHealth)
+			},
+			wantReady: true,
+		},
+		{
+			name: "ready but not green",
+			effects: func(s *State) {
+				s.UpdateElasticsearchReady(ResourcesState{}, observer.State{})
+			},
+		},
+		{
+			name: "applying changes",
+			effects: func(s *State) {
+				s.UpdateElasticsearchApplyingChanges([]corev1.Pod{})
+			},
+			wantProgressing: true,
+		},
+		{
+			name: "migrating data",
+			effects: func(s *State) {
+				s.UpdateElasticsearchMigrating(ResourcesState{}, greenHealth)
+			},
+			wantProgressing: true,
+		},
+		{
+			name: "invalid",
+			effects: func(s *State) {
+				s.UpdateElasticsearchInvalid([]validation.Result{{Reason: "invalid"}})
+			},
+			wantConfigValid: corev1.ConditionFalse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewState(v1beta1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Generation: 2}})
+			tt.effects(s)
+			_, cluster := s.Apply()
+			require.NotNil(t, cluster)
+			conditions := cluster.Status.Conditions
+			if tt.wantConfigValid != "" {
+				require.Equal(t, tt.wantConfigValid, conditions.Get(v1beta12.ConfigValidCondition).Status)
+				return
+			}
+			require.Equal(t, tt.wantReady, conditions.IsTrue(v1beta12.ReadyCondition))
+			require.Equal(t, tt.wantProgressing, conditions.IsTrue(v1beta12.ProgressingCondition))
+			require.Equal(t, int64(2), conditions.Get(v1beta12.ReadyCondition).ObservedGeneration)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remotecluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/services"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("remotecluster")
+
+// Reconcile configures the remote clusters declared in the specification of the given cluster through the
+// `cluster.remote.*` settings, and returns their connection status. Remote clusters previously configured by the
+// operator and no longer declared, no longer existing or no longer allowed are removed.
+func Reconcile(
+	ctx context.Context,
+	c k8s.Client,
+	esClient esclient.Client,
+	es esv1beta1.Elasticsearch,
+) ([]esv1beta1.RemoteClusterStatus, error) {
+	// seeds by alias of the remote clusters to configure, nil for the ones to remove
+	expected := make(map[string][]string, len(es.Spec.RemoteClusters))
+	statuses := make([]esv1beta1.RemoteClusterStatus, 0, len(es.Spec.RemoteClusters))
+	for _, remote := range es.Spec.RemoteClusters {
+		status := esv1beta1.RemoteClusterStatus{Name: remote.Name}
+		remoteES, err := getRemoteCluster(c, es, remote)
+		switch {
+		case err != nil:
+			return nil, err
+		case remoteES == nil:
+			nsn := remote.NamespacedName(es.Namespace)
+			status.Message = fmt.Sprintf("Elasticsearch %s/%s not found", nsn.Namespace, nsn.Name)
+			expected[remote.Name] = nil
+		case !association.IsReferenceAllowed(*remoteES, &es, referenceKind):
+			status.Message = association.ReferenceDeniedError(*remoteES, &es, referenceKind).Error()
+			expected[remote.Name] = nil
+		default:
+			expected[remote.Name] = []string{services.TransportServiceHost(k8s.ExtractNamespacedName(remoteES))}
+		}
+		statuses = append(statuses, status)
+	}
+	for _, previous := range es.Status.RemoteClusters {
+		if _, declared := expected[previous.Name]; !declared {
+			expected[previous.Name] = nil
+		}
+	}
+	if len(expected) == 0 {
+		return nil, nil
+	}
+
+	info, err := esClient.GetRemoteClusterInfo(ctx)
+	if err != nil {
+		return statuses, err
+	}
+
+	changes := make(map[string][]string)
+	for alias, seeds := range expected {
+		current, exists := info[alias]
+		switch {
+		case seeds == nil && exists:
+			changes[alias] = nil
+		case seeds != nil && !reflect.DeepEqual(seeds, current.Seeds):
+			changes[alias] = seeds
+		}
+	}
+	if len(changes) > 0 {
+		log.Info("Updating remote clusters", "namespace", es.Namespace, "es_name", es.Name, "remote_clusters", changes)
+		if err := esClient.UpdateRemoteClusterSettings(ctx, esclient.NewRemoteClustersSettings(changes)); err != nil {
+			return statuses, err
+		}
+		if info, err = esClient.GetRemoteClusterInfo(ctx); err != nil {
+			return statuses, err
+		}
+	}
+
+	for i := range statuses {
+		if current, exists := info[statuses[i].Name]; exists && statuses[i].Message == "" {
+			statuses[i].Connected = current.Connected
+			statuses[i].NumNodesConnected = current.NumNodesConnected
+		}
+	}
+	return statuses, nil
+}
@@ -0,0 +1,93 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remotecluster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeES keeps the remote clusters configured in Elasticsearch, by alias.
+type fakeES struct {
+	remotes map[string]esclient.RemoteClusterInfo
+	updates int
+}
+
+func (f *fakeES) client(t *testing.T) esclient.Client {
+	return esclient.NewMockClient(version.MustParse("7.4.0"), func(req *http.Request) *http.Response {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/_remote/info":
+			body, err := json.Marshal(f.remotes)
+			require.NoError(t, err)
+			return esclient.NewMockResponse(200, req, string(body))
+		case req.Method == http.MethodPut && req.URL.Path == "/_cluster/settings":
+			var settings esclient.RemoteClustersSettings
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&settings))
+			for alias, remote := range settings.Persistent.Cluster.Remote {
+				if remote.Seeds == nil {
+					delete(f.remotes, alias)
+					continue
+				}
+				f.remotes[alias] = esclient.RemoteClusterInfo{Seeds: remote.Seeds, Connected: true, NumNodesConnected: 1}
+			}
+			f.updates++
+			return esclient.NewMockResponse(200, req, `{"acknowledged":true}`)
+		}
+		t.Fatalf("unexpected request %s %s", req.Method, req.URL.Path)
+		return nil
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	central := newES("central", "es",
+		remote("eu", "eu", "es"),
+		remote("us", "us", "es"),
+		remote("apac", "apac", "es"),
+	)
+	c := newClient(t,
+		central,
+		allowing(newES("eu", "es"), "central"),
+		newES("us", "es"),
+	)
+	fake := &fakeES{remotes: map[string]esclient.RemoteClusterInfo{
+		"us":    {Seeds: []string{"es-es-transport.us.svc:9300"}},
+		"other": {Seeds: []string{"other:9300"}},
+	}}
+
+	statuses, err := Reconcile(context.Background(), c, fake.client(t), *central)
+	require.NoError(t, err)
+	require.Equal(t, []esv1beta1.RemoteClusterStatus{
+		{Name: "eu", Connected: true, NumNodesConnected: 1},
+		{Name: "us", Message: "Elasticsearch central/es is not allowed to reference Elasticsearch us/es: add Elasticsearch/central to the association.k8s.elastic.co/allowed-references annotation of the Elasticsearch resource"},
+		{Name: "apac", Message: "Elasticsearch apac/es not found"},
+	}, statuses)
+	// the denied remote cluster is removed, the one not managed by the operator is kept
+	require.Equal(t, map[string]esclient.RemoteClusterInfo{
+		"eu":    {Seeds: []string{"es-es-transport.eu.svc:9300"}, Connected: true, NumNodesConnected: 1},
+		"other": {Seeds: []string{"other:9300"}},
+	}, fake.remotes)
+	require.Equal(t, 1, fake.updates)
+
+	// nothing to update
+	central.Status.RemoteClusters = statuses
+	_, err = Reconcile(context.Background(), c, fake.client(t), *central)
+	require.NoError(t, err)
+	require.Equal(t, 1, fake.updates)
+
+	// remote clusters removed from the specification are removed from Elasticsearch
+	central.Spec.RemoteClusters = nil
+	statuses, err = Reconcile(context.Background(), c, fake.client(t), *central)
+	require.NoError(t, err)
+	require.Empty(t, statuses)
+	require.Equal(t, map[string]esclient.RemoteClusterInfo{"other": {Seeds: []string{"other:9300"}}}, fake.remotes)
+	require.Equal(t, 2, fake.updates)
+}
@@ -0,0 +1,135 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remotecluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/finalizer"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/watches"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/certificates/transport"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// referenceKind is the kind of the objects referencing remote clusters, used to authorize references across namespaces.
+const referenceKind = "Elasticsearch"
+
+// TrustedCAs returns the transport certificate authorities of the clusters trusted by the given cluster, and watches
+// them for rotations.
+func TrustedCAs(c k8s.Client, w watches.DynamicWatches, es esv1beta1.Elasticsearch) ([]byte, error) {
+	trusted, err := TrustedClusters(c, es)
+	if err != nil {
+		return nil, err
+	}
+	if err := WatchTrustedClusters(w, k8s.ExtractNamespacedName(&es), trusted); err != nil {
+		return nil, err
+	}
+	return transport.RemoteCAs(c, trusted)
+}
+
+// TrustedClusters returns the clusters whose transport certificate authority must be trusted by the given cluster:
+// the remote clusters it declares, and the clusters declaring it as a remote cluster. Trust is mutual since nodes
+// of both clusters authenticate each other. References not allowed by the referenced cluster are ignored.
+func TrustedClusters(c k8s.Client, es esv1beta1.Elasticsearch) ([]types.NamespacedName, error) {
+	esNSN := k8s.ExtractNamespacedName(&es)
+	trusted := map[types.NamespacedName]struct{}{}
+
+	for _, remote := range es.Spec.RemoteClusters {
+		remoteES, err := getRemoteCluster(c, es, remote)
+		if err != nil {
+			return nil, err
+		}
+		if remoteES == nil || !association.IsReferenceAllowed(*remoteES, &es, referenceKind) {
+			continue
+		}
+		trusted[k8s.ExtractNamespacedName(remoteES)] = struct{}{}
+	}
+
+	var clusters esv1beta1.ElasticsearchList
+	if err := c.List(&clusters); err != nil {
+		return nil, err
+	}
+	for i := range clusters.Items {
+		other := clusters.Items[i]
+		if !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, remote := range other.Spec.RemoteClusters {
+			if remote.NamespacedName(other.Namespace) == esNSN && association.IsReferenceAllowed(es, &other, referenceKind) {
+				trusted[k8s.ExtractNamespacedName(&other)] = struct{}{}
+			}
+		}
+	}
+
+	// a cluster always trusts its own certificate authority
+	delete(trusted, esNSN)
+
+	result := make([]types.NamespacedName, 0, len(trusted))
+	for nsn := range trusted {
+		result = append(result, nsn)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+	return result, nil
+}
+
+// getRemoteCluster returns the Elasticsearch resource referenced by the given remote cluster, or nil if it does not
+// exist or is being deleted.
+func getRemoteCluster(c k8s.Client, es esv1beta1.Elasticsearch, remote esv1beta1.RemoteCluster) (*esv1beta1.Elasticsearch, error) {
+	var remoteES esv1beta1.Elasticsearch
+	if err := c.Get(remote.NamespacedName(es.Namespace), &remoteES); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !remoteES.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return &remoteES, nil
+}
+
+// trustWatchName returns the name of the watch on the transport certificate authorities trusted by the given cluster.
+func trustWatchName(es types.NamespacedName) string {
+	return fmt.Sprintf("%s-%s-remote-clusters-ca", es.Namespace, es.Name)
+}
+
+// WatchTrustedClusters registers a watch on the public transport certificates secret of the given trusted clusters,
+// so the given cluster trusts their new certificate authority when it is rotated. The watch is removed if there is
+// no trusted cluster.
+func WatchTrustedClusters(w watches.DynamicWatches, es types.NamespacedName, trusted []types.NamespacedName) error {
+	watchName := trustWatchName(es)
+	if len(trusted) == 0 {
+		w.Secrets.RemoveHandlerForKey(watchName)
+		return nil
+	}
+	secrets := make([]types.NamespacedName, 0, len(trusted))
+	for _, nsn := range trusted {
+		secrets = append(secrets, transport.PublicCertsSecretRef(nsn))
+	}
+	return w.Secrets.AddHandler(watches.NamedWatch{
+		Name:    watchName,
+		Watched: secrets,
+		Watcher: es,
+	})
+}
+
+// Finalizer removes the watch on the transport certificate authorities trusted by the given cluster.
+func Finalizer(es types.NamespacedName, w watches.DynamicWatches) finalizer.Finalizer {
+	return finalizer.Finalizer{
+		Name: "finalizer." + strings.ToLower(referenceKind) + ".k8s.elastic.co/remote-clusters-ca",
+		Execute: func() error {
+			w.Secrets.RemoveHandlerForKey(trustWatchName(es))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package remotecluster
+
+import (
+	"testing"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/scheme"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/watches"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/certificates/transport"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newES(namespace, name string, remotes ...esv1beta1.RemoteCluster) *esv1beta1.Elasticsearch {
+	return &esv1beta1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       esv1beta1.ElasticsearchSpec{RemoteClusters: remotes},
+	}
+}
+
+func remote(alias, namespace, name string) esv1beta1.RemoteCluster {
+	return esv1beta1.RemoteCluster{
+		Name:             alias,
+		ElasticsearchRef: commonv1beta1.ObjectSelector{Namespace: namespace, Name: name},
+	}
+}
+
+func allowing(es *esv1beta1.Elasticsearch, allowed string) *esv1beta1.Elasticsearch {
+	es.Annotations = map[string]string{association.AllowedReferencesAnnotation: allowed}
+	return es
+}
+
+func publicCASecret(es types.NamespacedName, ca string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: k8s.ToObjectMeta(transport.PublicCertsSecretRef(es)),
+		Data:       map[string][]byte{certificates.CAFileName: []byte(ca)},
+	}
+}
+
+func newClient(t *testing.T, objs ...runtime.Object) k8s.Client {
+	require.NoError(t, scheme.SetupScheme())
+	return k8s.WrapClient(fake.NewFakeClient(objs...))
+}
+
+func TestTrustedClusters(t *testing.T) {
+	central := newES("central", "es", remote("eu", "eu", "es"), remote("us", "us", "es"), remote("local", "", "local"))
+	tests := []struct {
+		name    string
+		es      *esv1beta1.Elasticsearch
+		objects []runtime.Object
+		want    []types.NamespacedName
+	}{
+		{
+			name:    "no remote clusters",
+			es:      newES("ns", "es"),
+			objects: []runtime.Object{newES("ns", "es")},
+			want:    []types.NamespacedName{},
+		},
+		{
+			name: "remote clusters declared by the cluster, missing and denied ones are ignored",
+			es:   central,
+			objects: []runtime.Object{
+				central,
+				allowing(newES("eu", "es"), "Elasticsearch/central"),
+				newES("us", "es"),
+				newES("central", "local"),
+			},
+			want: []types.NamespacedName{{Namespace: "central", Name: "local"}, {Namespace: "eu", Name: "es"}},
+		},
+		{
+			name: "clusters declaring the cluster as a remote cluster",
+			es:   allowing(newES("eu", "es"), "central"),
+			objects: []runtime.Object{
+				central,
+				allowing(newES("eu", "es"), "central"),
+				newES("eu", "other", remote("eu", "", "es")),
+				newES("us", "other", remote("eu", "eu", "es")),
+			},
+			want: []types.NamespacedName{{Namespace: "central", Name: "es"}, {Namespace: "eu", Name: "other"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TrustedClusters(newClient(t, tt.objects...), *tt.es)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTrustedCAs(t *testing.T) {
+	central := newES("central", "es", remote("eu", "", "eu"), remote("us", "", "us"))
+	c := newClient(t,
+		central,
+		newES("central", "eu"),
+		newES("central", "us"),
+		publicCASecret(types.NamespacedName{Namespace: "central", Name: "eu"}, "eu-ca\n"),
+	)
+	w := watches.NewDynamicWatches()
+	require.NoError(t, w.Secrets.InjectScheme(clientgoscheme.Scheme))
+
+	// the CA of the us cluster is not published yet
+	cas, err := TrustedCAs(c, w, *central)
+	require.NoError(t, err)
+	require.Equal(t, "eu-ca\n", string(cas))
+	require.Len(t, w.Secrets.Registrations(), 1)
+
+	require.NoError(t, c.Create(publicCASecret(types.NamespacedName{Namespace: "central", Name: "us"}, "us-ca\n")))
+	cas, err = TrustedCAs(c, w, *central)
+	require.NoError(t, err)
+	require.Equal(t, "eu-ca\nus-ca\n", string(cas))
+
+	// the watch is removed with the last remote cluster
+	central.Spec.RemoteClusters = nil
+	require.NoError(t, c.Update(central))
+	cas, err = TrustedCAs(c, w, *central)
+	require.NoError(t, err)
+	require.Empty(t, cas)
+	require.Empty(t, w.Secrets.Registrations())
+}
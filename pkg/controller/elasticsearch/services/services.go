@@ -10,6 +10,7 @@ import (
 	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
@@ -61,6 +62,46 @@ func NewExternalService(es v1beta1.Elasticsearch) *corev1.Service {
 	return defaults.SetServiceDefaults(&svc, labels, labels, ports)
 }
 
+// TransportServiceName returns the name for the transport service
+// associated to this cluster
+func TransportServiceName(esName string) string {
+	return name.TransportService(esName)
+}
+
+// TransportServiceHost returns the host and port used by remote clusters to reach the transport endpoint of the
+// given cluster.
+func TransportServiceHost(es types.NamespacedName) string {
+	return stringsutil.Concat(TransportServiceName(es.Name), ".", es.Namespace, globalServiceSuffix, ":", strconv.Itoa(network.TransportPort))
+}
+
+// NewTransportService returns the headless transport service associated to the given cluster.
+// It is used by remote clusters to discover the nodes of the cluster.
+func NewTransportService(es v1beta1.Elasticsearch) *corev1.Service {
+	nsn := k8s.ExtractNamespacedName(&es)
+
+	svc := corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: es.Namespace,
+			Name:      TransportServiceName(es.Name),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: corev1.ClusterIPNone,
+		},
+	}
+
+	labels := label.NewLabels(nsn)
+	ports := []corev1.ServicePort{
+		{
+			Name:     "tls-transport",
+			Protocol: corev1.ProtocolTCP,
+			Port:     network.TransportPort,
+		},
+	}
+
+	return defaults.SetServiceDefaults(&svc, labels, labels, ports)
+}
+
 // IsServiceReady checks if a service has one or more ready endpoints.
 func IsServiceReady(c k8s.Client, service corev1.Service) (bool, error) {
 	endpoints := corev1.Endpoints{}
@@ -7,6 +7,8 @@ package settings
 const (
 	ClusterName = "cluster.name"
 
+	ClusterRoutingAllocationAwarenessAttributes = "cluster.routing.allocation.awareness.attributes"
+
 	DiscoveryZenMinimumMasterNodes = "discovery.zen.minimum_master_nodes"
 	ClusterInitialMasterNodes      = "cluster.initial_master_nodes"
 	DiscoveryZenHostsProvider      = "discovery.zen.hosts_provider"
@@ -14,7 +16,8 @@ const (
 	NetworkHost        = "network.host"
 	NetworkPublishHost = "network.publish_host"
 
-	NodeName = "node.name"
+	NodeName     = "node.name"
+	NodeAttrZone = "node.attr." + ZoneAttribute
 
 	PathData = "path.data"
 	PathLogs = "path.logs"
@@ -35,6 +38,9 @@ const (
 	XPackSecurityTransportSslVerificationMode       = "xpack.security.transport.ssl.verification_mode"
 )
 
+// ZoneAttribute is the node attribute holding the zone of the Kubernetes node, when topology awareness is enabled.
+const ZoneAttribute = "zone"
+
 var Blacklist = []string{
 	ClusterName,
 	DiscoveryZenMinimumMasterNodes,
@@ -8,6 +8,7 @@ import (
 	"path"
 
 	"github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates"
 	common "github.com/cloudptio/logstash-operator/pkg/controller/common/settings"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
@@ -23,6 +24,7 @@ func NewMergedESConfig(
 	httpConfig v1beta1.HTTPConfig,
 	userConfig v1beta1.Config,
 	certResources *escerts.CertificateResources,
+	topologyAwareness *esv1beta1.TopologyAwareness,
 ) (CanonicalConfig, error) {
 	config, err := common.NewCanonicalConfigFrom(userConfig.Data)
 	if err != nil {
@@ -35,6 +37,11 @@ func NewMergedESConfig(
 	if err != nil {
 		return CanonicalConfig{}, err
 	}
+	if topologyAwareness != nil {
+		if err := config.MergeWith(topologyConfig().CanonicalConfig); err != nil {
+			return CanonicalConfig{}, err
+		}
+	}
 	return CanonicalConfig{config}, nil
 }
 
@@ -57,6 +64,14 @@ func baseConfig(clusterName string) *CanonicalConfig {
 	return &CanonicalConfig{common.MustCanonicalConfig(cfg)}
 }
 
+// topologyConfig returns the configuration enabling shard allocation awareness on the zone node attribute.
+// The attribute itself is set by the topology init container, once the pod is scheduled on a Kubernetes node.
+func topologyConfig() *CanonicalConfig {
+	return &CanonicalConfig{common.MustCanonicalConfig(map[string]interface{}{
+		ClusterRoutingAllocationAwarenessAttributes: ZoneAttribute,
+	})}
+}
+
 // xpackConfig returns the configuration bit related to XPack settings
 func xpackConfig(ver version.Version, httpCfg v1beta1.HTTPConfig, certResources *escerts.CertificateResources) *CanonicalConfig {
 	// enable x-pack security, including TLS
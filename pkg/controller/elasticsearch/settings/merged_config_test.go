@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/certificates"
 	"github.com/stretchr/testify/require"
@@ -20,10 +21,11 @@ func TestNewMergedESConfig(t *testing.T) {
 	xPackSecurityAuthcRealmsNative1Order := "xpack.security.authc.realms.native1.order"
 
 	tests := []struct {
-		name    string
-		version string
-		cfgData map[string]interface{}
-		assert  func(cfg CanonicalConfig)
+		name     string
+		version  string
+		cfgData  map[string]interface{}
+		topology *esv1beta1.TopologyAwareness
+		assert   func(cfg CanonicalConfig)
 	}{
 		{
 			name:    "in 6.x, empty config should have the default file realm settings configured",
@@ -96,6 +98,23 @@ func TestNewMergedESConfig(t *testing.T) {
 				require.Equal(t, 1, len(cfg.HasKeys([]string{xPackSecurityAuthcRealmsNativeNative1Order})))
 			},
 		},
+		{
+			name:    "without topology awareness, no allocation awareness should be configured",
+			version: "7.4.0",
+			cfgData: map[string]interface{}{},
+			assert: func(cfg CanonicalConfig) {
+				require.Equal(t, 0, len(cfg.HasKeys([]string{ClusterRoutingAllocationAwarenessAttributes})))
+			},
+		},
+		{
+			name:     "with topology awareness, allocation awareness should be configured on the zone attribute",
+			version:  "7.4.0",
+			cfgData:  map[string]interface{}{},
+			topology: &esv1beta1.TopologyAwareness{},
+			assert: func(cfg CanonicalConfig) {
+				require.Equal(t, 1, len(cfg.HasKeys([]string{ClusterRoutingAllocationAwarenessAttributes})))
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -107,6 +126,7 @@ func TestNewMergedESConfig(t *testing.T) {
 				v1beta1.HTTPConfig{},
 				v1beta1.Config{Data: tt.cfgData},
 				&certificates.CertificateResources{},
+				tt.topology,
 			)
 			require.NoError(t, err)
 			tt.assert(cfg)
@@ -0,0 +1,207 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SLMMinVersion is the first Elasticsearch version supporting snapshot lifecycle management.
+var SLMMinVersion = version.MustParse("7.4.0")
+
+// SLMRetentionMinVersion is the first Elasticsearch version supporting the retention of snapshot lifecycle management
+// policies.
+var SLMRetentionMinVersion = version.MustParse("7.5.0")
+
+// PolicyName returns the name of the snapshot lifecycle management policy of the given Elasticsearch cluster.
+func PolicyName(es v1beta1.Elasticsearch) string {
+	return es.Name
+}
+
+// Repository returns the snapshot repository of the given spec.
+func Repository(spec v1beta1.SnapshotsSpec) esclient.SnapshotRepository {
+	settings := map[string]interface{}{}
+	if spec.Repository.Settings != nil {
+		settings = spec.Repository.Settings.DeepCopy().Data
+	}
+	return esclient.SnapshotRepository{
+		Type:     spec.Repository.Type,
+		Settings: settings,
+	}
+}
+
+// Policy returns the snapshot lifecycle management policy of the given Elasticsearch cluster, which must have a
+// snapshot policy.
+func Policy(es v1beta1.Elasticsearch) esclient.SLMPolicy {
+	spec := es.Spec.Snapshots
+	snapshotName := spec.Policy.SnapshotName
+	if snapshotName == "" {
+		snapshotName = fmt.Sprintf("<%s-snap-{now/d}>", es.Name)
+	}
+	policy := esclient.SLMPolicy{
+		Schedule:   spec.Policy.Schedule,
+		Name:       snapshotName,
+		Repository: spec.Repository.RepositoryName(),
+		Config:     esclient.SLMConfig{Indices: spec.Policy.Indices},
+	}
+	if retention := spec.Policy.Retention; retention != nil {
+		policy.Retention = &esclient.SLMRetention{
+			ExpireAfter: retention.ExpireAfter,
+			MinCount:    retention.MinCount,
+			MaxCount:    retention.MaxCount,
+		}
+	}
+	return policy
+}
+
+// Reconcile registers the snapshot repository of the given Elasticsearch cluster of the given version, along with its
+// snapshot lifecycle management policy if any, and returns the status of the repository and policy.
+// The policy and repository previously created by the operator, as recorded in the status, are deleted once removed
+// from the specification, and so is the previous repository once renamed. The snapshots are left in place.
+// It returns a nil status if there is neither a repository nor a policy.
+func Reconcile(
+	ctx context.Context,
+	c esclient.Client,
+	es v1beta1.Elasticsearch,
+	esVersion version.Version,
+) (*v1beta1.SnapshotsStatus, error) {
+	spec := es.Spec.Snapshots
+	previous := v1beta1.SnapshotsStatus{}
+	if es.Status.Snapshots != nil {
+		previous = *es.Status.Snapshots
+	}
+
+	status := v1beta1.SnapshotsStatus{}
+	if spec != nil {
+		if err := reconcileRepository(ctx, c, spec.Repository.RepositoryName(), Repository(*spec)); err != nil {
+			return nil, err
+		}
+		status.Repository = spec.Repository.RepositoryName()
+	}
+
+	if spec != nil && spec.Policy != nil {
+		policyStatus, err := reconcilePolicy(ctx, c, es, esVersion)
+		if err != nil {
+			return nil, err
+		}
+		policyStatus.Repository = status.Repository
+		status = *policyStatus
+	} else if previous.Policy != "" {
+		// the policy must be deleted before its repository
+		if err := c.DeleteSLMPolicy(ctx, previous.Policy); err != nil && !esclient.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	if previous.Repository != "" && previous.Repository != status.Repository {
+		if err := c.DeleteSnapshotRepository(ctx, previous.Repository); err != nil && !esclient.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	if status == (v1beta1.SnapshotsStatus{}) {
+		return nil, nil
+	}
+	return &status, nil
+}
+
+// reconcileRepository registers the given snapshot repository, unless it is already registered with the same
+// settings: registering a repository verifies it on all the nodes.
+func reconcileRepository(ctx context.Context, c esclient.Client, name string, expected esclient.SnapshotRepository) error {
+	current, err := c.GetSnapshotRepository(ctx, name)
+	if err != nil && !esclient.IsNotFound(err) {
+		return err
+	}
+	if err == nil && repositoryEqual(current, expected) {
+		return nil
+	}
+	return c.PutSnapshotRepository(ctx, name, expected)
+}
+
+// repositoryEqual returns true if the given repositories have the same type and settings. Elasticsearch returns the
+// repository settings as strings, they are compared in their string form.
+func repositoryEqual(current, expected esclient.SnapshotRepository) bool {
+	return current.Type == expected.Type &&
+		reflect.DeepEqual(flattenSettings("", current.Settings), flattenSettings("", expected.Settings))
+}
+
+// flattenSettings returns the given settings as strings indexed by their dotted path.
+func flattenSettings(prefix string, settings map[string]interface{}) map[string]string {
+	flat := map[string]string{}
+	for k, v := range settings {
+		key := prefix + k
+		if nested, ok := v.(map[string]interface{}); ok {
+			for nestedKey, nestedValue := range flattenSettings(key+".", nested) {
+				flat[nestedKey] = nestedValue
+			}
+			continue
+		}
+		flat[key] = fmt.Sprintf("%v", v)
+	}
+	return flat
+}
+
+// reconcilePolicy creates or updates the snapshot lifecycle management policy of the given Elasticsearch cluster,
+// which must have one, and returns its status.
+func reconcilePolicy(
+	ctx context.Context,
+	c esclient.Client,
+	es v1beta1.Elasticsearch,
+	esVersion version.Version,
+) (*v1beta1.SnapshotsStatus, error) {
+	if !esVersion.IsSameOrAfter(SLMMinVersion) {
+		return nil, fmt.Errorf(
+			"snapshot lifecycle management requires Elasticsearch %s or above, got %s", SLMMinVersion, esVersion,
+		)
+	}
+	if es.Spec.Snapshots.Policy.Retention != nil && !esVersion.IsSameOrAfter(SLMRetentionMinVersion) {
+		return nil, fmt.Errorf(
+			"snapshot retention requires Elasticsearch %s or above, got %s", SLMRetentionMinVersion, esVersion,
+		)
+	}
+	expected := Policy(es)
+	current, err := c.GetSLMStatus(ctx, PolicyName(es))
+	if err != nil && !esclient.IsNotFound(err) {
+		return nil, err
+	}
+	// updating a policy bumps its version, only do it on changes
+	if !reflect.DeepEqual(current.Policy, expected) {
+		if err := c.PutSLMPolicy(ctx, PolicyName(es), expected); err != nil {
+			return nil, err
+		}
+	}
+	status := newStatus(current)
+	status.Policy = PolicyName(es)
+	return status, nil
+}
+
+// newStatus returns the status of the given snapshot lifecycle management policy.
+func newStatus(policy esclient.SLMPolicyStatus) *v1beta1.SnapshotsStatus {
+	status := v1beta1.SnapshotsStatus{}
+	if policy.LastSuccess != nil {
+		status.LastSuccessfulSnapshot = policy.LastSuccess.SnapshotName
+		lastSuccess := metav1.NewTime(millisToTime(policy.LastSuccess.Time))
+		status.LastSuccessTime = &lastSuccess
+	}
+	if policy.LastFailure != nil && (policy.LastSuccess == nil || policy.LastFailure.Time > policy.LastSuccess.Time) {
+		status.LastFailure = policy.LastFailure.Details
+		if status.LastFailure == "" {
+			status.LastFailure = fmt.Sprintf("snapshot %s failed", policy.LastFailure.SnapshotName)
+		}
+	}
+	return &status
+}
+
+func millisToTime(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond)).UTC()
+}
@@ -0,0 +1,240 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func esWithSnapshots(policy *v1beta1.SnapshotPolicy) v1beta1.Elasticsearch {
+	settings := commonv1beta1.NewConfig(map[string]interface{}{"location": "/snapshots"})
+	return v1beta1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Spec: v1beta1.ElasticsearchSpec{
+			Snapshots: &v1beta1.SnapshotsSpec{
+				Repository: v1beta1.SnapshotRepository{Type: "fs", Settings: &settings},
+				Policy:     policy,
+			},
+		},
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	maxCount := int32(10)
+	es := esWithSnapshots(&v1beta1.SnapshotPolicy{
+		Schedule:  "0 30 1 * * ?",
+		Indices:   []string{"logs-*"},
+		Retention: &v1beta1.SnapshotRetention{ExpireAfter: "30d", MaxCount: &maxCount},
+	})
+	require.Equal(t, esclient.SLMPolicy{
+		Schedule:   "0 30 1 * * ?",
+		Name:       "<es-snap-{now/d}>",
+		Repository: v1beta1.DefaultSnapshotRepositoryName,
+		Config:     esclient.SLMConfig{Indices: []string{"logs-*"}},
+		Retention:  &esclient.SLMRetention{ExpireAfter: "30d", MaxCount: &maxCount},
+	}, Policy(es))
+
+	es.Spec.Snapshots.Repository.Name = "backups"
+	es.Spec.Snapshots.Policy.SnapshotName = "<nightly-{now/d}>"
+	policy := Policy(es)
+	require.Equal(t, "backups", policy.Repository)
+	require.Equal(t, "<nightly-{now/d}>", policy.Name)
+}
+
+func TestReconcile(t *testing.T) {
+	lastSuccess := time.Date(2019, 10, 2, 1, 30, 0, 0, time.UTC)
+	policy := &v1beta1.SnapshotPolicy{Schedule: "0 30 1 * * ?"}
+	withStatus := func(es v1beta1.Elasticsearch, status v1beta1.SnapshotsStatus) v1beta1.Elasticsearch {
+		es.Status.Snapshots = &status
+		return es
+	}
+	renamed := esWithSnapshots(policy)
+	renamed.Spec.Snapshots.Repository.Name = "backups"
+	tests := []struct {
+		name              string
+		es                v1beta1.Elasticsearch
+		version           string
+		currentRepository string
+		currentPolicy     string
+		wantRequests      []string
+		wantStatus        *v1beta1.SnapshotsStatus
+		wantErr           bool
+	}{
+		{
+			name:         "no snapshots",
+			es:           v1beta1.Elasticsearch{},
+			version:      "7.4.0",
+			wantRequests: nil,
+		},
+		{
+			name:         "repository created",
+			es:           esWithSnapshots(nil),
+			version:      "6.8.0",
+			wantRequests: []string{"GET /_snapshot/default", "PUT /_snapshot/default"},
+			wantStatus:   &v1beta1.SnapshotsStatus{Repository: "default"},
+		},
+		{
+			name:              "repository up to date",
+			es:                esWithSnapshots(nil),
+			version:           "6.8.0",
+			currentRepository: `{"default":{"type":"fs","settings":{"location":"/snapshots"}}}`,
+			wantRequests:      []string{"GET /_snapshot/default"},
+			wantStatus:        &v1beta1.SnapshotsStatus{Repository: "default"},
+		},
+		{
+			name:              "repository updated",
+			es:                esWithSnapshots(nil),
+			version:           "6.8.0",
+			currentRepository: `{"default":{"type":"fs","settings":{"location":"/other"}}}`,
+			wantRequests:      []string{"GET /_snapshot/default", "PUT /_snapshot/default"},
+			wantStatus:        &v1beta1.SnapshotsStatus{Repository: "default"},
+		},
+		{
+			name:              "policy not supported",
+			es:                esWithSnapshots(policy),
+			version:           "7.3.0",
+			currentRepository: `{"default":{"type":"fs","settings":{"location":"/snapshots"}}}`,
+			wantRequests:      []string{"GET /_snapshot/default"},
+			wantErr:           true,
+		},
+		{
+			name: "retention not supported",
+			es: esWithSnapshots(&v1beta1.SnapshotPolicy{
+				Schedule:  "0 30 1 * * ?",
+				Retention: &v1beta1.SnapshotRetention{ExpireAfter: "30d"},
+			}),
+			version:           "7.4.0",
+			currentRepository: `{"default":{"type":"fs","settings":{"location":"/snapshots"}}}`,
+			wantRequests:      []string{"GET /_snapshot/default"},
+			wantErr:           true,
+		},
+		{
+			name:              "policy created",
+			es:                esWithSnapshots(policy),
+			version:           "7.4.0",
+			currentRepository: `{"default":{"type":"fs","settings":{"location":"/snapshots"}}}`,
+			wantRequests:      []string{"GET /_snapshot/default", "GET /_slm/policy/es", "PUT /_slm/policy/es"},
+			wantStatus:        &v1beta1.SnapshotsStatus{Repository: "default", Policy: "es"},
+		},
+		{
+			name:              "policy up to date",
+			es:                esWithSnapshots(policy),
+			version:           "7.4.0",
+			currentRepository: `{"default":{"type":"fs","settings":{"location":"/snapshots"}}}`,
+			currentPolicy: `{"es":{"policy":{"schedule":"0 30 1 * * ?","name":"<es-snap-{now/d}>","repository":"default"},
+				"last_success":{"snapshot_name":"es-snap-2019.10.02-abc","time":1569979800000},
+				"last_failure":{"snapshot_name":"es-snap-2019.10.01-def","time":1569893400000,"details":"boom"}}}`,
+			wantRequests: []string{"GET /_snapshot/default", "GET /_slm/policy/es"},
+			wantStatus: &v1beta1.SnapshotsStatus{
+				Repository:             "default",
+				Policy:                 "es",
+				LastSuccessfulSnapshot: "es-snap-2019.10.02-abc",
+				LastSuccessTime:        &metav1.Time{Time: lastSuccess},
+			},
+		},
+		{
+			name:              "policy updated after a failure",
+			es:                esWithSnapshots(&v1beta1.SnapshotPolicy{Schedule: "0 0 * * * ?"}),
+			version:           "7.4.0",
+			currentRepository: `{"default":{"type":"fs","settings":{"location":"/snapshots"}}}`,
+			currentPolicy: `{"es":{"policy":{"schedule":"0 30 1 * * ?","name":"<es-snap-{now/d}>","repository":"default"},
+				"last_success":{"snapshot_name":"es-snap-2019.10.02-abc","time":1569979800000},
+				"last_failure":{"snapshot_name":"es-snap-2019.10.03-def","time":1570066200000,"details":"boom"}}}`,
+			wantRequests: []string{"GET /_snapshot/default", "GET /_slm/policy/es", "PUT /_slm/policy/es"},
+			wantStatus: &v1beta1.SnapshotsStatus{
+				Repository:             "default",
+				Policy:                 "es",
+				LastSuccessfulSnapshot: "es-snap-2019.10.02-abc",
+				LastSuccessTime:        &metav1.Time{Time: lastSuccess},
+				LastFailure:            "boom",
+			},
+		},
+		{
+			name:              "policy removed",
+			es:                withStatus(esWithSnapshots(nil), v1beta1.SnapshotsStatus{Repository: "default", Policy: "es"}),
+			version:           "7.4.0",
+			currentRepository: `{"default":{"type":"fs","settings":{"location":"/snapshots"}}}`,
+			wantRequests:      []string{"GET /_snapshot/default", "DELETE /_slm/policy/es"},
+			wantStatus:        &v1beta1.SnapshotsStatus{Repository: "default"},
+		},
+		{
+			name:         "snapshots removed",
+			es:           withStatus(v1beta1.Elasticsearch{}, v1beta1.SnapshotsStatus{Repository: "default", Policy: "es"}),
+			version:      "7.4.0",
+			wantRequests: []string{"DELETE /_slm/policy/es", "DELETE /_snapshot/default"},
+		},
+		{
+			name:         "repository renamed",
+			es:           withStatus(renamed, v1beta1.SnapshotsStatus{Repository: "default", Policy: "es"}),
+			version:      "7.4.0",
+			wantRequests: []string{"GET /_snapshot/backups", "PUT /_snapshot/backups", "GET /_slm/policy/es", "PUT /_slm/policy/es", "DELETE /_snapshot/default"},
+			wantStatus:   &v1beta1.SnapshotsStatus{Repository: "backups", Policy: "es"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			c := esclient.NewMockClient(version.MustParse(tt.version), func(req *http.Request) *http.Response {
+				requests = append(requests, req.Method+" "+req.URL.Path)
+				switch {
+				case req.Method == http.MethodDelete:
+				case strings.HasPrefix(req.URL.Path, "/_snapshot/") && req.Method == http.MethodGet:
+					if tt.currentRepository == "" {
+						return esclient.NewMockResponse(404, req, `{"error":{"type":"repository_missing_exception"}}`)
+					}
+					return esclient.NewMockResponse(200, req, tt.currentRepository)
+				case strings.HasPrefix(req.URL.Path, "/_snapshot/"):
+					body, err := ioutil.ReadAll(req.Body)
+					require.NoError(t, err)
+					require.JSONEq(t, `{"type":"fs","settings":{"location":"/snapshots"}}`, string(body))
+				case req.Method == http.MethodGet && tt.currentPolicy == "":
+					return esclient.NewMockResponse(404, req, `{"error":{"type":"resource_not_found_exception"}}`)
+				case req.Method == http.MethodGet:
+					return esclient.NewMockResponse(200, req, tt.currentPolicy)
+				case req.Method == http.MethodPut:
+					var policy esclient.SLMPolicy
+					require.NoError(t, json.NewDecoder(req.Body).Decode(&policy))
+					require.Equal(t, Policy(tt.es), policy)
+				}
+				return esclient.NewMockResponse(200, req, `{"acknowledged":true}`)
+			})
+			status, err := Reconcile(context.Background(), c, tt.es, version.MustParse(tt.version))
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.wantRequests, requests)
+			require.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func Test_repositoryEqual(t *testing.T) {
+	expected := esclient.SnapshotRepository{
+		Type:     "s3",
+		Settings: map[string]interface{}{"bucket": "backups", "compress": true, "client": map[string]interface{}{"name": "default"}},
+	}
+	// Elasticsearch returns the settings as strings
+	current := esclient.SnapshotRepository{
+		Type:     "s3",
+		Settings: map[string]interface{}{"bucket": "backups", "compress": "true", "client": map[string]interface{}{"name": "default"}},
+	}
+	require.True(t, repositoryEqual(current, expected))
+	current.Settings["compress"] = "false"
+	require.False(t, repositoryEqual(current, expected))
+	current.Settings["compress"] = "true"
+	current.Type = "fs"
+	require.False(t, repositoryEqual(current, expected))
+}
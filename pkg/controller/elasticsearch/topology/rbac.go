@@ -0,0 +1,184 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package topology
+
+import (
+	"reflect"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/finalizer"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/reconciler"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/label"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/name"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// NodeReaderClusterRole is the ClusterRole allowing to get Kubernetes nodes, installed with the operator.
+const NodeReaderClusterRole = "elastic-es-node-reader"
+
+var log = logf.Log.WithName("topology")
+
+// Reconcile ensures the ServiceAccount used by the topology init container exists along with its token, and is bound
+// to the node reader ClusterRole, if topology awareness is enabled. Those resources are removed otherwise.
+func Reconcile(c k8s.Client, scheme *runtime.Scheme, es v1beta1.Elasticsearch) error {
+	if es.Spec.TopologyAwareness == nil {
+		return cleanup(c, es)
+	}
+
+	if err := reconcileServiceAccount(c, scheme, es); err != nil {
+		return err
+	}
+	if err := reconcileTokenSecret(c, scheme, es); err != nil {
+		return err
+	}
+	return reconcileClusterRoleBinding(c, scheme, es)
+}
+
+func reconcileServiceAccount(c k8s.Client, scheme *runtime.Scheme, es v1beta1.Elasticsearch) error {
+	expected := corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: es.Namespace,
+			Name:      name.TopologyServiceAccount(es.Name),
+			Labels:    label.NewLabels(k8s.ExtractNamespacedName(&es)),
+		},
+	}
+	return reconciler.ReconcileResource(reconciler.Params{
+		Client:     c,
+		Scheme:     scheme,
+		Owner:      &es,
+		Expected:   &expected,
+		Reconciled: &corev1.ServiceAccount{},
+		NeedsUpdate: func() bool {
+			return false
+		},
+		UpdateReconciled: func() {},
+	})
+}
+
+// reconcileTokenSecret creates a token Secret for the ServiceAccount, populated by Kubernetes. Contrary to the token
+// automatically mounted in pods, it can be mounted in the topology init container only.
+func reconcileTokenSecret(c k8s.Client, scheme *runtime.Scheme, es v1beta1.Elasticsearch) error {
+	expected := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: es.Namespace,
+			Name:      name.TopologyTokenSecret(es.Name),
+			Labels:    label.NewLabels(k8s.ExtractNamespacedName(&es)),
+			Annotations: map[string]string{
+				corev1.ServiceAccountNameKey: name.TopologyServiceAccount(es.Name),
+			},
+		},
+		Type: corev1.SecretTypeServiceAccountToken,
+	}
+	return reconciler.ReconcileResource(reconciler.Params{
+		Client:     c,
+		Scheme:     scheme,
+		Owner:      &es,
+		Expected:   &expected,
+		Reconciled: &corev1.Secret{},
+		NeedsUpdate: func() bool {
+			// the data is managed by Kubernetes
+			return false
+		},
+		UpdateReconciled: func() {},
+	})
+}
+
+// reconcileClusterRoleBinding binds the node reader ClusterRole to the ServiceAccount. Being cluster-scoped, the
+// ClusterRoleBinding cannot be owned by the Elasticsearch resource, it is removed by a finalizer instead.
+func reconcileClusterRoleBinding(c k8s.Client, scheme *runtime.Scheme, es v1beta1.Elasticsearch) error {
+	expected := newClusterRoleBinding(es)
+	reconciled := &rbacv1.ClusterRoleBinding{}
+	return reconciler.ReconcileResource(reconciler.Params{
+		Client:     c,
+		Scheme:     scheme,
+		Expected:   &expected,
+		Reconciled: reconciled,
+		NeedsUpdate: func() bool {
+			return !reflect.DeepEqual(expected.Subjects, reconciled.Subjects)
+		},
+		UpdateReconciled: func() {
+			reconciled.Subjects = expected.Subjects
+		},
+	})
+}
+
+func newClusterRoleBinding(es v1beta1.Elasticsearch) rbacv1.ClusterRoleBinding {
+	return rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name.TopologyClusterRoleBinding(k8s.ExtractNamespacedName(&es)),
+			Labels: label.NewLabels(k8s.ExtractNamespacedName(&es)),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     NodeReaderClusterRole,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: es.Namespace,
+				Name:      name.TopologyServiceAccount(es.Name),
+			},
+		},
+	}
+}
+
+// cleanup removes the resources created for topology awareness, if any. The ServiceAccount is used as a marker to
+// avoid requests on cluster-scoped resources for clusters that never enabled topology awareness.
+func cleanup(c k8s.Client, es v1beta1.Elasticsearch) error {
+	var sa corev1.ServiceAccount
+	err := c.Get(types.NamespacedName{Namespace: es.Namespace, Name: name.TopologyServiceAccount(es.Name)}, &sa)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Info("Removing topology awareness resources", "namespace", es.Namespace, "es_name", es.Name)
+	if err := deleteClusterRoleBinding(c, es); err != nil {
+		return err
+	}
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: es.Namespace, Name: name.TopologyTokenSecret(es.Name)},
+	}
+	if err := c.Delete(&secret); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := c.Delete(&sa); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func deleteClusterRoleBinding(c k8s.Client, es v1beta1.Elasticsearch) error {
+	binding := newClusterRoleBinding(es)
+	if err := c.Delete(&binding); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// Finalizer removes the ClusterRoleBinding of the given cluster, which cannot be garbage collected. An operator
+// restricted to the given managed namespace never creates it.
+func Finalizer(c k8s.Client, es v1beta1.Elasticsearch, managedNamespace string) finalizer.Finalizer {
+	return finalizer.Finalizer{
+		Name: "finalizer.elasticsearch.k8s.elastic.co/topology-cluster-role-binding",
+		Execute: func() error {
+			if es.Spec.TopologyAwareness == nil || managedNamespace != "" {
+				// already removed by the reconciliation, or never created
+				return nil
+			}
+			return deleteClusterRoleBinding(c, es)
+		},
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package topology
+
+import (
+	"testing"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/scheme"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var (
+	saKey      = types.NamespacedName{Namespace: "ns", Name: "es-es-topology"}
+	secretKey  = types.NamespacedName{Namespace: "ns", Name: "es-es-topology-token"}
+	bindingKey = types.NamespacedName{Name: "ns.es-es-topology"}
+)
+
+func newES(topologyAwareness *v1beta1.TopologyAwareness) v1beta1.Elasticsearch {
+	return v1beta1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Spec:       v1beta1.ElasticsearchSpec{TopologyAwareness: topologyAwareness},
+	}
+}
+
+func requireNotFound(t *testing.T, c k8s.Client, key types.NamespacedName, obj runtime.Object) {
+	require.True(t, apierrors.IsNotFound(c.Get(key, obj)), "%s should not exist", key)
+}
+
+func TestReconcile(t *testing.T) {
+	require.NoError(t, scheme.SetupScheme())
+	c := k8s.WrapClient(fake.NewFakeClient())
+
+	// nothing to do without topology awareness
+	require.NoError(t, Reconcile(c, clientgoscheme.Scheme, newES(nil)))
+	requireNotFound(t, c, saKey, &corev1.ServiceAccount{})
+	requireNotFound(t, c, bindingKey, &rbacv1.ClusterRoleBinding{})
+
+	// enabling topology awareness creates the service account, its token and binds it to the node reader role
+	es := newES(&v1beta1.TopologyAwareness{})
+	require.NoError(t, Reconcile(c, clientgoscheme.Scheme, es))
+
+	var sa corev1.ServiceAccount
+	require.NoError(t, c.Get(saKey, &sa))
+	require.Equal(t, "es", sa.OwnerReferences[0].Name)
+
+	var secret corev1.Secret
+	require.NoError(t, c.Get(secretKey, &secret))
+	require.Equal(t, corev1.SecretTypeServiceAccountToken, secret.Type)
+	require.Equal(t, saKey.Name, secret.Annotations[corev1.ServiceAccountNameKey])
+
+	var binding rbacv1.ClusterRoleBinding
+	require.NoError(t, c.Get(bindingKey, &binding))
+	require.Empty(t, binding.OwnerReferences)
+	require.Equal(t, NodeReaderClusterRole, binding.RoleRef.Name)
+	require.Equal(t, []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "ns", Name: saKey.Name}}, binding.Subjects)
+
+	// reconciling again is a no-op
+	require.NoError(t, Reconcile(c, clientgoscheme.Scheme, es))
+
+	// disabling topology awareness removes everything
+	require.NoError(t, Reconcile(c, clientgoscheme.Scheme, newES(nil)))
+	requireNotFound(t, c, saKey, &corev1.ServiceAccount{})
+	requireNotFound(t, c, secretKey, &corev1.Secret{})
+	requireNotFound(t, c, bindingKey, &rbacv1.ClusterRoleBinding{})
+}
+
+func TestFinalizer(t *testing.T) {
+	require.NoError(t, scheme.SetupScheme())
+	es := newES(&v1beta1.TopologyAwareness{})
+	binding := newClusterRoleBinding(es)
+	c := k8s.WrapClient(fake.NewFakeClient(&binding))
+
+	require.NoError(t, Finalizer(c, es, "").Execute())
+	requireNotFound(t, c, bindingKey, &rbacv1.ClusterRoleBinding{})
+	// the binding is already gone
+	require.NoError(t, Finalizer(c, es, "").Execute())
+	// a namespace-scoped operator never creates the binding, and is not allowed to delete it
+	require.NoError(t, Finalizer(k8s.WrapClient(fake.NewFakeClient()), es, "ns").Execute())
+}
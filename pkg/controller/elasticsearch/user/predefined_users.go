@@ -24,6 +24,12 @@ const (
 	// LogstashAdminUserBuiltinRole is the name of the built-in role for the Logstash system user
 	// TODO (bh) getting an error using either logstash_admin or logstash_system.
 	LogstashAdminUserBuiltinRole = "superuser"
+	// LogstashMonitoringUserBuiltinRoles are the built-in roles of the user shipping Logstash monitoring data, either
+	// from Logstash itself or from Metricbeat
+	LogstashMonitoringUserBuiltinRoles = "logstash_system,remote_monitoring_agent"
+	// LogstashManagementUserBuiltinRole is the name of the built-in role of the user reading the centrally managed
+	// Logstash pipelines
+	LogstashManagementUserBuiltinRole = "logstash_admin"
 
 	// ProbeUserRole is the name of the custom elastic_internal_probe_user role
 	ProbeUserRole = "elastic_internal_probe_user"
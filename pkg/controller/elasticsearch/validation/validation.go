@@ -12,14 +12,24 @@ import (
 )
 
 const (
-	cfgInvalidMsg            = "configuration invalid"
-	validationFailedMsg      = "Spec validation failed"
-	masterRequiredMsg        = "Elasticsearch needs to have at least one master node"
-	parseVersionErrMsg       = "Cannot parse Elasticsearch version"
-	parseStoredVersionErrMsg = "Cannot parse current Elasticsearch version"
-	invalidSanIPErrMsg       = "invalid SAN IP address"
-	pvcImmutableMsg          = "Volume claim templates cannot be modified"
-	invalidNamesErrMsg       = "Elasticsearch configuration would generate resources with invalid names"
+	cfgInvalidMsg                 = "configuration invalid"
+	validationFailedMsg           = "Spec validation failed"
+	masterRequiredMsg             = "Elasticsearch needs to have at least one master node"
+	parseVersionErrMsg            = "Cannot parse Elasticsearch version"
+	parseStoredVersionErrMsg      = "Cannot parse current Elasticsearch version"
+	invalidSanIPErrMsg            = "invalid SAN IP address"
+	pvcImmutableMsg               = "Volume claim templates cannot be modified"
+	invalidNamesErrMsg            = "Elasticsearch configuration would generate resources with invalid names"
+	snapshotPolicyVersionMsg      = "Snapshot policies require Elasticsearch"
+	snapshotRetentionVersionMsg   = "Snapshot retention requires Elasticsearch"
+	duplicateIndexManagementMsg   = "Duplicate index management object"
+	composableTemplatesVersionMsg = "Component and index templates require Elasticsearch"
+	duplicateRemoteClusterMsg     = "Duplicate remote cluster"
+	selfRemoteClusterMsg          = "Elasticsearch cannot be a remote cluster of itself"
+	invalidTopologyKeyMsg         = "Invalid topology key"
+	topologySettingsMsg           = "Settings managed by the operator when topology awareness is enabled"
+	topologyNamespacedMsg         = "Topology awareness is not available when the operator is restricted to a single namespace, " +
+		"as it cannot bind the Kubernetes nodes reader ClusterRole"
 )
 
 // Validation is a function from a currently stored Elasticsearch spec and proposed new spec
@@ -93,3 +103,12 @@ func Validate(es estype.Elasticsearch) ([]validation.Result, error) {
 	}
 	return errs, nil
 }
+
+// ValidateOperatorScope checks that the given Elasticsearch only relies on features available to an operator
+// restricted to the given namespace, if any.
+func ValidateOperatorScope(es estype.Elasticsearch, managedNamespace string) []validation.Result {
+	if managedNamespace != "" && es.Spec.TopologyAwareness != nil {
+		return []validation.Result{{Reason: topologyNamespacedMsg}}
+	}
+	return nil
+}
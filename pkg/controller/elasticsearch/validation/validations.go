@@ -15,11 +15,16 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
 	common "github.com/cloudptio/logstash-operator/pkg/controller/common/settings"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/validation"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/indexmanagement"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/name"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/settings"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/snapshot"
 	esversion "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/version"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
 	netutil "github.com/cloudptio/logstash-operator/pkg/utils/net"
 	"github.com/cloudptio/logstash-operator/pkg/utils/set"
+	corev1 "k8s.io/api/core/v1"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 )
 
 // Validations are all registered Elasticsearch validations.
@@ -33,6 +38,10 @@ var Validations = []Validation{
 	noBlacklistedSettings,
 	validSanIP,
 	pvcModification,
+	snapshotPolicySupported,
+	validIndexManagement,
+	validRemoteClusters,
+	validTopologyAwareness,
 }
 
 // validName checks whether the name is valid.
@@ -164,7 +173,8 @@ func validSanIP(ctx Context) validation.Result {
 	return validation.OK
 }
 
-// pvcModification ensures no PVCs are changed, as volume claim templates are immutable in stateful sets
+// pvcModification ensures no PVCs are changed, as volume claim templates are immutable in stateful sets.
+// Storage requests can only be increased: the volumes are then expanded if their storage class allows it.
 func pvcModification(ctx Context) validation.Result {
 	if ctx.Current == nil {
 		return validation.OK
@@ -178,7 +188,7 @@ func pvcModification(ctx Context) validation.Result {
 
 		// ssets do not allow modifications to fields other than 'replicas', 'template', and 'updateStrategy'
 		// reflection isn't ideal, but okay here since the ES object does not have the status of the claims
-		if !reflect.DeepEqual(node.VolumeClaimTemplates, currNodeSet.VolumeClaimTemplates) {
+		if !reflect.DeepEqual(withoutStorageIncreases(node.VolumeClaimTemplates, currNodeSet.VolumeClaimTemplates), currNodeSet.VolumeClaimTemplates) {
 			return validation.Result{
 				Allowed: false,
 				Reason:  pvcImmutableMsg,
@@ -188,6 +198,131 @@ func pvcModification(ctx Context) validation.Result {
 	return validation.OK
 }
 
+// withoutStorageIncreases returns a copy of the proposed claims where the storage requests higher than the ones of the
+// current claims of the same name are replaced by the current ones.
+func withoutStorageIncreases(proposed, current []corev1.PersistentVolumeClaim) []corev1.PersistentVolumeClaim {
+	if proposed == nil {
+		return nil
+	}
+	result := make([]corev1.PersistentVolumeClaim, 0, len(proposed))
+	for _, claim := range proposed {
+		claim = *claim.DeepCopy()
+		for _, currentClaim := range current {
+			if claim.Name != currentClaim.Name {
+				continue
+			}
+			proposedStorage, proposedExists := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+			currentStorage, currentExists := currentClaim.Spec.Resources.Requests[corev1.ResourceStorage]
+			if proposedExists && currentExists && proposedStorage.Cmp(currentStorage) > 0 {
+				claim.Spec.Resources.Requests[corev1.ResourceStorage] = currentStorage
+			}
+		}
+		result = append(result, claim)
+	}
+	return result
+}
+
+// snapshotPolicySupported checks that a snapshot policy is only set on versions supporting snapshot lifecycle
+// management, and its retention on versions supporting it.
+func snapshotPolicySupported(ctx Context) validation.Result {
+	snapshots := ctx.Proposed.Elasticsearch.Spec.Snapshots
+	if snapshots == nil || snapshots.Policy == nil {
+		return validation.OK
+	}
+	if !ctx.Proposed.Version.IsSameOrAfter(snapshot.SLMMinVersion) {
+		return validation.Result{
+			Allowed: false,
+			Reason:  fmt.Sprintf("%s %s", snapshotPolicyVersionMsg, snapshot.SLMMinVersion),
+		}
+	}
+	if snapshots.Policy.Retention != nil && !ctx.Proposed.Version.IsSameOrAfter(snapshot.SLMRetentionMinVersion) {
+		return validation.Result{
+			Allowed: false,
+			Reason:  fmt.Sprintf("%s %s", snapshotRetentionVersionMsg, snapshot.SLMRetentionMinVersion),
+		}
+	}
+	return validation.OK
+}
+
+// validIndexManagement checks that the objects of the index management specification have unique names, and that
+// composable templates are only set on versions supporting them.
+func validIndexManagement(ctx Context) validation.Result {
+	spec := ctx.Proposed.Elasticsearch.Spec.IndexManagement
+	if spec == nil {
+		return validation.OK
+	}
+	for kind, objects := range map[v1beta1.IndexManagementKind][]v1beta1.IndexManagementObject{
+		v1beta1.ILMPolicyKind:         spec.ILMPolicies,
+		v1beta1.ComponentTemplateKind: spec.ComponentTemplates,
+		v1beta1.IndexTemplateKind:     spec.IndexTemplates,
+		v1beta1.IngestPipelineKind:    spec.IngestPipelines,
+	} {
+		names := set.StringSet{}
+		for _, o := range objects {
+			if names.Has(o.Name) {
+				return validation.Result{Reason: fmt.Sprintf("%s: %s %s", duplicateIndexManagementMsg, kind, o.Name)}
+			}
+			names.Add(o.Name)
+		}
+	}
+	if (len(spec.ComponentTemplates) > 0 || len(spec.IndexTemplates) > 0) &&
+		!ctx.Proposed.Version.IsSameOrAfter(indexmanagement.ComposableTemplatesMinVersion) {
+		return validation.Result{
+			Reason: fmt.Sprintf("%s %s", composableTemplatesVersionMsg, indexmanagement.ComposableTemplatesMinVersion),
+		}
+	}
+	return validation.OK
+}
+
+// validRemoteClusters checks that the remote clusters have unique names, and do not reference the cluster itself.
+func validRemoteClusters(ctx Context) validation.Result {
+	es := ctx.Proposed.Elasticsearch
+	names := set.StringSet{}
+	for _, remote := range es.Spec.RemoteClusters {
+		if names.Has(remote.Name) {
+			return validation.Result{Reason: fmt.Sprintf("%s: %s", duplicateRemoteClusterMsg, remote.Name)}
+		}
+		names.Add(remote.Name)
+		if remote.NamespacedName(es.Namespace) == k8s.ExtractNamespacedName(&es) {
+			return validation.Result{Reason: fmt.Sprintf("%s: %s", selfRemoteClusterMsg, remote.Name)}
+		}
+	}
+	return validation.OK
+}
+
+// topologySettings are set by the operator when topology awareness is enabled.
+var topologySettings = []string{settings.NodeAttrZone, settings.ClusterRoutingAllocationAwarenessAttributes}
+
+// validTopologyAwareness checks that the topology key is a valid label key, and that the node sets do not configure
+// the settings managed by the operator when topology awareness is enabled.
+func validTopologyAwareness(ctx Context) validation.Result {
+	topologyAwareness := ctx.Proposed.Elasticsearch.Spec.TopologyAwareness
+	if topologyAwareness == nil {
+		return validation.OK
+	}
+	if key := topologyAwareness.TopologyKey; key != "" {
+		if errs := k8svalidation.IsQualifiedName(key); len(errs) > 0 {
+			return validation.Result{Reason: fmt.Sprintf("%s '%s': %s", invalidTopologyKeyMsg, key, strings.Join(errs, ", "))}
+		}
+	}
+	for _, nodeSet := range ctx.Proposed.Elasticsearch.Spec.NodeSets {
+		if nodeSet.Config == nil {
+			continue
+		}
+		config, err := common.NewCanonicalConfigFrom(nodeSet.Config.Data)
+		if err != nil {
+			// reported by noBlacklistedSettings
+			continue
+		}
+		if forbidden := config.HasKeys(topologySettings); len(forbidden) > 0 {
+			return validation.Result{
+				Reason: fmt.Sprintf("%s: node set %s: %s", topologySettingsMsg, nodeSet.Name, strings.Join(forbidden, ", ")),
+			}
+		}
+	}
+	return validation.OK
+}
+
 func getNodeSet(name string, es v1beta1.Elasticsearch) *v1beta1.NodeSet {
 	for i := range es.Spec.NodeSets {
 		if es.Spec.NodeSets[i].Name == name {
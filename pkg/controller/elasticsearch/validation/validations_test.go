@@ -526,7 +526,7 @@ func Test_pvcModified(t *testing.T) {
 		want     validation.Result
 	}{
 		{
-			name:    "resize fails",
+			name:    "storage increase accepted",
 			current: current,
 			proposed: v1beta1.Elasticsearch{
 				Spec: v1beta1.ElasticsearchSpec{
@@ -552,6 +552,36 @@ func Test_pvcModified(t *testing.T) {
 					},
 				},
 			},
+			want: validation.OK,
+		},
+
+		{
+			name:    "storage decrease fails",
+			current: current,
+			proposed: v1beta1.Elasticsearch{
+				Spec: v1beta1.ElasticsearchSpec{
+					Version: "7.2.0",
+					NodeSets: []v1beta1.NodeSet{
+						{
+							Name: "master",
+							VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+								{
+									ObjectMeta: metav1.ObjectMeta{
+										Name: "elasticsearch-data",
+									},
+									Spec: corev1.PersistentVolumeClaimSpec{
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{
+												corev1.ResourceStorage: resource.MustParse("3Gi"),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
 			want: failedValidation,
 		},
 
@@ -748,3 +778,252 @@ func getEsCluster() *v1beta1.Elasticsearch {
 		},
 	}
 }
+
+func Test_snapshotPolicySupported(t *testing.T) {
+	withSnapshots := func(v string, policy *estype.SnapshotPolicy) estype.Elasticsearch {
+		cluster := *es(v)
+		cluster.Spec.Snapshots = &estype.SnapshotsSpec{Repository: estype.SnapshotRepository{Type: "fs"}, Policy: policy}
+		return cluster
+	}
+	policy := &estype.SnapshotPolicy{Schedule: "0 30 1 * * ?"}
+	withRetention := &estype.SnapshotPolicy{Schedule: "0 30 1 * * ?", Retention: &estype.SnapshotRetention{ExpireAfter: "30d"}}
+	tests := []struct {
+		name      string
+		esCluster estype.Elasticsearch
+		want      bool
+	}{
+		{
+			name:      "no snapshots",
+			esCluster: *es("6.8.0"),
+			want:      true,
+		},
+		{
+			name:      "repository without policy",
+			esCluster: withSnapshots("6.8.0", nil),
+			want:      true,
+		},
+		{
+			name:      "policy on a version without snapshot lifecycle management",
+			esCluster: withSnapshots("7.3.2", policy),
+			want:      false,
+		},
+		{
+			name:      "policy on a version with snapshot lifecycle management",
+			esCluster: withSnapshots("7.4.0", policy),
+			want:      true,
+		},
+		{
+			name:      "retention on a version without snapshot retention",
+			esCluster: withSnapshots("7.4.2", withRetention),
+			want:      false,
+		},
+		{
+			name:      "retention on a version with snapshot retention",
+			esCluster: withSnapshots("7.5.0", withRetention),
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := NewValidationContext(nil, tt.esCluster)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, snapshotPolicySupported(*ctx).Allowed)
+		})
+	}
+}
+
+func Test_validIndexManagement(t *testing.T) {
+	withIndexManagement := func(v string, spec estype.IndexManagementSpec) estype.Elasticsearch {
+		cluster := *es(v)
+		cluster.Spec.IndexManagement = &spec
+		return cluster
+	}
+	tests := []struct {
+		name      string
+		esCluster estype.Elasticsearch
+		want      bool
+	}{
+		{
+			name:      "no index management",
+			esCluster: *es("6.8.0"),
+			want:      true,
+		},
+		{
+			name: "policies and pipelines",
+			esCluster: withIndexManagement("6.8.0", estype.IndexManagementSpec{
+				ILMPolicies:     []estype.IndexManagementObject{{Name: "logs"}},
+				IngestPipelines: []estype.IndexManagementObject{{Name: "logs"}},
+			}),
+			want: true,
+		},
+		{
+			name: "duplicate names",
+			esCluster: withIndexManagement("7.9.0", estype.IndexManagementSpec{
+				IngestPipelines: []estype.IndexManagementObject{{Name: "logs"}, {Name: "logs"}},
+			}),
+			want: false,
+		},
+		{
+			name: "templates on a version without composable templates",
+			esCluster: withIndexManagement("7.7.0", estype.IndexManagementSpec{
+				IndexTemplates: []estype.IndexManagementObject{{Name: "logs"}},
+			}),
+			want: false,
+		},
+		{
+			name: "templates on a version with composable templates",
+			esCluster: withIndexManagement("7.8.0", estype.IndexManagementSpec{
+				ComponentTemplates: []estype.IndexManagementObject{{Name: "logs"}},
+				IndexTemplates:     []estype.IndexManagementObject{{Name: "logs"}},
+			}),
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := NewValidationContext(nil, tt.esCluster)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, validIndexManagement(*ctx).Allowed)
+		})
+	}
+}
+
+func Test_validRemoteClusters(t *testing.T) {
+	withRemotes := func(remotes ...estype.RemoteCluster) estype.Elasticsearch {
+		cluster := *es("7.4.0")
+		cluster.Namespace = "ns"
+		cluster.Name = "es"
+		cluster.Spec.RemoteClusters = remotes
+		return cluster
+	}
+	tests := []struct {
+		name      string
+		esCluster estype.Elasticsearch
+		want      bool
+	}{
+		{
+			name:      "no remote clusters",
+			esCluster: withRemotes(),
+			want:      true,
+		},
+		{
+			name: "remote clusters",
+			esCluster: withRemotes(
+				estype.RemoteCluster{Name: "eu", ElasticsearchRef: common.ObjectSelector{Name: "es-eu"}},
+				estype.RemoteCluster{Name: "us", ElasticsearchRef: common.ObjectSelector{Name: "es", Namespace: "us"}},
+			),
+			want: true,
+		},
+		{
+			name: "duplicate names",
+			esCluster: withRemotes(
+				estype.RemoteCluster{Name: "eu", ElasticsearchRef: common.ObjectSelector{Name: "es-eu"}},
+				estype.RemoteCluster{Name: "eu", ElasticsearchRef: common.ObjectSelector{Name: "es-eu-2"}},
+			),
+			want: false,
+		},
+		{
+			name: "reference to itself",
+			esCluster: withRemotes(
+				estype.RemoteCluster{Name: "self", ElasticsearchRef: common.ObjectSelector{Name: "es", Namespace: "ns"}},
+			),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := NewValidationContext(nil, tt.esCluster)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, validRemoteClusters(*ctx).Allowed)
+		})
+	}
+}
+
+func Test_validTopologyAwareness(t *testing.T) {
+	withTopology := func(topologyAwareness *estype.TopologyAwareness, cfg map[string]interface{}) estype.Elasticsearch {
+		cluster := *es("7.4.0")
+		cluster.Spec.TopologyAwareness = topologyAwareness
+		cluster.Spec.NodeSets = []estype.NodeSet{{Name: "default", Count: 3, Config: &common.Config{Data: cfg}}}
+		return cluster
+	}
+	tests := []struct {
+		name      string
+		esCluster estype.Elasticsearch
+		want      bool
+	}{
+		{
+			name:      "no topology awareness",
+			esCluster: withTopology(nil, map[string]interface{}{"node.attr.zone": "a"}),
+			want:      true,
+		},
+		{
+			name:      "default topology key",
+			esCluster: withTopology(&estype.TopologyAwareness{}, map[string]interface{}{"node.attr.rack": "a"}),
+			want:      true,
+		},
+		{
+			name:      "custom topology key",
+			esCluster: withTopology(&estype.TopologyAwareness{TopologyKey: "example.com/rack"}, nil),
+			want:      true,
+		},
+		{
+			name:      "invalid topology key",
+			esCluster: withTopology(&estype.TopologyAwareness{TopologyKey: "not a label"}, nil),
+			want:      false,
+		},
+		{
+			name:      "zone attribute set in the configuration",
+			esCluster: withTopology(&estype.TopologyAwareness{}, map[string]interface{}{"node": map[string]interface{}{"attr.zone": "a"}}),
+			want:      false,
+		},
+		{
+			name: "awareness attributes set in the configuration",
+			esCluster: withTopology(&estype.TopologyAwareness{}, map[string]interface{}{
+				"cluster.routing.allocation.awareness.attributes": "rack",
+			}),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := NewValidationContext(nil, tt.esCluster)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, validTopologyAwareness(*ctx).Allowed)
+		})
+	}
+}
+
+func TestValidateOperatorScope(t *testing.T) {
+	withTopology := *es("7.4.0")
+	withTopology.Spec.TopologyAwareness = &estype.TopologyAwareness{}
+	tests := []struct {
+		name             string
+		esCluster        estype.Elasticsearch
+		managedNamespace string
+		wantViolations   int
+	}{
+		{
+			name:             "no topology awareness, namespace-scoped operator",
+			esCluster:        *es("7.4.0"),
+			managedNamespace: "ns",
+			wantViolations:   0,
+		},
+		{
+			name:             "topology awareness, cluster-scoped operator",
+			esCluster:        withTopology,
+			managedNamespace: "",
+			wantViolations:   0,
+		},
+		{
+			name:             "topology awareness, namespace-scoped operator",
+			esCluster:        withTopology,
+			managedNamespace: "ns",
+			wantViolations:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Len(t, ValidateOperatorScope(tt.esCluster, tt.managedNamespace), tt.wantViolations)
+		})
+	}
+}
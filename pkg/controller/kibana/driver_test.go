@@ -11,9 +11,11 @@ import (
 	kbtype "github.com/cloudptio/logstash-operator/pkg/apis/kibana/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates/http"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/deployment"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/watches"
+	"github.com/cloudptio/logstash-operator/pkg/controller/kibana/label"
 	"github.com/cloudptio/logstash-operator/pkg/controller/kibana/pod"
 	"github.com/cloudptio/logstash-operator/pkg/controller/kibana/volume"
 	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
@@ -315,6 +317,7 @@ func expectedDeploymentParams() deployment.Params {
 					},
 					Resources: pod.DefaultResources,
 				}},
+				Affinity:                     defaults.SpreadAffinity(label.NewLabels("test")),
 				AutomountServiceAccountToken: &false,
 			},
 		},
@@ -8,10 +8,12 @@ import (
 	"reflect"
 	"sync/atomic"
 
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
 	kibanav1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/kibana/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/conditions"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/finalizer"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/keystore"
@@ -187,6 +189,7 @@ func (r *ReconcileKibana) doReconcile(request reconcile.Request, kb *kibanav1bet
 	ver, err := version.Parse(kb.Spec.Version)
 	if err != nil {
 		k8s.EmitErrorEvent(r.recorder, err, kb, events.EventReasonValidation, "Invalid version '%s': %v", kb.Spec.Version, err)
+		r.reportInvalid(kb, conditions.InvalidVersion(kb.Generation, err))
 		return reconcile.Result{}, err
 	}
 
@@ -197,6 +200,8 @@ func (r *ReconcileKibana) doReconcile(request reconcile.Request, kb *kibanav1bet
 	}
 	// version specific reconcile
 	results := driver.Reconcile(&state, kb, r.params)
+	_, reconcileErr := results.Aggregate()
+	state.SetConditions(conditions.ConfigValid(kb.Generation, nil), conditions.Reconciled(kb.Generation, reconcileErr))
 
 	// update status
 	err = r.updateStatus(state)
@@ -210,6 +215,14 @@ func (r *ReconcileKibana) doReconcile(request reconcile.Request, kb *kibanav1bet
 	return res, err
 }
 
+// reportInvalid sets the given ConfigValid condition in the status of a Kibana resource that cannot be reconciled.
+func (r *ReconcileKibana) reportInvalid(kb *kibanav1beta1.Kibana, condition commonv1beta1.Condition) {
+	kb.Status.Conditions = kb.Status.Conditions.Set(condition)
+	if err := r.Status().Update(kb); err != nil && !errors.IsConflict(err) {
+		log.Error(err, "Failed to update status", "namespace", kb.Namespace, "kibana_name", kb.Name)
+	}
+}
+
 func (r *ReconcileKibana) updateStatus(state State) error {
 	current := state.originalKibana
 	if reflect.DeepEqual(current.Status, state.Kibana.Status) {
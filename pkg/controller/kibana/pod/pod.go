@@ -69,6 +69,7 @@ func NewPodTemplateSpec(kb v1beta1.Kibana, keystore *keystore.Resources) corev1.
 	builder := defaults.NewPodTemplateBuilder(kb.Spec.PodTemplate, v1beta1.KibanaContainerName).
 		WithResources(DefaultResources).
 		WithLabels(label.NewLabels(kb.Name)).
+		WithAffinity(defaults.SpreadAffinity(label.NewLabels(kb.Name))).
 		WithDockerImage(kb.Spec.Image, imageWithVersion(defaultImageRepositoryAndName, kb.Spec.Version)).
 		WithReadinessProbe(readinessProbe(kb.Spec.HTTP.TLS.Enabled())).
 		WithPorts(ports).
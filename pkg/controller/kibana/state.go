@@ -5,7 +5,10 @@
 package kibana
 
 import (
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/apis/kibana/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/conditions"
+	commondeployment "github.com/cloudptio/logstash-operator/pkg/controller/common/deployment"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -35,4 +38,15 @@ func (s State) UpdateKibanaState(deployment v1.Deployment) {
 			s.Kibana.Status.Health = v1beta1.KibanaGreen
 		}
 	}
+	s.SetConditions(conditions.FromDeployment(s.Kibana.Generation, deployment)...)
+	if trigger, completed := commondeployment.CompletedRestartTrigger(deployment); completed {
+		s.Kibana.Status.RestartTrigger = trigger
+	}
+}
+
+// SetConditions sets the given conditions in the Kibana status.
+func (s State) SetConditions(c ...commonv1beta1.Condition) {
+	for _, condition := range c {
+		s.Kibana.Status.Conditions = s.Kibana.Status.Conditions.Set(condition)
+	}
 }
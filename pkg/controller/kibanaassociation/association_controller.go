@@ -15,6 +15,7 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates/http"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/conditions"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/finalizer"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/operator"
@@ -159,15 +160,22 @@ func (r *ReconcileAssociation) Reconcile(request reconcile.Request) (reconcile.R
 		return reconcile.Result{}, err
 	}
 
-	newStatus, err := r.reconcileInternal(&kibana)
+	newStatus, credentials, err := r.reconcileInternal(&kibana)
 	if err != nil {
 		k8s.EmitErrorEvent(r.recorder, err, &kibana, events.EventReconciliationError, "Reconciliation error: %v", err)
 	}
 
 	// maybe update status
-	if !reflect.DeepEqual(kibana.Status.AssociationStatus, newStatus) {
+	lastRotation := credentials.LastRotation(kibana.Status.LastCredentialRotation)
+	newConditions := conditions.SetAssociationReady(kibana.Status.Conditions, kibana.Generation,
+		map[string]commonv1beta1.AssociationStatus{"Elasticsearch": newStatus})
+	if !reflect.DeepEqual(kibana.Status.AssociationStatus, newStatus) ||
+		lastRotation != kibana.Status.LastCredentialRotation ||
+		!reflect.DeepEqual(kibana.Status.Conditions, newConditions) {
 		oldStatus := kibana.Status.AssociationStatus
 		kibana.Status.AssociationStatus = newStatus
+		kibana.Status.LastCredentialRotation = lastRotation
+		kibana.Status.Conditions = newConditions
 		if err := r.Status().Update(&kibana); err != nil {
 			if apierrors.IsConflict(err) {
 				// Conflicts are expected and will be resolved on next loop
@@ -177,13 +185,15 @@ func (r *ReconcileAssociation) Reconcile(request reconcile.Request) (reconcile.R
 
 			return defaultRequeue, err
 		}
-		r.recorder.AnnotatedEventf(&kibana,
-			annotation.ForAssociationStatusChange(oldStatus, newStatus),
-			corev1.EventTypeNormal,
-			events.EventAssociationStatusChange,
-			"Association status changed from [%s] to [%s]", oldStatus, newStatus)
+		if oldStatus != newStatus {
+			r.recorder.AnnotatedEventf(&kibana,
+				annotation.ForAssociationStatusChange(oldStatus, newStatus),
+				corev1.EventTypeNormal,
+				events.EventAssociationStatusChange,
+				"Association status changed from [%s] to [%s]", oldStatus, newStatus)
+		}
 	}
-	return resultFromStatus(newStatus), err
+	return association.WithCredentialsRequeue(resultFromStatus(newStatus), credentials.Requeue), err
 }
 
 func resultFromStatus(status commonv1beta1.AssociationStatus) reconcile.Result {
@@ -205,7 +215,9 @@ func (r *ReconcileAssociation) isCompatible(kibana *kbtype.Kibana) (bool, error)
 	return compat, err
 }
 
-func (r *ReconcileAssociation) reconcileInternal(kibana *kbtype.Kibana) (commonv1beta1.AssociationStatus, error) {
+// reconcileInternal reconciles the association of the given Kibana with Elasticsearch. It returns the status of the
+// association, and the reconciled credentials of Kibana if any.
+func (r *ReconcileAssociation) reconcileInternal(kibana *kbtype.Kibana) (commonv1beta1.AssociationStatus, association.Credentials, error) {
 	kibanaKey := k8s.ExtractNamespacedName(kibana)
 
 	// garbage collect leftover resources that are not required anymore
@@ -217,7 +229,7 @@ func (r *ReconcileAssociation) reconcileInternal(kibana *kbtype.Kibana) (commonv
 		// stop watching any ES cluster previously referenced for this Kibana resource
 		r.watches.ElasticsearchClusters.RemoveHandlerForKey(elasticsearchWatchName(kibanaKey))
 		// other leftover resources are already garbage-collected
-		return commonv1beta1.AssociationUnknown, nil
+		return commonv1beta1.AssociationUnknown, association.Credentials{}, nil
 	}
 
 	// this Kibana instance references an Elasticsearch cluster
@@ -234,17 +246,16 @@ func (r *ReconcileAssociation) reconcileInternal(kibana *kbtype.Kibana) (commonv
 		Watched: []types.NamespacedName{esRefKey},
 		Watcher: kibanaKey,
 	}); err != nil {
-		return commonv1beta1.AssociationFailed, err
+		return commonv1beta1.AssociationFailed, association.Credentials{}, err
 	}
 
-	userSecretKey := association.UserKey(kibana, kibanaUserSuffix)
-	// watch the user secret in the ES namespace
+	// watch the user secrets in the ES namespace
 	if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
 		Name:    elasticsearchWatchName(kibanaKey),
-		Watched: []types.NamespacedName{userSecretKey},
+		Watched: association.RotatedUserKeys(kibana, esRefKey.Namespace, kibanaUserSuffix),
 		Watcher: kibanaKey,
 	}); err != nil {
-		return commonv1beta1.AssociationFailed, err
+		return commonv1beta1.AssociationFailed, association.Credentials{}, err
 	}
 
 	var es estype.Elasticsearch
@@ -259,15 +270,19 @@ func (r *ReconcileAssociation) reconcileInternal(kibana *kbtype.Kibana) (commonv
 			if err := association.RemoveAssociationConf(r.Client, kibana); err != nil && !errors.IsConflict(err) {
 				log.Error(err, "Failed to remove Elasticsearch configuration from Kibana object",
 					"namespace", kibana.Namespace, "kibana_name", kibana.Name)
-				return commonv1beta1.AssociationPending, err
+				return commonv1beta1.AssociationPending, association.Credentials{}, err
 			}
 
-			return commonv1beta1.AssociationPending, nil
+			return commonv1beta1.AssociationPending, association.Credentials{}, nil
 		}
-		return commonv1beta1.AssociationFailed, err
+		return commonv1beta1.AssociationFailed, association.Credentials{}, err
+	}
+
+	if !association.IsReferenceAllowed(es, kibana, kibana.Kind) {
+		return commonv1beta1.AssociationFailed, association.Credentials{}, r.denyReference(kibana, es)
 	}
 
-	if err := association.ReconcileEsUser(
+	credentials, err := association.ReconcileRotatedEsUser(
 		r.Client,
 		r.scheme,
 		kibana,
@@ -277,20 +292,26 @@ func (r *ReconcileAssociation) reconcileInternal(kibana *kbtype.Kibana) (commonv
 		},
 		elasticsearchuser.KibanaSystemUserBuiltinRole,
 		kibanaUserSuffix,
-		es); err != nil {
-		return commonv1beta1.AssociationPending, err
+		es,
+		association.CredentialRotation{
+			Interval:  kibana.Spec.CredentialRotation.GetInterval(),
+			PodLabels: map[string]string{label.KibanaNameLabelName: kibana.Name},
+		},
+		time.Now(),
+	)
+	if err != nil {
+		return commonv1beta1.AssociationPending, association.Credentials{}, err
 	}
 
 	caSecret, err := r.reconcileElasticsearchCA(kibana, esRefKey)
 	if err != nil {
-		return commonv1beta1.AssociationPending, err
+		return commonv1beta1.AssociationPending, association.Credentials{}, err
 	}
 
 	// construct the expected association configuration
-	authSecret := association.ClearTextSecretKeySelector(kibana, kibanaUserSuffix)
 	expectedESAssoc := &commonv1beta1.AssociationConf{
-		AuthSecretName: authSecret.Name,
-		AuthSecretKey:  authSecret.Key,
+		AuthSecretName: credentials.Selector.Name,
+		AuthSecretKey:  credentials.Selector.Key,
 		CACertProvided: caSecret.CACertProvided,
 		CASecretName:   caSecret.Name,
 		URL:            services.ExternalServiceURL(es),
@@ -301,15 +322,47 @@ func (r *ReconcileAssociation) reconcileInternal(kibana *kbtype.Kibana) (commonv
 		log.Info("Updating Kibana spec with Elasticsearch backend configuration", "namespace", kibana.Namespace, "kibana_name", kibana.Name)
 		if err := association.UpdateAssociationConf(r.Client, kibana, expectedESAssoc); err != nil {
 			if errors.IsConflict(err) {
-				return commonv1beta1.AssociationPending, nil
+				return commonv1beta1.AssociationPending, association.Credentials{}, nil
 			}
 			log.Error(err, "Failed to update association configuration", "namespace", kibana.Namespace, "kibana_name", kibana.Name)
-			return commonv1beta1.AssociationPending, err
+			return commonv1beta1.AssociationPending, association.Credentials{}, err
 		}
 		kibana.SetAssociationConf(expectedESAssoc)
 	}
 
-	return commonv1beta1.AssociationEstablished, nil
+	return commonv1beta1.AssociationEstablished, credentials, nil
+}
+
+// denyReference reports that the given Kibana is not allowed to reference the given Elasticsearch cluster, and revokes
+// the connection details and the user it may have been granted before.
+func (r *ReconcileAssociation) denyReference(kibana *kbtype.Kibana, es estype.Elasticsearch) error {
+	r.recorder.Event(kibana, corev1.EventTypeWarning, events.EventAssociationDenied,
+		association.ReferenceDeniedError(es, kibana, kibana.Kind).Error())
+	if err := association.RemoveAssociationConf(r.Client, kibana); err != nil && !errors.IsConflict(err) {
+		return err
+	}
+	keys := append(association.RotatedUserKeys(kibana, es.Namespace, kibanaUserSuffix), types.NamespacedName{
+		Namespace: kibana.Namespace,
+		Name:      association.ClearTextSecretKeySelector(kibana, kibanaUserSuffix).Name,
+	})
+	for _, key := range keys {
+		var secret corev1.Secret
+		err := r.Get(key, &secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(&secret, kibana) && !hasBeenCreatedBy(&secret, kibana) {
+			continue
+		}
+		log.Info("Deleting secret", "namespace", secret.Namespace, "secret_name", secret.Name, "kibana_name", kibana.Name)
+		if err := r.Delete(&secret); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *ReconcileAssociation) reconcileElasticsearchCA(kibana *kbtype.Kibana, es types.NamespacedName) (association.CASecret, error) {
@@ -0,0 +1,283 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/deployment"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/reconciler"
+	lsclient "github.com/cloudptio/logstash-operator/pkg/controller/logstash/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/configmap"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	lsname "github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pod"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/volume"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/cloudptio/logstash-operator/pkg/utils/net"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// canaryRequeue is the interval at which the canary node statistics are checked during the analysis window.
+var canaryRequeue = 10 * time.Second
+
+// pipelineChecksum returns a checksum of the content of the given pipeline config map.
+func pipelineChecksum(cm corev1.ConfigMap) string {
+	keys := make([]string, 0, len(cm.Data))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	checksum := sha256.New224()
+	for _, k := range keys {
+		_, _ = checksum.Write([]byte(k))
+		_, _ = checksum.Write([]byte(cm.Data[k]))
+	}
+	return fmt.Sprintf("%x", checksum.Sum(nil))
+}
+
+// reconcilePipeline reconciles the pipeline config map of the given Logstash according to its update strategy.
+// It returns true if a pipeline change is being tested on a canary node.
+func (d *driver) reconcilePipeline(state *State, ls *lstype.Logstash, dialer net.Dialer) (bool, *reconciler.Results) {
+	results := &reconciler.Results{}
+	pipelines, err := d.reconcileSelectedPipelines(*ls)
+	if err != nil {
+		return false, results.WithError(err)
+	}
+	expected, err := configmap.NewPipelineConfigMap(d.client, *ls, pipelines, d.esOutputSSLSettings(*ls))
+	if err != nil {
+		return false, results.WithError(err)
+	}
+
+	if !ls.Spec.UpdateStrategy.IsCanary() {
+		if err := d.deleteCanary(*ls); err != nil {
+			return false, results.WithError(err)
+		}
+		return false, results.WithError(configmap.ReconcileConfigMap(d.client, d.scheme, *ls, expected))
+	}
+
+	var stable corev1.ConfigMap
+	err = d.client.Get(k8s.ExtractNamespacedName(&expected), &stable)
+	if apierrors.IsNotFound(err) {
+		// first rollout: there is no previous pipeline to compare the canary with
+		return false, results.WithError(configmap.ReconcileConfigMap(d.client, d.scheme, *ls, expected))
+	}
+	if err != nil {
+		return false, results.WithError(err)
+	}
+
+	status := ls.Status.Canary
+	if reflect.DeepEqual(stable.Data, expected.Data) {
+		if status != nil && status.Phase == lstype.CanaryProgressing {
+			state.UpdateCanaryStatus(lstype.CanaryRolledBack, status.ConfigChecksum, status.StartTime, "Pipeline change reverted")
+		}
+		return false, results.WithError(d.deleteCanary(*ls))
+	}
+
+	checksum := pipelineChecksum(expected)
+	switch {
+	case status != nil && status.ConfigChecksum == checksum && status.Phase == lstype.CanaryRolledBack:
+		// this pipeline was already rejected, do not try it again until it is changed
+		return false, results.WithError(d.deleteCanary(*ls))
+	case status == nil || status.ConfigChecksum != checksum || status.Phase != lstype.CanaryProgressing:
+		now := metav1.Now()
+		state.UpdateCanaryStatus(lstype.CanaryProgressing, checksum, &now, "Testing pipeline change on a canary node")
+		d.recorder.Eventf(ls, corev1.EventTypeNormal, events.EventReasonStateChange,
+			"Testing pipeline change %s on a canary node", checksum)
+		status = state.Logstash.Status.Canary
+	}
+
+	canaryConfigMap := configmap.NewConfigMapWithData(
+		types.NamespacedName{Namespace: ls.Namespace, Name: lsname.CanaryPipelineConfigMap(ls.Name)},
+		expected.Data,
+	)
+	if err := configmap.ReconcileConfigMap(d.client, d.scheme, *ls, canaryConfigMap); err != nil {
+		return true, results.WithError(err)
+	}
+	params, err := d.deploymentParams(ls)
+	if err != nil {
+		return true, results.WithError(err)
+	}
+	canaryParams := canaryDeploymentParams(*ls, params)
+	setPipelinesFileChecksum(&canaryParams.PodTemplateSpec, canaryConfigMap)
+	canaryDeployment := deployment.New(canaryParams)
+	deleted, err := deleteDeploymentWithOutdatedSelector(d.client, canaryDeployment)
+	if err != nil {
+		return true, results.WithError(err)
+	}
+	if deleted {
+		return true, results.WithResult(reconcile.Result{RequeueAfter: canaryRequeue})
+	}
+	if _, err := deployment.Reconcile(d.client, d.scheme, canaryDeployment, ls); err != nil {
+		return true, results.WithError(err)
+	}
+
+	elapsed := time.Since(status.StartTime.Time)
+	window := ls.Spec.UpdateStrategy.Canary.GetAnalysisWindow()
+	stats, err := d.canaryNodeStats(*ls, dialer)
+	if err != nil {
+		if elapsed < window {
+			log.V(1).Info("Canary node statistics not available yet", "namespace", ls.Namespace, "logstash_name", ls.Name, "error", err)
+			return true, results.WithResult(reconcile.Result{RequeueAfter: canaryRequeue})
+		}
+		return false, results.WithError(d.rollbackCanary(state, ls, fmt.Sprintf("Canary node unavailable: %v", err)))
+	}
+
+	if reason := canaryThresholdExceeded(stats, ls.Spec.UpdateStrategy.Canary); reason != "" {
+		return false, results.WithError(d.rollbackCanary(state, ls, reason))
+	}
+
+	if elapsed < window {
+		requeueAfter := window - elapsed
+		if requeueAfter > canaryRequeue {
+			requeueAfter = canaryRequeue
+		}
+		return true, results.WithResult(reconcile.Result{RequeueAfter: requeueAfter})
+	}
+
+	// the canary node was healthy during the whole analysis window: promote the pipeline to all nodes
+	if err := configmap.ReconcileConfigMap(d.client, d.scheme, *ls, expected); err != nil {
+		return true, results.WithError(err)
+	}
+	state.UpdateCanaryStatus(lstype.CanaryPromoted, checksum, status.StartTime, "Pipeline change applied to all nodes")
+	d.recorder.Eventf(ls, corev1.EventTypeNormal, events.EventReasonStateChange,
+		"Pipeline change %s promoted to all nodes", checksum)
+	return false, results.WithError(d.deleteCanary(*ls))
+}
+
+// rollbackCanary discards the pipeline under test and records the reason in the status.
+func (d *driver) rollbackCanary(state *State, ls *lstype.Logstash, reason string) error {
+	status := state.Logstash.Status.Canary
+	state.UpdateCanaryStatus(lstype.CanaryRolledBack, status.ConfigChecksum, status.StartTime, reason)
+	d.recorder.Eventf(ls, corev1.EventTypeWarning, events.EventReasonUnhealthy,
+		"Pipeline change %s rolled back: %s", status.ConfigChecksum, reason)
+	return d.deleteCanary(*ls)
+}
+
+// canaryNodeStats retrieves the node statistics of the canary pod.
+func (d *driver) canaryNodeStats(ls lstype.Logstash, dialer net.Dialer) (lsclient.NodeStats, error) {
+	var pods corev1.PodList
+	if err := d.client.List(&pods,
+		client.InNamespace(ls.Namespace),
+		client.MatchingLabels(label.NewCanaryLabels(ls.Name)),
+	); err != nil {
+		return lsclient.NodeStats{}, err
+	}
+	for _, p := range pods.Items {
+		if !p.DeletionTimestamp.IsZero() || !k8s.IsPodReady(p) || p.Status.PodIP == "" {
+			continue
+		}
+		lsClient := lsclient.NewLogstashClient(dialer, pod.MonitoringURL(p))
+		defer lsClient.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), lsclient.DefaultReqTimeout)
+		defer cancel()
+		return lsClient.GetNodeStats(ctx)
+	}
+	return lsclient.NodeStats{}, fmt.Errorf("no ready canary pod")
+}
+
+// canaryThresholdExceeded returns a non-empty reason if the given canary statistics exceed the strategy thresholds.
+func canaryThresholdExceeded(stats lsclient.NodeStats, strategy *lstype.CanaryStrategy) string {
+	if failures, max := stats.FilterFailures(), strategy.GetMaxFilterFailures(); failures > max {
+		return fmt.Sprintf("%d filter failures exceed the threshold of %d", failures, max)
+	}
+	if size, max := stats.DeadLetterQueueBytes(), strategy.GetMaxDeadLetterQueueBytes(); size > max {
+		return fmt.Sprintf("%d bytes written to the dead letter queue exceed the threshold of %d", size, max)
+	}
+	if errors, max := stats.OutputErrors(), strategy.GetMaxOutputErrors(); errors > max {
+		return fmt.Sprintf("%d output errors exceed the threshold of %d", errors, max)
+	}
+	return ""
+}
+
+// canaryDeploymentParams derives the parameters of the single-node canary deployment from the stable ones.
+func canaryDeploymentParams(ls lstype.Logstash, stable deployment.Params) deployment.Params {
+	canary := stable
+	canary.Name = lsname.CanaryDeployment(ls.Name)
+	canary.Replicas = 1
+	canary.Selector = label.NewCanaryLabels(ls.Name)
+	canary.SelectorExpressions = nil
+	canary.Labels = label.NewCanaryLabels(ls.Name)
+	canary.PodTemplateSpec = *stable.PodTemplateSpec.DeepCopy()
+	canary.PodTemplateSpec.Labels[label.TrackLabelName] = label.CanaryTrack
+	for i, v := range canary.PodTemplateSpec.Spec.Volumes {
+		if (v.Name == volume.PipelineVolumeName || v.Name == volume.PipelinesFileVolumeName) && v.ConfigMap != nil {
+			canary.PodTemplateSpec.Spec.Volumes[i].ConfigMap.Name = lsname.CanaryPipelineConfigMap(ls.Name)
+		}
+	}
+	return canary
+}
+
+// deleteCanary deletes the canary deployment and pipeline config map, if any.
+func (d *driver) deleteCanary(ls lstype.Logstash) error {
+	canaryDeployment := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ls.Namespace, Name: lsname.CanaryDeployment(ls.Name)},
+	}
+	if err := d.client.Delete(&canaryDeployment); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	canaryConfigMap := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ls.Namespace, Name: lsname.CanaryPipelineConfigMap(ls.Name)},
+	}
+	if err := d.client.Delete(&canaryConfigMap); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// keepDeploymentSelector sets the selector of the given expected deployment to the one of the existing deployment, if
+// any, since the selector of a deployment cannot be updated. The stable deployments created before the canary pod was
+// excluded from their selector keep selecting it, which is harmless: the canary pod belongs to the ReplicaSet of the
+// canary deployment, so it is never adopted, and the stable pods are never restarted for a selector change.
+func keepDeploymentSelector(c k8s.Client, expected *appsv1.Deployment) error {
+	var actual appsv1.Deployment
+	err := c.Get(k8s.ExtractNamespacedName(expected), &actual)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	expected.Spec.Selector = actual.Spec.Selector.DeepCopy()
+	return nil
+}
+
+// deleteDeploymentWithOutdatedSelector deletes the existing canary deployment of the given expected one if their
+// selectors differ, since the selector of a deployment cannot be updated. This is the case of the canary deployments
+// created before the stable and canary pods were distinguished by their track label: the canary pod is restarted once.
+// It returns true if the deployment was deleted, to be recreated by the next reconciliation.
+func deleteDeploymentWithOutdatedSelector(c k8s.Client, expected appsv1.Deployment) (bool, error) {
+	var actual appsv1.Deployment
+	err := c.Get(k8s.ExtractNamespacedName(&expected), &actual)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if reflect.DeepEqual(actual.Spec.Selector, expected.Spec.Selector) {
+		return false, nil
+	}
+	log.Info("Deleting deployment to update its selector",
+		"namespace", actual.Namespace, "deployment_name", actual.Name)
+	uid := actual.UID
+	if err := c.Delete(&actual, client.Preconditions{UID: &uid}); err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,190 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"testing"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/deployment"
+	lsclient "github.com/cloudptio/logstash-operator/pkg/controller/logstash/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func Test_canaryThresholdExceeded(t *testing.T) {
+	stats := lsclient.NodeStats{
+		Pipelines: map[string]lsclient.PipelineStats{
+			"main": {
+				Plugins: lsclient.PluginsStats{
+					Filters: []lsclient.PluginStats{{ID: "grok", Failures: 3}},
+					Outputs: []lsclient.PluginStats{{ID: "es", Failures: 1}},
+				},
+				DeadLetterQueue: lsclient.DeadLetterQueueStats{QueueSizeInBytes: 100},
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		strategy *lstype.CanaryStrategy
+		stats    lsclient.NodeStats
+		exceeded bool
+	}{
+		{
+			name:     "no failure, no thresholds",
+			strategy: nil,
+			stats:    lsclient.NodeStats{},
+			exceeded: false,
+		},
+		{
+			name:     "failures within the default thresholds",
+			strategy: nil,
+			stats:    stats,
+			exceeded: false,
+		},
+		{
+			name:     "failures above the default thresholds",
+			strategy: &lstype.CanaryStrategy{},
+			stats: lsclient.NodeStats{Pipelines: map[string]lsclient.PipelineStats{"main": {
+				Plugins: lsclient.PluginsStats{Filters: []lsclient.PluginStats{{ID: "grok", Failures: 11}}},
+			}}},
+			exceeded: true,
+		},
+		{
+			name:     "failures within thresholds",
+			strategy: &lstype.CanaryStrategy{MaxFilterFailures: int64Ptr(3), MaxDeadLetterQueueBytes: int64Ptr(100), MaxOutputErrors: int64Ptr(1)},
+			stats:    stats,
+			exceeded: false,
+		},
+		{
+			name:     "filter failures above threshold",
+			strategy: &lstype.CanaryStrategy{MaxFilterFailures: int64Ptr(2), MaxDeadLetterQueueBytes: int64Ptr(100), MaxOutputErrors: int64Ptr(1)},
+			stats:    stats,
+			exceeded: true,
+		},
+		{
+			name:     "dead letter queue above threshold",
+			strategy: &lstype.CanaryStrategy{MaxFilterFailures: int64Ptr(3), MaxDeadLetterQueueBytes: int64Ptr(99), MaxOutputErrors: int64Ptr(1)},
+			stats:    stats,
+			exceeded: true,
+		},
+		{
+			name:     "output errors above threshold",
+			strategy: &lstype.CanaryStrategy{MaxFilterFailures: int64Ptr(3), MaxDeadLetterQueueBytes: int64Ptr(100), MaxOutputErrors: int64Ptr(0)},
+			stats:    stats,
+			exceeded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := canaryThresholdExceeded(tt.stats, tt.strategy)
+			require.Equal(t, tt.exceeded, reason != "", reason)
+		})
+	}
+}
+
+func Test_pipelineChecksum(t *testing.T) {
+	a := corev1.ConfigMap{Data: map[string]string{"input.conf": "a", "output.conf": "b"}}
+	b := corev1.ConfigMap{Data: map[string]string{"output.conf": "b", "input.conf": "a"}}
+	c := corev1.ConfigMap{Data: map[string]string{"input.conf": "a", "output.conf": "c"}}
+	require.Equal(t, pipelineChecksum(a), pipelineChecksum(b))
+	require.NotEqual(t, pipelineChecksum(a), pipelineChecksum(c))
+}
+
+func Test_canaryDeploymentParams(t *testing.T) {
+	ls := lstype.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+	stablePodLabels := label.NewLabels("ls")
+	stablePodLabels[label.TrackLabelName] = label.StableTrack
+	stable := deployment.Params{
+		Name:                "ls-ls",
+		Selector:            label.NewLabels("ls"),
+		SelectorExpressions: label.NonCanaryRequirements(),
+		Labels:              label.NewLabels("ls"),
+		PodTemplateSpec: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: stablePodLabels},
+		},
+		Replicas: 3,
+	}
+	canary := canaryDeploymentParams(ls, stable)
+	require.Equal(t, int32(1), canary.Replicas)
+	require.Equal(t, label.CanaryTrack, canary.PodTemplateSpec.Labels[label.TrackLabelName])
+	require.Equal(t, label.StableTrack, stable.PodTemplateSpec.Labels[label.TrackLabelName])
+	// the selectors do not overlap
+	stableSelector, err := metav1.LabelSelectorAsSelector(deployment.New(stable).Spec.Selector)
+	require.NoError(t, err)
+	canarySelector, err := metav1.LabelSelectorAsSelector(deployment.New(canary).Spec.Selector)
+	require.NoError(t, err)
+	require.True(t, stableSelector.Matches(labels.Set(stable.PodTemplateSpec.Labels)))
+	require.False(t, stableSelector.Matches(labels.Set(canary.PodTemplateSpec.Labels)))
+	require.True(t, canarySelector.Matches(labels.Set(canary.PodTemplateSpec.Labels)))
+	require.False(t, canarySelector.Matches(labels.Set(stable.PodTemplateSpec.Labels)))
+	// the stable pods created before the track label was introduced are still selected
+	require.True(t, stableSelector.Matches(labels.Set(label.NewLabels("ls"))))
+}
+
+func Test_keepDeploymentSelector(t *testing.T) {
+	expected := deployment.New(deployment.Params{
+		Name:                "ls-ls",
+		Namespace:           "ns",
+		Selector:            label.NewLabels("ls"),
+		SelectorExpressions: label.NonCanaryRequirements(),
+	})
+	c := k8s.WrapClient(fake.NewFakeClient())
+
+	// no existing deployment: the expected selector is used
+	dp := *expected.DeepCopy()
+	require.NoError(t, keepDeploymentSelector(c, &dp))
+	require.Equal(t, expected.Spec.Selector, dp.Spec.Selector)
+
+	// the selector of a deployment created before the canary pod was excluded is kept, the deployment is not deleted
+	legacy := deployment.New(deployment.Params{Name: "ls-ls", Namespace: "ns", Selector: label.NewLabels("ls")})
+	require.NoError(t, c.Create(&legacy))
+	dp = *expected.DeepCopy()
+	require.NoError(t, keepDeploymentSelector(c, &dp))
+	require.Equal(t, legacy.Spec.Selector, dp.Spec.Selector)
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(&legacy), &appsv1.Deployment{}))
+}
+
+func Test_deleteDeploymentWithOutdatedSelector(t *testing.T) {
+	expected := deployment.New(deployment.Params{
+		Name:      "ls-ls-canary",
+		Namespace: "ns",
+		Selector:  label.NewCanaryLabels("ls"),
+	})
+	outdated := deployment.New(deployment.Params{
+		Name:      "ls-ls-canary",
+		Namespace: "ns",
+		Selector:  label.NewLabels("ls"),
+	})
+	c := k8s.WrapClient(fake.NewFakeClient())
+
+	// nothing to delete
+	deleted, err := deleteDeploymentWithOutdatedSelector(c, expected)
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	require.NoError(t, c.Create(&outdated))
+	deleted, err = deleteDeploymentWithOutdatedSelector(c, expected)
+	require.NoError(t, err)
+	require.True(t, deleted)
+	require.True(t, apierrors.IsNotFound(c.Get(k8s.ExtractNamespacedName(&outdated), &appsv1.Deployment{})))
+
+	// the deployment with the expected selector is kept
+	require.NoError(t, c.Create(&expected))
+	deleted, err = deleteDeploymentWithOutdatedSelector(c, expected)
+	require.NoError(t, err)
+	require.False(t, deleted)
+}
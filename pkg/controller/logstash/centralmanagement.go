@@ -0,0 +1,150 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"time"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/reconciler"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/watches"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/configmap"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/management"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/cloudptio/logstash-operator/pkg/utils/net"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// centralManagementRequeue is the delay before checking the license again when it does not include central management
+// or could not be checked.
+var centralManagementRequeue = 1 * time.Minute
+
+func centralManagementSecretWatchKey(logstash lstype.Logstash) string {
+	return fmt.Sprintf("%s-%s-management-es-secret", logstash.Namespace, logstash.Name)
+}
+
+// reconcileCentralManagement checks that the license of the associated Elasticsearch cluster includes the central
+// management of the pipelines, seeds the pipelines defined in the given Logstash if requested, and records the outcome
+// in its status. Logstash keeps loading its pipelines from where it did before if the license cannot be checked.
+func (d *driver) reconcileCentralManagement(state *State, ls *lstype.Logstash, dialer net.Dialer) *reconciler.Results {
+	results := &reconciler.Results{}
+	if !ls.Spec.CentralManagement.Enabled {
+		state.UpdateCentralManagementStatus(nil)
+		return results
+	}
+
+	previous := lstype.CentralManagementStatus{}
+	if ls.Status.CentralManagement != nil {
+		previous = *ls.Status.CentralManagement
+	}
+	status, err := d.centralManagementStatus(*ls, dialer, previous)
+	switch {
+	case err != nil:
+		log.Error(err, "Failed to set up central management", "namespace", ls.Namespace, "logstash_name", ls.Name)
+		k8s.EmitErrorEvent(d.recorder, err, ls, events.EventReconciliationError, "Failed to set up central management: %v", err)
+		status.Phase = lstype.CentralManagementFailed
+		status.Message = err.Error()
+		results.WithResult(reconcile.Result{RequeueAfter: centralManagementRequeue})
+	case status.Phase == lstype.CentralManagementUnlicensed:
+		if previous.Phase != lstype.CentralManagementUnlicensed {
+			d.recorder.Event(ls, corev1.EventTypeWarning, events.EventReasonUnexpected, status.Message)
+		}
+		results.WithResult(reconcile.Result{RequeueAfter: centralManagementRequeue})
+	}
+	state.UpdateCentralManagementStatus(&status)
+	return results
+}
+
+// centralManagementStatus checks the license of the associated Elasticsearch cluster and seeds the pipelines, with the
+// credentials of the association with Elasticsearch. The seeded pipelines reference the credentials of the association
+// user through an environment variable, so that they are not stored in Elasticsearch. On error, the returned status keeps the previous active state
+// and records the pipelines seeded so far.
+func (d *driver) centralManagementStatus(
+	ls lstype.Logstash,
+	dialer net.Dialer,
+	previous lstype.CentralManagementStatus,
+) (lstype.CentralManagementStatus, error) {
+	status := lstype.CentralManagementStatus{Active: previous.Active, SeededPipelines: previous.SeededPipelines}
+
+	esVersion, err := d.associatedElasticsearchVersion(ls)
+	if err != nil {
+		return status, err
+	}
+	esClient, err := d.newElasticsearchClient(ls, dialer, esVersion)
+	if err != nil {
+		return status, err
+	}
+	defer esClient.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	license, err := esClient.GetLicense(ctx)
+	if err != nil {
+		return status, err
+	}
+	if !management.LicenseCompatible(license) {
+		status.Phase = lstype.CentralManagementUnlicensed
+		status.Active = false
+		status.Message = fmt.Sprintf(
+			"Central management requires an active gold, platinum or trial license, found %s license with status %s: running the pipelines defined in the resource",
+			license.Type, license.Status,
+		)
+		return status, nil
+	}
+	status.Active = true
+
+	if ls.Spec.CentralManagement.SeedPipelines {
+		_, admitted, _, err := d.admitPipelines(ls)
+		if err != nil {
+			return status, err
+		}
+		cm, err := configmap.NewCentrallyManagedPipelineConfigMap(ls, admitted, d.esOutputSSLSettings(ls))
+		if err != nil {
+			return status, err
+		}
+		status.SeededPipelines, err = management.SeedPipelines(
+			ctx, esClient, management.Pipelines(cm), previous.SeededPipelines, time.Now(),
+		)
+		if err != nil {
+			return status, err
+		}
+	}
+	status.Phase = lstype.CentralManagementReady
+	return status, nil
+}
+
+// centralManagementAuth returns the selector of the active password of the central management user, and writes it to
+// the given configuration checksum so that Logstash pods are rotated when it changes, including on rotation. It returns
+// nil if central management is not active.
+func (d *driver) centralManagementAuth(ls lstype.Logstash, configChecksum hash.Hash) (*corev1.SecretKeySelector, error) {
+	if !management.IsActive(ls) {
+		d.dynamicWatches.Secrets.RemoveHandlerForKey(centralManagementSecretWatchKey(ls))
+		return nil, nil
+	}
+
+	authSecretKey := types.NamespacedName{Namespace: ls.Namespace, Name: management.AuthSecretKeySelector(ls).Name}
+	if err := d.dynamicWatches.Secrets.AddHandler(watches.NamedWatch{
+		Name:    centralManagementSecretWatchKey(ls),
+		Watched: []types.NamespacedName{authSecretKey},
+		Watcher: k8s.ExtractNamespacedName(&ls),
+	}); err != nil {
+		return nil, err
+	}
+
+	var authSecret corev1.Secret
+	if err := d.client.Get(authSecretKey, &authSecret); err != nil {
+		return nil, err
+	}
+	auth := management.ActiveAuthSecretKeySelector(ls, authSecret)
+	_, _ = configChecksum.Write(authSecret.Data[auth.Key])
+	return auth, nil
+}
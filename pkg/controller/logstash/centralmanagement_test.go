@@ -0,0 +1,108 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/version/version7"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_driver_reconcileCentralManagement(t *testing.T) {
+	require.NoError(t, lstype.AddToScheme(scheme.Scheme))
+	require.NoError(t, esv1beta1.AddToScheme(scheme.Scheme))
+
+	esStatus := http.StatusOK
+	licenseType := "basic"
+	var created []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if esStatus != http.StatusOK {
+			w.WriteHeader(esStatus)
+			return
+		}
+		if r.URL.Path == "/_license" {
+			_, _ = w.Write([]byte(`{"license":{"status":"active","type":"` + licenseType + `"}}`))
+			return
+		}
+		created = append(created, r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	es := esv1beta1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Spec:       esv1beta1.ElasticsearchSpec{Version: "7.4.0"},
+	}
+	ls := lstype.Logstash{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"},
+		Spec: lstype.LogstashSpec{
+			ElasticsearchRef: commonv1beta1.ObjectSelector{Name: "es"},
+		},
+	}
+	ls.SetAssociationConf(&commonv1beta1.AssociationConf{URL: server.URL})
+	d := driver{
+		client:             k8s.WrapClient(fake.NewFakeClientWithScheme(scheme.Scheme, &es)),
+		scheme:             scheme.Scheme,
+		recorder:           record.NewFakeRecorder(10),
+		esOutputSSLFactory: version7.ElasticsearchOutputSSLSettings,
+	}
+	state := NewState(reconcile.Request{}, &ls)
+
+	// disabled: Elasticsearch is not queried
+	results := d.reconcileCentralManagement(&state, &ls, nil)
+	require.False(t, results.HasError())
+	require.Nil(t, ls.Status.CentralManagement)
+
+	// basic license: pipelines are not seeded, Logstash runs the local pipelines
+	ls.Spec.CentralManagement = lstype.CentralManagementSpec{Enabled: true, SeedPipelines: true}
+	results = d.reconcileCentralManagement(&state, &ls, nil)
+	res, err := results.Aggregate()
+	require.NoError(t, err)
+	require.Equal(t, centralManagementRequeue, res.RequeueAfter)
+	require.Equal(t, lstype.CentralManagementUnlicensed, ls.Status.CentralManagement.Phase)
+	require.False(t, ls.Status.CentralManagement.Active)
+	require.Empty(t, created)
+
+	// platinum license: the main pipeline is seeded
+	licenseType = "platinum"
+	results = d.reconcileCentralManagement(&state, &ls, nil)
+	require.False(t, results.HasError())
+	require.Equal(t, []string{"/.logstash/_create/main"}, created)
+	require.Equal(t, &lstype.CentralManagementStatus{
+		Phase:           lstype.CentralManagementReady,
+		Active:          true,
+		SeededPipelines: []string{"main"},
+	}, ls.Status.CentralManagement)
+
+	// Elasticsearch unavailable: Logstash keeps loading the pipelines from Elasticsearch
+	esStatus = http.StatusServiceUnavailable
+	results = d.reconcileCentralManagement(&state, &ls, nil)
+	res, err = results.Aggregate()
+	require.NoError(t, err)
+	require.Equal(t, centralManagementRequeue, res.RequeueAfter)
+	require.Equal(t, lstype.CentralManagementFailed, ls.Status.CentralManagement.Phase)
+	require.True(t, ls.Status.CentralManagement.Active)
+	require.Equal(t, []string{"main"}, ls.Status.CentralManagement.SeededPipelines)
+
+	// the seeded pipeline is not created again
+	esStatus = http.StatusOK
+	created = nil
+	d.reconcileCentralManagement(&state, &ls, nil)
+	require.Empty(t, created)
+	require.Equal(t, lstype.CentralManagementReady, ls.Status.CentralManagement.Phase)
+}
@@ -0,0 +1,149 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cloudptio/logstash-operator/pkg/utils/net"
+	"github.com/cloudptio/logstash-operator/pkg/utils/stringsutil"
+)
+
+// DefaultReqTimeout is the default timeout used when performing HTTP calls against the Logstash API
+const DefaultReqTimeout = 30 * time.Second
+
+// Client captures the information needed to interact with the monitoring API of a single Logstash node via HTTP.
+type Client interface {
+	// Close idle connections in the underlying http client.
+	Close()
+	// Equal returns true if other can be considered as the same client.
+	Equal(other Client) bool
+	// GetNodeStats calls the _node/stats api of the Logstash node.
+	GetNodeStats(ctx context.Context) (NodeStats, error)
+	// GetRawNodeStats calls the _node/stats api of the Logstash node and returns the response as is.
+	GetRawNodeStats(ctx context.Context) (json.RawMessage, error)
+	// GetHotThreads calls the _node/hot_threads api of the Logstash node.
+	GetHotThreads(ctx context.Context) (json.RawMessage, error)
+	// SetLogLevels sets the level of the given loggers through the _node/logging api of the Logstash node.
+	SetLogLevels(ctx context.Context, levels map[string]string) error
+}
+
+// NewLogstashClient creates a new client for the Logstash node reachable at the given URL.
+//
+// If dialer is not nil, it will be used to create new TCP connections
+func NewLogstashClient(dialer net.Dialer, url string) Client {
+	transportConfig := http.Transport{}
+	// use the custom dialer if provided
+	if dialer != nil {
+		transportConfig.DialContext = dialer.DialContext
+	}
+	return &baseClient{
+		Endpoint:  url,
+		transport: &transportConfig,
+		HTTP: &http.Client{
+			Transport: &transportConfig,
+		},
+	}
+}
+
+type baseClient struct {
+	HTTP      *http.Client
+	transport *http.Transport
+	Endpoint  string
+}
+
+// Close idle connections in the underlying http client.
+func (c *baseClient) Close() {
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
+}
+
+// Equal returns true if other can be considered as the same client.
+func (c *baseClient) Equal(other Client) bool {
+	otherBase, ok := other.(*baseClient)
+	if !ok || otherBase == nil {
+		return false
+	}
+	return c.Endpoint == otherBase.Endpoint
+}
+
+func (c *baseClient) GetNodeStats(ctx context.Context) (NodeStats, error) {
+	var stats NodeStats
+	return stats, c.get(ctx, "/_node/stats", &stats)
+}
+
+func (c *baseClient) GetRawNodeStats(ctx context.Context) (json.RawMessage, error) {
+	var stats json.RawMessage
+	return stats, c.get(ctx, "/_node/stats", &stats)
+}
+
+func (c *baseClient) GetHotThreads(ctx context.Context) (json.RawMessage, error) {
+	var hotThreads json.RawMessage
+	return hotThreads, c.get(ctx, "/_node/hot_threads", &hotThreads)
+}
+
+func (c *baseClient) SetLogLevels(ctx context.Context, levels map[string]string) error {
+	settings := make(map[string]string, len(levels))
+	for logger, level := range levels {
+		settings["logger."+logger] = level
+	}
+	body, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return c.request(ctx, http.MethodPut, "/_node/logging", bytes.NewReader(body), nil)
+}
+
+func (c *baseClient) get(ctx context.Context, path string, out interface{}) error {
+	return c.request(ctx, http.MethodGet, path, http.NoBody, out)
+}
+
+// request performs a new http request against the Logstash API.
+//
+// if responseObj is not nil, it should be a pointer to an struct. the response body will be unmarshalled from JSON
+// into this struct.
+func (c *baseClient) request(ctx context.Context, method string, path string, body io.Reader, responseObj interface{}) error {
+	request, err := http.NewRequest(method, stringsutil.Concat(c.Endpoint, path), body)
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := c.HTTP.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	if responseObj != nil {
+		if err := json.NewDecoder(resp.Body).Decode(responseObj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// APIError is a non 2xx response from the Logstash API
+type APIError struct {
+	StatusCode int
+	Status     string
+}
+
+// Error() implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("logstash api error: %s", e.Status)
+}
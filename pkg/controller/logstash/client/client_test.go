@@ -0,0 +1,99 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const sampleNodeStats = `{
+  "id": "2fcd4a62-1ae2-4d0a-9a53-43e7a4a7dc25",
+  "name": "logstash-sample-ls-7b9c4f5d8-x2x7q",
+  "version": "7.4.0",
+  "status": "green",
+  "events": {"in": 120, "filtered": 120, "out": 118},
+  "pipelines": {
+    "main": {
+      "events": {"in": 100, "filtered": 100, "out": 98},
+      "plugins": {
+        "inputs": [{"id": "beats", "name": "beats", "events": {"out": 100}}],
+        "filters": [
+          {"id": "grok", "name": "grok", "events": {"in": 100, "out": 100}, "matches": 90, "failures": 10},
+          {"id": "date", "name": "date", "events": {"in": 100, "out": 100}, "matches": 100}
+        ],
+        "outputs": [
+          {
+            "id": "es", "name": "elasticsearch", "events": {"in": 100, "out": 98},
+            "documents": {"successes": 98, "non_retryable_failures": 2},
+            "bulk_requests": {"successes": 10, "with_errors": 1}
+          }
+        ]
+      },
+      "reloads": {"successes": 1, "failures": 0},
+      "queue": {"type": "persisted", "events_count": 3, "queue_size_in_bytes": 4096},
+      "dead_letter_queue": {"queue_size_in_bytes": 1024}
+    },
+    "other": {
+      "events": {"in": 20, "filtered": 20, "out": 20},
+      "plugins": {
+        "filters": [{"id": "grok2", "name": "grok", "failures": 5}],
+        "outputs": [{"id": "stdout", "name": "stdout", "events": {"in": 20, "out": 20}}]
+      },
+      "queue": {"type": "memory"},
+      "dead_letter_queue": {"queue_size_in_bytes": 1}
+    }
+  }
+}`
+
+func TestClient_GetNodeStats(t *testing.T) {
+	c := NewMockClient(func(req *http.Request) *http.Response {
+		require.Equal(t, "/_node/stats", req.URL.Path)
+		return NewMockResponse(200, req, sampleNodeStats)
+	})
+	stats, err := c.GetNodeStats(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "7.4.0", stats.Version)
+	require.Len(t, stats.Pipelines, 2)
+	require.Equal(t, int64(3), stats.Pipelines["main"].Queue.EventsCount)
+	require.Equal(t, int64(15), stats.FilterFailures())
+	require.Equal(t, int64(3), stats.OutputErrors())
+	require.Equal(t, int64(1025), stats.DeadLetterQueueBytes())
+}
+
+func TestClient_GetNodeStats_Error(t *testing.T) {
+	c := NewMockClient(func(req *http.Request) *http.Response {
+		return NewMockResponse(503, req, "")
+	})
+	_, err := c.GetNodeStats(context.Background())
+	require.Error(t, err)
+}
+
+func TestClient_SetLogLevels(t *testing.T) {
+	c := NewMockClient(func(req *http.Request) *http.Response {
+		require.Equal(t, http.MethodPut, req.Method)
+		require.Equal(t, "/_node/logging", req.URL.Path)
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"logger.logstash.outputs.elasticsearch":"DEBUG"}`, string(body))
+		return NewMockResponse(200, req, `{"acknowledged":true}`)
+	})
+	require.NoError(t, c.SetLogLevels(context.Background(), map[string]string{"logstash.outputs.elasticsearch": "DEBUG"}))
+}
+
+func TestClient_GetHotThreads(t *testing.T) {
+	hotThreads := `{"hot_threads":{"threads":[{"name":"[main]>worker0","percent_of_cpu_time":12.5}]}}`
+	c := NewMockClient(func(req *http.Request) *http.Response {
+		require.Equal(t, "/_node/hot_threads", req.URL.Path)
+		return NewMockResponse(200, req, hotThreads)
+	})
+	raw, err := c.GetHotThreads(context.Background())
+	require.NoError(t, err)
+	require.JSONEq(t, hotThreads, string(raw))
+}
@@ -0,0 +1,127 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package client
+
+// NodeStats partially models the response from a request to /_node/stats
+type NodeStats struct {
+	ID        string                   `json:"id"`
+	Name      string                   `json:"name"`
+	Version   string                   `json:"version"`
+	Status    string                   `json:"status"`
+	Events    EventsStats              `json:"events"`
+	JVM       JVMStats                 `json:"jvm"`
+	Pipelines map[string]PipelineStats `json:"pipelines"`
+}
+
+// JVMStats partially models the JVM statistics of a node.
+type JVMStats struct {
+	Mem struct {
+		HeapUsedInBytes int64 `json:"heap_used_in_bytes"`
+		HeapMaxInBytes  int64 `json:"heap_max_in_bytes"`
+	} `json:"mem"`
+}
+
+// EventsStats counts the events flowing through a node or a pipeline.
+type EventsStats struct {
+	In       int64 `json:"in"`
+	Filtered int64 `json:"filtered"`
+	Out      int64 `json:"out"`
+}
+
+// PipelineStats partially models the statistics of a single pipeline.
+type PipelineStats struct {
+	Events          EventsStats          `json:"events"`
+	Plugins         PluginsStats         `json:"plugins"`
+	Reloads         ReloadsStats         `json:"reloads"`
+	Queue           QueueStats           `json:"queue"`
+	DeadLetterQueue DeadLetterQueueStats `json:"dead_letter_queue"`
+}
+
+// PluginsStats groups the statistics of the plugins of a pipeline by plugin type.
+type PluginsStats struct {
+	Inputs  []PluginStats `json:"inputs"`
+	Filters []PluginStats `json:"filters"`
+	Outputs []PluginStats `json:"outputs"`
+}
+
+// PluginStats partially models the statistics of a single plugin instance.
+type PluginStats struct {
+	ID       string      `json:"id"`
+	Name     string      `json:"name"`
+	Events   EventsStats `json:"events"`
+	Failures int64       `json:"failures"`
+	// Documents is only reported by the elasticsearch output.
+	Documents *struct {
+		Successes            int64 `json:"successes"`
+		NonRetryableFailures int64 `json:"non_retryable_failures"`
+	} `json:"documents,omitempty"`
+	// BulkRequests is only reported by the elasticsearch output.
+	BulkRequests *struct {
+		Successes  int64 `json:"successes"`
+		WithErrors int64 `json:"with_errors"`
+		Failures   int64 `json:"failures"`
+	} `json:"bulk_requests,omitempty"`
+}
+
+// Errors returns the number of errors reported by an output plugin.
+func (p PluginStats) Errors() int64 {
+	errors := p.Failures
+	if p.Documents != nil {
+		errors += p.Documents.NonRetryableFailures
+	}
+	if p.BulkRequests != nil {
+		errors += p.BulkRequests.WithErrors + p.BulkRequests.Failures
+	}
+	return errors
+}
+
+// ReloadsStats counts the configuration reloads of a pipeline.
+type ReloadsStats struct {
+	Successes int64 `json:"successes"`
+	Failures  int64 `json:"failures"`
+}
+
+// QueueStats describes the queue of a pipeline.
+type QueueStats struct {
+	Type             string `json:"type"`
+	EventsCount      int64  `json:"events_count"`
+	QueueSizeInBytes int64  `json:"queue_size_in_bytes"`
+}
+
+// DeadLetterQueueStats describes the dead letter queue of a pipeline.
+type DeadLetterQueueStats struct {
+	QueueSizeInBytes int64 `json:"queue_size_in_bytes"`
+}
+
+// FilterFailures returns the number of failures reported by the filter plugins of all pipelines.
+func (s NodeStats) FilterFailures() int64 {
+	var failures int64
+	for _, p := range s.Pipelines {
+		for _, f := range p.Plugins.Filters {
+			failures += f.Failures
+		}
+	}
+	return failures
+}
+
+// OutputErrors returns the number of errors reported by the output plugins of all pipelines.
+func (s NodeStats) OutputErrors() int64 {
+	var errors int64
+	for _, p := range s.Pipelines {
+		for _, o := range p.Plugins.Outputs {
+			errors += o.Errors()
+		}
+	}
+	return errors
+}
+
+// DeadLetterQueueBytes returns the size of the dead letter queues of all pipelines.
+func (s NodeStats) DeadLetterQueueBytes() int64 {
+	var size int64
+	for _, p := range s.Pipelines {
+		size += p.DeadLetterQueue.QueueSizeInBytes
+	}
+	return size
+}
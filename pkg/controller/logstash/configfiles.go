@@ -0,0 +1,44 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"hash"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/configmap"
+	lsname "github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reconcileConfigFiles reconciles the config map holding the customized jvm.options and log4j2.properties files of
+// the given Logstash, or deletes it if there is none.
+func (d *driver) reconcileConfigFiles(ls lstype.Logstash) error {
+	if expected, ok := configmap.NewConfigFilesConfigMap(ls); ok {
+		return configmap.ReconcileConfigMap(d.client, d.scheme, ls, expected)
+	}
+	configFiles := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ls.Namespace, Name: lsname.ConfigFilesConfigMap(ls.Name)},
+	}
+	if err := d.client.Delete(&configFiles); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// writeConfigFilesChecksum writes the customized jvm.options and log4j2.properties files of the given Logstash to the
+// given configuration checksum, so that Logstash pods are rotated when they change. Logstash only reads them on
+// startup, and the files being mounted through sub paths are not updated in running pods anyway.
+func writeConfigFilesChecksum(ls lstype.Logstash, configChecksum hash.Hash) {
+	configFiles, ok := configmap.NewConfigFilesConfigMap(ls)
+	if !ok {
+		return
+	}
+	for _, file := range []string{configmap.JVMOptionsFile, configmap.Log4j2PropertiesFile} {
+		_, _ = configChecksum.Write([]byte(configFiles.Data[file]))
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package configmap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// JVMOptionsFile is the key of the jvm.options file in the config files config map.
+	JVMOptionsFile = "jvm.options"
+	// Log4j2PropertiesFile is the key of the log4j2.properties file in the config files config map.
+	Log4j2PropertiesFile = "log4j2.properties"
+)
+
+// defaultJVMOptions are the options of the default jvm.options file of Logstash that do not depend on the JVM
+// version. The heap size is set through the LS_JAVA_OPTS environment variable.
+var defaultJVMOptions = []string{
+	"-Djava.awt.headless=true",
+	"-Dfile.encoding=UTF-8",
+	"-Djruby.compile.invokedynamic=true",
+	"-Djruby.jit.threshold=0",
+	"-Djruby.regexp.interruptible=true",
+	"-XX:+HeapDumpOnOutOfMemoryError",
+	"-Djava.security.egd=file:/dev/urandom",
+	"-Dlog4j2.isThreadContextMapInheritable=true",
+}
+
+// log4j2Properties is the default log4j2.properties file of the Logstash docker image, logging to the console with
+// the level and format of the log.level and log.format settings.
+const log4j2Properties = `status = error
+name = LogstashPropertiesConfig
+
+appender.console.type = Console
+appender.console.name = plain_console
+appender.console.layout.type = PatternLayout
+appender.console.layout.pattern = [%d{ISO8601}][%-5p][%-25c] %m%n
+
+appender.json_console.type = Console
+appender.json_console.name = json_console
+appender.json_console.layout.type = JSONLayout
+appender.json_console.layout.compact = true
+appender.json_console.layout.eventEol = true
+
+rootLogger.level = ${sys:ls.log.level}
+rootLogger.appenderRef.console.ref = ${sys:ls.log.format}_console
+`
+
+// UsesJVMOptionsFile returns true if the given Logstash reads its JVM options from the config files config map.
+func UsesJVMOptionsFile(ls v1beta1.Logstash) bool {
+	return len(ls.Spec.JVMOptions) > 0
+}
+
+// UsesLog4j2PropertiesFile returns true if the given Logstash reads its logging configuration from the config files
+// config map.
+func UsesLog4j2PropertiesFile(ls v1beta1.Logstash) bool {
+	return len(ls.Spec.Logging.Loggers) > 0
+}
+
+// NewConfigFilesConfigMap builds the config map holding the jvm.options and log4j2.properties files of the given
+// Logstash, if customized. It returns false if there is no customized file.
+func NewConfigFilesConfigMap(ls v1beta1.Logstash) (corev1.ConfigMap, bool) {
+	data := map[string]string{}
+	if UsesJVMOptionsFile(ls) {
+		options := append(append([]string{}, defaultJVMOptions...), ls.Spec.JVMOptions...)
+		data[JVMOptionsFile] = strings.Join(options, "\n") + "\n"
+	}
+	if UsesLog4j2PropertiesFile(ls) {
+		data[Log4j2PropertiesFile] = log4j2Properties + loggersProperties(ls.Spec.Logging.Loggers)
+	}
+	if len(data) == 0 {
+		return corev1.ConfigMap{}, false
+	}
+	return NewConfigMapWithData(
+		types.NamespacedName{Namespace: ls.Namespace, Name: name.ConfigFilesConfigMap(ls.Name)},
+		data,
+	), true
+}
+
+// loggersProperties returns the log4j2 properties overriding the level of the given loggers.
+func loggersProperties(loggers map[string]string) string {
+	names := make([]string, 0, len(loggers))
+	for logger := range loggers {
+		names = append(names, logger)
+	}
+	sort.Strings(names)
+	var properties strings.Builder
+	for i, logger := range names {
+		properties.WriteString(fmt.Sprintf("\nlogger.override%d.name = %s\n", i, logger))
+		properties.WriteString(fmt.Sprintf("logger.override%d.level = %s\n", i, loggers[logger]))
+	}
+	return properties.String()
+}
@@ -0,0 +1,47 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package configmap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewConfigFilesConfigMap(t *testing.T) {
+	ls := v1beta1.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+
+	// nothing customized
+	ls.Spec.Logging = v1beta1.LoggingSpec{Level: "debug", Format: "json"}
+	_, ok := NewConfigFilesConfigMap(ls)
+	require.False(t, ok)
+
+	ls.Spec.JVMOptions = []string{"-XX:+UseG1GC", "-Xlog:gc*:file=/tmp/gc.log"}
+	ls.Spec.Logging.Loggers = map[string]string{
+		"logstash.outputs.elasticsearch": "debug",
+		"logstash.filters.grok":          "trace",
+	}
+	cm, ok := NewConfigFilesConfigMap(ls)
+	require.True(t, ok)
+	require.Equal(t, "ls-ls-config", cm.Name)
+	require.Equal(t, "ns", cm.Namespace)
+
+	jvmOptions := cm.Data[JVMOptionsFile]
+	require.True(t, strings.HasPrefix(jvmOptions, "-Djava.awt.headless=true\n"))
+	require.True(t, strings.HasSuffix(jvmOptions, "\n-XX:+UseG1GC\n-Xlog:gc*:file=/tmp/gc.log\n"))
+
+	log4j2 := cm.Data[Log4j2PropertiesFile]
+	require.True(t, strings.HasPrefix(log4j2, log4j2Properties))
+	require.Equal(t, `
+logger.override0.name = logstash.filters.grok
+logger.override0.level = trace
+
+logger.override1.name = logstash.outputs.elasticsearch
+logger.override1.level = debug
+`, strings.TrimPrefix(log4j2, log4j2Properties))
+}
@@ -6,12 +6,15 @@ package configmap
 
 import (
 	"bytes"
-	"html/template"
+	"fmt"
+	"strings"
+	"text/template"
 
 	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pipeline"
 	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,6 +34,15 @@ func NewConfigMapWithData(ls types.NamespacedName, data map[string]string) corev
 	}
 }
 
+const (
+	// PasswordEnvVar is the environment variable holding the password of the association user in the Logstash
+	// container, referenced by the centrally managed pipelines.
+	PasswordEnvVar = "ES_PASSWORD"
+	// APIKeyEnvVar is the environment variable holding the API key of the association user in the Logstash container,
+	// referenced by the centrally managed pipelines.
+	APIKeyEnvVar = "ES_API_KEY"
+)
+
 var inputConfTemplateStr = `input {
   # udp {
   #   port => 1514
@@ -59,17 +71,72 @@ var outputConfTemplateStr = `output {
 	# stdout { codec => rubydebug }
 	elasticsearch {
 		hosts => ["{{ .ElasticsearchHost }}"]
+{{- if .APIKey }}
+		api_key => "{{ .APIKey }}"
+{{- else }}
 		user => "{{ .Username }}"
 		password => "{{ .Password }}"
+{{- end }}
+{{- if .DataStream }}
+		data_stream => "true"
+		data_stream_type => "{{ .DataStream.Type }}"
+		data_stream_dataset => "{{ .DataStream.Dataset }}"
+		data_stream_namespace => "{{ .DataStream.Namespace }}"
+{{- else if .RolloverAlias }}
+		manage_template => false
+		ilm_enabled => "true"
+		ilm_rollover_alias => "{{ .RolloverAlias }}"
+		ilm_pattern => "{now/d}-000001"
+		ilm_policy => "{{ .RolloverAlias }}"
+{{- else }}
 		manage_template => false
 		index => "%{[@metadata][beat]}-%{+YYYY.MM.dd}"
-		ssl => true
-		cacert => "/usr/share/kibana/config/elasticsearch-certs/tls.crt"
+{{- end }}
+{{- range $name, $value := .SSLSettings }}
+		{{ $name }} => {{ $value }}
+{{- end }}
+	}
+}`
+
+// deadLetterQueueConfTemplateStr is the configuration of the pipeline reading the dead letter queues of the other
+// pipelines, and indexing the failed events along with the reason of their failure.
+var deadLetterQueueConfTemplateStr = `input {
+{{- range .PipelineIDs }}
+	dead_letter_queue {
+		path => "{{ $.Path }}"
+		pipeline_id => "{{ . }}"
+		commit_offsets => true
+	}
+{{- end }}
+}
+filter {
+	mutate {
+		add_field => {
+			"[dead_letter_queue][pipeline_id]" => "%{[@metadata][dead_letter_queue][pipeline_id]}"
+			"[dead_letter_queue][plugin_id]" => "%{[@metadata][dead_letter_queue][plugin_id]}"
+			"[dead_letter_queue][reason]" => "%{[@metadata][dead_letter_queue][reason]}"
+		}
+	}
+}
+output {
+	elasticsearch {
+		hosts => ["{{ .ElasticsearchHost }}"]
+{{- if .APIKey }}
+		api_key => "{{ .APIKey }}"
+{{- else }}
+		user => "{{ .Username }}"
+		password => "{{ .Password }}"
+{{- end }}
+		index => "{{ .Index }}"
+{{- range $name, $value := .SSLSettings }}
+		{{ $name }} => {{ $value }}
+{{- end }}
 	}
 }`
 
 var inputConfTemplate *template.Template
 var outputConfTemplate *template.Template
+var deadLetterQueueConfTemplate *template.Template
 
 func init() {
 	var err error
@@ -81,48 +148,227 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	deadLetterQueueConfTemplate, err = template.New("dead_letter_queue").Parse(deadLetterQueueConfTemplateStr)
+	if err != nil {
+		panic(err)
+	}
 }
 
 type confStruct struct {
 	ElasticsearchHost string
 	Username          string
 	Password          string
+	// APIKey is the API key, as `id:api_key`, used instead of the user and password if set.
+	APIKey      string
+	SSLSettings map[string]string
+	// RolloverAlias is the rollover alias the default output writes to, if any.
+	RolloverAlias string
+	// DataStream is the data stream the default output writes to, if any.
+	DataStream *v1beta1.DataStreamSpec
 }
 
-// ReconcileScriptsConfigMap reconciles a configmap containing pipeline input
-// and output files.
-func ReconcilePipelineConfigMap(c k8s.Client, scheme *runtime.Scheme, ls v1beta1.Logstash) error {
+// withIndexBootstrap makes the default output write to the rollover alias or data stream installed by the last
+// successful index bootstrap of the given Logstash, if any.
+func (c *confStruct) withIndexBootstrap(ls v1beta1.Logstash) {
+	if !ls.Spec.IndexBootstrap.Enabled || ls.Status.IndexBootstrap == nil {
+		return
+	}
+	switch ls.Status.IndexBootstrap.Mode {
+	case v1beta1.IndexBootstrapDataStream:
+		if ls.Spec.IndexBootstrap.DataStream != nil {
+			dataStream := ls.Spec.IndexBootstrap.DataStream.WithDefaults()
+			c.DataStream = &dataStream
+		}
+	case v1beta1.IndexBootstrapRolloverAlias:
+		c.RolloverAlias = ls.Spec.IndexBootstrap.GetName()
+	}
+}
+
+type deadLetterQueueConfStruct struct {
+	confStruct
+	Path        string
+	PipelineIDs []string
+	Index       string
+}
+
+// defaultOutputIndexPattern matches the daily indices the default output writes to without index bootstrap, named
+// after the beat sending the events.
+const defaultOutputIndexPattern = "*beat-*"
+
+// OutputIndexPatterns returns the patterns of the indices the elasticsearch outputs of the pipelines managed by the
+// operator write to: the default output, with or without index bootstrap, and the dead letter queue reprocessing.
+func OutputIndexPatterns(ls v1beta1.Logstash) []string {
+	patterns := []string{defaultOutputIndexPattern}
+	if bootstrap := ls.Spec.IndexBootstrap; bootstrap.Enabled {
+		patterns = append(patterns, bootstrap.GetName(), bootstrap.GetName()+"-*")
+		if bootstrap.DataStream != nil {
+			name := bootstrap.DataStream.Name()
+			patterns = append(patterns, name, ".ds-"+name+"-*")
+		}
+	}
+	if ls.Spec.DeadLetterQueue.ReprocessingEnabled() {
+		index := ls.Spec.DeadLetterQueue.Reprocessing.GetIndex()
+		// the index may reference fields or the date of the events
+		if i := strings.Index(index, "%{"); i >= 0 {
+			index = index[:i] + "*"
+		}
+		patterns = append(patterns, index)
+	}
+	return patterns
+}
+
+// NewPipelineConfigMap builds the config map containing pipeline input and output files.
+// The given SSL settings are applied to the default elasticsearch output.
+// If the Logstash has a topology, selects pipelines or reprocesses its dead letter queues, the config map also contains
+// the configuration of these pipelines and the pipelines.yml file declaring them.
+func NewPipelineConfigMap(
+	c k8s.Client,
+	ls v1beta1.Logstash,
+	pipelines []v1beta1.LogstashPipeline,
+	sslSettings map[string]string,
+) (corev1.ConfigMap, error) {
 	username, password, err := association.ElasticsearchAuthSettings(c, &ls)
 	if err != nil {
-		return err
+		return corev1.ConfigMap{}, err
+	}
+	conf := confStruct{
+		ElasticsearchHost: ls.AssociationConf().GetURL(),
+		SSLSettings:       sslSettings,
 	}
+	if ls.AssociationConf().IsAPIKey() {
+		conf.APIKey = password
+	} else {
+		conf.Username, conf.Password = username, password
+	}
+	return newPipelineConfigMap(ls, conf, pipelines)
+}
+
+// NewCentrallyManagedPipelineConfigMap builds the same config map as NewPipelineConfigMap, except that the elasticsearch
+// outputs reference the password or API key of the association user through the environment variable set by
+// CredentialsEnvVar, rather than including it. It is meant to be stored in Elasticsearch for central management.
+func NewCentrallyManagedPipelineConfigMap(
+	ls v1beta1.Logstash,
+	pipelines []v1beta1.LogstashPipeline,
+	sslSettings map[string]string,
+) (corev1.ConfigMap, error) {
 	conf := confStruct{
-		ls.AssociationConf().GetURL(),
-		username,
-		password,
+		ElasticsearchHost: ls.AssociationConf().GetURL(),
+		SSLSettings:       sslSettings,
+	}
+	if envVar, exists := CredentialsEnvVar(ls); exists {
+		reference := fmt.Sprintf("${%s}", envVar.Name)
+		if ls.AssociationConf().IsAPIKey() {
+			conf.APIKey = reference
+		} else {
+			conf.Username, conf.Password = ls.AssociationConf().GetAuthSecretKey(), reference
+		}
+	}
+	return newPipelineConfigMap(ls, conf, pipelines)
+}
+
+// CredentialsEnvVar returns the environment variable holding the password or API key of the association user, read
+// from the association secret, if the association uses authentication.
+func CredentialsEnvVar(ls v1beta1.Logstash) (corev1.EnvVar, bool) {
+	if !ls.AssociationConf().AuthIsConfigured() {
+		return corev1.EnvVar{}, false
 	}
+	name := PasswordEnvVar
+	if ls.AssociationConf().IsAPIKey() {
+		name = APIKeyEnvVar
+	}
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: ls.AssociationConf().GetAuthSecretName()},
+				Key:                  ls.AssociationConf().GetAuthSecretKey(),
+			},
+		},
+	}, true
+}
+
+func newPipelineConfigMap(ls v1beta1.Logstash, conf confStruct, pipelines []v1beta1.LogstashPipeline) (corev1.ConfigMap, error) {
+	conf.withIndexBootstrap(ls)
 	if ls.Spec.InputConf == "" {
 		var buf bytes.Buffer
 		if err := inputConfTemplate.Execute(&buf, conf); err != nil {
-			return err
+			return corev1.ConfigMap{}, err
 		}
 		ls.Spec.InputConf = buf.String()
 	}
 	if ls.Spec.OutputConf == "" {
 		var buf bytes.Buffer
 		if err := outputConfTemplate.Execute(&buf, conf); err != nil {
-			return err
+			return corev1.ConfigMap{}, err
 		}
 		ls.Spec.OutputConf = buf.String()
 	}
 
-	pipelineConfigmap := NewConfigMapWithData(
+	data := map[string]string{
+		"input_main.conf":  ls.Spec.InputConf,
+		"output_main.conf": ls.Spec.OutputConf,
+	}
+	if pipeline.UsesPipelinesFile(ls) {
+		pipelines = append(pipeline.TopologyPipelines(ls), pipelines...)
+		if ls.Spec.DeadLetterQueue.ReprocessingEnabled() {
+			dlqPipeline, err := newDeadLetterQueuePipeline(ls, conf, pipelines)
+			if err != nil {
+				return corev1.ConfigMap{}, err
+			}
+			pipelines = append(pipelines, dlqPipeline)
+		}
+		pipelinesFile, err := pipeline.PipelinesFileData(pipelines)
+		if err != nil {
+			return corev1.ConfigMap{}, err
+		}
+		data[pipeline.PipelinesFile] = pipelinesFile
+		for _, p := range pipelines {
+			data[pipeline.ConfigKey(p.GetPipelineID())] = p.Spec.Config
+		}
+	}
+
+	return NewConfigMapWithData(
 		types.NamespacedName{Namespace: ls.Namespace, Name: name.PipelineConfigMap(ls.Name)},
-		map[string]string{
-			"input_main.conf":  ls.Spec.InputConf,
-			"output_main.conf": ls.Spec.OutputConf,
+		data,
+	), nil
+}
+
+// newDeadLetterQueuePipeline returns the pipeline reprocessing the dead letter queues of the main pipeline and of
+// the given pipelines.
+func newDeadLetterQueuePipeline(ls v1beta1.Logstash, conf confStruct, pipelines []v1beta1.LogstashPipeline) (v1beta1.LogstashPipeline, error) {
+	dlqConf := deadLetterQueueConfStruct{
+		confStruct:  conf,
+		Path:        pipeline.DeadLetterQueuePath(ls),
+		PipelineIDs: []string{pipeline.MainPipelineID},
+		Index:       ls.Spec.DeadLetterQueue.Reprocessing.GetIndex(),
+	}
+	for _, p := range pipelines {
+		dlqConf.PipelineIDs = append(dlqConf.PipelineIDs, p.GetPipelineID())
+	}
+	var buf bytes.Buffer
+	if err := deadLetterQueueConfTemplate.Execute(&buf, dlqConf); err != nil {
+		return v1beta1.LogstashPipeline{}, err
+	}
+	return v1beta1.LogstashPipeline{
+		Spec: v1beta1.LogstashPipelineSpec{
+			PipelineID: pipeline.DeadLetterQueuePipelineID,
+			Config:     buf.String(),
 		},
-	)
+	}, nil
+}
 
+// ReconcilePipelineConfigMap reconciles a configmap containing pipeline input
+// and output files.
+func ReconcilePipelineConfigMap(
+	c k8s.Client,
+	scheme *runtime.Scheme,
+	ls v1beta1.Logstash,
+	pipelines []v1beta1.LogstashPipeline,
+	sslSettings map[string]string,
+) error {
+	pipelineConfigmap, err := NewPipelineConfigMap(c, ls, pipelines, sslSettings)
+	if err != nil {
+		return err
+	}
 	return ReconcileConfigMap(c, scheme, ls, pipelineConfigmap)
 }
@@ -0,0 +1,309 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package configmap
+
+import (
+	"testing"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewPipelineConfigMap(t *testing.T) {
+	ls := v1beta1.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+	ls.SetAssociationConf(&commonv1beta1.AssociationConf{URL: "https://es-http:9200"})
+	tests := []struct {
+		name            string
+		sslSettings     map[string]string
+		indexBootstrap  v1beta1.IndexBootstrapSpec
+		bootstrapStatus *v1beta1.IndexBootstrapStatus
+		wantOutput      string
+	}{
+		{
+			name: "no ssl settings",
+			wantOutput: `output {
+	# stdout { codec => rubydebug }
+	elasticsearch {
+		hosts => ["https://es-http:9200"]
+		user => ""
+		password => ""
+		manage_template => false
+		index => "%{[@metadata][beat]}-%{+YYYY.MM.dd}"
+	}
+}`,
+		},
+		{
+			name: "ssl settings",
+			sslSettings: map[string]string{
+				"ssl_enabled":                 "true",
+				"ssl_certificate_authorities": `["/path/tls.crt"]`,
+			},
+			wantOutput: `output {
+	# stdout { codec => rubydebug }
+	elasticsearch {
+		hosts => ["https://es-http:9200"]
+		user => ""
+		password => ""
+		manage_template => false
+		index => "%{[@metadata][beat]}-%{+YYYY.MM.dd}"
+		ssl_certificate_authorities => ["/path/tls.crt"]
+		ssl_enabled => true
+	}
+}`,
+		},
+		{
+			name:            "index bootstrap not done yet",
+			indexBootstrap:  v1beta1.IndexBootstrapSpec{Enabled: true},
+			bootstrapStatus: nil,
+			wantOutput: `output {
+	# stdout { codec => rubydebug }
+	elasticsearch {
+		hosts => ["https://es-http:9200"]
+		user => ""
+		password => ""
+		manage_template => false
+		index => "%{[@metadata][beat]}-%{+YYYY.MM.dd}"
+	}
+}`,
+		},
+		{
+			name:           "rollover alias",
+			indexBootstrap: v1beta1.IndexBootstrapSpec{Enabled: true, Name: "events"},
+			bootstrapStatus: &v1beta1.IndexBootstrapStatus{
+				Phase: v1beta1.IndexBootstrapReady,
+				Mode:  v1beta1.IndexBootstrapRolloverAlias,
+			},
+			wantOutput: `output {
+	# stdout { codec => rubydebug }
+	elasticsearch {
+		hosts => ["https://es-http:9200"]
+		user => ""
+		password => ""
+		manage_template => false
+		ilm_enabled => "true"
+		ilm_rollover_alias => "events"
+		ilm_pattern => "{now/d}-000001"
+		ilm_policy => "events"
+	}
+}`,
+		},
+		{
+			name: "data stream",
+			indexBootstrap: v1beta1.IndexBootstrapSpec{
+				Enabled:    true,
+				DataStream: &v1beta1.DataStreamSpec{Dataset: "nginx"},
+			},
+			bootstrapStatus: &v1beta1.IndexBootstrapStatus{
+				Phase: v1beta1.IndexBootstrapFailed,
+				Mode:  v1beta1.IndexBootstrapDataStream,
+			},
+			wantOutput: `output {
+	# stdout { codec => rubydebug }
+	elasticsearch {
+		hosts => ["https://es-http:9200"]
+		user => ""
+		password => ""
+		data_stream => "true"
+		data_stream_type => "logs"
+		data_stream_dataset => "nginx"
+		data_stream_namespace => "default"
+	}
+}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := *ls.DeepCopy()
+			ls.Spec.IndexBootstrap = tt.indexBootstrap
+			ls.Status.IndexBootstrap = tt.bootstrapStatus
+			cm, err := NewPipelineConfigMap(k8s.WrapClient(fake.NewFakeClient()), ls, nil, tt.sslSettings)
+			require.NoError(t, err)
+			require.Equal(t, "ls-ls-pipeline", cm.Name)
+			require.Equal(t, tt.wantOutput, cm.Data["output_main.conf"])
+		})
+	}
+}
+
+func TestNewPipelineConfigMap_APIKey(t *testing.T) {
+	ls := v1beta1.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+	ls.SetAssociationConf(&commonv1beta1.AssociationConf{
+		URL:            "https://es-http:9200",
+		AuthSecretName: "ls-logstash-api-key",
+		AuthSecretKey:  "ns-ls-logstash-api-key",
+		AuthMode:       commonv1beta1.APIKeyAuthMode,
+	})
+	c := k8s.WrapClient(fake.NewFakeClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls-logstash-api-key"},
+		Data:       map[string][]byte{"ns-ls-logstash-api-key": []byte("id:key")},
+	}))
+	cm, err := NewPipelineConfigMap(c, ls, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, `output {
+	# stdout { codec => rubydebug }
+	elasticsearch {
+		hosts => ["https://es-http:9200"]
+		api_key => "id:key"
+		manage_template => false
+		index => "%{[@metadata][beat]}-%{+YYYY.MM.dd}"
+	}
+}`, cm.Data["output_main.conf"])
+}
+
+func TestNewPipelineConfigMap_SelectedPipelines(t *testing.T) {
+	ls := v1beta1.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+	ls.SetAssociationConf(&commonv1beta1.AssociationConf{URL: "https://es-http:9200"})
+	pipelines := []v1beta1.LogstashPipeline{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "team-a"},
+			Spec:       v1beta1.LogstashPipelineSpec{Config: "input { generator {} }"},
+		},
+	}
+
+	// without selector, only the main pipeline is configured
+	cm, err := NewPipelineConfigMap(k8s.WrapClient(fake.NewFakeClient()), ls, pipelines, nil)
+	require.NoError(t, err)
+	require.Len(t, cm.Data, 2)
+
+	ls.Spec.PipelineSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	cm, err = NewPipelineConfigMap(k8s.WrapClient(fake.NewFakeClient()), ls, pipelines, nil)
+	require.NoError(t, err)
+	require.Len(t, cm.Data, 4)
+	require.Equal(t, "input { generator {} }", cm.Data["pipeline.team-a.conf"])
+	require.Contains(t, cm.Data["pipelines.yml"], "pipeline.id: team-a")
+}
+
+func TestNewPipelineConfigMap_DeadLetterQueueReprocessing(t *testing.T) {
+	ls := v1beta1.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+	ls.SetAssociationConf(&commonv1beta1.AssociationConf{URL: "https://es-http:9200"})
+	ls.Spec.Topology = []v1beta1.TopologyPipeline{{Name: "collector", Config: "output { stdout {} }"}}
+	ls.Spec.DeadLetterQueue = v1beta1.DeadLetterQueueSpec{
+		Enabled:      true,
+		Reprocessing: &v1beta1.DeadLetterQueueReprocessing{Index: "failed"},
+	}
+
+	cm, err := NewPipelineConfigMap(k8s.WrapClient(fake.NewFakeClient()), ls, nil, map[string]string{"ssl": "true"})
+	require.NoError(t, err)
+	require.Contains(t, cm.Data["pipelines.yml"], "pipeline.id: dead_letter_queue")
+	require.Equal(t, `input {
+	dead_letter_queue {
+		path => "/usr/share/logstash/data/dead_letter_queue"
+		pipeline_id => "main"
+		commit_offsets => true
+	}
+	dead_letter_queue {
+		path => "/usr/share/logstash/data/dead_letter_queue"
+		pipeline_id => "collector"
+		commit_offsets => true
+	}
+}
+filter {
+	mutate {
+		add_field => {
+			"[dead_letter_queue][pipeline_id]" => "%{[@metadata][dead_letter_queue][pipeline_id]}"
+			"[dead_letter_queue][plugin_id]" => "%{[@metadata][dead_letter_queue][plugin_id]}"
+			"[dead_letter_queue][reason]" => "%{[@metadata][dead_letter_queue][reason]}"
+		}
+	}
+}
+output {
+	elasticsearch {
+		hosts => ["https://es-http:9200"]
+		user => ""
+		password => ""
+		index => "failed"
+		ssl => true
+	}
+}`, cm.Data["pipeline.dead_letter_queue.conf"])
+}
+
+func TestNewCentrallyManagedPipelineConfigMap(t *testing.T) {
+	ls := v1beta1.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+	ls.Spec.DeadLetterQueue = v1beta1.DeadLetterQueueSpec{
+		Enabled:      true,
+		Reprocessing: &v1beta1.DeadLetterQueueReprocessing{Index: "failed"},
+	}
+	ls.SetAssociationConf(&commonv1beta1.AssociationConf{
+		URL:            "https://es-http:9200",
+		AuthSecretName: "ls-logstash-user",
+		AuthSecretKey:  "ns-ls-logstash-user",
+	})
+
+	// the credentials are referenced rather than included
+	cm, err := NewCentrallyManagedPipelineConfigMap(ls, nil, nil)
+	require.NoError(t, err)
+	for _, key := range []string{"output_main.conf", "pipeline.dead_letter_queue.conf"} {
+		require.Contains(t, cm.Data[key], `user => "ns-ls-logstash-user"`)
+		require.Contains(t, cm.Data[key], `password => "${ES_PASSWORD}"`)
+	}
+	envVar, exists := CredentialsEnvVar(ls)
+	require.True(t, exists)
+	require.Equal(t, PasswordEnvVar, envVar.Name)
+	require.Equal(t, "ls-logstash-user", envVar.ValueFrom.SecretKeyRef.Name)
+	require.Equal(t, "ns-ls-logstash-user", envVar.ValueFrom.SecretKeyRef.Key)
+
+	ls.AssociationConf().AuthMode = commonv1beta1.APIKeyAuthMode
+	cm, err = NewCentrallyManagedPipelineConfigMap(ls, nil, nil)
+	require.NoError(t, err)
+	require.Contains(t, cm.Data["output_main.conf"], `api_key => "${ES_API_KEY}"`)
+	envVar, _ = CredentialsEnvVar(ls)
+	require.Equal(t, APIKeyEnvVar, envVar.Name)
+
+	// no credentials without authentication
+	ls.SetAssociationConf(&commonv1beta1.AssociationConf{URL: "https://es-http:9200"})
+	_, exists = CredentialsEnvVar(ls)
+	require.False(t, exists)
+}
+
+func TestOutputIndexPatterns(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v1beta1.LogstashSpec
+		want []string
+	}{
+		{
+			name: "default output",
+			want: []string{"*beat-*"},
+		},
+		{
+			name: "rollover alias",
+			spec: v1beta1.LogstashSpec{IndexBootstrap: v1beta1.IndexBootstrapSpec{Enabled: true, Name: "events"}},
+			want: []string{"*beat-*", "events", "events-*"},
+		},
+		{
+			name: "data stream",
+			spec: v1beta1.LogstashSpec{IndexBootstrap: v1beta1.IndexBootstrapSpec{
+				Enabled:    true,
+				DataStream: &v1beta1.DataStreamSpec{Dataset: "nginx"},
+			}},
+			want: []string{"*beat-*", "logstash", "logstash-*", "logs-nginx-default", ".ds-logs-nginx-default-*"},
+		},
+		{
+			name: "dead letter queue reprocessing",
+			spec: v1beta1.LogstashSpec{DeadLetterQueue: v1beta1.DeadLetterQueueSpec{
+				Enabled:      true,
+				Reprocessing: &v1beta1.DeadLetterQueueReprocessing{},
+			}},
+			want: []string{"*beat-*", "logstash-dlq-*"},
+		},
+		{
+			name: "dead letter queue reprocessing to a static index",
+			spec: v1beta1.LogstashSpec{DeadLetterQueue: v1beta1.DeadLetterQueueSpec{
+				Enabled:      true,
+				Reprocessing: &v1beta1.DeadLetterQueueReprocessing{Index: "failed"},
+			}},
+			want: []string{"*beat-*", "failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, OutputIndexPatterns(v1beta1.Logstash{Spec: tt.spec}))
+		})
+	}
+}
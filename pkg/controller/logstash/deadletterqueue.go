@@ -0,0 +1,106 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/reconciler"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	lsname "github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deadLetterQueueStatusRequeue is the interval at which the dead letter queues size is reported in the status.
+var deadLetterQueueStatusRequeue = 1 * time.Minute
+
+// reconcileDeadLetterQueueClaim creates the persistent volume claim storing the dead letter queues, if configured.
+// The specification of an existing claim is not updated, most of it being immutable.
+func (d *driver) reconcileDeadLetterQueueClaim(ls lstype.Logstash) error {
+	dlq := ls.Spec.DeadLetterQueue
+	if !dlq.Enabled || dlq.VolumeClaimSpec == nil {
+		return nil
+	}
+	expected := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ls.Namespace,
+			Name:      lsname.DeadLetterQueueClaim(ls.Name),
+			Labels:    label.NewLabels(ls.Name),
+		},
+		Spec: *dlq.VolumeClaimSpec.DeepCopy(),
+	}
+	reconciled := &corev1.PersistentVolumeClaim{}
+	return reconciler.ReconcileResource(reconciler.Params{
+		Client:     d.client,
+		Scheme:     d.scheme,
+		Owner:      &ls,
+		Expected:   &expected,
+		Reconciled: reconciled,
+		NeedsUpdate: func() bool {
+			return false
+		},
+		UpdateReconciled: func() {},
+	})
+}
+
+// reconcileDeadLetterQueueSlots assigns a slot to the Logstash pods without one, if the dead letter queues are
+// persisted. Each pod writes its dead letter queues to the directory of its slot on the shared volume, and the lowest
+// slot not held by an existing pod is assigned first: a pod replacing a deleted one takes over its directory, and the
+// number of directories is bounded by the number of pods running at the same time.
+func (d *driver) reconcileDeadLetterQueueSlots(ls lstype.Logstash) error {
+	dlq := ls.Spec.DeadLetterQueue
+	if !dlq.Enabled || dlq.VolumeClaimSpec == nil {
+		return nil
+	}
+	var pods corev1.PodList
+	if err := d.client.List(&pods,
+		client.InNamespace(ls.Namespace),
+		client.MatchingLabels(label.NewLabels(ls.Name)),
+	); err != nil {
+		return err
+	}
+
+	held := map[int]bool{}
+	var unassigned []corev1.Pod
+	for _, p := range pods.Items {
+		if slot, err := strconv.Atoi(p.Annotations[label.DeadLetterQueueSlotAnnotation]); err == nil && slot >= 0 {
+			// terminating pods keep their slot until they are gone, they may still be writing to it
+			held[slot] = true
+			continue
+		}
+		if p.DeletionTimestamp.IsZero() {
+			unassigned = append(unassigned, p)
+		}
+	}
+	sort.Slice(unassigned, func(i, j int) bool {
+		if !unassigned[i].CreationTimestamp.Equal(&unassigned[j].CreationTimestamp) {
+			return unassigned[i].CreationTimestamp.Before(&unassigned[j].CreationTimestamp)
+		}
+		return unassigned[i].Name < unassigned[j].Name
+	})
+
+	slot := 0
+	for _, p := range unassigned {
+		for held[slot] {
+			slot++
+		}
+		held[slot] = true
+		if p.Annotations == nil {
+			p.Annotations = map[string]string{}
+		}
+		p.Annotations[label.DeadLetterQueueSlotAnnotation] = strconv.Itoa(slot)
+		log.V(1).Info("Assigning dead letter queue slot",
+			"namespace", p.Namespace, "pod_name", p.Name, "slot", slot)
+		if err := d.client.Update(&p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
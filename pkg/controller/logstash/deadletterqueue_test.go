@@ -0,0 +1,117 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"testing"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	lsclient "github.com/cloudptio/logstash-operator/pkg/controller/logstash/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/observer"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_driver_reconcileDeadLetterQueueClaim(t *testing.T) {
+	require.NoError(t, lstype.AddToScheme(scheme.Scheme))
+	ls := lstype.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+	c := k8s.WrapClient(fake.NewFakeClientWithScheme(scheme.Scheme))
+	d := driver{client: c, scheme: scheme.Scheme}
+	claimKey := types.NamespacedName{Namespace: "ns", Name: "ls-ls-dlq"}
+
+	// no claim if not persisted
+	ls.Spec.DeadLetterQueue = lstype.DeadLetterQueueSpec{Enabled: true}
+	require.NoError(t, d.reconcileDeadLetterQueueClaim(ls))
+	var claim corev1.PersistentVolumeClaim
+	require.Error(t, c.Get(claimKey, &claim))
+
+	ls.Spec.DeadLetterQueue.VolumeClaimSpec = &corev1.PersistentVolumeClaimSpec{
+		AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+		},
+	}
+	require.NoError(t, d.reconcileDeadLetterQueueClaim(ls))
+	require.NoError(t, c.Get(claimKey, &claim))
+	require.Equal(t, *ls.Spec.DeadLetterQueue.VolumeClaimSpec, claim.Spec)
+}
+
+func Test_driver_reconcileDeadLetterQueueSlots(t *testing.T) {
+	require.NoError(t, lstype.AddToScheme(scheme.Scheme))
+	ls := lstype.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+	ls.Spec.DeadLetterQueue = lstype.DeadLetterQueueSpec{Enabled: true, VolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{}}
+	now := metav1.Now()
+	newPod := func(name string, created int, slot string, terminating bool) runtime.Object {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              name,
+			Labels:            label.NewLabels("ls"),
+			CreationTimestamp: metav1.Unix(int64(created), 0),
+		}}
+		if slot != "" {
+			p.Annotations = map[string]string{label.DeadLetterQueueSlotAnnotation: slot}
+		}
+		if terminating {
+			p.DeletionTimestamp = &now
+		}
+		return &p
+	}
+	c := k8s.WrapClient(fake.NewFakeClientWithScheme(scheme.Scheme,
+		// slot 0 is held by a terminating pod, slot 1 was released by a deleted pod
+		newPod("terminating", 1, "0", true),
+		newPod("running", 2, "2", false),
+		newPod("replacement", 3, "", false),
+		newPod("new", 4, "", false),
+		newPod("other", 5, "", false),
+	))
+	d := driver{client: c, scheme: scheme.Scheme}
+	require.NoError(t, d.reconcileDeadLetterQueueSlots(ls))
+
+	for name, want := range map[string]string{
+		"terminating": "0",
+		"running":     "2",
+		"replacement": "1",
+		"new":         "3",
+		"other":       "4",
+	} {
+		var p corev1.Pod
+		require.NoError(t, c.Get(types.NamespacedName{Namespace: "ns", Name: name}, &p))
+		require.Equal(t, want, p.Annotations[label.DeadLetterQueueSlotAnnotation], name)
+	}
+}
+
+func TestState_UpdateDeadLetterQueueStatus(t *testing.T) {
+	dlqStats := func(size int64) lsclient.NodeStats {
+		return lsclient.NodeStats{Pipelines: map[string]lsclient.PipelineStats{
+			"main": {DeadLetterQueue: lsclient.DeadLetterQueueStats{QueueSizeInBytes: size}},
+		}}
+	}
+	ls := lstype.Logstash{Status: lstype.LogstashStatus{DeadLetterQueueBytes: 10}}
+	ls.Spec.DeadLetterQueue.Enabled = true
+	state := NewState(reconcile.Request{}, &ls)
+
+	// not observed yet: the previous size is kept
+	state.UpdateDeadLetterQueueStatus(observer.State{})
+	require.Equal(t, int64(10), ls.Status.DeadLetterQueueBytes)
+
+	state.UpdateDeadLetterQueueStatus(observer.State{Nodes: map[string]lsclient.NodeStats{
+		"a": dlqStats(100),
+		"b": dlqStats(20),
+	}})
+	require.Equal(t, int64(120), ls.Status.DeadLetterQueueBytes)
+
+	ls.Spec.DeadLetterQueue.Enabled = false
+	state.UpdateDeadLetterQueueStatus(observer.State{})
+	require.Equal(t, int64(0), ls.Status.DeadLetterQueueBytes)
+}
@@ -0,0 +1,236 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/reconciler"
+	lsclient "github.com/cloudptio/logstash-operator/pkg/controller/logstash/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	lsname "github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/observer"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// SetLogLevelsAnnotation holds a JSON object mapping logger names to the level to set on all the running
+	// Logstash nodes, for example {"logstash.outputs.elasticsearch": "DEBUG"}. The levels are not persisted: they are
+	// lost when a node restarts. The annotation is removed once the levels are set, or if it is not a valid JSON object.
+	SetLogLevelsAnnotation = "logstash.k8s.elastic.co/set-log-levels"
+	// CaptureDiagnosticsAnnotation requests the capture of the hot threads and node stats of all the running Logstash
+	// nodes into a timestamped config map owned by the Logstash resource. The annotation is removed once the
+	// diagnostics are captured. Only the latest captures are kept.
+	CaptureDiagnosticsAnnotation = "logstash.k8s.elastic.co/capture-diagnostics"
+
+	diagnosticsTimestampFormat = "20060102-150405"
+
+	// maxDiagnosticsConfigMaps is the number of diagnostics config maps kept for a Logstash resource.
+	maxDiagnosticsConfigMaps = 5
+	// maxDiagnosticsSize is the maximum size of the diagnostics captured in a config map, leaving room for its
+	// metadata below the 1MiB limit of config maps.
+	maxDiagnosticsSize = 1000 * 1024
+	// truncatedMarker ends the diagnostics truncated to fit in a config map.
+	truncatedMarker = "\n[truncated]"
+)
+
+// diagnosticsRequeue is the delay before retrying a diagnostic action while no Logstash node is running.
+var diagnosticsRequeue = 10 * time.Second
+
+// reconcileDiagnostics runs the diagnostic actions requested through the annotations of the given Logstash on its
+// running nodes, and removes the annotations of the completed actions.
+func reconcileDiagnostics(
+	c k8s.Client,
+	scheme *runtime.Scheme,
+	recorder record.EventRecorder,
+	ls *lstype.Logstash,
+	runningNodes observer.NodesFunc,
+) *reconciler.Results {
+	results := &reconciler.Results{}
+	levels, setLevels := ls.Annotations[SetLogLevelsAnnotation]
+	_, capture := ls.Annotations[CaptureDiagnosticsAnnotation]
+	if !setLevels && !capture {
+		return results
+	}
+
+	var completed []string
+	var loggers map[string]string
+	if setLevels {
+		if err := json.Unmarshal([]byte(levels), &loggers); err != nil {
+			// retrying cannot fix an invalid annotation: report it and drop it
+			recorder.Eventf(ls, corev1.EventTypeWarning, events.EventReasonValidation, "Ignoring invalid %s annotation: %v", SetLogLevelsAnnotation, err)
+			completed = append(completed, SetLogLevelsAnnotation)
+			setLevels = false
+		}
+	}
+	if setLevels || capture {
+		completed = append(completed, runDiagnosticActions(c, scheme, recorder, ls, runningNodes, loggers, setLevels, capture, results)...)
+	}
+
+	if len(completed) == 0 {
+		return results
+	}
+	for _, annotation := range completed {
+		delete(ls.Annotations, annotation)
+	}
+	return results.WithError(c.Update(ls))
+}
+
+// runDiagnosticActions sets the given log levels and captures the diagnostics of the running nodes of the given
+// Logstash, as requested. It returns the annotations of the completed actions.
+func runDiagnosticActions(
+	c k8s.Client,
+	scheme *runtime.Scheme,
+	recorder record.EventRecorder,
+	ls *lstype.Logstash,
+	runningNodes observer.NodesFunc,
+	loggers map[string]string,
+	setLevels, capture bool,
+	results *reconciler.Results,
+) []string {
+	nodes, err := runningNodes(k8s.ExtractNamespacedName(ls))
+	if err != nil {
+		results.WithError(err)
+		return nil
+	}
+	defer func() {
+		for _, n := range nodes {
+			n.Close()
+		}
+	}()
+	if len(nodes) == 0 {
+		log.V(1).Info("Delaying diagnostic actions until Logstash nodes are running", "namespace", ls.Namespace, "logstash_name", ls.Name)
+		results.WithResult(reconcile.Result{RequeueAfter: diagnosticsRequeue})
+		return nil
+	}
+
+	var completed []string
+	if setLevels {
+		if err := setLogLevels(nodes, loggers); err != nil {
+			results.WithError(err)
+		} else {
+			recorder.Eventf(ls, corev1.EventTypeNormal, events.EventReasonStateChange, "Log levels %s set on %d Logstash nodes", ls.Annotations[SetLogLevelsAnnotation], len(nodes))
+			completed = append(completed, SetLogLevelsAnnotation)
+		}
+	}
+	if capture {
+		cm := newDiagnosticsConfigMap(*ls, nodes, time.Now())
+		err := controllerutil.SetControllerReference(ls, &cm, scheme)
+		if err == nil {
+			err = c.Create(&cm)
+		}
+		if err != nil {
+			results.WithError(err)
+		} else {
+			recorder.Eventf(ls, corev1.EventTypeNormal, events.EventReasonCreated, "Diagnostics of %d Logstash nodes captured in config map %s", len(nodes), cm.Name)
+			completed = append(completed, CaptureDiagnosticsAnnotation)
+			results.WithError(deleteOldDiagnosticsConfigMaps(c, *ls))
+		}
+	}
+	return completed
+}
+
+// setLogLevels sets the given log levels, mapping logger names to levels, on the given nodes.
+func setLogLevels(nodes map[string]lsclient.Client, loggers map[string]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), lsclient.DefaultReqTimeout)
+	defer cancel()
+	for podName, c := range nodes {
+		if err := c.SetLogLevels(ctx, loggers); err != nil {
+			return fmt.Errorf("failed to set log levels on %s: %v", podName, err)
+		}
+	}
+	return nil
+}
+
+// deleteOldDiagnosticsConfigMaps deletes the diagnostics config maps of the given Logstash but the latest ones.
+func deleteOldDiagnosticsConfigMaps(c k8s.Client, ls lstype.Logstash) error {
+	var configMaps corev1.ConfigMapList
+	if err := c.List(
+		&configMaps,
+		client.InNamespace(ls.Namespace),
+		client.MatchingLabels(label.NewDiagnosticsLabels(ls.Name)),
+	); err != nil {
+		return err
+	}
+	if len(configMaps.Items) <= maxDiagnosticsConfigMaps {
+		return nil
+	}
+	// the names end with the capture timestamp
+	sort.Slice(configMaps.Items, func(i, j int) bool {
+		return configMaps.Items[i].Name < configMaps.Items[j].Name
+	})
+	for i := range configMaps.Items[:len(configMaps.Items)-maxDiagnosticsConfigMaps] {
+		cm := configMaps.Items[i]
+		log.Info("Deleting old diagnostics config map", "namespace", cm.Namespace, "configmap_name", cm.Name)
+		if err := c.Delete(&cm); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// newDiagnosticsConfigMap returns a config map holding the hot threads and node stats of the given nodes. A node
+// whose diagnostics cannot be retrieved is reported with the corresponding error. The diagnostics exceeding the
+// maximum size of a config map are truncated.
+func newDiagnosticsConfigMap(ls lstype.Logstash, nodes map[string]lsclient.Client, now time.Time) corev1.ConfigMap {
+	podNames := make([]string, 0, len(nodes))
+	for podName := range nodes {
+		podNames = append(podNames, podName)
+	}
+	sort.Strings(podNames)
+
+	ctx, cancel := context.WithTimeout(context.Background(), lsclient.DefaultReqTimeout)
+	defer cancel()
+	data := map[string]string{}
+	size := 0
+	add := func(key, value string) {
+		if available := maxDiagnosticsSize - size - len(key); len(value) > available {
+			if available -= len(truncatedMarker); available < 0 {
+				available = 0
+			}
+			value = value[:available] + truncatedMarker
+		}
+		data[key] = value
+		size += len(key) + len(value)
+	}
+	for _, podName := range podNames {
+		c := nodes[podName]
+		hotThreads, err := c.GetHotThreads(ctx)
+		if err != nil {
+			add(podName+".error", err.Error())
+			continue
+		}
+		stats, err := c.GetRawNodeStats(ctx)
+		if err != nil {
+			add(podName+".error", err.Error())
+			continue
+		}
+		add(podName+".hot_threads.json", string(hotThreads))
+		add(podName+".node_stats.json", string(stats))
+	}
+
+	return corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      lsname.DiagnosticsConfigMap(ls.Name, now.UTC().Format(diagnosticsTimestampFormat)),
+			Namespace: ls.Namespace,
+			Labels:    label.NewDiagnosticsLabels(ls.Name),
+		},
+		Data: data,
+	}
+}
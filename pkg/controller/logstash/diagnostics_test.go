@@ -0,0 +1,174 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	lsclient "github.com/cloudptio/logstash-operator/pkg/controller/logstash/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_reconcileDiagnostics(t *testing.T) {
+	require.NoError(t, lstype.AddToScheme(scheme.Scheme))
+	ls := lstype.Logstash{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "ls",
+			Annotations: map[string]string{
+				SetLogLevelsAnnotation:       `{"logstash.outputs.elasticsearch": "DEBUG"}`,
+				CaptureDiagnosticsAnnotation: "true",
+				"other":                      "annotation",
+			},
+		},
+	}
+	c := k8s.WrapClient(fake.NewFakeClientWithScheme(scheme.Scheme, &ls))
+
+	var setLevels []string
+	node := func(name string, statusCode int) lsclient.Client {
+		return lsclient.NewMockClient(func(req *http.Request) *http.Response {
+			switch req.URL.Path {
+			case "/_node/logging":
+				setLevels = append(setLevels, name)
+				return lsclient.NewMockResponse(200, req, `{"acknowledged":true}`)
+			case "/_node/hot_threads":
+				return lsclient.NewMockResponse(statusCode, req, `{"hot_threads":{}}`)
+			default:
+				return lsclient.NewMockResponse(statusCode, req, `{"name":"`+name+`"}`)
+			}
+		})
+	}
+	var nodes map[string]lsclient.Client
+	runningNodes := func(types.NamespacedName) (map[string]lsclient.Client, error) {
+		return nodes, nil
+	}
+
+	// no running node: the actions are delayed
+	results := reconcileDiagnostics(c, scheme.Scheme, record.NewFakeRecorder(10), &ls, runningNodes)
+	res, err := results.Aggregate()
+	require.NoError(t, err)
+	require.Equal(t, diagnosticsRequeue, res.RequeueAfter)
+	require.Len(t, ls.Annotations, 3)
+
+	// the actions are run on the running nodes and their annotations removed
+	nodes = map[string]lsclient.Client{"ls-ls-a": node("ls-ls-a", 200), "ls-ls-b": node("ls-ls-b", 503)}
+	results = reconcileDiagnostics(c, scheme.Scheme, record.NewFakeRecorder(10), &ls, runningNodes)
+	require.False(t, results.HasError())
+	require.ElementsMatch(t, []string{"ls-ls-a", "ls-ls-b"}, setLevels)
+
+	var updated lstype.Logstash
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(&ls), &updated))
+	require.Equal(t, map[string]string{"other": "annotation"}, updated.Annotations)
+
+	var configMaps corev1.ConfigMapList
+	require.NoError(t, c.List(&configMaps, client.InNamespace("ns")))
+	require.Len(t, configMaps.Items, 1)
+	cm := configMaps.Items[0]
+	require.Regexp(t, `^ls-ls-diag-\d{8}-\d{6}$`, cm.Name)
+	require.Equal(t, "ls", cm.OwnerReferences[0].Name)
+	require.Equal(t, map[string]string{
+		"ls-ls-a.hot_threads.json": `{"hot_threads":{}}`,
+		"ls-ls-a.node_stats.json":  `{"name":"ls-ls-a"}`,
+		"ls-ls-b.error":            "logstash api error: ",
+	}, cm.Data)
+}
+
+func Test_reconcileDiagnostics_InvalidLogLevels(t *testing.T) {
+	require.NoError(t, lstype.AddToScheme(scheme.Scheme))
+	ls := lstype.Logstash{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "ls",
+			Annotations: map[string]string{SetLogLevelsAnnotation: "DEBUG"},
+		},
+	}
+	c := k8s.WrapClient(fake.NewFakeClientWithScheme(scheme.Scheme, &ls))
+	runningNodes := func(types.NamespacedName) (map[string]lsclient.Client, error) {
+		t.Fatal("no node should be contacted")
+		return nil, nil
+	}
+	recorder := record.NewFakeRecorder(10)
+
+	// the invalid annotation is reported and removed, without error
+	results := reconcileDiagnostics(c, scheme.Scheme, recorder, &ls, runningNodes)
+	res, err := results.Aggregate()
+	require.NoError(t, err)
+	require.Equal(t, reconcile.Result{}, res)
+	require.Contains(t, <-recorder.Events, "Warning Validation Ignoring invalid "+SetLogLevelsAnnotation)
+
+	var updated lstype.Logstash
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(&ls), &updated))
+	require.Empty(t, updated.Annotations)
+}
+
+func Test_newDiagnosticsConfigMap_Truncated(t *testing.T) {
+	stats := `"` + strings.Repeat("x", 600*1024) + `"`
+	node := lsclient.NewMockClient(func(req *http.Request) *http.Response {
+		if req.URL.Path == "/_node/hot_threads" {
+			return lsclient.NewMockResponse(200, req, `{"hot_threads":{}}`)
+		}
+		return lsclient.NewMockResponse(200, req, stats)
+	})
+	nodes := map[string]lsclient.Client{"ls-ls-a": node, "ls-ls-b": node}
+
+	cm := newDiagnosticsConfigMap(lstype.Logstash{ObjectMeta: metav1.ObjectMeta{Name: "ls"}}, nodes, time.Now())
+	size := 0
+	for k, v := range cm.Data {
+		size += len(k) + len(v)
+	}
+	require.True(t, size <= maxDiagnosticsSize)
+	require.Equal(t, stats, cm.Data["ls-ls-a.node_stats.json"])
+	require.Equal(t, `{"hot_threads":{}}`, cm.Data["ls-ls-b.hot_threads.json"])
+	require.True(t, strings.HasSuffix(cm.Data["ls-ls-b.node_stats.json"], truncatedMarker))
+}
+
+func Test_deleteOldDiagnosticsConfigMaps(t *testing.T) {
+	ls := lstype.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+	objs := []runtime.Object{
+		// not a diagnostics config map
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls-ls-pipeline", Labels: label.NewLabels("ls")}},
+	}
+	for i := 0; i < maxDiagnosticsConfigMaps+2; i++ {
+		objs = append(objs, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      fmt.Sprintf("ls-ls-diag-20191010-00000%d", i),
+			Labels:    label.NewDiagnosticsLabels("ls"),
+		}})
+	}
+	c := k8s.WrapClient(fake.NewFakeClient(objs...))
+
+	require.NoError(t, deleteOldDiagnosticsConfigMaps(c, ls))
+
+	var configMaps corev1.ConfigMapList
+	require.NoError(t, c.List(&configMaps, client.InNamespace("ns")))
+	names := make([]string, 0, len(configMaps.Items))
+	for _, cm := range configMaps.Items {
+		names = append(names, cm.Name)
+	}
+	require.ElementsMatch(t, []string{
+		"ls-ls-pipeline",
+		"ls-ls-diag-20191010-000002",
+		"ls-ls-diag-20191010-000003",
+		"ls-ls-diag-20191010-000004",
+		"ls-ls-diag-20191010-000005",
+		"ls-ls-diag-20191010-000006",
+	}, names)
+}
@@ -22,19 +22,22 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/watches"
 	lscerts "github.com/cloudptio/logstash-operator/pkg/controller/logstash/certificates"
-	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/configmap"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/es"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/management"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/monitoring"
 	lsname "github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pod"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/version/version6"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/version/version7"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/version/version8"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/volume"
 	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // initContainersParameters is used to generate the init container that will load the secure settings into a keystore
@@ -49,8 +52,10 @@ type driver struct {
 	client          k8s.Client
 	scheme          *runtime.Scheme
 	settingsFactory func(ls lstype.Logstash) map[string]interface{}
-	dynamicWatches  watches.DynamicWatches
-	recorder        record.EventRecorder
+	// esOutputSSLFactory returns the SSL options of the elasticsearch output trusting the given CA file
+	esOutputSSLFactory func(caFile string) map[string]string
+	dynamicWatches     watches.DynamicWatches
+	recorder           record.EventRecorder
 }
 
 func (d *driver) DynamicWatches() watches.DynamicWatches {
@@ -80,6 +85,8 @@ func secretWatchFinalizer(logstash lstype.Logstash, watches watches.DynamicWatch
 		Name: "finalizer.logstash.k8s.elastic.co/es-auth-secret",
 		Execute: func() error {
 			watches.Secrets.RemoveHandlerForKey(secretWatchKey(logstash))
+			watches.Secrets.RemoveHandlerForKey(monitoringSecretWatchKey(logstash))
+			watches.Secrets.RemoveHandlerForKey(centralManagementSecretWatchKey(logstash))
 			return nil
 		},
 	}
@@ -98,7 +105,13 @@ func (d *driver) deploymentParams(ls *lstype.Logstash) (deployment.Params, error
 		return deployment.Params{}, err
 	}
 
-	logstashPodSpec := pod.NewPodTemplateSpec(*ls, keystoreResources)
+	logstashPodSpec := pod.NewPodTemplateSpec(*ls, keystoreResources, d.settingsFactory(*ls))
+	monitoring.WithMonitoring(&logstashPodSpec, *ls)
+
+	stableConfigMap, err := d.stablePipelineConfigMap(*ls)
+	if err != nil {
+		return deployment.Params{}, err
+	}
 
 	// TODO: Add reference to dynamic ES connection
 	//logstashPodSpec.ls.AssociationConf().URL
@@ -165,6 +178,18 @@ func (d *driver) deploymentParams(ls *lstype.Logstash) (deployment.Params, error
 			esCertsVolume.VolumeMount())
 	}
 
+	if err := d.writeMonitoringChecksum(*ls, configChecksum); err != nil {
+		return deployment.Params{}, err
+	}
+	managementAuth, err := d.centralManagementAuth(*ls, configChecksum)
+	if err != nil {
+		return deployment.Params{}, err
+	}
+	management.WithCentralManagement(&logstashPodSpec, *ls, stableConfigMap, managementAuth)
+	writeConfigFilesChecksum(*ls, configChecksum)
+
+	setPipelinesFileChecksum(&logstashPodSpec, stableConfigMap)
+
 	if ls.Spec.HTTP.TLS.Enabled() {
 		// fetch the secret to calculate the checksum
 		var httpCerts corev1.Secret
@@ -198,17 +223,28 @@ func (d *driver) deploymentParams(ls *lstype.Logstash) (deployment.Params, error
 	// add the checksum to a label for the deployment and its pods (the important bit is that the pod template
 	// changes, which will trigger a rolling update)
 	logstashPodSpec.Labels[configChecksumLabel] = fmt.Sprintf("%x", configChecksum.Sum(nil))
+	// the stable pods must not be selected by the canary deployment, and conversely
+	logstashPodSpec.Labels[label.TrackLabelName] = label.StableTrack
 
 	return deployment.Params{
-		Name:            lsname.LSNamer.Suffix(ls.Name),
-		Namespace:       ls.Namespace,
-		Replicas:        ls.Spec.Count,
-		Selector:        label.NewLabels(ls.Name),
-		Labels:          label.NewLabels(ls.Name),
-		PodTemplateSpec: logstashPodSpec,
+		Name:                lsname.LSNamer.Suffix(ls.Name),
+		Namespace:           ls.Namespace,
+		Replicas:            ls.Spec.Count,
+		Selector:            label.NewLabels(ls.Name),
+		SelectorExpressions: label.NonCanaryRequirements(),
+		Labels:              label.NewLabels(ls.Name),
+		PodTemplateSpec:     logstashPodSpec,
 	}, nil
 }
 
+// esOutputSSLSettings returns the SSL options of the default elasticsearch output, if the Elasticsearch CA is available.
+func (d *driver) esOutputSSLSettings(ls lstype.Logstash) map[string]string {
+	if !ls.AssociationConf().CAIsConfigured() {
+		return nil
+	}
+	return d.esOutputSSLFactory(es.CaCertFile())
+}
+
 func (d *driver) Reconcile(
 	state *State,
 	ls *lstype.Logstash,
@@ -221,8 +257,19 @@ func (d *driver) Reconcile(
 		return &results
 	}
 
-	if err := configmap.ReconcilePipelineConfigMap(d.client, d.scheme, *ls); err != nil {
-		return results.WithError(err)
+	indexReady, indexResults := d.reconcileIndexBootstrap(state, ls, params.Dialer)
+	results.WithResults(indexResults)
+	if !indexReady {
+		log.Info("Delaying Logstash deployment until the index template and ILM policy are installed", "namespace", ls.Namespace, "logstash_name", ls.Name)
+		return &results
+	}
+
+	results.WithResults(d.reconcileCentralManagement(state, ls, params.Dialer))
+
+	canaryInProgress, pipelineResults := d.reconcilePipeline(state, ls, params.Dialer)
+	results.WithResults(pipelineResults)
+	if results.HasError() {
+		return &results
 	}
 
 	svc, err := common.ReconcileService(d.client, d.scheme, NewService(*ls), ls)
@@ -231,6 +278,12 @@ func (d *driver) Reconcile(
 		return results.WithError(err)
 	}
 
+	inputServices, err := d.reconcileInputServices(*ls)
+	if err != nil {
+		return results.WithError(err)
+	}
+	state.UpdateInputServicesStatus(inputServices)
+
 	results.WithResults(lscerts.Reconcile(d, *ls, []corev1.Service{*svc}, params.CACertRotation))
 	if results.HasError() {
 		return &results
@@ -251,11 +304,41 @@ func (d *driver) Reconcile(
 	// 	return results.WithError(err)
 	// }
 
+	if err := d.reconcileMetricbeatConfig(*ls); err != nil {
+		return results.WithError(err)
+	}
+
+	if err := d.reconcileConfigFiles(*ls); err != nil {
+		return results.WithError(err)
+	}
+
+	if err := d.reconcileDeadLetterQueueClaim(*ls); err != nil {
+		return results.WithError(err)
+	}
+
+	if err := d.reconcileDeadLetterQueueSlots(*ls); err != nil {
+		return results.WithError(err)
+	}
+
 	deploymentParams, err := d.deploymentParams(ls)
 	if err != nil {
 		return results.WithError(err)
 	}
+	if canaryInProgress && deploymentParams.Replicas > 0 {
+		// the canary node replaces one of the stable nodes while the pipeline change is tested
+		deploymentParams.Replicas--
+	}
+	scaleDownPending, err := d.limitScaleDown(*ls, &deploymentParams)
+	if err != nil {
+		return results.WithError(err)
+	}
+	if scaleDownPending {
+		results.WithResult(reconcile.Result{RequeueAfter: scaleDownRequeue})
+	}
 	expectedDp := deployment.New(deploymentParams)
+	if err := keepDeploymentSelector(d.client, &expectedDp); err != nil {
+		return results.WithError(err)
+	}
 	reconciledDp, err := deployment.Reconcile(d.client, d.scheme, expectedDp, ls)
 	if err != nil {
 		return results.WithError(err)
@@ -286,8 +369,19 @@ func newDriver(
 		default:
 			d.settingsFactory = version6.SettingsFactory
 		}
+		d.esOutputSSLFactory = version6.ElasticsearchOutputSSLSettings
 	case 7:
 		d.settingsFactory = version7.SettingsFactory
+		d.esOutputSSLFactory = version7.ElasticsearchOutputSSLSettings
+	case 8:
+		d.settingsFactory = version8.SettingsFactory
+		switch {
+		case version.Minor >= 8:
+			d.esOutputSSLFactory = version8.ElasticsearchOutputSSLSettings
+		default:
+			// the ssl_* options of the elasticsearch output are only available from 8.8
+			d.esOutputSSLFactory = version7.ElasticsearchOutputSSLSettings
+		}
 	default:
 		return nil, fmt.Errorf("unsupported version: %s", version)
 	}
@@ -158,6 +158,7 @@ func TestDriverDeploymentParams(t *testing.T) {
 					"common.k8s.elastic.co/type":              "logstash",
 					"logstash.k8s.elastic.co/name":            "test",
 					"logstash.k8s.elastic.co/config-checksum": "c5496152d789682387b90ea9b94efcd82a2c6f572f40c016fb86c0d7",
+					"logstash.k8s.elastic.co/track":           "stable",
 				}
 				return p
 			}(),
@@ -219,20 +220,61 @@ func TestDriverDeploymentParams(t *testing.T) {
 	}
 }
 
+func Test_newDriver_esOutputSSLFactory(t *testing.T) {
+	tests := []struct {
+		version string
+		want    map[string]string
+	}{
+		{
+			version: "7.4.0",
+			want:    map[string]string{"ssl": "true", "cacert": `"/ca.crt"`},
+		},
+		{
+			version: "8.0.0",
+			want:    map[string]string{"ssl": "true", "cacert": `"/ca.crt"`},
+		},
+		{
+			version: "8.7.1",
+			want:    map[string]string{"ssl": "true", "cacert": `"/ca.crt"`},
+		},
+		{
+			version: "8.8.0",
+			want: map[string]string{
+				"ssl_enabled":                 "true",
+				"ssl_verification_mode":       `"full"`,
+				"ssl_certificate_authorities": `["/ca.crt"]`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+			d, err := newDriver(nil, nil, *v, watches.NewDynamicWatches(), record.NewFakeRecorder(100))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, d.esOutputSSLFactory("/ca.crt"))
+		})
+	}
+}
+
 func expectedDeploymentParams() deployment.Params {
 	false := false
 	return deployment.Params{
 		Name:      "test-ls",
 		Namespace: "default",
 		Selector:  map[string]string{"common.k8s.elastic.co/type": "logstash", "logstash.k8s.elastic.co/name": "test"},
-		Labels:    map[string]string{"common.k8s.elastic.co/type": "logstash", "logstash.k8s.elastic.co/name": "test"},
-		Replicas:  1,
+		SelectorExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "logstash.k8s.elastic.co/track", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"canary"}},
+		},
+		Labels:   map[string]string{"common.k8s.elastic.co/type": "logstash", "logstash.k8s.elastic.co/name": "test"},
+		Replicas: 1,
 		PodTemplateSpec: corev1.PodTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
 				Labels: map[string]string{
 					"common.k8s.elastic.co/type":              "logstash",
 					"logstash.k8s.elastic.co/name":            "test",
 					"logstash.k8s.elastic.co/config-checksum": "c530a02188193a560326ce91e34fc62dcbd5722b45534a3f60957663",
+					"logstash.k8s.elastic.co/track":           "stable",
 				},
 			},
 			Spec: corev1.PodSpec{
@@ -5,10 +5,13 @@
 package es
 
 import (
+	"path"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/volume"
 	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
 )
@@ -25,6 +28,11 @@ func CaCertSecretVolume(ls v1beta1.Logstash) volume.SecretVolume {
 	)
 }
 
+// CaCertFile returns the path to the Elasticsearch CA certificate file in the Logstash container.
+func CaCertFile() string {
+	return path.Join(eSCertsVolumeMountPath, certificates.CertFileName)
+}
+
 // GetAuthSecret returns the Elasticsearch auth secret for the given Logstash resource.
 func GetAuthSecret(client k8s.Client, ls v1beta1.Logstash) (*corev1.Secret, error) {
 	esAuthSecret := types.NamespacedName{
@@ -0,0 +1,155 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package index
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	lsversion "github.com/cloudptio/logstash-operator/pkg/controller/logstash/version"
+)
+
+const (
+	// dataStreamTemplatePriority overrides the priority of the built-in templates matching the data stream names.
+	dataStreamTemplatePriority = 200
+
+	lifecycleNameSetting          = "index.lifecycle.name"
+	lifecycleRolloverAliasSetting = "index.lifecycle.rollover_alias"
+)
+
+var (
+	// ilmMinVersion is the first Elasticsearch version supporting index lifecycle management.
+	ilmMinVersion = version.MustParse("6.6.0")
+	// dataStreamMinVersion is the first Elasticsearch version supporting data streams.
+	dataStreamMinVersion = version.MustParse("7.9.0")
+)
+
+// defaultPhases roll the indices over after 30 days or once they reach 50gb.
+func defaultPhases() map[string]interface{} {
+	return map[string]interface{}{
+		"hot": map[string]interface{}{
+			"actions": map[string]interface{}{
+				"rollover": map[string]interface{}{
+					"max_age":  "30d",
+					"max_size": "50gb",
+				},
+			},
+		},
+	}
+}
+
+// Policy returns the ILM policy of the given spec.
+func Policy(spec v1beta1.IndexBootstrapSpec) esclient.ILMPolicy {
+	if spec.ILMPolicy == nil || len(spec.ILMPolicy.Data) == 0 {
+		return esclient.ILMPolicy{Phases: defaultPhases()}
+	}
+	phases, ok := spec.ILMPolicy.Data["phases"].(map[string]interface{})
+	if !ok {
+		// the phases are given without the enclosing key
+		phases = spec.ILMPolicy.Data
+	}
+	return esclient.ILMPolicy{Phases: phases}
+}
+
+// Mode returns where the default output writes its events with the given Elasticsearch and Logstash versions, along
+// with a message explaining why a rollover alias is used if a data stream was requested.
+func Mode(spec v1beta1.IndexBootstrapSpec, esVersion version.Version, lsVersion version.Version) (v1beta1.IndexBootstrapMode, string) {
+	if spec.DataStream == nil {
+		return v1beta1.IndexBootstrapRolloverAlias, ""
+	}
+	if !esVersion.IsSameOrAfter(dataStreamMinVersion) {
+		return v1beta1.IndexBootstrapRolloverAlias, fmt.Sprintf(
+			"data streams require Elasticsearch %s or above, writing to rollover alias %s instead", dataStreamMinVersion, spec.GetName(),
+		)
+	}
+	if !lsVersion.IsSameOrAfter(lsversion.DataStreamMinVersion) {
+		return v1beta1.IndexBootstrapRolloverAlias, fmt.Sprintf(
+			"data streams require Logstash %s or above, writing to rollover alias %s instead", lsversion.DataStreamMinVersion, spec.GetName(),
+		)
+	}
+	return v1beta1.IndexBootstrapDataStream, ""
+}
+
+// templateConfig returns a copy of the settings and mappings of the index template of the given spec.
+func templateConfig(spec v1beta1.IndexBootstrapSpec) (settings map[string]interface{}, mappings map[string]interface{}) {
+	settings = map[string]interface{}{}
+	if spec.Template == nil {
+		return settings, nil
+	}
+	template := spec.Template.DeepCopy()
+	if s, ok := template.Data["settings"].(map[string]interface{}); ok {
+		settings = s
+	}
+	if m, ok := template.Data["mappings"].(map[string]interface{}); ok {
+		mappings = m
+	}
+	return settings, mappings
+}
+
+// RolloverAliasTemplate returns the legacy index template of the indices behind the rollover alias of the given spec.
+func RolloverAliasTemplate(spec v1beta1.IndexBootstrapSpec) esclient.IndexTemplate {
+	settings, mappings := templateConfig(spec)
+	settings[lifecycleNameSetting] = spec.GetName()
+	settings[lifecycleRolloverAliasSetting] = spec.GetName()
+	return esclient.IndexTemplate{
+		IndexPatterns: []string{spec.GetName() + "-*"},
+		Settings:      settings,
+		Mappings:      mappings,
+	}
+}
+
+// DataStreamTemplate returns the composable index template of the data stream of the given spec.
+func DataStreamTemplate(spec v1beta1.IndexBootstrapSpec) esclient.ComposableIndexTemplate {
+	settings, mappings := templateConfig(spec)
+	settings[lifecycleNameSetting] = spec.GetName()
+	return esclient.ComposableIndexTemplate{
+		IndexPatterns: []string{spec.DataStream.Name()},
+		Priority:      dataStreamTemplatePriority,
+		DataStream:    &esclient.DataStreamTemplate{},
+		Template: esclient.ComposableTemplateConfig{
+			Settings: settings,
+			Mappings: mappings,
+		},
+	}
+}
+
+// Bootstrap installs the ILM policy and the index template of the given spec in the Elasticsearch cluster of the given
+// version, for Logstash nodes of the given version, and returns the resulting status.
+func Bootstrap(
+	ctx context.Context,
+	c esclient.Client,
+	spec v1beta1.IndexBootstrapSpec,
+	esVersion version.Version,
+	lsVersion version.Version,
+) (v1beta1.IndexBootstrapStatus, error) {
+	if !esVersion.IsSameOrAfter(ilmMinVersion) {
+		return v1beta1.IndexBootstrapStatus{}, fmt.Errorf(
+			"index lifecycle management requires Elasticsearch %s or above, got %s", ilmMinVersion, esVersion,
+		)
+	}
+	if err := c.PutILMPolicy(ctx, spec.GetName(), Policy(spec)); err != nil {
+		return v1beta1.IndexBootstrapStatus{}, err
+	}
+
+	mode, message := Mode(spec, esVersion, lsVersion)
+	var err error
+	switch mode {
+	case v1beta1.IndexBootstrapDataStream:
+		err = c.PutComposableIndexTemplate(ctx, spec.GetName(), DataStreamTemplate(spec))
+	default:
+		err = c.PutIndexTemplate(ctx, spec.GetName(), RolloverAliasTemplate(spec))
+	}
+	if err != nil {
+		return v1beta1.IndexBootstrapStatus{}, err
+	}
+	return v1beta1.IndexBootstrapStatus{
+		Phase:   v1beta1.IndexBootstrapReady,
+		Mode:    mode,
+		Message: message,
+	}, nil
+}
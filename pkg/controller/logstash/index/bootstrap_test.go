@@ -0,0 +1,143 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package index
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrap(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       v1beta1.IndexBootstrapSpec
+		esVersion  string
+		lsVersion  string
+		wantStatus v1beta1.IndexBootstrapStatus
+		wantErr    bool
+		wantBodies map[string]string
+	}{
+		{
+			name:      "ILM is not supported",
+			spec:      v1beta1.IndexBootstrapSpec{Enabled: true},
+			esVersion: "6.5.0",
+			wantErr:   true,
+		},
+		{
+			name:      "rollover alias with default policy",
+			spec:      v1beta1.IndexBootstrapSpec{Enabled: true},
+			esVersion: "7.4.0",
+			wantStatus: v1beta1.IndexBootstrapStatus{
+				Phase: v1beta1.IndexBootstrapReady,
+				Mode:  v1beta1.IndexBootstrapRolloverAlias,
+			},
+			wantBodies: map[string]string{
+				"/_ilm/policy/logstash": `{"policy":{"phases":{"hot":{"actions":{"rollover":{"max_age":"30d","max_size":"50gb"}}}}}}`,
+				"/_template/logstash": `{"index_patterns":["logstash-*"],"settings":{
+					"index.lifecycle.name":"logstash","index.lifecycle.rollover_alias":"logstash"}}`,
+			},
+		},
+		{
+			name: "data stream falls back to a rollover alias before 7.9",
+			spec: v1beta1.IndexBootstrapSpec{
+				Enabled:    true,
+				Name:       "events",
+				DataStream: &v1beta1.DataStreamSpec{},
+			},
+			esVersion: "7.8.0",
+			lsVersion: "7.13.0",
+			wantStatus: v1beta1.IndexBootstrapStatus{
+				Phase:   v1beta1.IndexBootstrapReady,
+				Mode:    v1beta1.IndexBootstrapRolloverAlias,
+				Message: "data streams require Elasticsearch 7.9.0 or above, writing to rollover alias events instead",
+			},
+			wantBodies: map[string]string{
+				"/_ilm/policy/events": `{"policy":{"phases":{"hot":{"actions":{"rollover":{"max_age":"30d","max_size":"50gb"}}}}}}`,
+				"/_template/events": `{"index_patterns":["events-*"],"settings":{
+					"index.lifecycle.name":"events","index.lifecycle.rollover_alias":"events"}}`,
+			},
+		},
+		{
+			name: "data stream falls back to a rollover alias before Logstash 7.13",
+			spec: v1beta1.IndexBootstrapSpec{
+				Enabled:    true,
+				DataStream: &v1beta1.DataStreamSpec{},
+			},
+			esVersion: "7.9.0",
+			lsVersion: "7.12.1",
+			wantStatus: v1beta1.IndexBootstrapStatus{
+				Phase:   v1beta1.IndexBootstrapReady,
+				Mode:    v1beta1.IndexBootstrapRolloverAlias,
+				Message: "data streams require Logstash 7.13.0 or above, writing to rollover alias logstash instead",
+			},
+			wantBodies: map[string]string{
+				"/_ilm/policy/logstash": `{"policy":{"phases":{"hot":{"actions":{"rollover":{"max_age":"30d","max_size":"50gb"}}}}}}`,
+				"/_template/logstash": `{"index_patterns":["logstash-*"],"settings":{
+					"index.lifecycle.name":"logstash","index.lifecycle.rollover_alias":"logstash"}}`,
+			},
+		},
+		{
+			name: "data stream with custom template and policy",
+			spec: v1beta1.IndexBootstrapSpec{
+				Enabled: true,
+				Template: &commonv1beta1.Config{Data: map[string]interface{}{
+					"settings": map[string]interface{}{"number_of_shards": 2},
+					"mappings": map[string]interface{}{"dynamic": false},
+				}},
+				ILMPolicy: &commonv1beta1.Config{Data: map[string]interface{}{
+					"phases": map[string]interface{}{"delete": map[string]interface{}{"min_age": "7d"}},
+				}},
+				DataStream: &v1beta1.DataStreamSpec{Dataset: "nginx"},
+			},
+			esVersion: "7.9.0",
+			lsVersion: "7.13.0",
+			wantStatus: v1beta1.IndexBootstrapStatus{
+				Phase: v1beta1.IndexBootstrapReady,
+				Mode:  v1beta1.IndexBootstrapDataStream,
+			},
+			wantBodies: map[string]string{
+				"/_ilm/policy/logstash": `{"policy":{"phases":{"delete":{"min_age":"7d"}}}}`,
+				"/_index_template/logstash": `{"index_patterns":["logs-nginx-default"],"priority":200,"data_stream":{},
+					"template":{"settings":{"number_of_shards":2,"index.lifecycle.name":"logstash"},"mappings":{"dynamic":false}}}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			esVersion := version.MustParse(tt.esVersion)
+			lsVersion := esVersion
+			if tt.lsVersion != "" {
+				lsVersion = version.MustParse(tt.lsVersion)
+			}
+			bodies := map[string]string{}
+			c := esclient.NewMockClient(esVersion, func(req *http.Request) *http.Response {
+				body, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				bodies[req.URL.Path] = string(body)
+				return esclient.NewMockResponse(200, req, "{}")
+			})
+			status, err := Bootstrap(context.Background(), c, tt.spec, esVersion, lsVersion)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Empty(t, bodies)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, status)
+			require.Equal(t, len(tt.wantBodies), len(bodies))
+			for path, body := range tt.wantBodies {
+				require.JSONEq(t, body, bodies[path], path)
+			}
+		})
+	}
+}
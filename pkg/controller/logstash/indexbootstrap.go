@@ -0,0 +1,127 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/reconciler"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/index"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/cloudptio/logstash-operator/pkg/utils/net"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// indexBootstrapRequeue is the delay before retrying a failed installation of the index template and ILM policy.
+var indexBootstrapRequeue = 30 * time.Second
+
+// reconcileIndexBootstrap installs the index template and ILM policy of the given Logstash in the associated
+// Elasticsearch cluster, and records the outcome in its status. It returns false if Logstash must not be deployed
+// yet, the installation having never succeeded.
+func (d *driver) reconcileIndexBootstrap(state *State, ls *lstype.Logstash, dialer net.Dialer) (bool, *reconciler.Results) {
+	results := &reconciler.Results{}
+	if !ls.Spec.IndexBootstrap.Enabled {
+		state.UpdateIndexBootstrapStatus(nil)
+		return true, results
+	}
+
+	previous := ls.Status.IndexBootstrap
+	status, err := d.bootstrapIndex(*ls, dialer)
+	if err != nil {
+		log.Error(err, "Failed to install index template and ILM policy", "namespace", ls.Namespace, "logstash_name", ls.Name)
+		k8s.EmitErrorEvent(d.recorder, err, ls, events.EventReconciliationError, "Failed to install index template and ILM policy: %v", err)
+		status = lstype.IndexBootstrapStatus{Phase: lstype.IndexBootstrapFailed, Message: err.Error()}
+		if previous != nil {
+			// the default output keeps writing to where the last successful installation pointed it to
+			status.Mode = previous.Mode
+		}
+		state.UpdateIndexBootstrapStatus(&status)
+		results.WithResult(reconcile.Result{RequeueAfter: indexBootstrapRequeue})
+		return status.Mode != "", results
+	}
+	state.UpdateIndexBootstrapStatus(&status)
+	return true, results
+}
+
+// bootstrapIndex installs the index template and ILM policy of the given Logstash with the credentials of its
+// association with Elasticsearch.
+func (d *driver) bootstrapIndex(ls lstype.Logstash, dialer net.Dialer) (lstype.IndexBootstrapStatus, error) {
+	lsVersion, err := version.Parse(ls.Spec.Version)
+	if err != nil {
+		return lstype.IndexBootstrapStatus{}, err
+	}
+	esVersion, err := d.associatedElasticsearchVersion(ls)
+	if err != nil {
+		return lstype.IndexBootstrapStatus{}, err
+	}
+	esClient, err := d.newElasticsearchClient(ls, dialer, esVersion)
+	if err != nil {
+		return lstype.IndexBootstrapStatus{}, err
+	}
+	defer esClient.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	return index.Bootstrap(ctx, esClient, ls.Spec.IndexBootstrap, esVersion, *lsVersion)
+}
+
+// associatedElasticsearchVersion returns the version of the Elasticsearch cluster referenced by the given Logstash.
+func (d *driver) associatedElasticsearchVersion(ls lstype.Logstash) (version.Version, error) {
+	esRef := ls.Spec.ElasticsearchRef
+	if esRef.Namespace == "" {
+		esRef.Namespace = ls.Namespace
+	}
+	var es esv1beta1.Elasticsearch
+	if err := d.client.Get(esRef.NamespacedName(), &es); err != nil {
+		return version.Version{}, err
+	}
+	v, err := version.Parse(es.Spec.Version)
+	if err != nil {
+		return version.Version{}, err
+	}
+	return *v, nil
+}
+
+// newElasticsearchClient creates a client of the associated Elasticsearch cluster, authenticated as the Logstash user.
+func (d *driver) newElasticsearchClient(ls lstype.Logstash, dialer net.Dialer, v version.Version) (esclient.Client, error) {
+	username, password, err := association.ElasticsearchAuthSettings(d.client, &ls)
+	if err != nil {
+		return nil, err
+	}
+	var caCerts []*x509.Certificate
+	if ls.AssociationConf().CAIsConfigured() {
+		var caSecret corev1.Secret
+		key := types.NamespacedName{Namespace: ls.Namespace, Name: ls.AssociationConf().GetCASecretName()}
+		if err := d.client.Get(key, &caSecret); err != nil {
+			return nil, err
+		}
+		caCerts, err = certificates.ParsePEMCerts(caSecret.Data[certificates.CertFileName])
+		if err != nil {
+			return nil, err
+		}
+	}
+	auth := esclient.UserAuth{Name: username, Password: password}
+	if ls.AssociationConf().IsAPIKey() {
+		auth = esclient.UserAuth{APIKey: password}
+	}
+	return esclient.NewElasticsearchClient(
+		dialer,
+		ls.AssociationConf().GetURL(),
+		auth,
+		v,
+		caCerts,
+	), nil
+}
@@ -0,0 +1,91 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	esv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_driver_reconcileIndexBootstrap(t *testing.T) {
+	require.NoError(t, lstype.AddToScheme(scheme.Scheme))
+	require.NoError(t, esv1beta1.AddToScheme(scheme.Scheme))
+
+	esStatus := http.StatusOK
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(esStatus)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	es := esv1beta1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "es"},
+		Spec:       esv1beta1.ElasticsearchSpec{Version: "7.9.0"},
+	}
+	ls := lstype.Logstash{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"},
+		Spec: lstype.LogstashSpec{
+			Version:          "7.13.0",
+			ElasticsearchRef: commonv1beta1.ObjectSelector{Name: "es"},
+		},
+	}
+	ls.SetAssociationConf(&commonv1beta1.AssociationConf{URL: server.URL})
+	d := driver{
+		client:   k8s.WrapClient(fake.NewFakeClientWithScheme(scheme.Scheme, &es)),
+		scheme:   scheme.Scheme,
+		recorder: record.NewFakeRecorder(10),
+	}
+	state := NewState(reconcile.Request{}, &ls)
+
+	// disabled: nothing installed
+	ready, results := d.reconcileIndexBootstrap(&state, &ls, nil)
+	require.True(t, ready)
+	require.False(t, results.HasError())
+	require.Empty(t, paths)
+	require.Nil(t, ls.Status.IndexBootstrap)
+
+	// Elasticsearch fails before the first installation: Logstash is not deployed
+	ls.Spec.IndexBootstrap = lstype.IndexBootstrapSpec{Enabled: true, DataStream: &lstype.DataStreamSpec{}}
+	esStatus = http.StatusInternalServerError
+	ready, results = d.reconcileIndexBootstrap(&state, &ls, nil)
+	require.False(t, ready)
+	res, err := results.Aggregate()
+	require.NoError(t, err)
+	require.Equal(t, indexBootstrapRequeue, res.RequeueAfter)
+	require.Equal(t, lstype.IndexBootstrapFailed, ls.Status.IndexBootstrap.Phase)
+	require.Empty(t, ls.Status.IndexBootstrap.Mode)
+
+	// successful installation
+	esStatus = http.StatusOK
+	paths = nil
+	ready, _ = d.reconcileIndexBootstrap(&state, &ls, nil)
+	require.True(t, ready)
+	require.Equal(t, []string{"/_ilm/policy/logstash", "/_index_template/logstash"}, paths)
+	require.Equal(t, &lstype.IndexBootstrapStatus{
+		Phase: lstype.IndexBootstrapReady,
+		Mode:  lstype.IndexBootstrapDataStream,
+	}, ls.Status.IndexBootstrap)
+
+	// Elasticsearch fails after a successful installation: Logstash keeps being deployed
+	esStatus = http.StatusInternalServerError
+	ready, _ = d.reconcileIndexBootstrap(&state, &ls, nil)
+	require.True(t, ready)
+	require.Equal(t, lstype.IndexBootstrapFailed, ls.Status.IndexBootstrap.Phase)
+	require.Equal(t, lstype.IndexBootstrapDataStream, ls.Status.IndexBootstrap.Mode)
+}
@@ -4,12 +4,40 @@
 
 package label
 
-import "github.com/cloudptio/logstash-operator/pkg/controller/common"
+import (
+	"fmt"
+
+	"github.com/cloudptio/logstash-operator/pkg/controller/common"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
 
 const (
 	// LogstashNameLabelName used to represent a Logstash in k8s resources
 	LogstashNameLabelName = "logstash.k8s.elastic.co/name"
 
+	// VersionLabelName used to store the Logstash version of the pods
+	VersionLabelName = "logstash.k8s.elastic.co/version"
+
+	// TrackLabelName distinguishes the stable Logstash pods from the canary pod running a pipeline change under test
+	TrackLabelName = "logstash.k8s.elastic.co/track"
+	// StableTrack is the track of the Logstash pods running the current pipeline
+	StableTrack = "stable"
+	// CanaryTrack is the track of the Logstash pod running a pipeline change under test
+	CanaryTrack = "canary"
+
+	// DeadLetterQueueSlotAnnotation is the annotation storing the slot assigned to a Logstash pod, naming its
+	// directory on the shared dead letter queue volume. Slots are reused by the pods replacing deleted ones.
+	DeadLetterQueueSlotAnnotation = "logstash.k8s.elastic.co/dead-letter-queue-slot"
+
+	// InputServiceLabelName marks the input services with their name
+	InputServiceLabelName = "logstash.k8s.elastic.co/input-service"
+
+	// DiagnosticsLabelName marks the config maps holding captured diagnostics
+	DiagnosticsLabelName = "logstash.k8s.elastic.co/diagnostics"
+
 	// Type represents the Logstash type
 	Type = "logstash"
 )
@@ -17,7 +45,51 @@ const (
 // NewLabels constructs a new set of labels for a Logstash pod
 func NewLabels(logstashName string) map[string]string {
 	return map[string]string{
-		LogstashNameLabelName:  logstashName,
-		common.TypeLabelName: Type,
+		LogstashNameLabelName: logstashName,
+		common.TypeLabelName:  Type,
+	}
+}
+
+// NonCanaryRequirements exclude the canary pod from the selector of the stable Logstash pods. Unlike the stable track
+// label, they also match the pods created before the track label was introduced.
+func NonCanaryRequirements() []metav1.LabelSelectorRequirement {
+	return []metav1.LabelSelectorRequirement{
+		{Key: TrackLabelName, Operator: metav1.LabelSelectorOpNotIn, Values: []string{CanaryTrack}},
+	}
+}
+
+// NewStableSelector returns the selector of the stable Logstash pods: all the pods of the given Logstash but the canary
+// pod.
+func NewStableSelector(logstashName string) (labels.Selector, error) {
+	return metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels:      NewLabels(logstashName),
+		MatchExpressions: NonCanaryRequirements(),
+	})
+}
+
+// NewCanaryLabels constructs a new set of labels for a Logstash canary pod
+func NewCanaryLabels(logstashName string) map[string]string {
+	labels := NewLabels(logstashName)
+	labels[TrackLabelName] = CanaryTrack
+	return labels
+}
+
+// NewDiagnosticsLabels constructs a new set of labels for a config map holding diagnostics of a Logstash
+func NewDiagnosticsLabels(logstashName string) map[string]string {
+	labels := NewLabels(logstashName)
+	labels[DiagnosticsLabelName] = "true"
+	return labels
+}
+
+// ExtractVersion extracts the Logstash version from the given labels.
+func ExtractVersion(labels map[string]string) (*version.Version, error) {
+	labelValue, ok := labels[VersionLabelName]
+	if !ok {
+		return nil, fmt.Errorf("version label %s is missing", VersionLabelName)
+	}
+	v, err := version.Parse(labelValue)
+	if err != nil {
+		return nil, errors.Wrapf(err, "version label %s is invalid: %s", VersionLabelName, labelValue)
 	}
+	return v, nil
 }
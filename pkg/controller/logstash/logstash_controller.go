@@ -5,13 +5,16 @@
 package logstash
 
 import (
+	"fmt"
 	"reflect"
 	"sync/atomic"
 
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
 	logstashv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/conditions"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/finalizer"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/keystore"
@@ -19,16 +22,24 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/watches"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/monitoring"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/observer"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pipeline"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/validation"
+	lsversion "github.com/cloudptio/logstash-operator/pkg/controller/logstash/version"
 	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -44,6 +55,10 @@ var log = logf.Log.WithName(name)
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager, params operator.Parameters) error {
 	reconciler := newReconciler(mgr, params)
+	// export the statistics of the observed Logstash resources along with the controller metrics
+	if err := metrics.Registry.Register(observer.NewCollector(reconciler.observers)); err != nil {
+		return err
+	}
 	c, err := add(mgr, reconciler)
 	if err != nil {
 		return err
@@ -60,6 +75,7 @@ func newReconciler(mgr manager.Manager, params operator.Parameters) *ReconcileLo
 		recorder:       mgr.GetEventRecorderFor(name),
 		dynamicWatches: watches.NewDynamicWatches(),
 		finalizers:     finalizer.NewHandler(client),
+		observers:      observer.NewManager(runningNodes(client, params.Dialer), observer.DefaultSettings),
 		params:         params,
 	}
 }
@@ -84,6 +100,21 @@ func addWatches(c controller.Controller, r *ReconcileLogstash) error {
 		return err
 	}
 
+	// Watch pods, to assign their dead letter queue slot once created
+	if err := c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(object handler.MapObject) []reconcile.Request {
+			lsName, isSet := object.Meta.GetLabels()[label.LogstashNameLabelName]
+			if !isSet {
+				return nil
+			}
+			return []reconcile.Request{{
+				NamespacedName: types.NamespacedName{Namespace: object.Meta.GetNamespace(), Name: lsName},
+			}}
+		}),
+	}); err != nil {
+		return err
+	}
+
 	// Watch services
 	if err := c.Watch(&source.Kind{Type: &corev1.Service{}}, &handler.EnqueueRequestForOwner{
 		IsController: true,
@@ -105,6 +136,13 @@ func addWatches(c controller.Controller, r *ReconcileLogstash) error {
 		return err
 	}
 
+	// Watch pipelines selected by Logstash resources
+	if err := c.Watch(&source.Kind{Type: &logstashv1beta1.LogstashPipeline{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: selectingLogstashes(r.Client),
+	}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -119,6 +157,9 @@ type ReconcileLogstash struct {
 	finalizers     finalizer.Handler
 	dynamicWatches watches.DynamicWatches
 
+	// observers regularly retrieve the statistics of the Logstash nodes
+	observers *observer.Manager
+
 	params operator.Parameters
 
 	// iteration is the number of times this controller has run its Reconcile method
@@ -135,6 +176,9 @@ func (r *ReconcileLogstash) Reconcile(request reconcile.Request) (reconcile.Resu
 	if ok, err := association.FetchWithAssociation(r.Client, request, &ls); !ok {
 		return reconcile.Result{}, err
 	}
+	if err := monitoring.LoadAssociationConf(&ls); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	// skip reconciliation if paused
 	if common.IsPaused(ls.ObjectMeta) {
@@ -187,9 +231,21 @@ func (r *ReconcileLogstash) doReconcile(request reconcile.Request, ls *logstashv
 	ver, err := version.Parse(ls.Spec.Version)
 	if err != nil {
 		k8s.EmitErrorEvent(r.recorder, err, ls, events.EventReasonValidation, "Invalid version '%s': %v", ls.Spec.Version, err)
+		r.reportInvalid(ls, conditions.InvalidVersion(ls.Generation, err))
 		return reconcile.Result{}, err
 	}
 
+	if valid, err := r.validate(ls); err != nil || !valid {
+		return reconcile.Result{}, err
+	}
+
+	// run the diagnostic actions requested through annotations before the status is computed, as removing the
+	// annotations updates the resource
+	diagnosticsResults := reconcileDiagnostics(r.Client, r.scheme, r.recorder, ls, runningNodes(r.Client, r.params.Dialer))
+
+	// start observing the Logstash nodes statistics, if not already done
+	lsObserver := r.observers.Observe(k8s.ExtractNamespacedName(ls))
+
 	state := NewState(request, ls)
 	driver, err := newDriver(r, r.scheme, *ver, r.dynamicWatches, r.recorder)
 	if err != nil {
@@ -197,6 +253,19 @@ func (r *ReconcileLogstash) doReconcile(request reconcile.Request, ls *logstashv
 	}
 	// version specific reconcile
 	results := driver.Reconcile(&state, ls, r.params)
+	results.WithResults(diagnosticsResults)
+	_, reconcileErr := results.Aggregate()
+	state.SetConditions(conditions.ConfigValid(ls.Generation, nil), conditions.Reconciled(ls.Generation, reconcileErr))
+	if ls.Status.Canary != nil && ls.Status.Canary.Phase == logstashv1beta1.CanaryProgressing {
+		state.SetConditions(conditions.New(commonv1beta1.ProgressingCondition, true, ls.Generation,
+			string(logstashv1beta1.CanaryProgressing), ls.Status.Canary.Message))
+	}
+
+	state.UpdateDeadLetterQueueStatus(lsObserver.LastState())
+	if ls.Spec.DeadLetterQueue.Enabled {
+		// keep the reported dead letter queues size up-to-date
+		results.WithResult(reconcile.Result{RequeueAfter: deadLetterQueueStatusRequeue})
+	}
 
 	// update status
 	err = r.updateStatus(state)
@@ -210,6 +279,45 @@ func (r *ReconcileLogstash) doReconcile(request reconcile.Request, ls *logstashv
 	return res, err
 }
 
+// validate checks the Logstash spec against the version of the running Logstash nodes.
+func (r *ReconcileLogstash) validate(ls *logstashv1beta1.Logstash) (bool, error) {
+	var pods corev1.PodList
+	if err := r.List(&pods, client.InNamespace(ls.Namespace), client.MatchingLabels(label.NewLabels(ls.Name))); err != nil {
+		return false, err
+	}
+	currentVersion, err := lsversion.MinVersion(pods.Items)
+	if err != nil {
+		return false, err
+	}
+	violations, err := validation.Validate(*ls, currentVersion)
+	if err != nil {
+		return false, err
+	}
+	if len(violations) > 0 {
+		log.Error(
+			fmt.Errorf("manifest validation failed"),
+			"Logstash manifest validation failed",
+			"namespace", ls.Namespace,
+			"logstash_name", ls.Name,
+			"violations", violations,
+		)
+		for _, v := range violations {
+			r.recorder.Event(ls, corev1.EventTypeWarning, events.EventReasonValidation, v.Reason)
+		}
+		r.reportInvalid(ls, conditions.ConfigValid(ls.Generation, violations))
+		return false, nil
+	}
+	return true, nil
+}
+
+// reportInvalid sets the given ConfigValid condition in the status of a Logstash resource that cannot be reconciled.
+func (r *ReconcileLogstash) reportInvalid(ls *logstashv1beta1.Logstash, condition commonv1beta1.Condition) {
+	ls.Status.Conditions = ls.Status.Conditions.Set(condition)
+	if err := r.Status().Update(ls); err != nil && !errors.IsConflict(err) {
+		log.Error(err, "Failed to update status", "namespace", ls.Namespace, "logstash_name", ls.Name)
+	}
+}
+
 func (r *ReconcileLogstash) updateStatus(state State) error {
 	current := state.originalLogstash
 	if reflect.DeepEqual(current.Status, state.Logstash.Status) {
@@ -226,6 +334,8 @@ func (r *ReconcileLogstash) updateStatus(state State) error {
 func (r *ReconcileLogstash) finalizersFor(ls *logstashv1beta1.Logstash) []finalizer.Finalizer {
 	return []finalizer.Finalizer{
 		secretWatchFinalizer(*ls, r.dynamicWatches),
+		r.observers.Finalizer(k8s.ExtractNamespacedName(ls)),
 		keystore.Finalizer(k8s.ExtractNamespacedName(ls), r.dynamicWatches, ls.Kind),
+		pipeline.Finalizer(r.Client, *ls),
 	}
 }
@@ -0,0 +1,183 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package management
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/license"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/configmap"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/es"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pipeline"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pod"
+	"github.com/cloudptio/logstash-operator/pkg/utils/stringsutil"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// UserSuffix is used to suffix the central management user and associated secret resources.
+	UserSuffix = "logstash-management-user"
+
+	// seedUsername is recorded as the author of the seeded pipelines, as displayed in Kibana.
+	seedUsername = "logstash-operator"
+	// pipelineType and pipelineVersion identify the format of the centrally managed pipelines.
+	pipelineType    = "logstash_pipeline"
+	pipelineVersion = "1"
+	// lastModifiedFormat is the format of the last modification date of the centrally managed pipelines.
+	lastModifiedFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// IsActive returns true if the given Logstash loads its pipelines from Elasticsearch.
+func IsActive(ls v1beta1.Logstash) bool {
+	return ls.Spec.CentralManagement.Enabled && ls.Status.CentralManagement != nil && ls.Status.CentralManagement.Active
+}
+
+// AuthSecretKeySelector selects the password of the central management user in the Logstash namespace.
+func AuthSecretKeySelector(ls v1beta1.Logstash) *corev1.SecretKeySelector {
+	return association.ClearTextSecretKeySelector(&ls, UserSuffix)
+}
+
+// ActiveAuthSecretKeySelector selects the active password of the central management user in the given secret, which
+// holds both the active and the previous password while the credentials are rotated.
+func ActiveAuthSecretKeySelector(ls v1beta1.Logstash, secret corev1.Secret) *corev1.SecretKeySelector {
+	return association.ActiveSecretKeySelector(&ls, UserSuffix, secret)
+}
+
+// LicenseCompatible returns true if the given Elasticsearch license includes the central management of the Logstash
+// pipelines: an active gold, platinum or trial license.
+func LicenseCompatible(l esclient.License) bool {
+	if l.Status != "" && l.Status != "active" {
+		return false
+	}
+	licenseType := license.ElasticsearchLicenseType(l.Type)
+	if licenseType == license.ElasticsearchLicenseTypeTrial {
+		return true
+	}
+	order, known := license.ElasticsearchLicenseTypeOrder[licenseType]
+	return known && order >= license.ElasticsearchLicenseTypeOrder[license.ElasticsearchLicenseTypeGold]
+}
+
+// PipelineIDs returns the ids of the centrally managed pipelines run by the given Logstash. They default to the ids of
+// the pipelines in the given pipeline config map: the main pipeline, the topology pipelines, the selected pipelines and
+// the dead letter queue reprocessing pipeline, since Logstash ignores the pipelines.yml file with central management.
+func PipelineIDs(ls v1beta1.Logstash, cm corev1.ConfigMap) []string {
+	if len(ls.Spec.CentralManagement.PipelineIDs) > 0 {
+		return ls.Spec.CentralManagement.PipelineIDs
+	}
+	var ids []string
+	for key := range cm.Data {
+		if id, isPipeline := pipeline.IDFromConfigKey(key); isPipeline {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return append([]string{pipeline.MainPipelineID}, ids...)
+}
+
+// Env returns the environment variables configuring the given Logstash to load its pipelines from the associated
+// Elasticsearch cluster with the given credentials of the central management user, including the credentials referenced
+// by the seeded pipelines.
+func Env(ls v1beta1.Logstash, cm corev1.ConfigMap, auth *corev1.SecretKeySelector) []corev1.EnvVar {
+	ids := PipelineIDs(ls, cm)
+	quoted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		quoted = append(quoted, fmt.Sprintf("%q", id))
+	}
+	env := []corev1.EnvVar{
+		{Name: "XPACK_MANAGEMENT_ENABLED", Value: "true"},
+		// the pipeline ids are written as a YAML list in the settings file
+		{Name: "XPACK_MANAGEMENT_PIPELINE_ID", Value: "[" + strings.Join(quoted, ", ") + "]"},
+		{Name: "XPACK_MANAGEMENT_ELASTICSEARCH_HOSTS", Value: ls.AssociationConf().GetURL()},
+		{Name: "XPACK_MANAGEMENT_ELASTICSEARCH_USERNAME", Value: auth.Key},
+		{Name: "XPACK_MANAGEMENT_ELASTICSEARCH_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: auth}},
+	}
+	if ls.Spec.CentralManagement.PollInterval != "" {
+		env = append(env, corev1.EnvVar{
+			Name: "XPACK_MANAGEMENT_LOGSTASH_POLL_INTERVAL", Value: ls.Spec.CentralManagement.PollInterval,
+		})
+	}
+	if ls.AssociationConf().CAIsConfigured() {
+		env = append(env, corev1.EnvVar{
+			Name: "XPACK_MANAGEMENT_ELASTICSEARCH_SSL_CERTIFICATE_AUTHORITY", Value: es.CaCertFile(),
+		})
+	}
+	if credentials, exists := configmap.CredentialsEnvVar(ls); exists {
+		env = append(env, credentials)
+	}
+	return env
+}
+
+// WithCentralManagement configures the given Logstash pod template to load the pipelines from Elasticsearch, among
+// the ones of the given pipeline config map by default, with the given credentials of the central management user.
+// It does nothing if central management is not active.
+func WithCentralManagement(podTemplate *corev1.PodTemplateSpec, ls v1beta1.Logstash, cm corev1.ConfigMap, auth *corev1.SecretKeySelector) {
+	if !IsActive(ls) || auth == nil {
+		return
+	}
+	logstashContainer := pod.GetLogstashContainer(podTemplate.Spec)
+	if logstashContainer == nil {
+		return
+	}
+	logstashContainer.Env = append(logstashContainer.Env, Env(ls, cm, auth)...)
+}
+
+// Pipelines returns the configuration of the pipelines of the given pipeline config map, indexed by pipeline id.
+// The config map is expected to be built by configmap.NewCentrallyManagedPipelineConfigMap, so that the credentials of
+// the association user are not stored in Elasticsearch.
+func Pipelines(cm corev1.ConfigMap) map[string]string {
+	pipelines := map[string]string{
+		pipeline.MainPipelineID: cm.Data["input_main.conf"] + "\n" + cm.Data["output_main.conf"],
+	}
+	for key, config := range cm.Data {
+		if id, isPipeline := pipeline.IDFromConfigKey(key); isPipeline {
+			pipelines[id] = config
+		}
+	}
+	return pipelines
+}
+
+// SeedPipelines stores the given pipelines in Elasticsearch, except the ones already seeded and the ones that already
+// exist, possibly edited in Kibana since. It returns the ids of all the seeded pipelines, including the given ones.
+func SeedPipelines(
+	ctx context.Context,
+	c esclient.Client,
+	pipelines map[string]string,
+	seeded []string,
+	now time.Time,
+) ([]string, error) {
+	ids := make([]string, 0, len(pipelines))
+	for id := range pipelines {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	result := append([]string{}, seeded...)
+	for _, id := range ids {
+		if stringsutil.StringInSlice(id, seeded) {
+			// never recreate a pipeline deleted in Kibana
+			continue
+		}
+		created, err := c.CreateLogstashPipeline(ctx, id, esclient.LogstashPipeline{
+			Pipeline:         pipelines[id],
+			LastModified:     now.UTC().Format(lastModifiedFormat),
+			PipelineMetadata: esclient.LogstashPipelineMetadata{Type: pipelineType, Version: pipelineVersion},
+			Username:         seedUsername,
+		})
+		if err != nil {
+			return result, err
+		}
+		if created {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,165 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package management
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLicenseCompatible(t *testing.T) {
+	tests := []struct {
+		license esclient.License
+		want    bool
+	}{
+		{license: esclient.License{Type: "basic", Status: "active"}, want: false},
+		{license: esclient.License{Type: "trial", Status: "active"}, want: true},
+		{license: esclient.License{Type: "gold", Status: "active"}, want: true},
+		{license: esclient.License{Type: "platinum", Status: "active"}, want: true},
+		{license: esclient.License{Type: "platinum", Status: "expired"}, want: false},
+		{license: esclient.License{Type: "unknown", Status: "active"}, want: false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, LicenseCompatible(tt.license), tt.license.Type+" "+tt.license.Status)
+	}
+}
+
+func TestPipelineIDs(t *testing.T) {
+	ls := v1beta1.Logstash{}
+	cm := corev1.ConfigMap{Data: map[string]string{
+		"input_main.conf":                 "input {}",
+		"output_main.conf":                "output {}",
+		"pipelines.yml":                   "- pipeline.id: main",
+		"pipeline.ingest.conf":            "topology pipeline",
+		"pipeline.selected.conf":          "selected pipeline",
+		"pipeline.dead_letter_queue.conf": "dead letter queue reprocessing pipeline",
+	}}
+	require.Equal(t, []string{"main"}, PipelineIDs(ls, corev1.ConfigMap{}))
+	require.Equal(t, []string{"main", "dead_letter_queue", "ingest", "selected"}, PipelineIDs(ls, cm))
+
+	ls.Spec.CentralManagement.PipelineIDs = []string{"apache-*"}
+	require.Equal(t, []string{"apache-*"}, PipelineIDs(ls, cm))
+}
+
+func TestWithCentralManagement(t *testing.T) {
+	ls := v1beta1.Logstash{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"},
+		Spec: v1beta1.LogstashSpec{
+			CentralManagement: v1beta1.CentralManagementSpec{Enabled: true, PollInterval: "10s"},
+		},
+	}
+	ls.SetAssociationConf(&commonv1beta1.AssociationConf{
+		URL:            "https://es:9200",
+		AuthSecretName: "ls-logstash-user",
+		AuthSecretKey:  "ns-ls-logstash-user",
+		CACertProvided: true,
+		CASecretName:   "ca",
+	})
+	// the alternate user is active after a rotation
+	auth := ActiveAuthSecretKeySelector(ls, corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{association.ActiveCredentialsAnnotation: "ns-ls-logstash-management-user-alt"},
+	}})
+	newPodTemplate := func() corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: v1beta1.LogstashContainerName}}}}
+	}
+
+	// not active until the license is checked
+	podTemplate := newPodTemplate()
+	WithCentralManagement(&podTemplate, ls, corev1.ConfigMap{}, auth)
+	require.Empty(t, podTemplate.Spec.Containers[0].Env)
+
+	ls.Status.CentralManagement = &v1beta1.CentralManagementStatus{Phase: v1beta1.CentralManagementFailed, Active: true}
+	podTemplate = newPodTemplate()
+	WithCentralManagement(&podTemplate, ls, corev1.ConfigMap{}, auth)
+	require.Equal(t, []corev1.EnvVar{
+		{Name: "XPACK_MANAGEMENT_ENABLED", Value: "true"},
+		{Name: "XPACK_MANAGEMENT_PIPELINE_ID", Value: `["main"]`},
+		{Name: "XPACK_MANAGEMENT_ELASTICSEARCH_HOSTS", Value: "https://es:9200"},
+		{Name: "XPACK_MANAGEMENT_ELASTICSEARCH_USERNAME", Value: "ns-ls-logstash-management-user-alt"},
+		{Name: "XPACK_MANAGEMENT_ELASTICSEARCH_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "ls-logstash-management-user"},
+				Key:                  "ns-ls-logstash-management-user-alt",
+			},
+		}},
+		{Name: "XPACK_MANAGEMENT_LOGSTASH_POLL_INTERVAL", Value: "10s"},
+		{Name: "XPACK_MANAGEMENT_ELASTICSEARCH_SSL_CERTIFICATE_AUTHORITY", Value: "/usr/share/kibana/config/elasticsearch-certs/tls.crt"},
+		// referenced by the seeded pipelines
+		{Name: "ES_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "ls-logstash-user"},
+				Key:                  "ns-ls-logstash-user",
+			},
+		}},
+	}, podTemplate.Spec.Containers[0].Env)
+
+	// disabling central management takes effect immediately
+	ls.Spec.CentralManagement.Enabled = false
+	podTemplate = newPodTemplate()
+	WithCentralManagement(&podTemplate, ls, corev1.ConfigMap{}, auth)
+	require.Empty(t, podTemplate.Spec.Containers[0].Env)
+}
+
+func TestPipelines(t *testing.T) {
+	cm := corev1.ConfigMap{Data: map[string]string{
+		"input_main.conf":        "input {}",
+		"output_main.conf":       "output {}",
+		"pipelines.yml":          "- pipeline.id: main",
+		"pipeline.index.conf":    "filter {}",
+		"pipeline.selected.conf": "output {}",
+	}}
+	require.Equal(t, map[string]string{
+		"main":     "input {}\noutput {}",
+		"index":    "filter {}",
+		"selected": "output {}",
+	}, Pipelines(cm))
+}
+
+func TestSeedPipelines(t *testing.T) {
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	var created []string
+	client := esclient.NewMockClient(version.MustParse("7.4.0"), func(req *http.Request) *http.Response {
+		switch req.URL.Path {
+		case "/.logstash/_create/existing":
+			return esclient.NewMockResponse(409, req, "{}")
+		case "/.logstash/_create/failing":
+			return esclient.NewMockResponse(500, req, "{}")
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"pipeline": "input {}",
+			"last_modified": "2019-10-01T12:00:00.000Z",
+			"pipeline_metadata": {"type": "logstash_pipeline", "version": "1"},
+			"username": "logstash-operator"
+		}`, string(body))
+		created = append(created, req.URL.Path)
+		return esclient.NewMockResponse(201, req, "{}")
+	})
+
+	pipelines := map[string]string{"main": "input {}", "existing": "input {}", "deleted": "input {}"}
+	seeded, err := SeedPipelines(context.Background(), client, pipelines, []string{"deleted"}, now)
+	require.NoError(t, err)
+	// already seeded pipelines are not recreated, existing ones are not overwritten
+	require.Equal(t, []string{"/.logstash/_create/main"}, created)
+	require.Equal(t, []string{"deleted", "main"}, seeded)
+
+	pipelines["failing"] = "input {}"
+	seeded, err = SeedPipelines(context.Background(), client, pipelines, seeded, now)
+	require.Error(t, err)
+	require.Equal(t, []string{"deleted", "main"}, seeded)
+}
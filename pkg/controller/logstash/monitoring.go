@@ -0,0 +1,80 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"fmt"
+	"hash"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/watches"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/configmap"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/monitoring"
+	lsname "github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func monitoringSecretWatchKey(logstash lstype.Logstash) string {
+	return fmt.Sprintf("%s-%s-monitoring-es-secrets", logstash.Namespace, logstash.Name)
+}
+
+// reconcileMetricbeatConfig reconciles the configuration of the Metricbeat sidecar container, or deletes it if
+// monitoring data is not collected by Metricbeat.
+func (d *driver) reconcileMetricbeatConfig(ls lstype.Logstash) error {
+	if monitoring.IsConfigured(ls) && ls.Spec.Monitoring.GetMode() == lstype.MetricbeatMonitoringMode {
+		return configmap.ReconcileConfigMap(d.client, d.scheme, ls, monitoring.NewMetricbeatConfigMap(ls))
+	}
+	metricbeatConfig := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ls.Namespace, Name: lsname.MetricbeatConfigMap(ls.Name)},
+	}
+	if err := d.client.Delete(&metricbeatConfig); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// writeMonitoringChecksum writes the monitoring user password and the monitoring Elasticsearch CA certificate to the
+// given configuration checksum, so that Logstash pods are rotated when they change.
+func (d *driver) writeMonitoringChecksum(ls lstype.Logstash, configChecksum hash.Hash) error {
+	if !monitoring.IsConfigured(ls) {
+		d.dynamicWatches.Secrets.RemoveHandlerForKey(monitoringSecretWatchKey(ls))
+		return nil
+	}
+
+	authSecretKey := monitoring.AuthSecretKey(ls)
+	caSecretKey := monitoring.CASecretKey(ls)
+	watched := []types.NamespacedName{authSecretKey}
+	if ls.MonitoringAssociationConf().CAIsConfigured() {
+		watched = append(watched, caSecretKey)
+	}
+	if err := d.dynamicWatches.Secrets.AddHandler(watches.NamedWatch{
+		Name:    monitoringSecretWatchKey(ls),
+		Watched: watched,
+		Watcher: k8s.ExtractNamespacedName(&ls),
+	}); err != nil {
+		return err
+	}
+
+	var authSecret corev1.Secret
+	if err := d.client.Get(authSecretKey, &authSecret); err != nil {
+		return err
+	}
+	_, _ = configChecksum.Write(authSecret.Data[ls.MonitoringAssociationConf().GetAuthSecretKey()])
+
+	if !ls.MonitoringAssociationConf().CAIsConfigured() {
+		return nil
+	}
+	var caSecret corev1.Secret
+	if err := d.client.Get(caSecretKey, &caSecret); err != nil {
+		return err
+	}
+	_, _ = configChecksum.Write(caSecret.Data[certificates.CAFileName])
+	return nil
+}
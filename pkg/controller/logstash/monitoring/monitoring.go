@@ -0,0 +1,201 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package monitoring
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/volume"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/configmap"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pod"
+	"github.com/cloudptio/logstash-operator/pkg/utils/stringsutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// MetricbeatContainerName is the name of the Metricbeat sidecar container collecting the monitoring data.
+	MetricbeatContainerName = "metricbeat"
+
+	caCertsVolumeName      = "monitoring-es-certs"
+	caCertsVolumeMountPath = "/mnt/elastic-internal/monitoring-es-certs"
+
+	metricbeatConfigVolumeName      = "metricbeat-config"
+	metricbeatConfigVolumeMountPath = "/mnt/elastic-internal/metricbeat-config"
+	metricbeatConfigFileName        = "metricbeat.yml"
+
+	defaultMetricbeatImageRepositoryAndName = "docker.elastic.co/beats/metricbeat"
+
+	hostsEnvVar    = "MONITORING_ELASTICSEARCH_HOSTS"
+	usernameEnvVar = "MONITORING_ELASTICSEARCH_USERNAME"
+	passwordEnvVar = "MONITORING_ELASTICSEARCH_PASSWORD"
+)
+
+// metricbeatConfig returns the configuration of the Metricbeat sidecar container, collecting the Logstash monitoring
+// data from the local monitoring API. The monitoring cluster connection details are read from the environment of the
+// Metricbeat container.
+func metricbeatConfig(ls v1beta1.Logstash) string {
+	config := fmt.Sprintf(`metricbeat.modules:
+- module: logstash
+  metricsets: ["node", "node_stats"]
+  period: 10s
+  hosts: ["http://localhost:%d"]
+  xpack.enabled: true
+output.elasticsearch:
+  hosts: ["${%s}"]
+  username: "${%s}"
+  password: "${%s}"
+`, pod.MonitorHTTPPort, hostsEnvVar, usernameEnvVar, passwordEnvVar)
+	if ls.MonitoringAssociationConf().CAIsConfigured() {
+		config += fmt.Sprintf("  ssl.certificate_authorities: [\"%s\"]\n", path.Join(caCertsVolumeMountPath, certificates.CAFileName))
+	}
+	return config
+}
+
+// metricbeatResources are the resources of the Metricbeat sidecar container.
+var metricbeatResources = corev1.ResourceRequirements{
+	Requests: map[corev1.ResourceName]resource.Quantity{
+		corev1.ResourceMemory: resource.MustParse("100Mi"),
+		corev1.ResourceCPU:    resource.MustParse("100m"),
+	},
+	Limits: map[corev1.ResourceName]resource.Quantity{
+		corev1.ResourceMemory: resource.MustParse("200Mi"),
+	},
+}
+
+// LoadAssociationConf reads the configuration of the association with the monitoring Elasticsearch cluster from the
+// annotations of the given Logstash.
+func LoadAssociationConf(ls *v1beta1.Logstash) error {
+	conf, err := association.GetAssociationConfFromAnnotation(ls, annotation.MonitoringAssociationConfAnnotation)
+	if err != nil {
+		return err
+	}
+	ls.SetMonitoringAssociationConf(conf)
+	return nil
+}
+
+// IsConfigured returns true if monitoring is enabled for the given Logstash and the association with the monitoring
+// Elasticsearch cluster is established. The CA certificate is optional, as the monitoring cluster may not use TLS.
+func IsConfigured(ls v1beta1.Logstash) bool {
+	conf := ls.MonitoringAssociationConf()
+	return ls.Spec.Monitoring.IsEnabled() && conf.AuthIsConfigured() && conf.URLIsConfigured()
+}
+
+// AuthSecretKey returns the key of the secret holding the password of the monitoring user.
+func AuthSecretKey(ls v1beta1.Logstash) types.NamespacedName {
+	return types.NamespacedName{Namespace: ls.Namespace, Name: ls.MonitoringAssociationConf().GetAuthSecretName()}
+}
+
+// CASecretKey returns the key of the secret holding the CA certificate of the monitoring Elasticsearch cluster.
+func CASecretKey(ls v1beta1.Logstash) types.NamespacedName {
+	return types.NamespacedName{Namespace: ls.Namespace, Name: ls.MonitoringAssociationConf().GetCASecretName()}
+}
+
+// caCertSecretVolume returns a SecretVolume to hold the monitoring Elasticsearch CA certs for the given Logstash resource.
+func caCertSecretVolume(ls v1beta1.Logstash) volume.SecretVolume {
+	return volume.NewSecretVolumeWithMountPath(
+		ls.MonitoringAssociationConf().GetCASecretName(),
+		caCertsVolumeName,
+		caCertsVolumeMountPath,
+	)
+}
+
+func passwordEnvVarSource(ls v1beta1.Logstash) *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: ls.MonitoringAssociationConf().GetAuthSecretName()},
+			Key:                  ls.MonitoringAssociationConf().GetAuthSecretKey(),
+		},
+	}
+}
+
+// internalCollectionEnv returns the environment variables configuring Logstash to ship its own monitoring data
+// through the legacy xpack.monitoring settings.
+func internalCollectionEnv(ls v1beta1.Logstash) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		{Name: "XPACK_MONITORING_ENABLED", Value: "true"},
+		{Name: "XPACK_MONITORING_ELASTICSEARCH_HOSTS", Value: ls.MonitoringAssociationConf().GetURL()},
+		{Name: "XPACK_MONITORING_ELASTICSEARCH_USERNAME", Value: ls.MonitoringAssociationConf().GetAuthSecretKey()},
+		{Name: "XPACK_MONITORING_ELASTICSEARCH_PASSWORD", ValueFrom: passwordEnvVarSource(ls)},
+	}
+	if ls.MonitoringAssociationConf().CAIsConfigured() {
+		env = append(env, corev1.EnvVar{
+			Name:  "XPACK_MONITORING_ELASTICSEARCH_SSL_CERTIFICATE_AUTHORITY",
+			Value: path.Join(caCertsVolumeMountPath, certificates.CAFileName),
+		})
+	}
+	return env
+}
+
+// metricbeatContainer returns the Metricbeat sidecar container collecting the monitoring data of the Logstash node.
+func metricbeatContainer(ls v1beta1.Logstash) corev1.Container {
+	volumeMounts := []corev1.VolumeMount{metricbeatConfigVolume(ls).VolumeMount()}
+	if ls.MonitoringAssociationConf().CAIsConfigured() {
+		volumeMounts = append(volumeMounts, caCertSecretVolume(ls).VolumeMount())
+	}
+	return corev1.Container{
+		Name:  MetricbeatContainerName,
+		Image: stringsutil.Concat(defaultMetricbeatImageRepositoryAndName, ":", ls.Spec.Version),
+		Args:  []string{"-e", "-c", path.Join(metricbeatConfigVolumeMountPath, metricbeatConfigFileName)},
+		Env: []corev1.EnvVar{
+			{Name: hostsEnvVar, Value: ls.MonitoringAssociationConf().GetURL()},
+			{Name: usernameEnvVar, Value: ls.MonitoringAssociationConf().GetAuthSecretKey()},
+			{Name: passwordEnvVar, ValueFrom: passwordEnvVarSource(ls)},
+		},
+		VolumeMounts: volumeMounts,
+		Resources:    metricbeatResources,
+	}
+}
+
+func metricbeatConfigVolume(ls v1beta1.Logstash) volume.ConfigMapVolume {
+	return volume.NewConfigMapVolume(
+		name.MetricbeatConfigMap(ls.Name), metricbeatConfigVolumeName, metricbeatConfigVolumeMountPath,
+	)
+}
+
+// NewMetricbeatConfigMap builds the config map containing the configuration of the Metricbeat sidecar container.
+func NewMetricbeatConfigMap(ls v1beta1.Logstash) corev1.ConfigMap {
+	return configmap.NewConfigMapWithData(
+		types.NamespacedName{Namespace: ls.Namespace, Name: name.MetricbeatConfigMap(ls.Name)},
+		map[string]string{metricbeatConfigFileName: metricbeatConfig(ls)},
+	)
+}
+
+// WithMonitoring configures the given Logstash pod template to ship monitoring data to the monitoring Elasticsearch
+// cluster, either from Logstash itself or from a Metricbeat sidecar container depending on the monitoring mode.
+// It does nothing if the association with the monitoring cluster is not established.
+func WithMonitoring(podTemplate *corev1.PodTemplateSpec, ls v1beta1.Logstash) {
+	if !IsConfigured(ls) {
+		return
+	}
+
+	caIsConfigured := ls.MonitoringAssociationConf().CAIsConfigured()
+	caVolume := caCertSecretVolume(ls)
+	if caIsConfigured {
+		podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, caVolume.Volume())
+	}
+
+	switch ls.Spec.Monitoring.GetMode() {
+	case v1beta1.MetricbeatMonitoringMode:
+		podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, metricbeatConfigVolume(ls).Volume())
+		podTemplate.Spec.Containers = append(podTemplate.Spec.Containers, metricbeatContainer(ls))
+	default:
+		logstashContainer := pod.GetLogstashContainer(podTemplate.Spec)
+		if logstashContainer == nil {
+			return
+		}
+		logstashContainer.Env = append(logstashContainer.Env, internalCollectionEnv(ls)...)
+		if caIsConfigured {
+			logstashContainer.VolumeMounts = append(logstashContainer.VolumeMounts, caVolume.VolumeMount())
+		}
+	}
+}
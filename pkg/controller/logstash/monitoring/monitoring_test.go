@@ -0,0 +1,114 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package monitoring
+
+import (
+	"testing"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func logstashWithMonitoring(mode v1beta1.MonitoringMode, conf *commonv1beta1.AssociationConf) v1beta1.Logstash {
+	ls := v1beta1.Logstash{
+		Spec: v1beta1.LogstashSpec{
+			Version: "7.4.0",
+			Monitoring: v1beta1.MonitoringSpec{
+				ElasticsearchRef: commonv1beta1.ObjectSelector{Name: "monitoring"},
+				Mode:             mode,
+			},
+		},
+	}
+	ls.Name = "ls"
+	ls.SetMonitoringAssociationConf(conf)
+	return ls
+}
+
+func newPodTemplate() corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: v1beta1.LogstashContainerName}},
+		},
+	}
+}
+
+func envNames(env []corev1.EnvVar) []string {
+	names := make([]string, 0, len(env))
+	for _, e := range env {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+var confFixture = &commonv1beta1.AssociationConf{
+	AuthSecretName: "ls-logstash-monitoring-user",
+	AuthSecretKey:  "ns-ls-logstash-monitoring-user",
+	CASecretName:   "ls-ls-monitoring-es-ca",
+	URL:            "https://monitoring-es-http.ns.svc:9200",
+}
+
+func TestWithMonitoring(t *testing.T) {
+	t.Run("association not established", func(t *testing.T) {
+		podTemplate := newPodTemplate()
+		WithMonitoring(&podTemplate, logstashWithMonitoring(v1beta1.InternalMonitoringMode, nil))
+		require.Equal(t, newPodTemplate(), podTemplate)
+	})
+
+	t.Run("internal collection", func(t *testing.T) {
+		podTemplate := newPodTemplate()
+		WithMonitoring(&podTemplate, logstashWithMonitoring("", confFixture))
+		require.Len(t, podTemplate.Spec.Containers, 1)
+		container := podTemplate.Spec.Containers[0]
+		require.Equal(t, []string{
+			"XPACK_MONITORING_ENABLED",
+			"XPACK_MONITORING_ELASTICSEARCH_HOSTS",
+			"XPACK_MONITORING_ELASTICSEARCH_USERNAME",
+			"XPACK_MONITORING_ELASTICSEARCH_PASSWORD",
+			"XPACK_MONITORING_ELASTICSEARCH_SSL_CERTIFICATE_AUTHORITY",
+		}, envNames(container.Env))
+		require.Equal(t, confFixture.AuthSecretName, container.Env[3].ValueFrom.SecretKeyRef.Name)
+		require.Equal(t, caCertsVolumeName, container.VolumeMounts[0].Name)
+		require.Equal(t, confFixture.CASecretName, podTemplate.Spec.Volumes[0].Secret.SecretName)
+	})
+
+	t.Run("metricbeat sidecar", func(t *testing.T) {
+		podTemplate := newPodTemplate()
+		WithMonitoring(&podTemplate, logstashWithMonitoring(v1beta1.MetricbeatMonitoringMode, confFixture))
+		require.Len(t, podTemplate.Spec.Containers, 2)
+		require.Empty(t, podTemplate.Spec.Containers[0].Env)
+		metricbeat := podTemplate.Spec.Containers[1]
+		require.Equal(t, MetricbeatContainerName, metricbeat.Name)
+		require.Equal(t, "docker.elastic.co/beats/metricbeat:7.4.0", metricbeat.Image)
+		require.Equal(t, []string{hostsEnvVar, usernameEnvVar, passwordEnvVar}, envNames(metricbeat.Env))
+		require.Len(t, podTemplate.Spec.Volumes, 2)
+	})
+
+	t.Run("no CA configured", func(t *testing.T) {
+		conf := *confFixture
+		conf.CASecretName = ""
+		conf.URL = "http://monitoring-es-http.ns.svc:9200"
+
+		podTemplate := newPodTemplate()
+		WithMonitoring(&podTemplate, logstashWithMonitoring("", &conf))
+		container := podTemplate.Spec.Containers[0]
+		require.NotContains(t, envNames(container.Env), "XPACK_MONITORING_ELASTICSEARCH_SSL_CERTIFICATE_AUTHORITY")
+		require.Empty(t, container.VolumeMounts)
+		require.Empty(t, podTemplate.Spec.Volumes)
+
+		podTemplate = newPodTemplate()
+		ls := logstashWithMonitoring(v1beta1.MetricbeatMonitoringMode, &conf)
+		WithMonitoring(&podTemplate, ls)
+		require.Len(t, podTemplate.Spec.Volumes, 1)
+		require.Len(t, podTemplate.Spec.Containers[1].VolumeMounts, 1)
+		require.NotContains(t, metricbeatConfig(ls), "ssl.certificate_authorities")
+	})
+}
+
+func Test_metricbeatConfig(t *testing.T) {
+	config := metricbeatConfig(logstashWithMonitoring(v1beta1.MetricbeatMonitoringMode, confFixture))
+	require.Contains(t, config, `ssl.certificate_authorities: ["/mnt/elastic-internal/monitoring-es-certs/ca.crt"]`)
+}
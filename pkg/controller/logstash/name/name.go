@@ -11,6 +11,11 @@ import (
 const (
 	httpServiceSuffix       = "http"
 	pipelineConfigMapSuffix = "pipeline"
+	canarySuffix            = "canary"
+	metricbeatSuffix        = "metricbeat"
+	deadLetterQueueSuffix   = "dlq"
+	diagnosticsSuffix       = "diag"
+	configFilesSuffix       = "config"
 )
 
 // LSNamer is a Namer that is configured with the defaults for resources related to a Logstash resource.
@@ -24,6 +29,34 @@ func Deployment(lsName string) string {
 	return LSNamer.Suffix(lsName)
 }
 
+func InputService(lsName, serviceName string) string {
+	return LSNamer.Suffix(lsName, serviceName)
+}
+
 func PipelineConfigMap(lsName string) string {
 	return LSNamer.Suffix(lsName, pipelineConfigMapSuffix)
 }
+
+func CanaryDeployment(lsName string) string {
+	return LSNamer.Suffix(lsName, canarySuffix)
+}
+
+func CanaryPipelineConfigMap(lsName string) string {
+	return LSNamer.Suffix(lsName, pipelineConfigMapSuffix, canarySuffix)
+}
+
+func MetricbeatConfigMap(lsName string) string {
+	return LSNamer.Suffix(lsName, metricbeatSuffix)
+}
+
+func DeadLetterQueueClaim(lsName string) string {
+	return LSNamer.Suffix(lsName, deadLetterQueueSuffix)
+}
+
+func ConfigFilesConfigMap(lsName string) string {
+	return LSNamer.Suffix(lsName, configFilesSuffix)
+}
+
+func DiagnosticsConfigMap(lsName string, timestamp string) string {
+	return LSNamer.Suffix(lsName, diagnosticsSuffix, timestamp)
+}
@@ -0,0 +1,38 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	lsclient "github.com/cloudptio/logstash-operator/pkg/controller/logstash/client"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/observer"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pod"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/cloudptio/logstash-operator/pkg/utils/net"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// runningNodes returns a function listing the running nodes of a Logstash resource, to be observed.
+func runningNodes(c k8s.Client, dialer net.Dialer) observer.NodesFunc {
+	return func(logstash types.NamespacedName) (map[string]lsclient.Client, error) {
+		var pods corev1.PodList
+		if err := c.List(&pods,
+			client.InNamespace(logstash.Namespace),
+			client.MatchingLabels(label.NewLabels(logstash.Name)),
+		); err != nil {
+			return nil, err
+		}
+		nodes := make(map[string]lsclient.Client, len(pods.Items))
+		for _, p := range pods.Items {
+			if !p.DeletionTimestamp.IsZero() || p.Status.Phase != corev1.PodRunning || p.Status.PodIP == "" {
+				continue
+			}
+			nodes[p.Name] = lsclient.NewLogstashClient(dialer, pod.MonitoringURL(p))
+		}
+		return nodes, nil
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/finalizer"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// FinalizerName registered for each Logstash resource
+	FinalizerName = "finalizer.logstash.k8s.elastic.co/observer"
+)
+
+// Finalizer returns a finalizer to be executed upon deletion of the given Logstash resource,
+// that makes sure it is not observed anymore
+func (m *Manager) Finalizer(logstash types.NamespacedName) finalizer.Finalizer {
+	return finalizer.Finalizer{
+		Name: FinalizerName,
+		Execute: func() error {
+			m.StopObserving(logstash)
+			return nil
+		},
+	}
+}
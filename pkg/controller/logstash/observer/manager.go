@@ -0,0 +1,65 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Manager for a set of observers
+type Manager struct {
+	observers map[types.NamespacedName]*Observer
+	lock      sync.RWMutex
+	nodes     NodesFunc
+	settings  Settings
+}
+
+// NewManager returns a new manager, whose observers retrieve the Logstash nodes with the given function
+func NewManager(nodes NodesFunc, settings Settings) *Manager {
+	return &Manager{
+		observers: make(map[types.NamespacedName]*Observer),
+		nodes:     nodes,
+		settings:  settings,
+	}
+}
+
+// Observe gets or creates an observer for the given Logstash resource
+func (m *Manager) Observe(logstash types.NamespacedName) *Observer {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if observer, exists := m.observers[logstash]; exists {
+		return observer
+	}
+	observer := NewObserver(logstash, m.nodes, m.settings)
+	observer.Start()
+	m.observers[logstash] = observer
+	return observer
+}
+
+// StopObserving stops and deletes the observer for the given Logstash resource
+// aimed to be called automatically by a finalizer
+func (m *Manager) StopObserving(logstash types.NamespacedName) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	observer, exists := m.observers[logstash]
+	if !exists {
+		return
+	}
+	observer.Stop()
+	delete(m.observers, logstash)
+}
+
+// LastStates returns the last observed state of every observed Logstash resource
+func (m *Manager) LastStates() map[types.NamespacedName]State {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	states := make(map[types.NamespacedName]State, len(m.observers))
+	for logstash, observer := range m.observers {
+		states[logstash] = observer.LastState()
+	}
+	return states
+}
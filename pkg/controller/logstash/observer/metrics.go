@@ -0,0 +1,143 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "logstash"
+
+var (
+	instanceLabels = []string{"namespace", "name"}
+	pipelineLabels = []string{"namespace", "name", "pipeline"}
+	// counters are exported per pod: their sum over the nodes would decrease when a node restarts or is removed
+	podLabels         = []string{"namespace", "name", "pod"}
+	podPipelineLabels = []string{"namespace", "name", "pod", "pipeline"}
+
+	nodesDesc            = newDesc("nodes", "Number of Logstash nodes whose statistics were retrieved.", instanceLabels)
+	unreachableNodesDesc = newDesc(
+		"unreachable_nodes", "Number of Logstash nodes whose statistics could not be retrieved.", instanceLabels,
+	)
+	eventsInDesc       = newDesc("events_in_total", "Number of events received by the Logstash node.", podLabels)
+	eventsFilteredDesc = newDesc("events_filtered_total", "Number of events filtered by the Logstash node.", podLabels)
+	eventsOutDesc      = newDesc("events_out_total", "Number of events sent by the Logstash node.", podLabels)
+	jvmHeapUsedDesc    = newDesc("jvm_heap_used_bytes", "JVM heap used by the Logstash nodes.", instanceLabels)
+	jvmHeapMaxDesc     = newDesc("jvm_heap_max_bytes", "Maximum JVM heap of the Logstash nodes.", instanceLabels)
+
+	pipelineEventsInDesc = newDesc(
+		"pipeline_events_in_total", "Number of events received by the pipeline on the Logstash node.", podPipelineLabels,
+	)
+	pipelineEventsFilteredDesc = newDesc(
+		"pipeline_events_filtered_total", "Number of events filtered by the pipeline on the Logstash node.", podPipelineLabels,
+	)
+	pipelineEventsOutDesc = newDesc(
+		"pipeline_events_out_total", "Number of events sent by the pipeline on the Logstash node.", podPipelineLabels,
+	)
+	pipelineQueueEventsDesc = newDesc(
+		"pipeline_queue_events", "Number of events waiting in the pipeline queue.", pipelineLabels,
+	)
+	pipelineQueueSizeDesc = newDesc(
+		"pipeline_queue_size_bytes", "Size of the pipeline queue.", pipelineLabels,
+	)
+	pipelineReloadSuccessesDesc = newDesc(
+		"pipeline_reload_successes_total", "Number of successful pipeline configuration reloads on the Logstash node.", podPipelineLabels,
+	)
+	pipelineReloadFailuresDesc = newDesc(
+		"pipeline_reload_failures_total", "Number of failed pipeline configuration reloads on the Logstash node.", podPipelineLabels,
+	)
+)
+
+func newDesc(name string, help string, labels []string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", name), help, labels, nil)
+}
+
+// Collector exports the statistics last observed for every Logstash resource as Prometheus metrics.
+// The gauges of the nodes of a Logstash resource are summed up, while the counters are exported per node so that they
+// never decrease.
+type Collector struct {
+	manager *Manager
+}
+
+var _ prometheus.Collector = &Collector{}
+
+// NewCollector returns a Collector for the Logstash resources observed by the given manager.
+func NewCollector(manager *Manager) *Collector {
+	return &Collector{manager: manager}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		nodesDesc,
+		unreachableNodesDesc,
+		eventsInDesc,
+		eventsFilteredDesc,
+		eventsOutDesc,
+		jvmHeapUsedDesc,
+		jvmHeapMaxDesc,
+		pipelineEventsInDesc,
+		pipelineEventsFilteredDesc,
+		pipelineEventsOutDesc,
+		pipelineQueueEventsDesc,
+		pipelineQueueSizeDesc,
+		pipelineReloadSuccessesDesc,
+		pipelineReloadFailuresDesc,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for logstash, state := range c.manager.LastStates() {
+		if state.Nodes == nil {
+			// not observed yet
+			continue
+		}
+		instance := []string{logstash.Namespace, logstash.Name}
+		var heapUsed, heapMax float64
+		pipelines := map[string]*pipelineMetrics{}
+		for podName, node := range state.Nodes {
+			pod := []string{logstash.Namespace, logstash.Name, podName}
+			ch <- prometheus.MustNewConstMetric(eventsInDesc, prometheus.CounterValue, float64(node.Events.In), pod...)
+			ch <- prometheus.MustNewConstMetric(eventsFilteredDesc, prometheus.CounterValue, float64(node.Events.Filtered), pod...)
+			ch <- prometheus.MustNewConstMetric(eventsOutDesc, prometheus.CounterValue, float64(node.Events.Out), pod...)
+			heapUsed += float64(node.JVM.Mem.HeapUsedInBytes)
+			heapMax += float64(node.JVM.Mem.HeapMaxInBytes)
+			for name, p := range node.Pipelines {
+				labels := []string{logstash.Namespace, logstash.Name, podName, name}
+				ch <- prometheus.MustNewConstMetric(pipelineEventsInDesc, prometheus.CounterValue, float64(p.Events.In), labels...)
+				ch <- prometheus.MustNewConstMetric(pipelineEventsFilteredDesc, prometheus.CounterValue, float64(p.Events.Filtered), labels...)
+				ch <- prometheus.MustNewConstMetric(pipelineEventsOutDesc, prometheus.CounterValue, float64(p.Events.Out), labels...)
+				ch <- prometheus.MustNewConstMetric(pipelineReloadSuccessesDesc, prometheus.CounterValue, float64(p.Reloads.Successes), labels...)
+				ch <- prometheus.MustNewConstMetric(pipelineReloadFailuresDesc, prometheus.CounterValue, float64(p.Reloads.Failures), labels...)
+				m, exists := pipelines[name]
+				if !exists {
+					m = &pipelineMetrics{}
+					pipelines[name] = m
+				}
+				m.queueEvents += float64(p.Queue.EventsCount)
+				m.queueSize += float64(p.Queue.QueueSizeInBytes)
+			}
+		}
+
+		ch <- prometheus.MustNewConstMetric(nodesDesc, prometheus.GaugeValue, float64(len(state.Nodes)), instance...)
+		ch <- prometheus.MustNewConstMetric(unreachableNodesDesc, prometheus.GaugeValue, float64(state.Unreachable), instance...)
+		ch <- prometheus.MustNewConstMetric(jvmHeapUsedDesc, prometheus.GaugeValue, heapUsed, instance...)
+		ch <- prometheus.MustNewConstMetric(jvmHeapMaxDesc, prometheus.GaugeValue, heapMax, instance...)
+
+		for name, m := range pipelines {
+			labels := []string{logstash.Namespace, logstash.Name, name}
+			ch <- prometheus.MustNewConstMetric(pipelineQueueEventsDesc, prometheus.GaugeValue, m.queueEvents, labels...)
+			ch <- prometheus.MustNewConstMetric(pipelineQueueSizeDesc, prometheus.GaugeValue, m.queueSize, labels...)
+		}
+	}
+}
+
+// pipelineMetrics sums up the gauges of a pipeline over all the nodes of a Logstash resource.
+type pipelineMetrics struct {
+	queueEvents, queueSize float64
+}
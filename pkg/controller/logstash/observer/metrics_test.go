@@ -0,0 +1,87 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"strings"
+	"testing"
+
+	lsclient "github.com/cloudptio/logstash-operator/pkg/controller/logstash/client"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func nodeStats(in, out int64, pipelineFailures int64) lsclient.NodeStats {
+	stats := lsclient.NodeStats{
+		Events: lsclient.EventsStats{In: in, Out: out},
+		Pipelines: map[string]lsclient.PipelineStats{
+			"main": {
+				Events:  lsclient.EventsStats{In: in, Out: out},
+				Reloads: lsclient.ReloadsStats{Successes: 1, Failures: pipelineFailures},
+				Queue:   lsclient.QueueStats{EventsCount: in - out},
+			},
+		},
+	}
+	stats.JVM.Mem.HeapUsedInBytes = 100
+	stats.JVM.Mem.HeapMaxInBytes = 1000
+	return stats
+}
+
+func TestCollector_Collect(t *testing.T) {
+	m := NewManager(nil, DefaultSettings)
+	m.observers = map[types.NamespacedName]*Observer{
+		{Namespace: "ns", Name: "observed"}: {lastState: State{
+			Nodes: map[string]lsclient.NodeStats{
+				"pod-1": nodeStats(10, 8, 0),
+				"pod-2": nodeStats(5, 5, 1),
+			},
+			Unreachable: 1,
+		}},
+		// not observed yet: no metrics
+		{Namespace: "ns", Name: "new"}: {},
+	}
+
+	expected := `
+# HELP logstash_events_in_total Number of events received by the Logstash node.
+# TYPE logstash_events_in_total counter
+logstash_events_in_total{name="observed",namespace="ns",pod="pod-1"} 10
+logstash_events_in_total{name="observed",namespace="ns",pod="pod-2"} 5
+# HELP logstash_jvm_heap_max_bytes Maximum JVM heap of the Logstash nodes.
+# TYPE logstash_jvm_heap_max_bytes gauge
+logstash_jvm_heap_max_bytes{name="observed",namespace="ns"} 2000
+# HELP logstash_nodes Number of Logstash nodes whose statistics were retrieved.
+# TYPE logstash_nodes gauge
+logstash_nodes{name="observed",namespace="ns"} 2
+# HELP logstash_unreachable_nodes Number of Logstash nodes whose statistics could not be retrieved.
+# TYPE logstash_unreachable_nodes gauge
+logstash_unreachable_nodes{name="observed",namespace="ns"} 1
+# HELP logstash_pipeline_events_out_total Number of events sent by the pipeline on the Logstash node.
+# TYPE logstash_pipeline_events_out_total counter
+logstash_pipeline_events_out_total{name="observed",namespace="ns",pipeline="main",pod="pod-1"} 8
+logstash_pipeline_events_out_total{name="observed",namespace="ns",pipeline="main",pod="pod-2"} 5
+# HELP logstash_pipeline_queue_events Number of events waiting in the pipeline queue.
+# TYPE logstash_pipeline_queue_events gauge
+logstash_pipeline_queue_events{name="observed",namespace="ns",pipeline="main"} 2
+# HELP logstash_pipeline_reload_failures_total Number of failed pipeline configuration reloads on the Logstash node.
+# TYPE logstash_pipeline_reload_failures_total counter
+logstash_pipeline_reload_failures_total{name="observed",namespace="ns",pipeline="main",pod="pod-1"} 0
+logstash_pipeline_reload_failures_total{name="observed",namespace="ns",pipeline="main",pod="pod-2"} 1
+# HELP logstash_pipeline_reload_successes_total Number of successful pipeline configuration reloads on the Logstash node.
+# TYPE logstash_pipeline_reload_successes_total counter
+logstash_pipeline_reload_successes_total{name="observed",namespace="ns",pipeline="main",pod="pod-1"} 1
+logstash_pipeline_reload_successes_total{name="observed",namespace="ns",pipeline="main",pod="pod-2"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(NewCollector(m), strings.NewReader(expected),
+		"logstash_events_in_total",
+		"logstash_jvm_heap_max_bytes",
+		"logstash_nodes",
+		"logstash_unreachable_nodes",
+		"logstash_pipeline_events_out_total",
+		"logstash_pipeline_queue_events",
+		"logstash_pipeline_reload_failures_total",
+		"logstash_pipeline_reload_successes_total",
+	))
+}
@@ -0,0 +1,127 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	lsclient "github.com/cloudptio/logstash-operator/pkg/controller/logstash/client"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("logstash-observer")
+
+// Settings for the Observer configuration
+type Settings struct {
+	ObservationInterval time.Duration
+	RequestTimeout      time.Duration
+}
+
+// Default values: the monitoring API of every Logstash node is requested every 10 seconds.
+const (
+	DefaultObservationInterval = 10 * time.Second
+	DefaultRequestTimeout      = 10 * time.Second
+)
+
+// DefaultSettings is an observer's Params with default values
+var DefaultSettings = Settings{
+	ObservationInterval: DefaultObservationInterval,
+	RequestTimeout:      DefaultRequestTimeout,
+}
+
+// NodesFunc returns clients for the Logstash nodes currently running for a Logstash resource, by pod name.
+type NodesFunc func(logstash types.NamespacedName) (map[string]lsclient.Client, error)
+
+// Observer regularly requests the monitoring API of the nodes of a Logstash resource for their statistics,
+// in a thread-safe way
+type Observer struct {
+	logstash types.NamespacedName
+	nodes    NodesFunc
+
+	settings Settings
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+
+	lastState State
+	mutex     sync.RWMutex
+}
+
+// NewObserver creates an Observer
+func NewObserver(logstash types.NamespacedName, nodes NodesFunc, settings Settings) *Observer {
+	log.Info("Creating observer for Logstash", "namespace", logstash.Namespace, "logstash_name", logstash.Name)
+	return &Observer{
+		logstash: logstash,
+		nodes:    nodes,
+		settings: settings,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start the observer in a separate goroutine
+func (o *Observer) Start() {
+	go o.runUntilStopped()
+}
+
+// Stop the observer loop
+func (o *Observer) Stop() {
+	o.stopOnce.Do(func() {
+		close(o.stopChan)
+	})
+}
+
+// LastState returns the last observed state
+func (o *Observer) LastState() State {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+	return o.lastState
+}
+
+// run the observer main loop, until stopped
+func (o *Observer) runUntilStopped() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go o.runPeriodically(ctx)
+	<-o.stopChan
+}
+
+// runPeriodically triggers a state retrieval every tick,
+// until the given context is cancelled
+func (o *Observer) runPeriodically(ctx context.Context) {
+	o.retrieveState(ctx)
+	ticker := time.NewTicker(o.settings.ObservationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			o.retrieveState(ctx)
+		case <-ctx.Done():
+			log.Info("Stopping observer for Logstash", "namespace", o.logstash.Namespace, "logstash_name", o.logstash.Name)
+			return
+		}
+	}
+}
+
+// retrieveState retrieves the current statistics of the Logstash nodes and stores the new state
+func (o *Observer) retrieveState(ctx context.Context) {
+	log.V(1).Info("Retrieving Logstash nodes statistics", "namespace", o.logstash.Namespace, "logstash_name", o.logstash.Name)
+	nodes, err := o.nodes(o.logstash)
+	if err != nil {
+		log.Error(err, "Failed to list Logstash nodes", "namespace", o.logstash.Namespace, "logstash_name", o.logstash.Name)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, o.settings.RequestTimeout)
+	defer cancel()
+	newState := RetrieveState(timeoutCtx, nodes)
+
+	o.mutex.Lock()
+	o.lastState = newState
+	o.mutex.Unlock()
+}
@@ -0,0 +1,45 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"context"
+	"sync"
+
+	lsclient "github.com/cloudptio/logstash-operator/pkg/controller/logstash/client"
+)
+
+// State contains information about the observed nodes of a Logstash resource.
+type State struct {
+	// Nodes are the statistics of the nodes that could be reached, by pod name.
+	Nodes map[string]lsclient.NodeStats
+	// Unreachable is the number of nodes whose statistics could not be retrieved.
+	Unreachable int
+}
+
+// RetrieveState requests the statistics of the given Logstash nodes in parallel, and closes their clients.
+func RetrieveState(ctx context.Context, nodes map[string]lsclient.Client) State {
+	state := State{Nodes: make(map[string]lsclient.NodeStats, len(nodes))}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(nodes))
+	for podName, c := range nodes {
+		go func(podName string, c lsclient.Client) {
+			defer wg.Done()
+			defer c.Close()
+			stats, err := c.GetNodeStats(ctx)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				log.V(1).Info("Unable to retrieve Logstash node statistics", "pod_name", podName, "error", err)
+				state.Unreachable++
+				return
+			}
+			state.Nodes[podName] = stats
+		}(podName, c)
+	}
+	wg.Wait()
+	return state
+}
@@ -0,0 +1,34 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package observer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	lsclient "github.com/cloudptio/logstash-operator/pkg/controller/logstash/client"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeLsClient(statusCode int, body string) lsclient.Client {
+	return lsclient.NewMockClient(func(req *http.Request) *http.Response {
+		return lsclient.NewMockResponse(statusCode, req, body)
+	})
+}
+
+func TestRetrieveState(t *testing.T) {
+	nodes := map[string]lsclient.Client{
+		"pod-1": fakeLsClient(200, `{"id":"1","events":{"in":3},"jvm":{"mem":{"heap_used_in_bytes":10}}}`),
+		"pod-2": fakeLsClient(200, `{"id":"2","events":{"in":5}}`),
+		"pod-3": fakeLsClient(500, ``),
+	}
+	state := RetrieveState(context.Background(), nodes)
+	require.Equal(t, 1, state.Unreachable)
+	require.Len(t, state.Nodes, 2)
+	require.Equal(t, int64(3), state.Nodes["pod-1"].Events.In)
+	require.Equal(t, int64(10), state.Nodes["pod-1"].JVM.Mem.HeapUsedInBytes)
+	require.Equal(t, int64(5), state.Nodes["pod-2"].Events.In)
+}
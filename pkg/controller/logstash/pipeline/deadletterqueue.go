@@ -0,0 +1,32 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"path"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/volume"
+)
+
+// DeadLetterQueuePipelineID is the id of the pipeline reprocessing the dead letter queues.
+const DeadLetterQueuePipelineID = "dead_letter_queue"
+
+// defaultDeadLetterQueuePath is the Logstash default path of the dead letter queues, in the data directory.
+var defaultDeadLetterQueuePath = path.Join(volume.DataVolumeMountPath, "dead_letter_queue")
+
+// DeadLetterQueueSlotEnvVar is the environment variable holding the dead letter queue slot of a Logstash pod.
+const DeadLetterQueueSlotEnvVar = "DEAD_LETTER_QUEUE_SLOT"
+
+// DeadLetterQueuePath returns the path of the dead letter queues of the given Logstash nodes.
+// Persisted dead letter queues are stored in a directory per slot on the shared volume, relying on the Logstash
+// environment variables substitution. A pod replacing a deleted one inherits its slot, so that its dead letter
+// queues keep being reprocessed instead of being orphaned.
+func DeadLetterQueuePath(ls v1beta1.Logstash) string {
+	if ls.Spec.DeadLetterQueue.VolumeClaimSpec == nil {
+		return defaultDeadLetterQueuePath
+	}
+	return path.Join(volume.DeadLetterQueueMountPath, "slot-${"+DeadLetterQueueSlotEnvVar+"}")
+}
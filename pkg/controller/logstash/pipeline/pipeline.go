@@ -0,0 +1,156 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/volume"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// MainPipelineID is the id of the pipeline defined by the input and output configuration of the Logstash resource.
+	MainPipelineID = "main"
+	// PipelinesFile is the key of the pipelines.yml file in the pipeline config map.
+	PipelinesFile = "pipelines.yml"
+
+	configKeyPrefix = "pipeline."
+	configKeySuffix = ".conf"
+)
+
+// mainConfigPath matches the input and output configuration files of the main pipeline, but not the configuration
+// files of the selected pipelines stored alongside them.
+var mainConfigPath = path.Join(volume.PipelineVolumeMountPath, "{input,output}_main.conf")
+
+// ConfigKey returns the key of the configuration of the given pipeline in the pipeline config map.
+func ConfigKey(pipelineID string) string {
+	return configKeyPrefix + pipelineID + configKeySuffix
+}
+
+// IDFromConfigKey returns the id of the pipeline configured by the given key of the pipeline config map, if it is the
+// configuration of a pipeline.
+func IDFromConfigKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, configKeyPrefix) || !strings.HasSuffix(key, configKeySuffix) ||
+		len(key) <= len(configKeyPrefix)+len(configKeySuffix) {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(key, configKeyPrefix), configKeySuffix), true
+}
+
+// Select returns the pipelines selected by the given Logstash among the given ones.
+func Select(ls v1beta1.Logstash, pipelines []v1beta1.LogstashPipeline) ([]v1beta1.LogstashPipeline, error) {
+	if ls.Spec.PipelineSelector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(ls.Spec.PipelineSelector)
+	if err != nil {
+		return nil, err
+	}
+	var selected []v1beta1.LogstashPipeline
+	for _, p := range pipelines {
+		if p.Namespace == ls.Namespace && selector.Matches(labels.Set(p.Labels)) {
+			selected = append(selected, p)
+		}
+	}
+	return selected, nil
+}
+
+// Admit checks the given pipelines selected by the given Logstash. It returns the pipelines to run and the
+// admission status of every given pipeline, by pipeline resource name.
+// When several pipelines share the same id, the oldest one is admitted.
+func Admit(ls v1beta1.Logstash, pipelines []v1beta1.LogstashPipeline) ([]v1beta1.LogstashPipeline, map[string]v1beta1.PipelineAdmission) {
+	sorted := make([]v1beta1.LogstashPipeline, len(pipelines))
+	copy(sorted, pipelines)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreationTimestamp.Equal(&sorted[j].CreationTimestamp) {
+			return sorted[i].CreationTimestamp.Before(&sorted[j].CreationTimestamp)
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	admitted := make([]v1beta1.LogstashPipeline, 0, len(sorted))
+	admissions := make(map[string]v1beta1.PipelineAdmission, len(sorted))
+	owners := map[string]string{}
+	for _, p := range sorted {
+		id := p.GetPipelineID()
+		var reason string
+		if id == MainPipelineID {
+			reason = fmt.Sprintf("pipeline id %s is reserved for the pipeline of the Logstash resource", id)
+		} else if id == DeadLetterQueuePipelineID && ls.Spec.DeadLetterQueue.ReprocessingEnabled() {
+			reason = fmt.Sprintf("pipeline id %s is reserved for the dead letter queue reprocessing pipeline", id)
+		} else if inTopology(ls, id) {
+			reason = fmt.Sprintf("pipeline id %s is already used by the topology of the Logstash resource", id)
+		} else if owner, exists := owners[id]; exists {
+			reason = fmt.Sprintf("pipeline id %s is already used by LogstashPipeline %s", id, owner)
+		} else if err := ValidateConfig(p.Spec.Config); err != nil {
+			reason = fmt.Sprintf("invalid pipeline configuration: %v", err)
+		}
+		if reason != "" {
+			admissions[p.Name] = v1beta1.PipelineAdmission{Logstash: ls.Name, Phase: v1beta1.PipelineRejected, Message: reason}
+			continue
+		}
+		owners[id] = p.Name
+		admitted = append(admitted, p)
+		admissions[p.Name] = v1beta1.PipelineAdmission{Logstash: ls.Name, Phase: v1beta1.PipelineAdmitted}
+	}
+	return admitted, admissions
+}
+
+// PipelinesFileData renders the pipelines.yml file declaring the main pipeline and the given pipelines.
+func PipelinesFileData(pipelines []v1beta1.LogstashPipeline) (string, error) {
+	entries := []map[string]interface{}{
+		{
+			"pipeline.id": MainPipelineID,
+			"path.config": mainConfigPath,
+		},
+	}
+	for _, p := range pipelines {
+		entry := map[string]interface{}{
+			"pipeline.id": p.GetPipelineID(),
+			"path.config": path.Join(volume.PipelineVolumeMountPath, ConfigKey(p.GetPipelineID())),
+		}
+		if p.Spec.Workers != nil {
+			entry["pipeline.workers"] = *p.Spec.Workers
+		}
+		if p.Spec.BatchSize != nil {
+			entry["pipeline.batch.size"] = *p.Spec.BatchSize
+		}
+		if p.Spec.BatchDelay != nil {
+			entry["pipeline.batch.delay"] = *p.Spec.BatchDelay
+		}
+		if p.Spec.Queue.Type != "" {
+			entry["queue.type"] = string(p.Spec.Queue.Type)
+		}
+		if p.Spec.Queue.MaxBytes != "" {
+			entry["queue.max_bytes"] = p.Spec.Queue.MaxBytes
+		}
+		if p.Spec.Queue.CheckpointWrites != nil {
+			entry["queue.checkpoint.writes"] = *p.Spec.Queue.CheckpointWrites
+		}
+		entries = append(entries, entry)
+	}
+	out, err := yaml.Marshal(entries)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// inTopology returns true if the topology of the given Logstash contains a pipeline with the given id.
+func inTopology(ls v1beta1.Logstash, pipelineID string) bool {
+	for _, p := range ls.Spec.Topology {
+		if p.Name == pipelineID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPipeline(name string, id string, age time.Duration, labels map[string]string) v1beta1.LogstashPipeline {
+	return v1beta1.LogstashPipeline{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              name,
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC).Add(-age)),
+		},
+		Spec: v1beta1.LogstashPipelineSpec{PipelineID: id, Config: "input { beats {} }"},
+	}
+}
+
+func names(pipelines []v1beta1.LogstashPipeline) []string {
+	result := make([]string, 0, len(pipelines))
+	for _, p := range pipelines {
+		result = append(result, p.Name)
+	}
+	return result
+}
+
+func TestIDFromConfigKey(t *testing.T) {
+	id, isPipeline := IDFromConfigKey(ConfigKey("team-a.logs"))
+	require.True(t, isPipeline)
+	require.Equal(t, "team-a.logs", id)
+	for _, key := range []string{"input_main.conf", "pipelines.yml", "pipeline..conf"} {
+		_, isPipeline := IDFromConfigKey(key)
+		require.False(t, isPipeline, key)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	pipelines := []v1beta1.LogstashPipeline{
+		newPipeline("a", "", 0, map[string]string{"team": "a"}),
+		newPipeline("b", "", 0, map[string]string{"team": "b"}),
+		newPipeline("c", "", 0, nil),
+	}
+	otherNamespace := newPipeline("d", "", 0, map[string]string{"team": "a"})
+	otherNamespace.Namespace = "other"
+	pipelines = append(pipelines, otherNamespace)
+
+	ls := v1beta1.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+	selected, err := Select(ls, pipelines)
+	require.NoError(t, err)
+	require.Empty(t, selected)
+
+	ls.Spec.PipelineSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	selected, err = Select(ls, pipelines)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, names(selected))
+
+	ls.Spec.PipelineSelector = &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: metav1.LabelSelectorOpExists}},
+	}
+	selected, err = Select(ls, pipelines)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, names(selected))
+}
+
+func TestAdmit(t *testing.T) {
+	invalid := newPipeline("invalid", "", time.Hour, nil)
+	invalid.Spec.Config = "input {"
+	pipelines := []v1beta1.LogstashPipeline{
+		newPipeline("newer", "shared", time.Minute, nil),
+		newPipeline("older", "shared", time.Hour, nil),
+		newPipeline("reserved", "main", time.Hour, nil),
+		newPipeline("other", "", time.Minute, nil),
+		newPipeline("topology", "distributor", time.Hour, nil),
+		invalid,
+	}
+
+	ls := v1beta1.Logstash{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"},
+		Spec:       v1beta1.LogstashSpec{Topology: []v1beta1.TopologyPipeline{{Name: "distributor"}}},
+	}
+	admitted, admissions := Admit(ls, pipelines)
+	require.Equal(t, []string{"older", "other"}, names(admitted))
+	require.Equal(t, map[string]v1beta1.PipelineAdmission{
+		"older": {Logstash: "ls", Phase: v1beta1.PipelineAdmitted},
+		"other": {Logstash: "ls", Phase: v1beta1.PipelineAdmitted},
+		"newer": {
+			Logstash: "ls",
+			Phase:    v1beta1.PipelineRejected,
+			Message:  "pipeline id shared is already used by LogstashPipeline older",
+		},
+		"reserved": {
+			Logstash: "ls",
+			Phase:    v1beta1.PipelineRejected,
+			Message:  "pipeline id main is reserved for the pipeline of the Logstash resource",
+		},
+		"topology": {
+			Logstash: "ls",
+			Phase:    v1beta1.PipelineRejected,
+			Message:  "pipeline id distributor is already used by the topology of the Logstash resource",
+		},
+		"invalid": {
+			Logstash: "ls",
+			Phase:    v1beta1.PipelineRejected,
+			Message:  "invalid pipeline configuration: missing closing brace",
+		},
+	}, admissions)
+}
+
+func TestPipelinesFileData(t *testing.T) {
+	workers, batchSize, checkpointWrites := int32(2), int32(500), int32(1)
+	tuned := newPipeline("tuned", "", 0, nil)
+	tuned.Spec.Workers = &workers
+	tuned.Spec.BatchSize = &batchSize
+	tuned.Spec.Queue = v1beta1.PipelineQueueSpec{
+		Type:             v1beta1.PersistedQueueType,
+		MaxBytes:         "4gb",
+		CheckpointWrites: &checkpointWrites,
+	}
+
+	data, err := PipelinesFileData([]v1beta1.LogstashPipeline{newPipeline("plain", "custom-id", 0, nil), tuned})
+	require.NoError(t, err)
+	require.Equal(t, `- path.config: /usr/share/logstash/pipeline/{input,output}_main.conf
+  pipeline.id: main
+- path.config: /usr/share/logstash/pipeline/pipeline.custom-id.conf
+  pipeline.id: custom-id
+- path.config: /usr/share/logstash/pipeline/pipeline.tuned.conf
+  pipeline.batch.size: 500
+  pipeline.id: tuned
+  pipeline.workers: 2
+  queue.checkpoint.writes: 1
+  queue.max_bytes: 4gb
+  queue.type: persisted
+`, data)
+}
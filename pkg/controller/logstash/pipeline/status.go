@@ -0,0 +1,62 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/finalizer"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FinalizerName is the name of the finalizer removing the admission statuses of a deleted Logstash resource.
+const FinalizerName = "finalizer.logstash.k8s.elastic.co/pipeline-admissions"
+
+// UpdateAdmissions reports the given admission statuses of the given Logstash resource, by pipeline resource name,
+// in the status of the given pipelines. The admission status of the pipelines not in the given admissions is removed.
+func UpdateAdmissions(c k8s.Client, lsName string, pipelines []v1beta1.LogstashPipeline, admissions map[string]v1beta1.PipelineAdmission) error {
+	for _, p := range pipelines {
+		expected := make([]v1beta1.PipelineAdmission, 0, len(p.Status.Admissions)+1)
+		for _, a := range p.Status.Admissions {
+			if a.Logstash != lsName {
+				expected = append(expected, a)
+			}
+		}
+		if admission, exists := admissions[p.Name]; exists {
+			expected = append(expected, admission)
+		}
+		sort.SliceStable(expected, func(i, j int) bool {
+			return expected[i].Logstash < expected[j].Logstash
+		})
+		if len(expected) == 0 {
+			expected = nil
+		}
+		if reflect.DeepEqual(expected, p.Status.Admissions) {
+			continue
+		}
+		p.Status.Admissions = expected
+		if err := c.Status().Update(&p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finalizer returns a finalizer removing the admission statuses of the given Logstash resource from its pipelines.
+func Finalizer(c k8s.Client, ls v1beta1.Logstash) finalizer.Finalizer {
+	return finalizer.Finalizer{
+		Name: FinalizerName,
+		Execute: func() error {
+			var pipelines v1beta1.LogstashPipelineList
+			if err := c.List(&pipelines, client.InNamespace(ls.Namespace)); err != nil {
+				return err
+			}
+			return UpdateAdmissions(c, ls.Name, pipelines.Items, nil)
+		},
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	commonscheme "github.com/cloudptio/logstash-operator/pkg/controller/common/scheme"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateAdmissions(t *testing.T) {
+	require.NoError(t, commonscheme.SetupScheme())
+
+	selected := newPipeline("selected", "", 0, nil)
+	selected.Status.Admissions = []v1beta1.PipelineAdmission{
+		{Logstash: "other", Phase: v1beta1.PipelineAdmitted},
+	}
+	unselected := newPipeline("unselected", "", 0, nil)
+	unselected.Status.Admissions = []v1beta1.PipelineAdmission{
+		{Logstash: "ls", Phase: v1beta1.PipelineAdmitted},
+	}
+	c := k8s.WrapClient(fake.NewFakeClient(&selected, &unselected))
+
+	err := UpdateAdmissions(c, "ls", []v1beta1.LogstashPipeline{selected, unselected}, map[string]v1beta1.PipelineAdmission{
+		"selected": {Logstash: "ls", Phase: v1beta1.PipelineRejected, Message: "invalid"},
+	})
+	require.NoError(t, err)
+
+	var actual v1beta1.LogstashPipeline
+	require.NoError(t, c.Get(types.NamespacedName{Namespace: "ns", Name: "selected"}, &actual))
+	require.Equal(t, []v1beta1.PipelineAdmission{
+		{Logstash: "ls", Phase: v1beta1.PipelineRejected, Message: "invalid"},
+		{Logstash: "other", Phase: v1beta1.PipelineAdmitted},
+	}, actual.Status.Admissions)
+
+	var actualUnselected v1beta1.LogstashPipeline
+	require.NoError(t, c.Get(types.NamespacedName{Namespace: "ns", Name: "unselected"}, &actualUnselected))
+	require.Empty(t, actualUnselected.Status.Admissions)
+}
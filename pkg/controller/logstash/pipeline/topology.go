@@ -0,0 +1,85 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+)
+
+// UsesPipelinesFile returns true if the pipelines of the given Logstash are declared in a pipelines.yml file, rather
+// than the main pipeline being the only one.
+func UsesPipelinesFile(ls v1beta1.Logstash) bool {
+	return ls.Spec.PipelineSelector != nil || len(ls.Spec.Topology) > 0 || ls.Spec.DeadLetterQueue.ReprocessingEnabled()
+}
+
+// TopologyPipelines returns the pipelines of the topology of the given Logstash, with their generated pipeline inputs
+// and outputs.
+func TopologyPipelines(ls v1beta1.Logstash) []v1beta1.LogstashPipeline {
+	upstreams := map[string]bool{}
+	for _, p := range ls.Spec.Topology {
+		for _, edge := range p.SendTo {
+			upstreams[edge.Pipeline] = true
+		}
+	}
+	pipelines := make([]v1beta1.LogstashPipeline, 0, len(ls.Spec.Topology))
+	for _, p := range ls.Spec.Topology {
+		pipelines = append(pipelines, v1beta1.LogstashPipeline{
+			Spec: v1beta1.LogstashPipelineSpec{
+				PipelineID: p.Name,
+				Config:     topologyPipelineConfig(p, upstreams[p.Name]),
+				Workers:    p.Workers,
+				Queue:      p.Queue,
+			},
+		})
+	}
+	return pipelines
+}
+
+// topologyPipelineConfig returns the configuration of the given topology pipeline, surrounded by the pipeline input
+// receiving its events if it has upstream pipelines, and the pipeline outputs sending its events downstream.
+func topologyPipelineConfig(p v1beta1.TopologyPipeline, hasUpstreams bool) string {
+	var config strings.Builder
+	if hasUpstreams {
+		fmt.Fprintf(&config, "input {\n  pipeline {\n    address => %q\n  }\n}\n", p.Name)
+	}
+	if p.Config != "" {
+		config.WriteString(strings.TrimSuffix(p.Config, "\n"))
+		config.WriteString("\n")
+	}
+	if len(p.SendTo) == 0 {
+		return config.String()
+	}
+
+	var unconditional []string
+	var conditional []v1beta1.PipelineEdge
+	for _, edge := range p.SendTo {
+		if edge.If == "" {
+			unconditional = append(unconditional, edge.Pipeline)
+		} else {
+			conditional = append(conditional, edge)
+		}
+	}
+	config.WriteString("output {\n")
+	if len(unconditional) > 0 {
+		// a single output forks the events to all the pipelines
+		fmt.Fprintf(&config, "  pipeline {\n    send_to => [%s]\n  }\n", quoteAll(unconditional))
+	}
+	for _, edge := range conditional {
+		fmt.Fprintf(&config, "  if %s {\n    pipeline {\n      send_to => [%q]\n    }\n  }\n", edge.If, edge.Pipeline)
+	}
+	config.WriteString("}\n")
+	return config.String()
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,62 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopologyPipelines(t *testing.T) {
+	workers := int32(4)
+	ls := v1beta1.Logstash{Spec: v1beta1.LogstashSpec{Topology: []v1beta1.TopologyPipeline{
+		{
+			Name:   "distributor",
+			Config: "input {\n  beats { port => 5044 }\n}\n",
+			SendTo: []v1beta1.PipelineEdge{
+				{Pipeline: "archive"},
+				{Pipeline: "collector"},
+				{Pipeline: "apache", If: `[type] == "apache"`},
+			},
+		},
+		{
+			Name:    "collector",
+			Config:  "output { stdout {} }",
+			Workers: &workers,
+		},
+	}}}
+
+	pipelines := TopologyPipelines(ls)
+	require.Len(t, pipelines, 2)
+
+	require.Equal(t, "distributor", pipelines[0].GetPipelineID())
+	require.Equal(t, `input {
+  beats { port => 5044 }
+}
+output {
+  pipeline {
+    send_to => ["archive", "collector"]
+  }
+  if [type] == "apache" {
+    pipeline {
+      send_to => ["apache"]
+    }
+  }
+}
+`, pipelines[0].Spec.Config)
+
+	require.Equal(t, "collector", pipelines[1].GetPipelineID())
+	require.Equal(t, &workers, pipelines[1].Spec.Workers)
+	require.Equal(t, `input {
+  pipeline {
+    address => "collector"
+  }
+}
+output { stdout {} }
+`, pipelines[1].Spec.Config)
+	require.NoError(t, ValidateConfig(pipelines[1].Spec.Config))
+}
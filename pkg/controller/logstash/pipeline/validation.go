@@ -0,0 +1,77 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// sections are the allowed top-level sections of a pipeline configuration.
+var sections = map[string]bool{"input": true, "filter": true, "output": true}
+
+// ValidateConfig performs a lightweight syntax check of the given pipeline configuration: it must contain at least
+// one input, filter or output section, with balanced braces and terminated strings.
+// Plugin settings are not validated, Logstash reports such errors when loading the pipeline.
+func ValidateConfig(config string) error {
+	depth := 0
+	found := 0
+	var word strings.Builder
+	runes := []rune(config)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '#':
+			// comment until the end of the line
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '"' || r == '\'':
+			quote := r
+			for i++; i < len(runes) && runes[i] != quote; i++ {
+				if runes[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(runes) {
+				return errors.New("unterminated string")
+			}
+			if depth == 0 {
+				return errors.New("unexpected string outside of a section")
+			}
+		case r == '{':
+			if depth == 0 {
+				if !sections[word.String()] {
+					return fmt.Errorf("unknown section %q, expected input, filter or output", word.String())
+				}
+				word.Reset()
+				found++
+			}
+			depth++
+		case r == '}':
+			if depth == 0 {
+				return errors.New("unexpected closing brace")
+			}
+			depth--
+		case depth == 0 && !unicode.IsSpace(r):
+			if word.Len() > 0 && unicode.IsSpace(runes[i-1]) {
+				return fmt.Errorf("unexpected %q outside of a section", word.String())
+			}
+			word.WriteRune(r)
+		}
+	}
+	if depth > 0 {
+		return errors.New("missing closing brace")
+	}
+	if word.Len() > 0 {
+		return fmt.Errorf("unexpected %q outside of a section", word.String())
+	}
+	if found == 0 {
+		return errors.New("no input, filter or output section")
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name: "valid configuration",
+			config: `# comment with a { brace
+input {
+  beats { port => 5044 }
+}
+filter {
+  mutate { add_field => { "message" => "}" } }
+}
+output { stdout {} }`,
+		},
+		{
+			name:    "empty configuration",
+			config:  "  # nothing\n",
+			wantErr: "no input, filter or output section",
+		},
+		{
+			name:    "unknown section",
+			config:  "inputs { beats {} }",
+			wantErr: `unknown section "inputs", expected input, filter or output`,
+		},
+		{
+			name:    "missing closing brace",
+			config:  "input { beats { port => 5044 }",
+			wantErr: "missing closing brace",
+		},
+		{
+			name:    "unexpected closing brace",
+			config:  "input { beats {} } }",
+			wantErr: "unexpected closing brace",
+		},
+		{
+			name:    "unterminated string",
+			config:  `output { stdout { codec => "json } }`,
+			wantErr: "unterminated string",
+		},
+		{
+			name:    "text outside of a section",
+			config:  "input { beats {} } stdout",
+			wantErr: `unexpected "stdout" outside of a section`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfig(tt.config)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	lsname "github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pipeline"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// pipelinesFileChecksumAnnotation is the checksum of the pipelines.yml file mounted in the Logstash pods.
+// The file is mounted with a sub path, which is not updated in running pods: changing it rolls the pods.
+const pipelinesFileChecksumAnnotation = "logstash.k8s.elastic.co/pipelines-file-checksum"
+
+// reconcileSelectedPipelines admits the LogstashPipeline resources selected by the given Logstash, reports their
+// admission status and returns the pipelines to run.
+func (d *driver) reconcileSelectedPipelines(ls lstype.Logstash) ([]lstype.LogstashPipeline, error) {
+	pipelines, admitted, admissions, err := d.admitPipelines(ls)
+	if err != nil {
+		return nil, err
+	}
+	// the admission status of the pipelines not selected anymore is removed
+	if err := pipeline.UpdateAdmissions(d.client, ls.Name, pipelines, admissions); err != nil {
+		return nil, err
+	}
+	return admitted, nil
+}
+
+// admitPipelines returns all the LogstashPipeline resources of the namespace of the given Logstash, the ones to run
+// and the admission status of the selected ones.
+func (d *driver) admitPipelines(ls lstype.Logstash) (
+	[]lstype.LogstashPipeline,
+	[]lstype.LogstashPipeline,
+	map[string]lstype.PipelineAdmission,
+	error,
+) {
+	var pipelines lstype.LogstashPipelineList
+	if err := d.client.List(&pipelines, client.InNamespace(ls.Namespace)); err != nil {
+		return nil, nil, nil, err
+	}
+	selected, err := pipeline.Select(ls, pipelines.Items)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	admitted, admissions := pipeline.Admit(ls, selected)
+	return pipelines.Items, admitted, admissions, nil
+}
+
+// pipelinesFileChecksum returns a checksum of the pipelines.yml file of the given pipeline config map, if any.
+func pipelinesFileChecksum(cm corev1.ConfigMap) string {
+	data, exists := cm.Data[pipeline.PipelinesFile]
+	if !exists {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum224([]byte(data)))
+}
+
+// setPipelinesFileChecksum annotates the given pod template with the checksum of the pipelines.yml file of the given
+// pipeline config map, if any.
+func setPipelinesFileChecksum(podTemplate *corev1.PodTemplateSpec, cm corev1.ConfigMap) {
+	checksum := pipelinesFileChecksum(cm)
+	if checksum == "" {
+		delete(podTemplate.Annotations, pipelinesFileChecksumAnnotation)
+		return
+	}
+	if podTemplate.Annotations == nil {
+		podTemplate.Annotations = map[string]string{}
+	}
+	podTemplate.Annotations[pipelinesFileChecksumAnnotation] = checksum
+}
+
+// stablePipelineConfigMap returns the pipeline config map of the given Logstash used by the stable nodes.
+func (d *driver) stablePipelineConfigMap(ls lstype.Logstash) (corev1.ConfigMap, error) {
+	var cm corev1.ConfigMap
+	err := d.client.Get(types.NamespacedName{Namespace: ls.Namespace, Name: lsname.PipelineConfigMap(ls.Name)}, &cm)
+	if apierrors.IsNotFound(err) {
+		return corev1.ConfigMap{}, nil
+	}
+	return cm, err
+}
+
+// selectingLogstashes returns a mapper enqueuing the Logstash resources selecting a LogstashPipeline, or reporting
+// an admission status in it.
+func selectingLogstashes(c k8s.Client) handler.ToRequestsFunc {
+	return handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+		p, ok := obj.Object.(*lstype.LogstashPipeline)
+		if !ok {
+			return nil
+		}
+		var logstashes lstype.LogstashList
+		if err := c.List(&logstashes, client.InNamespace(p.Namespace)); err != nil {
+			log.Error(err, "Failed to list Logstash resources", "namespace", p.Namespace)
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, ls := range logstashes.Items {
+			selected, err := pipeline.Select(ls, []lstype.LogstashPipeline{*p})
+			if err != nil || (len(selected) == 0 && !hasAdmission(*p, ls.Name)) {
+				continue
+			}
+			requests = append(requests, reconcile.Request{NamespacedName: k8s.ExtractNamespacedName(&ls)})
+		}
+		return requests
+	})
+}
+
+// hasAdmission returns true if the given pipeline reports an admission status for the given Logstash.
+func hasAdmission(p lstype.LogstashPipeline, lsName string) bool {
+	for _, a := range p.Status.Admissions {
+		if a.Logstash == lsName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
+	commonvolume "github.com/cloudptio/logstash-operator/pkg/controller/common/volume"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/configmap"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/volume"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// withLogging sets the level and format of the logs of the given Logstash, if configured.
+func withLogging(builder *defaults.PodTemplateBuilder, ls v1beta1.Logstash) {
+	if ls.Spec.Logging.Level != "" {
+		builder.WithEnv(corev1.EnvVar{Name: "LOG_LEVEL", Value: ls.Spec.Logging.Level})
+	}
+	if ls.Spec.Logging.Format != "" {
+		builder.WithEnv(corev1.EnvVar{Name: "LOG_FORMAT", Value: ls.Spec.Logging.Format})
+	}
+}
+
+// withConfigFiles mounts the customized jvm.options and log4j2.properties files of the given Logstash in place of the
+// default ones.
+func withConfigFiles(builder *defaults.PodTemplateBuilder, ls v1beta1.Logstash) {
+	if configmap.UsesJVMOptionsFile(ls) {
+		withConfigFile(builder, ls, volume.JVMOptionsVolumeName, volume.JVMOptionsMountPath, configmap.JVMOptionsFile)
+	}
+	if configmap.UsesLog4j2PropertiesFile(ls) {
+		withConfigFile(builder, ls, volume.Log4j2PropertiesVolumeName, volume.Log4j2PropertiesMountPath, configmap.Log4j2PropertiesFile)
+	}
+}
+
+// withConfigFile mounts the given file of the config files config map at the given path. Each file has its own volume,
+// the pod template builder ignoring several mounts of the same volume.
+func withConfigFile(builder *defaults.PodTemplateBuilder, ls v1beta1.Logstash, volumeName, mountPath, file string) {
+	configFilesVolume := commonvolume.NewConfigMapVolumeWithMode(
+		name.ConfigFilesConfigMap(ls.Name), volumeName, mountPath, int32(volume.PipelineVolumeMode))
+	mount := configFilesVolume.VolumeMount()
+	mount.SubPath = file
+	builder.WithVolumes(configFilesVolume.Volume()).
+		WithVolumeMounts(mount)
+}
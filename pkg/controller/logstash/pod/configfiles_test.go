@@ -0,0 +1,61 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_withConfigFiles(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       v1beta1.LogstashSpec
+		wantEnv    []corev1.EnvVar
+		wantMounts []corev1.VolumeMount
+	}{
+		{
+			name: "not customized",
+		},
+		{
+			name:    "log level and format",
+			spec:    v1beta1.LogstashSpec{Logging: v1beta1.LoggingSpec{Level: "debug", Format: "json"}},
+			wantEnv: []corev1.EnvVar{{Name: "LOG_FORMAT", Value: "json"}, {Name: "LOG_LEVEL", Value: "debug"}},
+		},
+		{
+			name: "jvm options and loggers",
+			spec: v1beta1.LogstashSpec{
+				JVMOptions: []string{"-XX:+UseG1GC"},
+				Logging:    v1beta1.LoggingSpec{Loggers: map[string]string{"logstash.outputs.elasticsearch": "debug"}},
+			},
+			wantMounts: []corev1.VolumeMount{
+				{Name: "jvm-options", ReadOnly: true, MountPath: "/usr/share/logstash/config/jvm.options", SubPath: "jvm.options"},
+				{Name: "log4j2-properties", ReadOnly: true, MountPath: "/usr/share/logstash/config/log4j2.properties", SubPath: "log4j2.properties"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := v1beta1.Logstash{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"},
+				Spec:       tt.spec,
+			}
+			builder := defaults.NewPodTemplateBuilder(corev1.PodTemplateSpec{}, v1beta1.LogstashContainerName)
+			withLogging(builder, ls)
+			withConfigFiles(builder, ls)
+			require.Equal(t, tt.wantEnv, builder.Container.Env)
+			require.Equal(t, tt.wantMounts, builder.Container.VolumeMounts)
+			require.Len(t, builder.PodTemplate.Spec.Volumes, len(tt.wantMounts))
+			for _, v := range builder.PodTemplate.Spec.Volumes {
+				require.Equal(t, "ls-ls-config", v.ConfigMap.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pipeline"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/volume"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DeadLetterQueueSlotInitContainerName is the name of the init container waiting for the operator to assign a dead
+// letter queue slot to the pod.
+const DeadLetterQueueSlotInitContainerName = "logstash-internal-init-dlq-slot"
+
+// waitForSlotScript waits until the slot annotation of the pod is set, the downward API volume being updated
+// asynchronously by the kubelet.
+var waitForSlotScript = fmt.Sprintf(`#!/usr/bin/env bash
+
+until [[ -s %[1]s ]]; do
+	echo "Waiting for the dead letter queue slot to be assigned."
+	sleep 1
+done
+echo "Assigned dead letter queue slot $(cat %[1]s)."
+`, path.Join(volume.DeadLetterQueueSlotMountPath, volume.DeadLetterQueueSlotFile))
+
+// withDeadLetterQueue enables the dead letter queue of the pipelines of the given Logstash, if configured, and mounts
+// the volume persisting it. Persisted dead letter queues are written to the directory of the slot the operator
+// assigns to the pod, which is only known once the pod is created: an init container delays the start of Logstash
+// until it is set.
+func withDeadLetterQueue(builder *defaults.PodTemplateBuilder, ls v1beta1.Logstash) {
+	dlq := ls.Spec.DeadLetterQueue
+	if !dlq.Enabled {
+		return
+	}
+	builder.WithEnv(corev1.EnvVar{Name: "DEAD_LETTER_QUEUE_ENABLE", Value: "true"})
+	if dlq.MaxBytes != "" {
+		builder.WithEnv(corev1.EnvVar{Name: "DEAD_LETTER_QUEUE_MAX_BYTES", Value: dlq.MaxBytes})
+	}
+	if dlq.VolumeClaimSpec == nil {
+		return
+	}
+	slotFieldRef := &corev1.ObjectFieldSelector{
+		FieldPath: fmt.Sprintf("metadata.annotations['%s']", label.DeadLetterQueueSlotAnnotation),
+	}
+	builder.WithEnv(
+		corev1.EnvVar{
+			Name:      pipeline.DeadLetterQueueSlotEnvVar,
+			ValueFrom: &corev1.EnvVarSource{FieldRef: slotFieldRef},
+		},
+		corev1.EnvVar{Name: "PATH_DEAD_LETTER_QUEUE", Value: pipeline.DeadLetterQueuePath(ls)},
+	).
+		WithVolumes(
+			corev1.Volume{
+				Name: volume.DeadLetterQueueVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: name.DeadLetterQueueClaim(ls.Name),
+					},
+				},
+			},
+			corev1.Volume{
+				Name: volume.DeadLetterQueueSlotVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					DownwardAPI: &corev1.DownwardAPIVolumeSource{
+						Items: []corev1.DownwardAPIVolumeFile{{
+							Path:     volume.DeadLetterQueueSlotFile,
+							FieldRef: slotFieldRef,
+						}},
+					},
+				},
+			},
+		).
+		WithVolumeMounts(corev1.VolumeMount{
+			Name:      volume.DeadLetterQueueVolumeName,
+			MountPath: volume.DeadLetterQueueMountPath,
+		}).
+		WithInitContainers(corev1.Container{
+			Name:    DeadLetterQueueSlotInitContainerName,
+			Command: []string{"/usr/bin/env", "bash", "-c", waitForSlotScript},
+			VolumeMounts: []corev1.VolumeMount{{
+				Name:      volume.DeadLetterQueueSlotVolumeName,
+				MountPath: volume.DeadLetterQueueSlotMountPath,
+				ReadOnly:  true,
+			}},
+		})
+}
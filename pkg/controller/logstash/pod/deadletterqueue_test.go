@@ -0,0 +1,82 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_withDeadLetterQueue(t *testing.T) {
+	tests := []struct {
+		name        string
+		dlq         v1beta1.DeadLetterQueueSpec
+		wantEnv     []corev1.EnvVar
+		wantVolumes []corev1.Volume
+		wantInit    []string
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:    "enabled",
+			dlq:     v1beta1.DeadLetterQueueSpec{Enabled: true, MaxBytes: "2gb"},
+			wantEnv: []corev1.EnvVar{{Name: "DEAD_LETTER_QUEUE_ENABLE", Value: "true"}, {Name: "DEAD_LETTER_QUEUE_MAX_BYTES", Value: "2gb"}},
+		},
+		{
+			name: "persisted",
+			dlq:  v1beta1.DeadLetterQueueSpec{Enabled: true, VolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{}},
+			wantEnv: []corev1.EnvVar{
+				{Name: "DEAD_LETTER_QUEUE_ENABLE", Value: "true"},
+				{Name: "DEAD_LETTER_QUEUE_SLOT", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.annotations['logstash.k8s.elastic.co/dead-letter-queue-slot']",
+				}}},
+				{Name: "PATH_DEAD_LETTER_QUEUE", Value: "/usr/share/logstash/dead_letter_queue/slot-${DEAD_LETTER_QUEUE_SLOT}"},
+			},
+			wantVolumes: []corev1.Volume{
+				{
+					Name: "dead-letter-queue",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "ls-ls-dlq"},
+					},
+				},
+				{
+					Name: "dead-letter-queue-slot",
+					VolumeSource: corev1.VolumeSource{
+						DownwardAPI: &corev1.DownwardAPIVolumeSource{Items: []corev1.DownwardAPIVolumeFile{{
+							Path: "slot",
+							FieldRef: &corev1.ObjectFieldSelector{
+								FieldPath: "metadata.annotations['logstash.k8s.elastic.co/dead-letter-queue-slot']",
+							},
+						}}},
+					},
+				},
+			},
+			wantInit: []string{DeadLetterQueueSlotInitContainerName},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := v1beta1.Logstash{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"},
+				Spec:       v1beta1.LogstashSpec{DeadLetterQueue: tt.dlq},
+			}
+			builder := defaults.NewPodTemplateBuilder(corev1.PodTemplateSpec{}, v1beta1.LogstashContainerName)
+			withDeadLetterQueue(builder, ls)
+			require.Equal(t, tt.wantEnv, builder.Container.Env)
+			require.Equal(t, tt.wantVolumes, builder.PodTemplate.Spec.Volumes)
+			var initContainers []string
+			for _, c := range builder.PodTemplate.Spec.InitContainers {
+				initContainers = append(initContainers, c.Name)
+			}
+			require.Equal(t, tt.wantInit, initContainers)
+		})
+	}
+}
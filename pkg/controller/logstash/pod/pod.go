@@ -17,6 +17,7 @@ import (
 	commonvolume "github.com/cloudptio/logstash-operator/pkg/controller/common/volume"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pipeline"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/volume"
 	"github.com/cloudptio/logstash-operator/pkg/utils/stringsutil"
 
@@ -49,7 +50,9 @@ func imageWithVersion(image string, version string) string {
 	return stringsutil.Concat(image, ":", version)
 }
 
-func NewPodTemplateSpec(ls v1beta1.Logstash, keystore *keystore.Resources) corev1.PodTemplateSpec {
+// NewPodTemplateSpec builds the pod template of the given Logstash. The given version specific settings are passed
+// to Logstash through environment variables.
+func NewPodTemplateSpec(ls v1beta1.Logstash, keystore *keystore.Resources, settings map[string]interface{}) corev1.PodTemplateSpec {
 
 	esURL := ls.AssociationConf().GetURL()
 
@@ -65,12 +68,19 @@ func NewPodTemplateSpec(ls v1beta1.Logstash, keystore *keystore.Resources) corev
 	memReq = strings.ReplaceAll(memReq, "Gi", "g")
 	memReq = strings.ReplaceAll(memReq, "Mi", "m")
 
-	builder = builder.WithLabels(label.NewLabels(ls.Name)).
+	labels := label.NewLabels(ls.Name)
+	labels[label.VersionLabelName] = ls.Spec.Version
+
+	builder = builder.WithLabels(labels).
+		WithAffinity(defaults.SpreadAffinity(label.NewLabels(ls.Name))).
 		WithDockerImage(ls.Spec.Image, imageWithVersion(defaultImageRepositoryAndName, ls.Spec.Version)).
 		WithPorts(ports).
+		WithPreStopHook(NewPreStopHook()).
+		WithTerminationGracePeriod(TerminationGracePeriod(ls.Spec.GetDrainTimeout())).
 		WithVolumes(logstashPipelineVolume.Volume()).
 		WithVolumeMounts(logstashPipelineVolume.VolumeMount()).
 		WithEnv(
+			drainTimeoutEnv(ls.Spec.GetDrainTimeout()),
 			corev1.EnvVar{
 				Name:  "ELASTICSEARCH_HOST",
 				Value: esURL,
@@ -84,22 +94,10 @@ func NewPodTemplateSpec(ls v1beta1.Logstash, keystore *keystore.Resources) corev
 				Value: fmt.Sprintf("-Xmx%s -Xms%s", memReq, memReq),
 			},
 
-			corev1.EnvVar{
-				Name:  "HTTP_HOST",
-				Value: "0.0.0.0",
-			},
-			corev1.EnvVar{
-				Name:  "HTTP_PORT",
-				Value: "9600",
-			},
 			corev1.EnvVar{
 				Name:  "CONFIG_RELOAD_AUTOMATIC",
 				Value: "true",
 			},
-			corev1.EnvVar{
-				Name:  "PATH_CONFIG",
-				Value: volume.PipelineVolumeMountPath,
-			},
 			corev1.EnvVar{
 				Name:  "PATH_DATA",
 				Value: volume.DataVolumeMountPath,
@@ -120,18 +118,81 @@ func NewPodTemplateSpec(ls v1beta1.Logstash, keystore *keystore.Resources) corev
 				Name:  "QUEUE_TYPE",
 				Value: "persisted",
 			},
-		)
+		).
+		WithEnv(SettingsEnv(settings)...)
+
+	if !pipeline.UsesPipelinesFile(ls) {
+		// the main pipeline is the only one, loaded from the pipeline directory
+		builder.WithEnv(corev1.EnvVar{
+			Name:  "PATH_CONFIG",
+			Value: volume.PipelineVolumeMountPath,
+		})
+	} else {
+		// pipelines are declared in pipelines.yml, which Logstash ignores if path.config is set
+		pipelinesFileVolume := commonvolume.NewConfigMapVolumeWithMode(
+			name.PipelineConfigMap(ls.Name), volume.PipelinesFileVolumeName, volume.PipelinesFileMountPath, int32(volume.PipelineVolumeMode))
+		pipelinesFileMount := pipelinesFileVolume.VolumeMount()
+		pipelinesFileMount.SubPath = pipeline.PipelinesFile
+		builder.WithVolumes(pipelinesFileVolume.Volume()).
+			WithVolumeMounts(pipelinesFileMount)
+	}
+
+	withDeadLetterQueue(builder, ls)
+	withLogging(builder, ls)
+	withConfigFiles(builder, ls)
 
 	if keystore != nil {
 		builder.WithVolumes(keystore.Volume).
-			WithInitContainers(keystore.InitContainer).
-			WithInitContainerDefaults()
+			WithInitContainers(keystore.InitContainer)
 	}
 
-	return builder.PodTemplate
+	return builder.WithInitContainerDefaults().PodTemplate
 }
 
 // GetLogstashContainer returns the Logstash container from the given podSpec.
 func GetLogstashContainer(podSpec corev1.PodSpec) *corev1.Container {
 	return pod.ContainerByName(podSpec, v1beta1.LogstashContainerName)
 }
+
+// ContainerPorts returns the ports of the Logstash container of the given Logstash: the ports declared in its pod
+// template, and the default ports not overridden by the pod template.
+func ContainerPorts(ls v1beta1.Logstash) []corev1.ContainerPort {
+	var containerPorts []corev1.ContainerPort
+	if c := GetLogstashContainer(ls.Spec.PodTemplate.Spec); c != nil {
+		containerPorts = append(containerPorts, c.Ports...)
+	}
+	for _, p := range ports {
+		if _, exists := ContainerPort(containerPorts, p.Name); !exists {
+			containerPorts = append(containerPorts, p)
+		}
+	}
+	return containerPorts
+}
+
+// ContainerPort returns the port of the given name among the given container ports.
+func ContainerPort(containerPorts []corev1.ContainerPort, name string) (corev1.ContainerPort, bool) {
+	for _, p := range containerPorts {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return corev1.ContainerPort{}, false
+}
+
+// MonitoringURL returns the URL of the monitoring API of the given Logstash pod.
+func MonitoringURL(p corev1.Pod) string {
+	return fmt.Sprintf("http://%s:%d", p.Status.PodIP, MonitorHTTPPort)
+}
+
+// SettingsEnv converts the given Logstash settings to the environment variables the Logstash docker image
+// turns into logstash.yml settings (for example api.http.port becomes API_HTTP_PORT).
+func SettingsEnv(settings map[string]interface{}) []corev1.EnvVar {
+	env := make([]corev1.EnvVar, 0, len(settings))
+	for k, v := range settings {
+		env = append(env, corev1.EnvVar{
+			Name:  strings.ToUpper(strings.ReplaceAll(k, ".", "_")),
+			Value: fmt.Sprintf("%v", v),
+		})
+	}
+	return env
+}
@@ -235,7 +235,7 @@ func TestNewPodTemplateSpec(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewPodTemplateSpec(tt.ls, tt.keystore)
+			got := NewPodTemplateSpec(tt.ls, tt.keystore, nil)
 			tt.assertions(got)
 		})
 	}
@@ -0,0 +1,64 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package pod
+
+import (
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DrainTimeoutEnvVar is the environment variable holding the drain timeout in seconds.
+	DrainTimeoutEnvVar = "DRAIN_TIMEOUT_SECONDS"
+	// shutdownGracePeriod is the duration left to Logstash to stop once its queues are drained.
+	shutdownGracePeriod = 30 * time.Second
+)
+
+// PreStopHookScript waits until the queues of all the pipelines of the Logstash node are empty, or until the drain
+// timeout is reached. The Logstash pod is removed from the Service endpoints while this script runs, so no new events
+// are received.
+const PreStopHookScript = `#!/usr/bin/env bash
+CURL_TIMEOUT=3
+DEADLINE=$(( $(date +%s) + ${DRAIN_TIMEOUT_SECONDS:-120} ))
+ENDPOINT="http://127.0.0.1:${HTTP_PORT:-9600}/_node/stats/pipelines"
+
+while [ "$(date +%s)" -lt "$DEADLINE" ]; do
+  stats=$(curl --max-time $CURL_TIMEOUT -s $ENDPOINT) || exit 0
+  pending=0
+  for count in $(echo "$stats" | grep -o '"events_count":[0-9]*' | cut -d: -f2); do
+    pending=$(( pending + count ))
+  done
+  if [ "$pending" -eq 0 ]; then
+    exit 0
+  fi
+  echo "Waiting for $pending queued events to be processed"
+  sleep 2
+done
+exit 0
+`
+
+// NewPreStopHook returns the pre-stop hook draining the pipeline queues of the Logstash node.
+func NewPreStopHook() corev1.Handler {
+	return corev1.Handler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"bash", "-c", PreStopHookScript},
+		},
+	}
+}
+
+// TerminationGracePeriod returns the termination grace period in seconds of the Logstash pods,
+// which leaves enough time for the queues to be drained and Logstash to stop.
+func TerminationGracePeriod(drainTimeout time.Duration) int64 {
+	return int64((drainTimeout + shutdownGracePeriod) / time.Second)
+}
+
+func drainTimeoutEnv(drainTimeout time.Duration) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name:  DrainTimeoutEnvVar,
+		Value: strconv.FormatInt(int64(drainTimeout/time.Second), 10),
+	}
+}
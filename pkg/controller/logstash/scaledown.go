@@ -0,0 +1,83 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"time"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/deployment"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scaleDownRequeue is the interval at which a pending scale down is re-evaluated.
+var scaleDownRequeue = 5 * time.Second
+
+// limitScaleDown adjusts the expected replicas so that Logstash nodes are removed one at a time, and only once the
+// previously removed nodes have drained their queues and exited.
+// It returns true if the scale down is not complete yet.
+func (d *driver) limitScaleDown(ls lstype.Logstash, params *deployment.Params) (bool, error) {
+	var actual appsv1.Deployment
+	err := d.client.Get(types.NamespacedName{Namespace: params.Namespace, Name: params.Name}, &actual)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	current := int32(1)
+	if actual.Spec.Replicas != nil {
+		current = *actual.Spec.Replicas
+	}
+	if params.Replicas >= current {
+		return false, nil
+	}
+
+	terminating, err := d.terminatingPods(ls)
+	if err != nil {
+		return false, err
+	}
+	if terminating > 0 {
+		log.V(1).Info(
+			"Delaying scale down until terminating pods have drained their queues",
+			"namespace", ls.Namespace, "logstash_name", ls.Name, "terminating", terminating,
+		)
+		params.Replicas = current
+		return true, nil
+	}
+
+	target := current - 1
+	pending := target > params.Replicas
+	params.Replicas = target
+	return pending, nil
+}
+
+// terminatingPods returns the number of stable Logstash pods being deleted. The canary pod is managed separately.
+func (d *driver) terminatingPods(ls lstype.Logstash) (int, error) {
+	selector, err := label.NewStableSelector(ls.Name)
+	if err != nil {
+		return 0, err
+	}
+	var pods corev1.PodList
+	if err := d.client.List(&pods,
+		client.InNamespace(ls.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return 0, err
+	}
+	terminating := 0
+	for _, p := range pods.Items {
+		if !p.DeletionTimestamp.IsZero() {
+			terminating++
+		}
+	}
+	return terminating, nil
+}
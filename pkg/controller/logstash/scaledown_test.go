@@ -0,0 +1,99 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"testing"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/deployment"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_driver_limitScaleDown(t *testing.T) {
+	ls := lstype.Logstash{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"}}
+	actualDeployment := func(replicas int32) runtime.Object {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls-ls"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+	}
+	lsPod := func(name string, terminating bool) runtime.Object {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Labels: label.NewLabels("ls")}}
+		if terminating {
+			now := metav1.Now()
+			p.DeletionTimestamp = &now
+		}
+		return &p
+	}
+	canaryPod := func(terminating bool) runtime.Object {
+		p := lsPod("canary", terminating).(*corev1.Pod)
+		p.Labels = label.NewCanaryLabels("ls")
+		return p
+	}
+	tests := []struct {
+		name         string
+		objects      []runtime.Object
+		replicas     int32
+		wantReplicas int32
+		wantPending  bool
+	}{
+		{
+			name:         "no existing deployment",
+			replicas:     1,
+			wantReplicas: 1,
+		},
+		{
+			name:         "scale up",
+			objects:      []runtime.Object{actualDeployment(1)},
+			replicas:     3,
+			wantReplicas: 3,
+		},
+		{
+			name:         "scale down by one",
+			objects:      []runtime.Object{actualDeployment(3), lsPod("a", false), lsPod("b", false), lsPod("c", false)},
+			replicas:     2,
+			wantReplicas: 2,
+		},
+		{
+			name:         "scale down one replica at a time",
+			objects:      []runtime.Object{actualDeployment(3), lsPod("a", false), lsPod("b", false), lsPod("c", false)},
+			replicas:     1,
+			wantReplicas: 2,
+			wantPending:  true,
+		},
+		{
+			name:         "wait for terminating pods",
+			objects:      []runtime.Object{actualDeployment(2), lsPod("a", false), lsPod("b", true)},
+			replicas:     1,
+			wantReplicas: 2,
+			wantPending:  true,
+		},
+		{
+			name:         "ignore the terminating canary pod",
+			objects:      []runtime.Object{actualDeployment(2), lsPod("a", false), lsPod("b", false), canaryPod(true)},
+			replicas:     1,
+			wantReplicas: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := driver{client: k8s.WrapClient(fake.NewFakeClientWithScheme(scheme.Scheme, tt.objects...))}
+			params := deployment.Params{Namespace: "ns", Name: "ls-ls", Replicas: tt.replicas}
+			pending, err := d.limitScaleDown(ls, &params)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPending, pending)
+			require.Equal(t, tt.wantReplicas, params.Replicas)
+		})
+	}
+}
@@ -5,9 +5,15 @@
 package logstash
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	logstashv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/defaults"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
 	lsname "github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
@@ -33,3 +39,77 @@ func NewService(ls logstashv1beta1.Logstash) *corev1.Service {
 
 	return defaults.SetServiceDefaults(&svc, labels, labels, ports)
 }
+
+// NewInputService returns the Kubernetes Service of the given input service, exposing the named ports of the Logstash
+// container.
+func NewInputService(ls logstashv1beta1.Logstash, inputService logstashv1beta1.InputService) (*corev1.Service, error) {
+	svc := corev1.Service{
+		ObjectMeta: *inputService.Service.ObjectMeta.DeepCopy(),
+		Spec:       *inputService.Service.Spec.DeepCopy(),
+	}
+
+	svc.ObjectMeta.Namespace = ls.Namespace
+	svc.ObjectMeta.Name = lsname.InputService(ls.Name, inputService.Name)
+
+	labels := label.NewLabels(ls.Name)
+	labels[label.InputServiceLabelName] = inputService.Name
+	containerPorts := pod.ContainerPorts(ls)
+	ports := make([]corev1.ServicePort, 0, len(inputService.Ports))
+	for _, portName := range inputService.Ports {
+		containerPort, exists := pod.ContainerPort(containerPorts, portName)
+		if !exists {
+			return nil, fmt.Errorf("input service %s exposes unknown container port %s", inputService.Name, portName)
+		}
+		protocol := containerPort.Protocol
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		ports = append(ports, corev1.ServicePort{
+			Name:       containerPort.Name,
+			Protocol:   protocol,
+			Port:       containerPort.ContainerPort,
+			TargetPort: intstr.FromString(containerPort.Name),
+		})
+	}
+
+	return defaults.SetServiceDefaults(&svc, labels, label.NewLabels(ls.Name), ports), nil
+}
+
+// reconcileInputServices reconciles the input services of the given Logstash, and deletes the services of the input
+// services that were removed from its specification.
+func (d *driver) reconcileInputServices(ls logstashv1beta1.Logstash) ([]corev1.Service, error) {
+	expected := make(map[string]struct{}, len(ls.Spec.Services))
+	reconciled := make([]corev1.Service, 0, len(ls.Spec.Services))
+	for _, inputService := range ls.Spec.Services {
+		svc, err := NewInputService(ls, inputService)
+		if err != nil {
+			return nil, err
+		}
+		expected[svc.Name] = struct{}{}
+		reconciledSvc, err := common.ReconcileService(d.client, d.scheme, svc, &ls)
+		if err != nil {
+			return nil, err
+		}
+		reconciled = append(reconciled, *reconciledSvc)
+	}
+
+	var services corev1.ServiceList
+	if err := d.client.List(&services,
+		client.InNamespace(ls.Namespace),
+		client.MatchingLabels(label.NewLabels(ls.Name)),
+	); err != nil {
+		return nil, err
+	}
+	for i := range services.Items {
+		if _, isInputService := services.Items[i].Labels[label.InputServiceLabelName]; !isInputService {
+			continue
+		}
+		if _, exists := expected[services.Items[i].Name]; exists {
+			continue
+		}
+		if err := d.client.Delete(&services.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return reconciled, nil
+}
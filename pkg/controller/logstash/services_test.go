@@ -0,0 +1,101 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstash
+
+import (
+	"testing"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestNewInputService(t *testing.T) {
+	ls := lstype.Logstash{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"},
+		Spec: lstype.LogstashSpec{
+			PodTemplate: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  lstype.LogstashContainerName,
+				Ports: []corev1.ContainerPort{{Name: "syslog", ContainerPort: 514, Protocol: corev1.ProtocolUDP}},
+			}}}},
+		},
+	}
+
+	svc, err := NewInputService(ls, lstype.InputService{
+		Name:  "syslog",
+		Ports: []string{"syslog"},
+		Service: commonv1beta1.ServiceTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"cloud.google.com/load-balancer-type": "Internal"},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ls-ls-syslog", svc.Name)
+	require.Equal(t, "ns", svc.Namespace)
+	require.Equal(t, "Internal", svc.Annotations["cloud.google.com/load-balancer-type"])
+	require.Equal(t, "syslog", svc.Labels["logstash.k8s.elastic.co/input-service"])
+	require.Equal(t, map[string]string{"logstash.k8s.elastic.co/name": "ls", "common.k8s.elastic.co/type": "logstash"}, svc.Spec.Selector)
+	require.Equal(t, corev1.ServiceTypeLoadBalancer, svc.Spec.Type)
+	require.Equal(t, []corev1.ServicePort{
+		{Name: "syslog", Protocol: corev1.ProtocolUDP, Port: 514, TargetPort: intstr.FromString("syslog")},
+	}, svc.Spec.Ports)
+
+	svc, err = NewInputService(ls, lstype.InputService{Name: "beats", Ports: []string{"beats"}})
+	require.NoError(t, err)
+	require.Equal(t, []corev1.ServicePort{
+		{Name: "beats", Protocol: corev1.ProtocolTCP, Port: 5044, TargetPort: intstr.FromString("beats")},
+	}, svc.Spec.Ports)
+
+	_, err = NewInputService(ls, lstype.InputService{Name: "kafka", Ports: []string{"kafka"}})
+	require.Error(t, err)
+}
+
+func Test_driver_reconcileInputServices(t *testing.T) {
+	require.NoError(t, lstype.AddToScheme(scheme.Scheme))
+	ls := lstype.Logstash{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"},
+		Spec: lstype.LogstashSpec{
+			Services: []lstype.InputService{
+				{Name: "a", Ports: []string{"beats"}},
+				{Name: "b", Ports: []string{"beats"}},
+			},
+		},
+	}
+	c := k8s.WrapClient(fake.NewFakeClientWithScheme(scheme.Scheme))
+	d := driver{client: c, scheme: scheme.Scheme}
+
+	services, err := d.reconcileInputServices(ls)
+	require.NoError(t, err)
+	require.Len(t, services, 2)
+
+	// the load balancer of b gets an address
+	var b corev1.Service
+	require.NoError(t, c.Get(types.NamespacedName{Namespace: "ns", Name: "ls-ls-b"}, &b))
+	b.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}, {Hostname: "lb.example.com"}}
+	require.NoError(t, c.Update(&b))
+
+	// a is removed from the spec
+	ls.Spec.Services = ls.Spec.Services[1:]
+	services, err = d.reconcileInputServices(ls)
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	require.Error(t, c.Get(types.NamespacedName{Namespace: "ns", Name: "ls-ls-a"}, &corev1.Service{}))
+
+	state := NewState(reconcile.Request{}, &ls)
+	state.UpdateInputServicesStatus(services)
+	require.Equal(t, []lstype.InputServiceStatus{
+		{Name: "b", ExternalAddresses: []string{"10.0.0.1", "lb.example.com"}},
+	}, ls.Status.Services)
+}
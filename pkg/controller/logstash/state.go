@@ -5,17 +5,23 @@
 package logstash
 
 import (
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/conditions"
+	commondeployment "github.com/cloudptio/logstash-operator/pkg/controller/common/deployment"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/observer"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // State holds the accumulated state during the reconcile loop including the response and a pointer to a Logstash
 // resource for status updates.
 type State struct {
-	Logstash  *v1beta1.Logstash
-	Request reconcile.Request
+	Logstash *v1beta1.Logstash
+	Request  reconcile.Request
 
 	originalLogstash *v1beta1.Logstash
 }
@@ -35,4 +41,72 @@ func (s State) UpdateLogstashState(deployment v1.Deployment) {
 			s.Logstash.Status.Health = v1beta1.LogstashGreen
 		}
 	}
+	s.SetConditions(conditions.FromDeployment(s.Logstash.Generation, deployment)...)
+	if trigger, completed := commondeployment.CompletedRestartTrigger(deployment); completed {
+		s.Logstash.Status.RestartTrigger = trigger
+	}
+}
+
+// SetConditions sets the given conditions in the Logstash status.
+func (s State) SetConditions(c ...commonv1beta1.Condition) {
+	for _, condition := range c {
+		s.Logstash.Status.Conditions = s.Logstash.Status.Conditions.Set(condition)
+	}
+}
+
+// UpdateCanaryStatus records the given canary rollout phase in the Logstash status.
+func (s State) UpdateCanaryStatus(phase v1beta1.CanaryPhase, checksum string, startTime *metav1.Time, message string) {
+	s.Logstash.Status.Canary = &v1beta1.CanaryStatus{
+		Phase:          phase,
+		ConfigChecksum: checksum,
+		StartTime:      startTime,
+		Message:        message,
+	}
+}
+
+// UpdateDeadLetterQueueStatus records the total size of the dead letter queues of the given observed nodes in the
+// Logstash status. The previous size is kept if the nodes were not observed yet.
+func (s State) UpdateDeadLetterQueueStatus(observed observer.State) {
+	if !s.Logstash.Spec.DeadLetterQueue.Enabled {
+		s.Logstash.Status.DeadLetterQueueBytes = 0
+		return
+	}
+	if observed.Nodes == nil {
+		return
+	}
+	var size int64
+	for _, stats := range observed.Nodes {
+		size += stats.DeadLetterQueueBytes()
+	}
+	s.Logstash.Status.DeadLetterQueueBytes = size
+}
+
+// UpdateIndexBootstrapStatus records the given installation status of the index template and ILM policy in the
+// Logstash status.
+func (s State) UpdateIndexBootstrapStatus(status *v1beta1.IndexBootstrapStatus) {
+	s.Logstash.Status.IndexBootstrap = status
+}
+
+// UpdateCentralManagementStatus records the given central management status in the Logstash status.
+func (s State) UpdateCentralManagementStatus(status *v1beta1.CentralManagementStatus) {
+	s.Logstash.Status.CentralManagement = status
+}
+
+// UpdateInputServicesStatus records the external addresses of the given input services in the Logstash status.
+func (s State) UpdateInputServicesStatus(services []corev1.Service) {
+	var statuses []v1beta1.InputServiceStatus
+	for _, svc := range services {
+		status := v1beta1.InputServiceStatus{Name: svc.Labels[label.InputServiceLabelName]}
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				status.ExternalAddresses = append(status.ExternalAddresses, ingress.IP)
+			}
+			if ingress.Hostname != "" {
+				status.ExternalAddresses = append(status.ExternalAddresses, ingress.Hostname)
+			}
+		}
+		status.ExternalAddresses = append(status.ExternalAddresses, svc.Spec.ExternalIPs...)
+		statuses = append(statuses, status)
+	}
+	s.Logstash.Status.Services = statuses
 }
@@ -0,0 +1,58 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package validation
+
+import (
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/validation"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+)
+
+// Validation is a function from the currently running Logstash version and proposed new spec
+// (both inside a Context struct) to a validation.Result.
+type Validation func(ctx Context) validation.Result
+
+// LogstashVersion groups a Logstash resource and its parsed version.
+type LogstashVersion struct {
+	Logstash lstype.Logstash
+	Version  version.Version
+}
+
+// Context is structured input for validation functions.
+type Context struct {
+	// CurrentVersion is the lowest version of the running Logstash nodes. Can be nil on new deployments.
+	CurrentVersion *version.Version
+	// Proposed is the Logstash spec/version submitted for validation.
+	Proposed LogstashVersion
+}
+
+func (v Context) isCreate() bool {
+	return v.CurrentVersion == nil
+}
+
+// Validate runs the registered validations against the given Logstash and the version of its running nodes, if any.
+func Validate(ls lstype.Logstash, currentVersion *version.Version) ([]validation.Result, error) {
+	v, err := version.Parse(ls.Spec.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	vCtx := Context{
+		CurrentVersion: currentVersion,
+		Proposed: LogstashVersion{
+			Logstash: ls,
+			Version:  *v,
+		},
+	}
+	var errs []validation.Result
+	for _, v := range Validations {
+		r := v(vCtx)
+		if r.Allowed {
+			continue
+		}
+		errs = append(errs, r)
+	}
+	return errs, nil
+}
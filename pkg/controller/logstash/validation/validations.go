@@ -0,0 +1,204 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/validation"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	lsname "github.com/cloudptio/logstash-operator/pkg/controller/logstash/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pipeline"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pod"
+	lsversion "github.com/cloudptio/logstash-operator/pkg/controller/logstash/version"
+)
+
+const (
+	noDowngradesMsg = "Downgrades are not supported"
+)
+
+// Validations are all registered Logstash validations.
+var Validations = []Validation{
+	supportedVersion,
+	noDowngrades,
+	validUpgradePath,
+	validTopology,
+	validInputServices,
+	apiKeyAuthModeSupported,
+}
+
+func unsupportedVersion(v *version.Version) string {
+	return fmt.Sprintf("unsupported version: %v", v)
+}
+
+func unsupportedUpgradePath(v1, v2 version.Version) string {
+	return fmt.Sprintf("unsupported version upgrade from %v to %v", v1, v2)
+}
+
+// supportedVersion checks if the version is supported.
+func supportedVersion(ctx Context) validation.Result {
+	if v := lsversion.SupportedVersions(ctx.Proposed.Version); v != nil {
+		if err := v.Supports(ctx.Proposed.Version); err == nil {
+			return validation.OK
+		}
+	}
+	return validation.Result{Allowed: false, Reason: unsupportedVersion(&ctx.Proposed.Version)}
+}
+
+func noDowngrades(ctx Context) validation.Result {
+	if ctx.isCreate() {
+		return validation.OK
+	}
+	if !ctx.Proposed.Version.IsSameOrAfter(*ctx.CurrentVersion) {
+		return validation.Result{Allowed: false, Reason: noDowngradesMsg}
+	}
+	return validation.OK
+}
+
+func validUpgradePath(ctx Context) validation.Result {
+	if ctx.isCreate() {
+		return validation.OK
+	}
+
+	v := lsversion.SupportedVersions(ctx.Proposed.Version)
+	if v == nil {
+		return validation.Result{Allowed: false, Reason: unsupportedVersion(&ctx.Proposed.Version)}
+	}
+	if err := v.Supports(*ctx.CurrentVersion); err != nil {
+		return validation.Result{
+			Allowed: false,
+			Reason:  unsupportedUpgradePath(*ctx.CurrentVersion, ctx.Proposed.Version),
+		}
+	}
+	return validation.OK
+}
+
+// validTopology checks that the pipelines of the topology have unique names, only send events to other pipelines
+// of the topology, do not form cycles and have a valid configuration.
+func validTopology(ctx Context) validation.Result {
+	topology := ctx.Proposed.Logstash.Spec.Topology
+	edges := make(map[string][]string, len(topology))
+	for _, p := range topology {
+		if p.Name == pipeline.MainPipelineID ||
+			(p.Name == pipeline.DeadLetterQueuePipelineID && ctx.Proposed.Logstash.Spec.DeadLetterQueue.ReprocessingEnabled()) {
+			return validation.Result{Allowed: false, Reason: fmt.Sprintf("topology pipeline name %s is reserved", p.Name)}
+		}
+		if _, exists := edges[p.Name]; exists {
+			return validation.Result{Allowed: false, Reason: fmt.Sprintf("topology pipeline %s is declared more than once", p.Name)}
+		}
+		edges[p.Name] = make([]string, 0, len(p.SendTo))
+		for _, edge := range p.SendTo {
+			edges[p.Name] = append(edges[p.Name], edge.Pipeline)
+		}
+	}
+	for _, p := range topology {
+		for _, target := range edges[p.Name] {
+			if _, exists := edges[target]; !exists {
+				return validation.Result{
+					Allowed: false,
+					Reason:  fmt.Sprintf("topology pipeline %s sends events to unknown pipeline %s", p.Name, target),
+				}
+			}
+		}
+	}
+	if cycle := findCycle(topology, edges); cycle != nil {
+		return validation.Result{
+			Allowed: false,
+			Reason:  fmt.Sprintf("topology pipelines form a cycle: %s", strings.Join(cycle, " -> ")),
+		}
+	}
+	for _, p := range pipeline.TopologyPipelines(ctx.Proposed.Logstash) {
+		if err := pipeline.ValidateConfig(p.Spec.Config); err != nil {
+			return validation.Result{
+				Allowed: false,
+				Reason:  fmt.Sprintf("topology pipeline %s has an invalid configuration: %v", p.Spec.PipelineID, err),
+			}
+		}
+	}
+	return validation.OK
+}
+
+// validInputServices checks that the input services have unique names that do not clash with the HTTP service, and
+// only expose ports of the Logstash container.
+func validInputServices(ctx Context) validation.Result {
+	ls := ctx.Proposed.Logstash
+	containerPorts := pod.ContainerPorts(ls)
+	names := make(map[string]struct{}, len(ls.Spec.Services))
+	for _, svc := range ls.Spec.Services {
+		if lsname.InputService(ls.Name, svc.Name) == lsname.HTTPService(ls.Name) {
+			return validation.Result{Allowed: false, Reason: fmt.Sprintf("input service name %s is reserved", svc.Name)}
+		}
+		if _, exists := names[svc.Name]; exists {
+			return validation.Result{Allowed: false, Reason: fmt.Sprintf("input service %s is declared more than once", svc.Name)}
+		}
+		names[svc.Name] = struct{}{}
+		for _, portName := range svc.Ports {
+			if _, exists := pod.ContainerPort(containerPorts, portName); !exists {
+				return validation.Result{
+					Allowed: false,
+					Reason:  fmt.Sprintf("input service %s exposes unknown container port %s", svc.Name, portName),
+				}
+			}
+		}
+	}
+	return validation.OK
+}
+
+// apiKeyAuthModeSupported checks that the apiKey auth mode is only set on versions whose elasticsearch output supports
+// API keys. The support of the referenced Elasticsearch cluster is checked by the association controller.
+func apiKeyAuthModeSupported(ctx Context) validation.Result {
+	if ctx.Proposed.Logstash.Spec.AuthMode != commonv1beta1.APIKeyAuthMode ||
+		ctx.Proposed.Version.IsSameOrAfter(lsversion.APIKeyMinVersion) {
+		return validation.OK
+	}
+	return validation.Result{
+		Allowed: false,
+		Reason:  fmt.Sprintf("the apiKey auth mode requires Logstash %s or above", lsversion.APIKeyMinVersion),
+	}
+}
+
+// findCycle returns the pipelines forming a cycle in the given topology edges, if any.
+func findCycle(topology []lstype.TopologyPipeline, edges map[string][]string) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(edges))
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+		for _, target := range edges[name] {
+			switch state[target] {
+			case visiting:
+				for i, n := range path {
+					if n == target {
+						return append(append([]string{}, path[i:]...), target)
+					}
+				}
+			case unvisited:
+				if cycle := visit(target); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, p := range topology {
+		if state[p.Name] == unvisited {
+			if cycle := visit(p.Name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package validation
+
+import (
+	"testing"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func ls(v string) lstype.Logstash {
+	return lstype.Logstash{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+		},
+		Spec: lstype.LogstashSpec{Version: v},
+	}
+}
+
+func versionPtr(v string) *version.Version {
+	parsed := version.MustParse(v)
+	return &parsed
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		ls             lstype.Logstash
+		currentVersion *version.Version
+		wantReasons    []string
+		wantErr        bool
+	}{
+		{
+			name:    "invalid version",
+			ls:      ls("not-a-version"),
+			wantErr: true,
+		},
+		{
+			name:        "unsupported version",
+			ls:          ls("5.6.0"),
+			wantReasons: []string{"unsupported version: 5.6.0"},
+		},
+		{
+			name: "new 8.x deployment",
+			ls:   ls("8.1.0"),
+		},
+		{
+			name:           "upgrade from 7.17 to 8.x",
+			ls:             ls("8.1.0"),
+			currentVersion: versionPtr("7.17.0"),
+		},
+		{
+			name:           "unsupported upgrade path",
+			ls:             ls("8.1.0"),
+			currentVersion: versionPtr("7.10.0"),
+			wantReasons:    []string{"unsupported version upgrade from 7.10.0 to 8.1.0"},
+		},
+		{
+			name:           "downgrade",
+			ls:             ls("7.17.0"),
+			currentVersion: versionPtr("8.1.0"),
+			wantReasons:    []string{noDowngradesMsg, "unsupported version upgrade from 8.1.0 to 7.17.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Validate(tt.ls, tt.currentVersion)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var reasons []string
+			for _, r := range results {
+				reasons = append(reasons, r.Reason)
+			}
+			require.Equal(t, tt.wantReasons, reasons)
+		})
+	}
+}
+
+func Test_validTopology(t *testing.T) {
+	edge := func(pipeline string) lstype.PipelineEdge {
+		return lstype.PipelineEdge{Pipeline: pipeline}
+	}
+	tests := []struct {
+		name       string
+		topology   []lstype.TopologyPipeline
+		wantReason string
+	}{
+		{
+			name: "no topology",
+		},
+		{
+			name: "distributor, forked path and collector",
+			topology: []lstype.TopologyPipeline{
+				{Name: "distributor", Config: "input { beats {} }", SendTo: []lstype.PipelineEdge{
+					{Pipeline: "apache", If: `[type] == "apache"`},
+					{Pipeline: "system", If: `[type] == "system"`},
+				}},
+				{Name: "apache", SendTo: []lstype.PipelineEdge{edge("collector"), edge("archive")}},
+				{Name: "system", SendTo: []lstype.PipelineEdge{edge("collector")}},
+				{Name: "collector", Config: "output { stdout {} }"},
+				{Name: "archive", Config: "output { stdout {} }"},
+			},
+		},
+		{
+			name:       "reserved name",
+			topology:   []lstype.TopologyPipeline{{Name: "main", Config: "input { beats {} }"}},
+			wantReason: "topology pipeline name main is reserved",
+		},
+		{
+			name: "duplicate name",
+			topology: []lstype.TopologyPipeline{
+				{Name: "a", Config: "input { beats {} }"},
+				{Name: "a", Config: "input { beats {} }"},
+			},
+			wantReason: "topology pipeline a is declared more than once",
+		},
+		{
+			name: "dangling address",
+			topology: []lstype.TopologyPipeline{
+				{Name: "a", Config: "input { beats {} }", SendTo: []lstype.PipelineEdge{edge("b")}},
+			},
+			wantReason: "topology pipeline a sends events to unknown pipeline b",
+		},
+		{
+			name: "cycle",
+			topology: []lstype.TopologyPipeline{
+				{Name: "a", Config: "input { beats {} }", SendTo: []lstype.PipelineEdge{edge("b")}},
+				{Name: "b", SendTo: []lstype.PipelineEdge{edge("c")}},
+				{Name: "c", SendTo: []lstype.PipelineEdge{edge("b")}},
+			},
+			wantReason: "topology pipelines form a cycle: b -> c -> b",
+		},
+		{
+			name: "invalid configuration",
+			topology: []lstype.TopologyPipeline{
+				{Name: "a", Config: "filter {"},
+			},
+			wantReason: "topology pipeline a has an invalid configuration: missing closing brace",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proposed := ls("7.4.0")
+			proposed.Spec.Topology = tt.topology
+			result := validTopology(Context{Proposed: LogstashVersion{Logstash: proposed}})
+			require.Equal(t, tt.wantReason == "", result.Allowed)
+			require.Equal(t, tt.wantReason, result.Reason)
+		})
+	}
+}
+
+func Test_validInputServices(t *testing.T) {
+	tests := []struct {
+		name       string
+		services   []lstype.InputService
+		wantReason string
+	}{
+		{
+			name: "no input service",
+		},
+		{
+			name: "default and pod template ports",
+			services: []lstype.InputService{
+				{Name: "syslog", Ports: []string{"syslog"}},
+				{Name: "beats", Ports: []string{"beats"}},
+			},
+		},
+		{
+			name:       "reserved name",
+			services:   []lstype.InputService{{Name: "http", Ports: []string{"beats"}}},
+			wantReason: "input service name http is reserved",
+		},
+		{
+			name: "duplicate name",
+			services: []lstype.InputService{
+				{Name: "beats", Ports: []string{"beats"}},
+				{Name: "beats", Ports: []string{"syslog"}},
+			},
+			wantReason: "input service beats is declared more than once",
+		},
+		{
+			name:       "unknown port",
+			services:   []lstype.InputService{{Name: "kafka", Ports: []string{"kafka"}}},
+			wantReason: "input service kafka exposes unknown container port kafka",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proposed := ls("7.4.0")
+			proposed.Spec.PodTemplate.Spec.Containers = []corev1.Container{{
+				Name:  lstype.LogstashContainerName,
+				Ports: []corev1.ContainerPort{{Name: "syslog", ContainerPort: 514, Protocol: corev1.ProtocolUDP}},
+			}}
+			proposed.Spec.Services = tt.services
+			result := validInputServices(Context{Proposed: LogstashVersion{Logstash: proposed}})
+			require.Equal(t, tt.wantReason == "", result.Allowed)
+			require.Equal(t, tt.wantReason, result.Reason)
+		})
+	}
+}
+
+func Test_apiKeyAuthModeSupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		authMode commonv1beta1.AuthMode
+		want     bool
+	}{
+		{
+			name:    "user auth mode",
+			version: "7.4.0",
+			want:    true,
+		},
+		{
+			name:     "apiKey auth mode without the api_key output option",
+			version:  "7.5.2",
+			authMode: commonv1beta1.APIKeyAuthMode,
+			want:     false,
+		},
+		{
+			name:     "apiKey auth mode with the api_key output option",
+			version:  "7.6.0",
+			authMode: commonv1beta1.APIKeyAuthMode,
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proposed := ls(tt.version)
+			proposed.Spec.AuthMode = tt.authMode
+			ctx := Context{Proposed: LogstashVersion{Logstash: proposed, Version: version.MustParse(tt.version)}}
+			require.Equal(t, tt.want, apiKeyAuthModeSupported(ctx).Allowed)
+		})
+	}
+}
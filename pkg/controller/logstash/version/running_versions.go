@@ -0,0 +1,29 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package version
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+)
+
+// MinVersion extracts the lowest Logstash version from the running pods.
+// Pods created before the version label was introduced are ignored. Returns nil if no version can be found.
+func MinVersion(pods []corev1.Pod) (*version.Version, error) {
+	var vs []version.Version
+	for _, pod := range pods {
+		if _, exists := pod.Labels[label.VersionLabelName]; !exists {
+			continue
+		}
+		v, err := label.ExtractVersion(pod.Labels)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, *v)
+	}
+	return version.Min(vs), nil
+}
@@ -0,0 +1,69 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package version
+
+import (
+	"fmt"
+
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/version"
+)
+
+// APIKeyMinVersion is the first Logstash version whose elasticsearch output supports the `api_key` option, required by
+// the apiKey auth mode.
+var APIKeyMinVersion = version.MustParse("7.6.0")
+
+// DataStreamMinVersion is the first Logstash version whose elasticsearch output supports the `data_stream` options,
+// required to write to a data stream.
+var DataStreamMinVersion = version.MustParse("7.13.0")
+
+// LowestHighestSupportedVersions expresses the range of Logstash versions a given version can be upgraded from.
+type LowestHighestSupportedVersions struct {
+	LowestSupportedVersion  version.Version
+	HighestSupportedVersion version.Version
+}
+
+func SupportedVersions(v version.Version) *LowestHighestSupportedVersions {
+	switch v.Major {
+	case 6:
+		return &LowestHighestSupportedVersions{
+			LowestSupportedVersion:  version.MustParse("6.0.0"),
+			HighestSupportedVersion: version.MustParse("6.99.99"),
+		}
+	case 7:
+		return &LowestHighestSupportedVersions{
+			// upgrades to 7.x are supported from the latest 6.x minor
+			LowestSupportedVersion:  version.MustParse("6.8.0"),
+			HighestSupportedVersion: version.MustParse("7.99.99"),
+		}
+	case 8:
+		return &LowestHighestSupportedVersions{
+			// upgrades to 8.x are supported from the latest 7.x minor
+			LowestSupportedVersion:  version.MustParse("7.17.0"),
+			HighestSupportedVersion: version.MustParse("8.99.99"),
+		}
+	default:
+		return nil
+	}
+}
+
+// Supports compares a given with the supported version range and returns an error if out of bounds.
+func (lh LowestHighestSupportedVersions) Supports(v version.Version) error {
+	if !v.IsSameOrAfter(lh.LowestSupportedVersion) {
+		return fmt.Errorf(
+			"%s is unsupported, it is older than the oldest supported version %s",
+			v,
+			lh.LowestSupportedVersion,
+		)
+	}
+
+	if !lh.HighestSupportedVersion.IsSameOrAfter(v) {
+		return fmt.Errorf(
+			"%s is unsupported, it is newer than the newest supported version %s",
+			v,
+			lh.HighestSupportedVersion,
+		)
+	}
+	return nil
+}
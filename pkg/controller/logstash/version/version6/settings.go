@@ -5,10 +5,25 @@
 package version6
 
 import (
+	"fmt"
+
 	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pod"
 )
 
 // SettingsFactory returns Logstash settings for a 6.x Logstash.
 func SettingsFactory(ls lstype.Logstash) map[string]interface{} {
-	return map[string]interface{}{}
+	return map[string]interface{}{
+		"http.host": "0.0.0.0",
+		"http.port": pod.MonitorHTTPPort,
+	}
+}
+
+// ElasticsearchOutputSSLSettings returns the SSL options of the elasticsearch output trusting the given CA file,
+// rendered in the Logstash configuration syntax.
+func ElasticsearchOutputSSLSettings(caFile string) map[string]string {
+	return map[string]string{
+		"ssl":    "true",
+		"cacert": fmt.Sprintf(`"%s"`, caFile),
+	}
 }
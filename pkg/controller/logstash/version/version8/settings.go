@@ -0,0 +1,34 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package version8
+
+import (
+	"fmt"
+
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/pod"
+)
+
+// SettingsFactory returns Logstash settings for a 8.x Logstash.
+// The http.* settings of the monitoring API are renamed to api.http.* in 8.x.
+func SettingsFactory(ls lstype.Logstash) map[string]interface{} {
+	return map[string]interface{}{
+		"api.http.host": "0.0.0.0",
+		"api.http.port": pod.MonitorHTTPPort,
+	}
+}
+
+// ElasticsearchOutputSSLSettings returns the SSL options of the elasticsearch output trusting the given CA file,
+// rendered in the Logstash configuration syntax.
+// Elasticsearch 8.x enables security by default: TLS verification is kept enabled and performed against the
+// CA of the referenced cluster, using the ssl_* options which replace the deprecated ssl and cacert options
+// from Logstash 8.8.
+func ElasticsearchOutputSSLSettings(caFile string) map[string]string {
+	return map[string]string{
+		"ssl_enabled":                 "true",
+		"ssl_verification_mode":       `"full"`,
+		"ssl_certificate_authorities": fmt.Sprintf(`["%s"]`, caFile),
+	}
+}
@@ -12,3 +12,36 @@ const (
 	PipelineVolumeMountPath = "/usr/share/logstash/pipeline"
 	PipelineVolumeMode      = 420
 )
+
+const (
+	// PipelinesFileVolumeName is the name of the volume exposing the pipelines.yml file of the pipeline config map.
+	PipelinesFileVolumeName = "pipelines-file"
+	// PipelinesFileMountPath is the path of the pipelines.yml file read by Logstash.
+	PipelinesFileMountPath = "/usr/share/logstash/config/pipelines.yml"
+)
+
+const (
+	// DeadLetterQueueVolumeName is the name of the volume storing the dead letter queues, if persisted.
+	DeadLetterQueueVolumeName = "dead-letter-queue"
+	// DeadLetterQueueMountPath is the mount path of the volume storing the dead letter queues, if persisted.
+	DeadLetterQueueMountPath = "/usr/share/logstash/dead_letter_queue"
+	// DeadLetterQueueSlotVolumeName is the name of the downward API volume exposing the dead letter queue slot
+	// annotation of the pod.
+	DeadLetterQueueSlotVolumeName = "dead-letter-queue-slot"
+	// DeadLetterQueueSlotMountPath is the mount path of the dead letter queue slot volume.
+	DeadLetterQueueSlotMountPath = "/mnt/logstash/dead-letter-queue-slot"
+	// DeadLetterQueueSlotFile is the file of the dead letter queue slot volume containing the slot of the pod.
+	DeadLetterQueueSlotFile = "slot"
+)
+
+const (
+	// JVMOptionsVolumeName is the name of the volume exposing the jvm.options file of the config files config map.
+	JVMOptionsVolumeName = "jvm-options"
+	// JVMOptionsMountPath is the path of the jvm.options file read by Logstash.
+	JVMOptionsMountPath = "/usr/share/logstash/config/jvm.options"
+	// Log4j2PropertiesVolumeName is the name of the volume exposing the log4j2.properties file of the config files
+	// config map.
+	Log4j2PropertiesVolumeName = "log4j2-properties"
+	// Log4j2PropertiesMountPath is the path of the log4j2.properties file read by Logstash.
+	Log4j2PropertiesMountPath = "/usr/share/logstash/config/log4j2.properties"
+)
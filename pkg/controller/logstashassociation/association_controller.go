@@ -15,6 +15,7 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates/http"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/conditions"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/finalizer"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/operator"
@@ -22,9 +23,11 @@ import (
 	"github.com/cloudptio/logstash-operator/pkg/controller/common/watches"
 	esname "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/name"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/services"
-	elasticsearchuser "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/user"
 	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
 	lslabel "github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/management"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/monitoring"
+	lsversion "github.com/cloudptio/logstash-operator/pkg/controller/logstash/version"
 	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -125,6 +128,9 @@ func (r *ReconcileAssociation) Reconcile(request reconcile.Request) (reconcile.R
 	if ok, err := association.FetchWithAssociation(r.Client, request, &logstash); !ok {
 		return reconcile.Result{}, err
 	}
+	if err := monitoring.LoadAssociationConf(&logstash); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	// register or execute watch finalizers
 	h := finalizer.NewHandler(r)
@@ -133,6 +139,7 @@ func (r *ReconcileAssociation) Reconcile(request reconcile.Request) (reconcile.R
 		&logstash,
 		watchFinalizer(lsName, r.watches),
 		user.UserFinalizer(r.Client, logstash.Kind, NewUserLabelSelector(lsName)),
+		association.APIKeyFinalizer(r.Client, r.Dialer, &logstash, logstash.Kind, logstashAPIKeySuffix),
 	)
 	if err != nil {
 		if apierrors.IsConflict(err) {
@@ -159,15 +166,33 @@ func (r *ReconcileAssociation) Reconcile(request reconcile.Request) (reconcile.R
 		return reconcile.Result{}, err
 	}
 
-	newStatus, err := r.reconcileInternal(&logstash)
+	newStatus, credentials, err := r.reconcileInternal(&logstash)
 	if err != nil {
 		k8s.EmitErrorEvent(r.recorder, err, &logstash, events.EventReconciliationError, "Reconciliation error: %v", err)
 	}
 
+	newMonitoringStatus, monitoringCredentials, monitoringErr := r.reconcileMonitoring(&logstash)
+	if monitoringErr != nil {
+		k8s.EmitErrorEvent(r.recorder, monitoringErr, &logstash, events.EventReconciliationError, "Monitoring reconciliation error: %v", monitoringErr)
+		if err == nil {
+			err = monitoringErr
+		}
+	}
+
 	// maybe update status
-	if !reflect.DeepEqual(logstash.Status.AssociationStatus, newStatus) {
+	lastRotation := credentials.LastRotation(logstash.Status.LastCredentialRotation)
+	newConditions := conditions.SetAssociationReady(logstash.Status.Conditions, logstash.Generation,
+		map[string]commonv1beta1.AssociationStatus{"Elasticsearch": newStatus, "Monitoring": newMonitoringStatus})
+	if !reflect.DeepEqual(logstash.Status.AssociationStatus, newStatus) ||
+		!reflect.DeepEqual(logstash.Status.MonitoringAssociationStatus, newMonitoringStatus) ||
+		lastRotation != logstash.Status.LastCredentialRotation ||
+		!reflect.DeepEqual(logstash.Status.Conditions, newConditions) {
 		oldStatus := logstash.Status.AssociationStatus
+		oldMonitoringStatus := logstash.Status.MonitoringAssociationStatus
 		logstash.Status.AssociationStatus = newStatus
+		logstash.Status.MonitoringAssociationStatus = newMonitoringStatus
+		logstash.Status.LastCredentialRotation = lastRotation
+		logstash.Status.Conditions = newConditions
 		if err := r.Status().Update(&logstash); err != nil {
 			if apierrors.IsConflict(err) {
 				// Conflicts are expected and will be resolved on next loop
@@ -177,22 +202,31 @@ func (r *ReconcileAssociation) Reconcile(request reconcile.Request) (reconcile.R
 
 			return defaultRequeue, err
 		}
-		r.recorder.AnnotatedEventf(&logstash,
-			annotation.ForAssociationStatusChange(oldStatus, newStatus),
-			corev1.EventTypeNormal,
-			events.EventAssociationStatusChange,
-			"Association status changed from [%s] to [%s]", oldStatus, newStatus)
+		if oldStatus != newStatus {
+			r.recorder.AnnotatedEventf(&logstash,
+				annotation.ForAssociationStatusChange(oldStatus, newStatus),
+				corev1.EventTypeNormal,
+				events.EventAssociationStatusChange,
+				"Association status changed from [%s] to [%s]", oldStatus, newStatus)
+		}
+		if oldMonitoringStatus != newMonitoringStatus {
+			r.recorder.Eventf(&logstash,
+				corev1.EventTypeNormal,
+				events.EventAssociationStatusChange,
+				"Monitoring association status changed from [%s] to [%s]", oldMonitoringStatus, newMonitoringStatus)
+		}
 	}
-	return resultFromStatus(newStatus), err
+	requeue := earliestRequeue(credentials.Requeue, monitoringCredentials.Requeue)
+	return association.WithCredentialsRequeue(resultFromStatus(newStatus, newMonitoringStatus), requeue), err
 }
 
-func resultFromStatus(status commonv1beta1.AssociationStatus) reconcile.Result {
-	switch status {
-	case commonv1beta1.AssociationPending:
-		return defaultRequeue // retry
-	default:
-		return reconcile.Result{} // we are done or there is not much we can do
+func resultFromStatus(statuses ...commonv1beta1.AssociationStatus) reconcile.Result {
+	for _, status := range statuses {
+		if status == commonv1beta1.AssociationPending {
+			return defaultRequeue // retry
+		}
 	}
+	return reconcile.Result{} // we are done or there is not much we can do
 }
 
 func (r *ReconcileAssociation) isCompatible(logstash *lstype.Logstash) (bool, error) {
@@ -205,7 +239,9 @@ func (r *ReconcileAssociation) isCompatible(logstash *lstype.Logstash) (bool, er
 	return compat, err
 }
 
-func (r *ReconcileAssociation) reconcileInternal(logstash *lstype.Logstash) (commonv1beta1.AssociationStatus, error) {
+// reconcileInternal reconciles the association of the given Logstash with Elasticsearch. It returns the status of the
+// association, and the reconciled credentials of Logstash if any.
+func (r *ReconcileAssociation) reconcileInternal(logstash *lstype.Logstash) (commonv1beta1.AssociationStatus, association.Credentials, error) {
 	logstashKey := k8s.ExtractNamespacedName(logstash)
 
 	// garbage collect leftover resources that are not required anymore
@@ -217,7 +253,7 @@ func (r *ReconcileAssociation) reconcileInternal(logstash *lstype.Logstash) (com
 		// stop watching any ES cluster previously referenced for this Logstash resource
 		r.watches.ElasticsearchClusters.RemoveHandlerForKey(elasticsearchWatchName(logstashKey))
 		// other leftover resources are already garbage-collected
-		return commonv1beta1.AssociationUnknown, nil
+		return commonv1beta1.AssociationUnknown, association.Credentials{}, nil
 	}
 
 	// this Logstash instance references an Elasticsearch cluster
@@ -234,17 +270,23 @@ func (r *ReconcileAssociation) reconcileInternal(logstash *lstype.Logstash) (com
 		Watched: []types.NamespacedName{esRefKey},
 		Watcher: logstashKey,
 	}); err != nil {
-		return commonv1beta1.AssociationFailed, err
+		return commonv1beta1.AssociationFailed, association.Credentials{}, err
 	}
 
-	userSecretKey := association.UserKey(logstash, logstashUserSuffix)
-	// watch the user secret in the ES namespace
+	apiKeySecretKey := types.NamespacedName{
+		Namespace: logstash.Namespace,
+		Name:      association.ClearTextSecretKeySelector(logstash, logstashAPIKeySuffix).Name,
+	}
+	// watch the user secrets in the ES namespace, and the API key secret
+	watched := append(association.RotatedUserKeys(logstash, esRefKey.Namespace, logstashUserSuffix),
+		association.RotatedUserKeys(logstash, esRefKey.Namespace, management.UserSuffix)...)
+	watched = append(watched, apiKeySecretKey)
 	if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
 		Name:    elasticsearchWatchName(logstashKey),
-		Watched: []types.NamespacedName{userSecretKey},
+		Watched: watched,
 		Watcher: logstashKey,
 	}); err != nil {
-		return commonv1beta1.AssociationFailed, err
+		return commonv1beta1.AssociationFailed, association.Credentials{}, err
 	}
 
 	var es estype.Elasticsearch
@@ -259,38 +301,47 @@ func (r *ReconcileAssociation) reconcileInternal(logstash *lstype.Logstash) (com
 			if err := association.RemoveAssociationConf(r.Client, logstash); err != nil && !errors.IsConflict(err) {
 				log.Error(err, "Failed to remove Elasticsearch configuration from Logstash object",
 					"namespace", logstash.Namespace, "logstash_name", logstash.Name)
-				return commonv1beta1.AssociationPending, err
+				return commonv1beta1.AssociationPending, association.Credentials{}, err
 			}
 
-			return commonv1beta1.AssociationPending, nil
+			return commonv1beta1.AssociationPending, association.Credentials{}, nil
 		}
-		return commonv1beta1.AssociationFailed, err
+		return commonv1beta1.AssociationFailed, association.Credentials{}, err
 	}
 
-	if err := association.ReconcileEsUser(
-		r.Client,
-		r.scheme,
-		logstash,
-		map[string]string{
-			AssociationLabelName:      logstash.Name,
-			AssociationLabelNamespace: logstash.Namespace,
-		},
-		elasticsearchuser.LogstashAdminUserBuiltinRole,
-		logstashUserSuffix,
-		es); err != nil {
-		return commonv1beta1.AssociationPending, err
+	if !association.IsReferenceAllowed(es, logstash, logstash.Kind) {
+		return commonv1beta1.AssociationFailed, association.Credentials{}, r.denyReference(logstash, es)
+	}
+
+	if logstash.Spec.AuthMode == commonv1beta1.APIKeyAuthMode {
+		if err := association.CheckAPIKeySupport(es, logstash.Spec.Version, lsversion.APIKeyMinVersion); err != nil {
+			k8s.EmitErrorEvent(r.recorder, err, logstash, events.EventAssociationError, "Unsupported apiKey auth mode: %v", err)
+			return commonv1beta1.AssociationFailed, association.Credentials{}, nil
+		}
 	}
 
+	credentials, err := r.reconcileAuth(logstash, es)
+	if err != nil {
+		return commonv1beta1.AssociationPending, association.Credentials{}, err
+	}
+
+	managementCredentials, err := r.reconcileManagementUser(logstash, es)
+	if err != nil {
+		return commonv1beta1.AssociationPending, association.Credentials{}, err
+	}
+	// reconcile again in time to rotate or retire the credentials of either user
+	credentials.Requeue = earliestRequeue(credentials.Requeue, managementCredentials.Requeue)
+
 	caSecret, err := r.reconcileElasticsearchCA(logstash, esRefKey)
 	if err != nil {
-		return commonv1beta1.AssociationPending, err
+		return commonv1beta1.AssociationPending, association.Credentials{}, err
 	}
 
 	// construct the expected association configuration
-	authSecret := association.ClearTextSecretKeySelector(logstash, logstashUserSuffix)
 	expectedESAssoc := &commonv1beta1.AssociationConf{
-		AuthSecretName: authSecret.Name,
-		AuthSecretKey:  authSecret.Key,
+		AuthSecretName: credentials.Selector.Name,
+		AuthSecretKey:  credentials.Selector.Key,
+		AuthMode:       logstash.Spec.AuthMode,
 		CACertProvided: caSecret.CACertProvided,
 		CASecretName:   caSecret.Name,
 		URL:            services.ExternalServiceURL(es),
@@ -301,15 +352,15 @@ func (r *ReconcileAssociation) reconcileInternal(logstash *lstype.Logstash) (com
 		log.Info("Updating Logstash spec with Elasticsearch backend configuration", "namespace", logstash.Namespace, "logstash_name", logstash.Name)
 		if err := association.UpdateAssociationConf(r.Client, logstash, expectedESAssoc); err != nil {
 			if errors.IsConflict(err) {
-				return commonv1beta1.AssociationPending, nil
+				return commonv1beta1.AssociationPending, association.Credentials{}, nil
 			}
 			log.Error(err, "Failed to update association configuration", "namespace", logstash.Namespace, "logstash_name", logstash.Name)
-			return commonv1beta1.AssociationPending, err
+			return commonv1beta1.AssociationPending, association.Credentials{}, err
 		}
 		logstash.SetAssociationConf(expectedESAssoc)
 	}
 
-	return commonv1beta1.AssociationEstablished, nil
+	return commonv1beta1.AssociationEstablished, credentials, nil
 }
 
 func (r *ReconcileAssociation) reconcileElasticsearchCA(logstash *lstype.Logstash, es types.NamespacedName) (association.CASecret, error) {
@@ -354,6 +405,10 @@ func deleteOrphanedResources(c k8s.Client, logstash *lstype.Logstash) error {
 	}
 
 	for _, s := range secrets.Items {
+		if isMonitoringResource(&s) {
+			// monitoring resources are garbage collected separately
+			continue
+		}
 		if metav1.IsControlledBy(&s, logstash) || hasBeenCreatedBy(&s, logstash) {
 			if !logstash.Spec.ElasticsearchRef.IsDefined() {
 				// look for association secrets owned by this logstash instance
@@ -0,0 +1,166 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstashassociation
+
+import (
+	"context"
+	"time"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	estype "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	esclient "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/client"
+	elasticsearchuser "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/user"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/configmap"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/management"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// logstashAPIKeySuffix is used to suffix the API key and associated secret resources.
+const logstashAPIKeySuffix = "logstash-api-key"
+
+// logstashAPIKeyRoleDescriptors returns the role descriptors limiting the privileges of the API key of the given
+// Logstash to what the elasticsearch outputs and the index bootstrap need, on the indices they write to.
+func logstashAPIKeyRoleDescriptors(logstash lstype.Logstash) map[string]esclient.RoleDescriptor {
+	return map[string]esclient.RoleDescriptor{
+		"logstash_writer": {
+			Cluster: []string{"monitor", "manage_index_templates", "manage_ilm"},
+			Indices: []esclient.IndicesPrivileges{{
+				Names:      configmap.OutputIndexPatterns(logstash),
+				Privileges: []string{"write", "create_index", "manage", "manage_ilm"},
+			}},
+		},
+	}
+}
+
+// credentialRotation returns the rotation policy of the credentials of the given Logstash, shared by all its users.
+func credentialRotation(logstash *lstype.Logstash) association.CredentialRotation {
+	return association.CredentialRotation{
+		Interval:  logstash.Spec.CredentialRotation.GetInterval(),
+		PodLabels: map[string]string{label.LogstashNameLabelName: logstash.Name},
+	}
+}
+
+// earliestRequeue returns the shortest of the given non-zero requeue delays, or zero if there is none.
+func earliestRequeue(requeues ...time.Duration) time.Duration {
+	var earliest time.Duration
+	for _, requeue := range requeues {
+		if requeue > 0 && (earliest == 0 || requeue < earliest) {
+			earliest = requeue
+		}
+	}
+	return earliest
+}
+
+// reconcileAuth reconciles the credentials Logstash uses to authenticate to the given Elasticsearch cluster, a user
+// or an API key depending on the auth mode, and deletes the credentials of the other mode.
+// The credentials are rotated with the rotation policy of Logstash.
+func (r *ReconcileAssociation) reconcileAuth(
+	logstash *lstype.Logstash,
+	es estype.Elasticsearch,
+) (association.Credentials, error) {
+	labels := map[string]string{
+		AssociationLabelName:      logstash.Name,
+		AssociationLabelNamespace: logstash.Namespace,
+	}
+	newESClient := func() (esclient.Client, error) {
+		return association.NewElasticsearchClient(r.Client, r.Dialer, es)
+	}
+	rotation := credentialRotation(logstash)
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+
+	if logstash.Spec.AuthMode != commonv1beta1.APIKeyAuthMode {
+		if err := association.DeleteAPIKey(ctx, r.Client, newESClient, logstash, logstashAPIKeySuffix); err != nil {
+			return association.Credentials{}, err
+		}
+		return association.ReconcileRotatedEsUser(
+			r.Client,
+			r.scheme,
+			logstash,
+			labels,
+			elasticsearchuser.LogstashAdminUserBuiltinRole,
+			logstashUserSuffix,
+			es,
+			rotation,
+			time.Now(),
+		)
+	}
+
+	credentials, err := association.ReconcileAPIKey(ctx, r.Client, r.scheme, newESClient, association.APIKeyParams{
+		Associated:      logstash,
+		Elasticsearch:   k8s.ExtractNamespacedName(&es),
+		Labels:          labels,
+		Suffix:          logstashAPIKeySuffix,
+		RoleDescriptors: logstashAPIKeyRoleDescriptors(*logstash),
+		Expiration:      commonv1beta1.APIKeyExpirationOrDefault(logstash.Spec.APIKeyExpiration),
+		Rotation:        rotation,
+	}, time.Now())
+	if err != nil {
+		return association.Credentials{}, err
+	}
+	if err := deleteUser(r.Client, logstash, es.Namespace, logstashUserSuffix); err != nil {
+		return association.Credentials{}, err
+	}
+	return credentials, nil
+}
+
+// denyReference reports that the given Logstash is not allowed to reference the given Elasticsearch cluster, and
+// revokes the connection details and the credentials it may have been granted before.
+func (r *ReconcileAssociation) denyReference(logstash *lstype.Logstash, es estype.Elasticsearch) error {
+	r.recorder.Event(logstash, corev1.EventTypeWarning, events.EventAssociationDenied,
+		association.ReferenceDeniedError(es, logstash, logstash.Kind).Error())
+	if err := association.RemoveAssociationConf(r.Client, logstash); err != nil && !apierrors.IsConflict(err) {
+		return err
+	}
+	newESClient := func() (esclient.Client, error) {
+		return association.NewElasticsearchClient(r.Client, r.Dialer, es)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), esclient.DefaultReqTimeout)
+	defer cancel()
+	if err := association.DeleteAPIKey(ctx, r.Client, newESClient, logstash, logstashAPIKeySuffix); err != nil {
+		return err
+	}
+	for _, suffix := range []string{logstashUserSuffix, management.UserSuffix} {
+		if err := deleteUser(r.Client, logstash, es.Namespace, suffix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteUser deletes the users of the given Logstash with the given suffix in the given Elasticsearch namespace, and
+// the secret holding their passwords, if they exist.
+func deleteUser(c k8s.Client, logstash *lstype.Logstash, esNamespace string, userSuffix string) error {
+	keys := append(association.RotatedUserKeys(logstash, esNamespace, userSuffix), types.NamespacedName{
+		Namespace: logstash.Namespace,
+		Name:      association.ClearTextSecretKeySelector(logstash, userSuffix).Name,
+	})
+	for _, key := range keys {
+		var secret corev1.Secret
+		err := c.Get(key, &secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(&secret, logstash) && !hasBeenCreatedBy(&secret, logstash) {
+			continue
+		}
+		log.Info("Deleting secret", "namespace", secret.Namespace, "secret_name", secret.Name, "logstash_name", logstash.Name)
+		if err := c.Delete(&secret); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
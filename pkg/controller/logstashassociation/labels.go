@@ -18,6 +18,11 @@ const (
 	AssociationLabelName = "logstashassociation.k8s.elastic.co/name"
 	// AssociationLabelNamespace marks resources created by this controller for easier retrieval.
 	AssociationLabelNamespace = "logstashassociation.k8s.elastic.co/namespace"
+	// MonitoringLabelName marks resources created by this controller for the association with the monitoring
+	// Elasticsearch cluster.
+	MonitoringLabelName = "logstashassociation.k8s.elastic.co/monitoring"
+	// ManagementLabelName marks resources created by this controller for the central management of the pipelines.
+	ManagementLabelName = "logstashassociation.k8s.elastic.co/management"
 )
 
 // NewResourceSelector selects resources labeled as related to the named association.
@@ -48,3 +53,7 @@ func NewUserLabelSelector(
 			common.TypeLabelName:      user.UserType,
 		})
 }
+
+func isMonitoringResource(object metav1.Object) bool {
+	return object.GetLabels()[MonitoringLabelName] == "true"
+}
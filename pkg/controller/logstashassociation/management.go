@@ -0,0 +1,39 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstashassociation
+
+import (
+	"time"
+
+	estype "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	elasticsearchuser "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/user"
+	"github.com/cloudptio/logstash-operator/pkg/controller/logstash/management"
+)
+
+// reconcileManagementUser creates the user reading the centrally managed pipelines of the given Logstash in the given
+// Elasticsearch cluster, or deletes it if central management is disabled. Its password is rotated with the rotation
+// policy of Logstash.
+func (r *ReconcileAssociation) reconcileManagementUser(logstash *lstype.Logstash, es estype.Elasticsearch) (association.Credentials, error) {
+	if !logstash.Spec.CentralManagement.Enabled {
+		return association.Credentials{}, deleteUser(r.Client, logstash, es.Namespace, management.UserSuffix)
+	}
+	return association.ReconcileRotatedEsUser(
+		r.Client,
+		r.scheme,
+		logstash,
+		map[string]string{
+			AssociationLabelName:      logstash.Name,
+			AssociationLabelNamespace: logstash.Namespace,
+			ManagementLabelName:       "true",
+		},
+		elasticsearchuser.LogstashManagementUserBuiltinRole,
+		management.UserSuffix,
+		es,
+		credentialRotation(logstash),
+		time.Now(),
+	)
+}
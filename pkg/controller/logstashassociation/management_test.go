@@ -0,0 +1,65 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstashassociation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	commonuser "github.com/cloudptio/logstash-operator/pkg/controller/common/user"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_reconcileManagementUser(t *testing.T) {
+	s := setupScheme(t)
+	ls := logstashFixture
+	ls.Spec.CentralManagement.Enabled = true
+	userKey := types.NamespacedName{Namespace: esFixture.Namespace, Name: "default-logstash-foo-logstash-management-user"}
+	passwordKey := types.NamespacedName{Namespace: ls.Namespace, Name: "logstash-foo-logstash-management-user"}
+	// a secret with the same name not created for this Logstash must be left untouched
+	unrelated := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: userKey.Name}}
+	r := &ReconcileAssociation{Client: k8s.WrapClient(fake.NewFakeClientWithScheme(s, &ls, &unrelated)), scheme: s}
+
+	credentials, err := r.reconcileManagementUser(&ls, esFixture)
+	require.NoError(t, err)
+	require.Equal(t, userKey.Name, credentials.Selector.Key)
+	var user corev1.Secret
+	require.NoError(t, r.Get(userKey, &user))
+	require.Equal(t, "logstash_admin", string(user.Data[commonuser.UserRoles]))
+	require.Equal(t, "true", user.Labels[ManagementLabelName])
+	var password corev1.Secret
+	require.NoError(t, r.Get(passwordKey, &password))
+	require.NotEmpty(t, password.Data[userKey.Name])
+
+	// the password is rotated with the rotation policy of Logstash
+	ls.Spec.CredentialRotation.Interval = &metav1.Duration{Duration: time.Hour}
+	password.Annotations[association.CredentialsIssuedAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	require.NoError(t, r.Update(&password))
+	credentials, err = r.reconcileManagementUser(&ls, esFixture)
+	require.NoError(t, err)
+	require.Equal(t, userKey.Name+"-alt", credentials.Selector.Key)
+	require.NotZero(t, credentials.Requeue)
+	require.NoError(t, r.Get(types.NamespacedName{Namespace: userKey.Namespace, Name: userKey.Name + "-alt"}, &corev1.Secret{}))
+
+	ls.Spec.CentralManagement.Enabled = false
+	_, err = r.reconcileManagementUser(&ls, esFixture)
+	require.NoError(t, err)
+	require.True(t, apierrors.IsNotFound(r.Get(userKey, &corev1.Secret{})))
+	require.True(t, apierrors.IsNotFound(r.Get(types.NamespacedName{Namespace: userKey.Namespace, Name: userKey.Name + "-alt"}, &corev1.Secret{})))
+	require.True(t, apierrors.IsNotFound(r.Get(passwordKey, &corev1.Secret{})))
+
+	otherES := esFixture
+	otherES.Namespace = "other"
+	_, err = r.reconcileManagementUser(&ls, otherES)
+	require.NoError(t, err)
+	require.NoError(t, r.Get(types.NamespacedName{Namespace: "other", Name: userKey.Name}, &corev1.Secret{}))
+}
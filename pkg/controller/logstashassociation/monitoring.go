@@ -0,0 +1,235 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstashassociation
+
+import (
+	"reflect"
+	"time"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	estype "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/annotation"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/certificates/http"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/events"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/user"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/watches"
+	esname "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/name"
+	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/services"
+	elasticsearchuser "github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/user"
+	lslabel "github.com/cloudptio/logstash-operator/pkg/controller/logstash/label"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// monitoringUserSuffix is used to suffix the monitoring user and associated secret resources.
+	monitoringUserSuffix = "logstash-monitoring-user"
+	// MonitoringCASecretSuffix is used as suffix for the monitoring Elasticsearch CA secret name.
+	MonitoringCASecretSuffix = "ls-monitoring-es-ca" // nolint
+)
+
+// reconcileMonitoring associates the Logstash resource with the Elasticsearch cluster its monitoring data is shipped
+// to: it creates the monitoring user, copies the monitoring cluster CA in the Logstash namespace and stores the
+// connection details in the monitoring association annotation. The password of the monitoring user is rotated with the
+// rotation policy of Logstash.
+func (r *ReconcileAssociation) reconcileMonitoring(logstash *lstype.Logstash) (commonv1beta1.AssociationStatus, association.Credentials, error) {
+	logstashKey := k8s.ExtractNamespacedName(logstash)
+
+	// garbage collect leftover resources that are not required anymore
+	if err := deleteOrphanedMonitoringResources(r, logstash); err != nil {
+		log.Error(err, "Error while trying to delete orphaned monitoring resources. Continuing.", "namespace", logstash.Namespace, "logstash_name", logstash.Name)
+	}
+
+	if !logstash.Spec.Monitoring.IsEnabled() {
+		// stop watching any monitoring cluster previously referenced for this Logstash resource
+		removeMonitoringWatches(logstashKey, r.watches)
+		return commonv1beta1.AssociationUnknown, association.Credentials{}, r.removeMonitoringAssociationConf(logstash)
+	}
+
+	esRef := logstash.Spec.Monitoring.ElasticsearchRef
+	if esRef.Namespace == "" {
+		// no namespace provided: default to Logstash's namespace
+		esRef.Namespace = logstash.Namespace
+	}
+	esRefKey := esRef.NamespacedName()
+
+	// watch the referenced monitoring cluster and the monitoring user for future reconciliations
+	if err := r.watches.ElasticsearchClusters.AddHandler(watches.NamedWatch{
+		Name:    monitoringWatchName(logstashKey),
+		Watched: []types.NamespacedName{esRefKey},
+		Watcher: logstashKey,
+	}); err != nil {
+		return commonv1beta1.AssociationFailed, association.Credentials{}, err
+	}
+	if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
+		Name:    monitoringWatchName(logstashKey),
+		Watched: association.RotatedUserKeys(logstash, esRef.Namespace, monitoringUserSuffix),
+		Watcher: logstashKey,
+	}); err != nil {
+		return commonv1beta1.AssociationFailed, association.Credentials{}, err
+	}
+
+	var es estype.Elasticsearch
+	if err := r.Get(esRefKey, &es); err != nil {
+		k8s.EmitErrorEvent(r.recorder, err, logstash, events.EventAssociationError, "Failed to find referenced monitoring cluster %s: %v", esRefKey, err)
+		if apierrors.IsNotFound(err) {
+			// not created yet or deleted: stop shipping monitoring data until it exists
+			return commonv1beta1.AssociationPending, association.Credentials{}, r.removeMonitoringAssociationConf(logstash)
+		}
+		return commonv1beta1.AssociationFailed, association.Credentials{}, err
+	}
+
+	if !association.IsReferenceAllowed(es, logstash, logstash.Kind) {
+		r.recorder.Event(logstash, corev1.EventTypeWarning, events.EventAssociationDenied,
+			association.ReferenceDeniedError(es, logstash, logstash.Kind).Error())
+		if err := r.removeMonitoringAssociationConf(logstash); err != nil {
+			return commonv1beta1.AssociationFailed, association.Credentials{}, err
+		}
+		return commonv1beta1.AssociationFailed, association.Credentials{}, deleteUser(r.Client, logstash, es.Namespace, monitoringUserSuffix)
+	}
+
+	credentials, err := association.ReconcileRotatedEsUser(
+		r.Client,
+		r.scheme,
+		logstash,
+		map[string]string{
+			AssociationLabelName:      logstash.Name,
+			AssociationLabelNamespace: logstash.Namespace,
+			MonitoringLabelName:       "true",
+		},
+		elasticsearchuser.LogstashMonitoringUserBuiltinRoles,
+		monitoringUserSuffix,
+		es,
+		credentialRotation(logstash),
+		time.Now(),
+	)
+	if err != nil {
+		return commonv1beta1.AssociationPending, association.Credentials{}, err
+	}
+
+	caSecret, err := r.reconcileMonitoringCA(logstash, esRefKey)
+	if err != nil {
+		return commonv1beta1.AssociationPending, association.Credentials{}, err
+	}
+
+	authSecret := credentials.Selector
+	expectedConf := &commonv1beta1.AssociationConf{
+		AuthSecretName: authSecret.Name,
+		AuthSecretKey:  authSecret.Key,
+		CACertProvided: caSecret.CACertProvided,
+		CASecretName:   caSecret.Name,
+		URL:            services.ExternalServiceURL(es),
+	}
+
+	if !reflect.DeepEqual(expectedConf, logstash.MonitoringAssociationConf()) {
+		log.Info("Updating Logstash monitoring configuration", "namespace", logstash.Namespace, "logstash_name", logstash.Name)
+		if err := association.UpdateAssociationConfAnnotation(
+			r.Client, logstash, annotation.MonitoringAssociationConfAnnotation, expectedConf,
+		); err != nil {
+			if apierrors.IsConflict(err) {
+				return commonv1beta1.AssociationPending, association.Credentials{}, nil
+			}
+			log.Error(err, "Failed to update monitoring association configuration", "namespace", logstash.Namespace, "logstash_name", logstash.Name)
+			return commonv1beta1.AssociationPending, association.Credentials{}, err
+		}
+		logstash.SetMonitoringAssociationConf(expectedConf)
+	}
+
+	return commonv1beta1.AssociationEstablished, credentials, nil
+}
+
+// removeMonitoringAssociationConf removes the monitoring association configuration, if any, so that Logstash stops
+// shipping monitoring data.
+func (r *ReconcileAssociation) removeMonitoringAssociationConf(logstash *lstype.Logstash) error {
+	if logstash.MonitoringAssociationConf() == nil {
+		return nil
+	}
+	err := association.RemoveAssociationConfAnnotation(r.Client, logstash, annotation.MonitoringAssociationConfAnnotation)
+	if err != nil && !apierrors.IsConflict(err) {
+		log.Error(err, "Failed to remove monitoring configuration from Logstash object",
+			"namespace", logstash.Namespace, "logstash_name", logstash.Name)
+		return err
+	}
+	logstash.SetMonitoringAssociationConf(nil)
+	return nil
+}
+
+func (r *ReconcileAssociation) reconcileMonitoringCA(logstash *lstype.Logstash, es types.NamespacedName) (association.CASecret, error) {
+	logstashKey := k8s.ExtractNamespacedName(logstash)
+	// watch the monitoring cluster CA secret to reconcile on any change
+	if err := r.watches.Secrets.AddHandler(watches.NamedWatch{
+		Name:    monitoringCAWatchName(logstashKey),
+		Watched: []types.NamespacedName{http.PublicCertsSecretRef(esname.ESNamer, es)},
+		Watcher: logstashKey,
+	}); err != nil {
+		return association.CASecret{}, err
+	}
+	labels := lslabel.NewLabels(logstash.Name)
+	labels[AssociationLabelName] = logstash.Name
+	labels[MonitoringLabelName] = "true"
+	return association.ReconcileCASecret(
+		r.Client,
+		r.scheme,
+		logstash,
+		es,
+		labels,
+		MonitoringCASecretSuffix,
+	)
+}
+
+// deleteOrphanedMonitoringResources deletes the monitoring resources that are not required anymore, because the
+// monitoring cluster reference was removed or moved to another namespace, including the monitoring users in the
+// namespace of the monitoring cluster.
+func deleteOrphanedMonitoringResources(c k8s.Client, logstash *lstype.Logstash) error {
+	var secrets corev1.SecretList
+	matchLabels := client.MatchingLabels(map[string]string{
+		AssociationLabelName: logstash.Name,
+		MonitoringLabelName:  "true",
+	})
+	if err := c.List(&secrets, client.InNamespace(logstash.Namespace), matchLabels); err != nil {
+		return err
+	}
+	// the monitoring users live in the namespace of the monitoring cluster, which may not be the Logstash one
+	var users corev1.SecretList
+	if err := c.List(&users, client.MatchingLabels(map[string]string{
+		AssociationLabelName:      logstash.Name,
+		AssociationLabelNamespace: logstash.Namespace,
+		MonitoringLabelName:       "true",
+		common.TypeLabelName:      user.UserType,
+	})); err != nil {
+		return err
+	}
+	for _, u := range users.Items {
+		if u.Namespace != logstash.Namespace {
+			secrets.Items = append(secrets.Items, u)
+		}
+	}
+
+	esRefNamespace := logstash.Spec.Monitoring.ElasticsearchRef.Namespace
+	if esRefNamespace == "" {
+		esRefNamespace = logstash.Namespace
+	}
+
+	for _, s := range secrets.Items {
+		if !metav1.IsControlledBy(&s, logstash) && !hasBeenCreatedBy(&s, logstash) {
+			continue
+		}
+		isStaleUser := s.Labels[common.TypeLabelName] == user.UserType && esRefNamespace != s.Namespace
+		if !logstash.Spec.Monitoring.IsEnabled() || isStaleUser {
+			log.Info("Deleting secret", "namespace", s.Namespace, "secret_name", s.Name, "logstash_name", logstash.Name)
+			if err := c.Delete(&s); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package logstashassociation
+
+import (
+	"testing"
+
+	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
+	lstype "github.com/cloudptio/logstash-operator/pkg/apis/logstash/v1beta1"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/user"
+	"github.com/cloudptio/logstash-operator/pkg/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_deleteOrphanedMonitoringResources(t *testing.T) {
+	s := setupScheme(t)
+	monitoringCASecretName := association.ElasticsearchCACertSecretName(&logstashFixture, MonitoringCASecretSuffix)
+	monitoringUserKey := association.UserKeyInNamespace(&logstashFixture, "monitoring-ns", monitoringUserSuffix)
+	secretFixtures := func() []corev1.Secret {
+		return []corev1.Secret{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            monitoringCASecretName,
+					Namespace:       logstashFixture.Namespace,
+					Labels:          map[string]string{AssociationLabelName: logstashFixture.Name, MonitoringLabelName: "true"},
+					OwnerReferences: []metav1.OwnerReference{ownerRefFixture},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            association.ElasticsearchCACertSecretName(&logstashFixture, ElasticsearchCASecretSuffix),
+					Namespace:       logstashFixture.Namespace,
+					Labels:          map[string]string{AssociationLabelName: logstashFixture.Name},
+					OwnerReferences: []metav1.OwnerReference{ownerRefFixture},
+				},
+			},
+			{
+				// the monitoring user in the namespace of the monitoring cluster
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      monitoringUserKey.Name,
+					Namespace: monitoringUserKey.Namespace,
+					Labels: map[string]string{
+						AssociationLabelName:      logstashFixture.Name,
+						AssociationLabelNamespace: logstashFixture.Namespace,
+						MonitoringLabelName:       "true",
+						common.TypeLabelName:      user.UserType,
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name                  string
+		monitoring            lstype.MonitoringSpec
+		wantMonitoringSecrets bool
+		wantMonitoringUser    bool
+	}{
+		{
+			name: "monitoring enabled",
+			monitoring: lstype.MonitoringSpec{
+				ElasticsearchRef: commonv1beta1.ObjectSelector{Name: "monitoring", Namespace: "monitoring-ns"},
+			},
+			wantMonitoringSecrets: true,
+			wantMonitoringUser:    true,
+		},
+		{
+			name:                  "monitoring cluster moved to another namespace",
+			monitoring:            lstype.MonitoringSpec{ElasticsearchRef: commonv1beta1.ObjectSelector{Name: "monitoring"}},
+			wantMonitoringSecrets: true,
+			wantMonitoringUser:    false,
+		},
+		{
+			name:                  "monitoring disabled",
+			wantMonitoringSecrets: false,
+			wantMonitoringUser:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := logstashFixture
+			ls.Spec.Monitoring = tt.monitoring
+			secrets := secretFixtures()
+			c := k8s.WrapClient(fake.NewFakeClientWithScheme(s, &secrets[0], &secrets[1], &secrets[2]))
+
+			require.NoError(t, deleteOrphanedMonitoringResources(c, &ls))
+
+			err := c.Get(types.NamespacedName{Namespace: ls.Namespace, Name: monitoringCASecretName}, &corev1.Secret{})
+			if tt.wantMonitoringSecrets {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+			err = c.Get(monitoringUserKey, &corev1.Secret{})
+			if tt.wantMonitoringUser {
+				require.NoError(t, err)
+			} else {
+				require.True(t, apierrors.IsNotFound(err))
+			}
+		})
+	}
+}
+
+func Test_deleteOrphanedResources_KeepsMonitoringResources(t *testing.T) {
+	s := setupScheme(t)
+	ls := lstype.Logstash{
+		ObjectMeta: logstashFixtureObjectMeta,
+		Spec: lstype.LogstashSpec{
+			Monitoring: lstype.MonitoringSpec{ElasticsearchRef: commonv1beta1.ObjectSelector{Name: "monitoring"}},
+		},
+	}
+	monitoringCASecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            association.ElasticsearchCACertSecretName(&ls, MonitoringCASecretSuffix),
+			Namespace:       ls.Namespace,
+			Labels:          map[string]string{AssociationLabelName: ls.Name, MonitoringLabelName: "true"},
+			OwnerReferences: []metav1.OwnerReference{ownerRefFixture},
+		},
+	}
+	c := k8s.WrapClient(fake.NewFakeClientWithScheme(s, &monitoringCASecret))
+
+	// no Elasticsearch output is referenced, but the monitoring cluster still is
+	require.NoError(t, deleteOrphanedResources(c, &ls))
+	require.NoError(t, c.Get(k8s.ExtractNamespacedName(&monitoringCASecret), &corev1.Secret{}))
+}
@@ -54,6 +54,24 @@ func esCAWatchName(logstash types.NamespacedName) string {
 	return logstash.Namespace + "-" + logstash.Name + "-ca-watch"
 }
 
+// monitoringWatchName returns the name of the watches setup on the monitoring Elasticsearch cluster and user
+// for a given Logstash resource.
+func monitoringWatchName(logstashKey types.NamespacedName) string {
+	return logstashKey.Namespace + "-" + logstashKey.Name + "-monitoring-es-watch"
+}
+
+// monitoringCAWatchName returns the name of the watch setup on the monitoring Elasticsearch CA secret
+func monitoringCAWatchName(logstashKey types.NamespacedName) string {
+	return logstashKey.Namespace + "-" + logstashKey.Name + "-monitoring-ca-watch"
+}
+
+// removeMonitoringWatches removes the watches setup for the monitoring Elasticsearch cluster of a given Logstash resource.
+func removeMonitoringWatches(logstashKey types.NamespacedName, w watches.DynamicWatches) {
+	w.ElasticsearchClusters.RemoveHandlerForKey(monitoringWatchName(logstashKey))
+	w.Secrets.RemoveHandlerForKey(monitoringWatchName(logstashKey))
+	w.Secrets.RemoveHandlerForKey(monitoringCAWatchName(logstashKey))
+}
+
 // watchFinalizer ensure that we remove watches for Elasticsearch clusters that we are no longer interested in
 // because not referenced by any Logstash resource.
 func watchFinalizer(logstashKey types.NamespacedName, w watches.DynamicWatches) finalizer.Finalizer {
@@ -62,6 +80,7 @@ func watchFinalizer(logstashKey types.NamespacedName, w watches.DynamicWatches)
 		Execute: func() error {
 			w.ElasticsearchClusters.RemoveHandlerForKey(elasticsearchWatchName(logstashKey))
 			w.Secrets.RemoveHandlerForKey(esCAWatchName(logstashKey))
+			removeMonitoringWatches(logstashKey, w)
 			return nil
 		},
 	}
@@ -34,6 +34,7 @@ func TestCrossNSAssociation(t *testing.T) {
 
 	esBuilder := elasticsearch.NewBuilder(name).
 		WithNamespace(esNamespace).
+		WithAllowedReferences("ApmServer/"+apmNamespace).
 		WithESMasterDataNodes(1, elasticsearch.DefaultResources).
 		WithRestrictedSecurityContext()
 	apmBuilder := apmserver.NewBuilder(name).
@@ -5,9 +5,12 @@
 package elasticsearch
 
 import (
+	"strings"
+
 	commonv1beta1 "github.com/cloudptio/logstash-operator/pkg/apis/common/v1beta1"
 	estype "github.com/cloudptio/logstash-operator/pkg/apis/elasticsearch/v1beta1"
 	"github.com/cloudptio/logstash-operator/pkg/controller/common"
+	"github.com/cloudptio/logstash-operator/pkg/controller/common/association"
 	"github.com/cloudptio/logstash-operator/pkg/controller/elasticsearch/volume"
 	"github.com/cloudptio/logstash-operator/test/e2e/test"
 	corev1 "k8s.io/api/core/v1"
@@ -90,6 +93,15 @@ func (b Builder) WithNamespace(namespace string) Builder {
 	return b
 }
 
+// WithAllowedReferences allows the given associated objects of other namespaces to reference the Elasticsearch cluster.
+func (b Builder) WithAllowedReferences(references ...string) Builder {
+	if b.Elasticsearch.Annotations == nil {
+		b.Elasticsearch.Annotations = make(map[string]string)
+	}
+	b.Elasticsearch.Annotations[association.AllowedReferencesAnnotation] = strings.Join(references, ",")
+	return b
+}
+
 func (b Builder) WithVersion(version string) Builder {
 	b.Elasticsearch.Spec.Version = version
 	return b
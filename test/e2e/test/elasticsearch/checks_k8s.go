@@ -201,6 +201,7 @@ func CheckServices(b Builder, k *test.K8sClient) test.Step {
 		Test: test.Eventually(func() error {
 			for _, s := range []string{
 				esname.HTTPService(b.Elasticsearch.Name),
+				esname.TransportService(b.Elasticsearch.Name),
 			} {
 				if _, err := k.GetService(b.Elasticsearch.Namespace, s); err != nil {
 					return err
@@ -217,7 +218,8 @@ func CheckServicesEndpoints(b Builder, k *test.K8sClient) test.Step {
 		Name: "ES services should have endpoints",
 		Test: test.Eventually(func() error {
 			for endpointName, addrCount := range map[string]int{
-				esname.HTTPService(b.Elasticsearch.Name): int(b.Elasticsearch.Spec.NodeCount()),
+				esname.HTTPService(b.Elasticsearch.Name):      int(b.Elasticsearch.Spec.NodeCount()),
+				esname.TransportService(b.Elasticsearch.Name): int(b.Elasticsearch.Spec.NodeCount()),
 			} {
 				if addrCount == 0 {
 					continue // maybe no Kibana